@@ -0,0 +1,23 @@
+// Package extractor registers the Datadog scraper sources with the relationship processor
+package extractor
+
+import (
+	ddShared "bacon/src/plugins/datadog/shared"
+	"bacon/src/shared/relationship-finding/extractors"
+)
+
+func init() {
+	extractors.MustRegister(
+		// Catalog definitions are curated by the owning teams themselves
+		extractors.Registration{Source: ddShared.SourceDatadogServiceCatalog, Extractor: extractors.Edges, Weight: 0.8, Priority: 3},
+		extractors.Registration{Source: ddShared.SourceDatadogAPM, Extractor: extractors.Edges, Weight: 0.7, Priority: 4},
+		extractors.Registration{Source: ddShared.SourceDatadogMonitors, Extractor: extractors.Edges, Weight: 0.7, Priority: 3},
+		extractors.Registration{Source: ddShared.SourceDatadogMetrics, Extractor: extractors.Edges, Weight: 0.5, Priority: 4},
+		extractors.Registration{Source: ddShared.SourceDatadogChanges, Extractor: extractors.ChangeEdges, Weight: 0.8, Priority: 3},
+		// Deletions only tombstone entities; they contribute no edges of their own
+		extractors.Registration{Source: ddShared.SourceDatadogDeletions, Weight: 1.0, Priority: 3},
+		// Identity directories only teach the processor the names teams and people go by
+		extractors.Registration{Source: ddShared.SourceDatadogUsers, Weight: 1.0, Priority: 3},
+		extractors.Registration{Source: ddShared.SourceDatadogTeams, Weight: 1.0, Priority: 3},
+	)
+}
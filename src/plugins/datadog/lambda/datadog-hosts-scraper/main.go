@@ -0,0 +1,249 @@
+// Package main implements the Datadog Hosts Scraper Lambda function.
+// This Lambda function derives infrastructure ownership from host and container tags using pure functional programming.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadog"
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV1"
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV2"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-xray-sdk-go/v2/xray"
+	"github.com/samber/lo"
+
+	"bacon/src/plugins/datadog/shared"
+	"bacon/src/plugins/datadog/types"
+)
+
+// HostsScraperHandler handles the Lambda invocation for hosts and containers scraping
+// Pure function that orchestrates the infrastructure tag collection pipeline
+func HostsScraperHandler(ctx context.Context, event types.ScraperEvent) (types.ScraperResponse, error) {
+	executionID := xray.TraceID(ctx)
+
+	return shared.WithTracedOperation(ctx, "hosts-scraper-handler", func(tracedCtx context.Context) (types.ScraperResponse, error) {
+		// Create Datadog client using pure function
+		client, err := shared.CreateDatadogClient()
+		if err != nil {
+			return createErrorResponse(executionID, fmt.Sprintf("Failed to create Datadog client: %v", err)), err
+		}
+
+		// Validate connection using pure function
+		if err := shared.ValidateDatadogConnection(tracedCtx, client); err != nil {
+			return createErrorResponse(executionID, fmt.Sprintf("Failed to validate Datadog connection: %v", err)), err
+		}
+
+		// Fetch hosts and containers using functional pipeline
+		hosts, err := fetchAllHosts(tracedCtx, client, event)
+		if err != nil {
+			return createErrorResponse(executionID, fmt.Sprintf("Failed to fetch hosts: %v", err)), err
+		}
+
+		containers, err := fetchAllContainers(tracedCtx, client, event)
+		if err != nil {
+			return createErrorResponse(executionID, fmt.Sprintf("Failed to fetch containers: %v", err)), err
+		}
+
+		// Transform API responses to internal types using pure functions
+		transformedContainers := lo.Filter(lo.Map(containers, shared.TransformContainer), func(container types.DatadogHost, _ int) bool {
+			return container.ID != ""
+		})
+		resources := append(lo.Map(hosts, shared.TransformHost), transformedContainers...)
+
+		// Derive ownership edges keyed by EC2 ARN or Kubernetes workload
+		relationships := shared.ExtractHostRelationships(resources)
+
+		response := createSuccessResponse(executionID, len(resources), createHostsMetadata(resources, relationships))
+		response.Outputs = []types.ScraperOutput{
+			shared.CreateRelationshipOutput(shared.SourceDatadogMetrics, relationships, shared.HostTagConfidence, time.Now()),
+		}
+		return response, nil
+	})
+}
+
+// fetchAllHosts fetches all hosts from the Datadog Hosts API with offset pagination
+// Pure functional approach to API data collection
+func fetchAllHosts(ctx context.Context, client *datadog.APIClient, event types.ScraperEvent) ([]datadogV1.Host, error) {
+	api := datadogV1.NewHostsApi(client)
+
+	var allHosts []datadogV1.Host
+	count := lo.Ternary(event.PageSize > 0, int64(event.PageSize), 1000)
+	var start int64 = 0
+
+	for {
+		opts := createHostsListOptions(count, start, event.FilterKeyword)
+
+		response, _, err := api.ListHosts(ctx, *opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list hosts: %w", err)
+		}
+
+		allHosts = append(allHosts, response.HostList...)
+
+		if len(response.HostList) < int(count) {
+			break
+		}
+
+		start += count
+	}
+
+	return allHosts, nil
+}
+
+// fetchAllContainers fetches all containers from the Datadog Containers API with cursor pagination
+// Pure functional approach to API data collection
+func fetchAllContainers(ctx context.Context, client *datadog.APIClient, event types.ScraperEvent) ([]datadogV2.ContainerItem, error) {
+	api := datadogV2.NewContainersApi(client)
+
+	var allContainers []datadogV2.ContainerItem
+	pageSize := lo.Ternary(event.PageSize > 0, int32(event.PageSize), 1000)
+	cursor := ""
+
+	for {
+		opts := createContainersListOptions(pageSize, cursor)
+
+		response, _, err := api.ListContainers(ctx, *opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list containers: %w", err)
+		}
+
+		allContainers = append(allContainers, response.Data...)
+
+		cursor = extractNextContainerCursor(response.Meta)
+		if cursor == "" || len(response.Data) == 0 {
+			break
+		}
+	}
+
+	return allContainers, nil
+}
+
+// createHostsListOptions creates host request options using pure function
+func createHostsListOptions(count int64, start int64, filterKeyword string) *datadogV1.ListHostsOptionalParameters {
+	opts := datadogV1.NewListHostsOptionalParameters().
+		WithCount(count).
+		WithStart(start)
+
+	if filterKeyword != "" {
+		opts = opts.WithFilter(filterKeyword)
+	}
+
+	return opts
+}
+
+// createContainersListOptions creates container request options using pure function
+func createContainersListOptions(pageSize int32, cursor string) *datadogV2.ListContainersOptionalParameters {
+	opts := datadogV2.NewListContainersOptionalParameters().
+		WithPageSize(pageSize)
+
+	if cursor != "" {
+		opts = opts.WithPageCursor(cursor)
+	}
+
+	return opts
+}
+
+// extractNextContainerCursor extracts the next page cursor using pure function
+func extractNextContainerCursor(meta *datadogV2.ContainerMeta) string {
+	if meta == nil || meta.Pagination == nil {
+		return ""
+	}
+	return lo.FromPtrOr(meta.Pagination.NextCursor, "")
+}
+
+// createHostsMetadata creates metadata for response using pure function
+func createHostsMetadata(resources []types.DatadogHost, relationships []types.DatadogRelationship) map[string]interface{} {
+	kindCounts := lo.CountValuesBy(resources, func(resource types.DatadogHost) string {
+		return resource.Kind
+	})
+
+	envCounts := lo.CountValuesBy(resources, func(resource types.DatadogHost) string {
+		return lo.Ternary(resource.Env != "", resource.Env, "unknown")
+	})
+
+	keyed := lo.CountBy(resources, func(resource types.DatadogHost) bool {
+		return resource.ResourceKey != ""
+	})
+
+	owned := lo.CountBy(resources, func(resource types.DatadogHost) bool {
+		return len(resource.Teams) > 0 || len(resource.Owners) > 0
+	})
+
+	return map[string]interface{}{
+		"hosts_fetched":         kindCounts[shared.HostKindHost],
+		"containers_fetched":    kindCounts[shared.HostKindContainer],
+		"keyed_resources":       keyed,
+		"owned_resources":       owned,
+		"env_distribution":      envCounts,
+		"relationships_emitted": len(relationships),
+		"functional_pipeline":   true,
+	}
+}
+
+// createSuccessResponse creates a success response using pure function
+func createSuccessResponse(executionID string, count int, metadata map[string]interface{}) types.ScraperResponse {
+	return types.ScraperResponse{
+		Status:      "success",
+		Message:     fmt.Sprintf("Successfully scraped %d hosts and containers", count),
+		Count:       count,
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		ExecutionID: executionID,
+		Metadata:    metadata,
+	}
+}
+
+// createErrorResponse creates an error response using pure function
+func createErrorResponse(executionID, message string) types.ScraperResponse {
+	return types.ScraperResponse{
+		Status:      "error",
+		Message:     message,
+		Count:       0,
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		ExecutionID: executionID,
+		Metadata: map[string]interface{}{
+			"error": true,
+		},
+	}
+}
+
+// validateEvent validates the input event using functional approach
+func validateEvent(event types.ScraperEvent) error {
+	validationRules := []func(types.ScraperEvent) bool{
+		func(e types.ScraperEvent) bool { return e.PageSize >= 0 },
+		func(e types.ScraperEvent) bool { return e.PageSize <= 1000 },
+	}
+
+	isValid := lo.EveryBy(validationRules, func(rule func(types.ScraperEvent) bool) bool {
+		return rule(event)
+	})
+
+	if !isValid {
+		return fmt.Errorf("invalid event parameters")
+	}
+
+	return nil
+}
+
+// main function initializes the Lambda handler
+func main() {
+	// Wrapper function to add event validation
+	handlerWithValidation := func(ctx context.Context, event json.RawMessage) (types.ScraperResponse, error) {
+		var scraperEvent types.ScraperEvent
+		if err := json.Unmarshal(event, &scraperEvent); err != nil {
+			executionID := xray.TraceID(ctx)
+			return createErrorResponse(executionID, fmt.Sprintf("Failed to parse event: %v", err)), err
+		}
+
+		if err := validateEvent(scraperEvent); err != nil {
+			executionID := xray.TraceID(ctx)
+			return createErrorResponse(executionID, fmt.Sprintf("Event validation failed: %v", err)), err
+		}
+
+		return HostsScraperHandler(ctx, scraperEvent)
+	}
+
+	lambda.Start(handlerWithValidation)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadog"
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bacon/src/plugins/datadog/shared"
+	"bacon/src/plugins/datadog/types"
+)
+
+// newHostsTestClient creates a Datadog client pointed at a stub API server
+func newHostsTestClient(t *testing.T, handler http.Handler) *datadog.APIClient {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	configuration := datadog.NewConfiguration()
+	configuration.Host = serverURL.Host
+	configuration.Scheme = serverURL.Scheme
+	configuration.RetryConfiguration.EnableRetry = false
+
+	return datadog.NewAPIClient(configuration)
+}
+
+// Test host pagination advances by start offset
+func TestFetchAllHosts(t *testing.T) {
+	var starts []string
+	client := newHostsTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v1/hosts", r.URL.Path)
+		starts = append(starts, r.URL.Query().Get("start"))
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("start") == "0" {
+			fmt.Fprint(w, `{"host_list": [{"id": 1, "host_name": "a"}, {"id": 2, "host_name": "b"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"host_list": []}`)
+	}))
+
+	hosts, err := fetchAllHosts(context.Background(), client, types.ScraperEvent{PageSize: 2})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0", "2"}, starts)
+	assert.Len(t, hosts, 2)
+}
+
+// Test container pagination follows the next cursor
+func TestFetchAllContainers(t *testing.T) {
+	var cursors []string
+	client := newHostsTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v2/containers", r.URL.Path)
+		cursor := r.URL.Query().Get("page[cursor]")
+		cursors = append(cursors, cursor)
+
+		w.Header().Set("Content-Type", "application/json")
+		if cursor == "" {
+			fmt.Fprint(w, `{"data": [{"type": "container", "id": "c-1", "attributes": {"name": "web"}}], "meta": {"pagination": {"next_cursor": "next"}}}`)
+			return
+		}
+		fmt.Fprint(w, `{"data": [{"type": "container", "id": "c-2", "attributes": {"name": "worker"}}], "meta": {"pagination": {}}}`)
+	}))
+
+	containers, err := fetchAllContainers(context.Background(), client, types.ScraperEvent{})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "next"}, cursors)
+	require.Len(t, containers, 2)
+	assert.Equal(t, "c-2", lo.FromPtr(containers[1].Container.Id))
+}
+
+// Test API errors are surfaced
+func TestFetchAllHosts_Error(t *testing.T) {
+	client := newHostsTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+
+	_, err := fetchAllHosts(context.Background(), client, types.ScraperEvent{})
+	assert.Error(t, err)
+}
+
+// Test cursor extraction handles missing metadata
+func TestExtractNextContainerCursor(t *testing.T) {
+	assert.Equal(t, "", extractNextContainerCursor(nil))
+	assert.Equal(t, "", extractNextContainerCursor(&datadogV2.ContainerMeta{}))
+	assert.Equal(t, "abc", extractNextContainerCursor(&datadogV2.ContainerMeta{
+		Pagination: &datadogV2.ContainerMetaPage{NextCursor: lo.ToPtr("abc")},
+	}))
+}
+
+// Test metadata aggregation for hosts and containers
+func TestCreateHostsMetadata(t *testing.T) {
+	resources := []types.DatadogHost{
+		{Kind: shared.HostKindHost, ResourceKey: "arn:aws:ec2:us-east-1:1:instance/i-1", Env: "prod", Teams: []string{"payments"}},
+		{Kind: shared.HostKindContainer, ResourceKey: "Deployment/web"},
+		{Kind: shared.HostKindHost},
+	}
+
+	metadata := createHostsMetadata(resources, nil)
+
+	assert.Equal(t, 2, metadata["hosts_fetched"])
+	assert.Equal(t, 1, metadata["containers_fetched"])
+	assert.Equal(t, 2, metadata["keyed_resources"])
+	assert.Equal(t, 1, metadata["owned_resources"])
+	assert.Equal(t, map[string]int{"prod": 1, "unknown": 2}, metadata["env_distribution"])
+}
+
+// Test event validation
+func TestValidateEvent(t *testing.T) {
+	assert.NoError(t, validateEvent(types.ScraperEvent{}))
+	assert.Error(t, validateEvent(types.ScraperEvent{PageSize: -1}))
+	assert.Error(t, validateEvent(types.ScraperEvent{PageSize: 1001}))
+}
@@ -0,0 +1,241 @@
+// Package main implements the Datadog Monitors Scraper Lambda function.
+// This Lambda function derives ownership from monitor and SLO alert routing using pure functional programming.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadog"
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV1"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-xray-sdk-go/v2/xray"
+	"github.com/samber/lo"
+
+	"bacon/src/plugins/datadog/shared"
+	"bacon/src/plugins/datadog/types"
+)
+
+// monitorsSourceConfidence is the base confidence of monitor routing; each edge carries its signal confidence
+const monitorsSourceConfidence = 1.0
+
+// MonitorsScraperHandler handles the Lambda invocation for monitors and SLOs scraping
+// Pure function that orchestrates the alert routing collection pipeline
+func MonitorsScraperHandler(ctx context.Context, event types.ScraperEvent) (types.ScraperResponse, error) {
+	executionID := xray.TraceID(ctx)
+
+	return shared.WithTracedOperation(ctx, "monitors-scraper-handler", func(tracedCtx context.Context) (types.ScraperResponse, error) {
+		// Create Datadog client using pure function
+		client, err := shared.CreateDatadogClient()
+		if err != nil {
+			return createErrorResponse(executionID, fmt.Sprintf("Failed to create Datadog client: %v", err)), err
+		}
+
+		// Validate connection using pure function
+		if err := shared.ValidateDatadogConnection(tracedCtx, client); err != nil {
+			return createErrorResponse(executionID, fmt.Sprintf("Failed to validate Datadog connection: %v", err)), err
+		}
+
+		// Fetch monitors and SLOs using functional pipeline
+		monitors, err := fetchAllMonitors(tracedCtx, client, event)
+		if err != nil {
+			return createErrorResponse(executionID, fmt.Sprintf("Failed to fetch monitors: %v", err)), err
+		}
+
+		slos, err := fetchAllSLOs(tracedCtx, client, event)
+		if err != nil {
+			return createErrorResponse(executionID, fmt.Sprintf("Failed to fetch SLOs: %v", err)), err
+		}
+
+		// Transform API responses to internal types using pure functions
+		alerting := append(lo.Map(monitors, shared.TransformMonitor), lo.Map(slos, shared.TransformSLO)...)
+
+		// Derive ownership edges from team tags and notification handles
+		relationships := shared.ExtractMonitorRelationships(alerting)
+
+		response := createSuccessResponse(executionID, len(alerting), createMonitorsMetadata(alerting, relationships))
+		response.Outputs = []types.ScraperOutput{
+			shared.CreateRelationshipOutput(shared.SourceDatadogMonitors, relationships, monitorsSourceConfidence, time.Now()),
+		}
+		return response, nil
+	})
+}
+
+// fetchAllMonitors fetches all monitors from the Datadog Monitors API with pagination
+// Pure functional approach to API data collection
+func fetchAllMonitors(ctx context.Context, client *datadog.APIClient, event types.ScraperEvent) ([]datadogV1.Monitor, error) {
+	api := datadogV1.NewMonitorsApi(client)
+
+	var allMonitors []datadogV1.Monitor
+	pageSize := lo.Ternary(event.PageSize > 0, int32(event.PageSize), 100)
+	var page int64 = 0
+
+	for {
+		opts := createMonitorsListOptions(pageSize, page, event.FilterKeyword)
+
+		response, _, err := api.ListMonitors(ctx, *opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list monitors: %w", err)
+		}
+
+		allMonitors = append(allMonitors, response...)
+
+		if len(response) < int(pageSize) {
+			break
+		}
+
+		page++
+	}
+
+	return allMonitors, nil
+}
+
+// fetchAllSLOs fetches all service level objectives with offset pagination
+// Pure functional approach to API data collection
+func fetchAllSLOs(ctx context.Context, client *datadog.APIClient, event types.ScraperEvent) ([]datadogV1.ServiceLevelObjective, error) {
+	api := datadogV1.NewServiceLevelObjectivesApi(client)
+
+	var allSLOs []datadogV1.ServiceLevelObjective
+	limit := lo.Ternary(event.PageSize > 0, int64(event.PageSize), 100)
+	var offset int64 = 0
+
+	for {
+		opts := createSLOsListOptions(limit, offset, event.FilterKeyword)
+
+		response, _, err := api.ListSLOs(ctx, *opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list SLOs: %w", err)
+		}
+
+		allSLOs = append(allSLOs, response.Data...)
+
+		if len(response.Data) < int(limit) {
+			break
+		}
+
+		offset += limit
+	}
+
+	return allSLOs, nil
+}
+
+// createMonitorsListOptions creates monitor request options using pure function
+func createMonitorsListOptions(pageSize int32, page int64, filterKeyword string) *datadogV1.ListMonitorsOptionalParameters {
+	opts := datadogV1.NewListMonitorsOptionalParameters().
+		WithPageSize(pageSize).
+		WithPage(page)
+
+	if filterKeyword != "" {
+		opts = opts.WithName(filterKeyword)
+	}
+
+	return opts
+}
+
+// createSLOsListOptions creates SLO request options using pure function
+func createSLOsListOptions(limit int64, offset int64, filterKeyword string) *datadogV1.ListSLOsOptionalParameters {
+	opts := datadogV1.NewListSLOsOptionalParameters().
+		WithLimit(limit).
+		WithOffset(offset)
+
+	if filterKeyword != "" {
+		opts = opts.WithQuery(filterKeyword)
+	}
+
+	return opts
+}
+
+// createMonitorsMetadata creates metadata for response using pure function
+func createMonitorsMetadata(alerting []types.DatadogMonitor, relationships []types.DatadogRelationship) map[string]interface{} {
+	kindCounts := lo.CountValuesBy(alerting, func(monitor types.DatadogMonitor) string {
+		return monitor.Kind
+	})
+
+	handleCounts := lo.CountValuesBy(lo.FlatMap(alerting, func(monitor types.DatadogMonitor, _ int) []types.DatadogNotificationHandle {
+		return monitor.Handles
+	}), func(handle types.DatadogNotificationHandle) string {
+		return handle.Type
+	})
+
+	unowned := lo.CountBy(alerting, func(monitor types.DatadogMonitor) bool {
+		return len(monitor.Teams) == 0 && len(monitor.Handles) == 0
+	})
+
+	return map[string]interface{}{
+		"monitors_fetched":         kindCounts[shared.MonitorKindMonitor],
+		"slos_fetched":             kindCounts[shared.MonitorKindSLO],
+		"unowned_monitors":         unowned,
+		"handle_type_distribution": handleCounts,
+		"relationships_emitted":    len(relationships),
+		"api_version":              "v1",
+		"functional_pipeline":      true,
+	}
+}
+
+// createSuccessResponse creates a success response using pure function
+func createSuccessResponse(executionID string, count int, metadata map[string]interface{}) types.ScraperResponse {
+	return types.ScraperResponse{
+		Status:      "success",
+		Message:     fmt.Sprintf("Successfully scraped %d monitors and SLOs", count),
+		Count:       count,
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		ExecutionID: executionID,
+		Metadata:    metadata,
+	}
+}
+
+// createErrorResponse creates an error response using pure function
+func createErrorResponse(executionID, message string) types.ScraperResponse {
+	return types.ScraperResponse{
+		Status:      "error",
+		Message:     message,
+		Count:       0,
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		ExecutionID: executionID,
+		Metadata: map[string]interface{}{
+			"error":       true,
+			"api_version": "v1",
+		},
+	}
+}
+
+// validateEvent validates the input event using functional approach
+func validateEvent(event types.ScraperEvent) error {
+	validationRules := []func(types.ScraperEvent) bool{
+		func(e types.ScraperEvent) bool { return e.PageSize >= 0 },
+		func(e types.ScraperEvent) bool { return e.PageSize <= 1000 },
+	}
+
+	isValid := lo.EveryBy(validationRules, func(rule func(types.ScraperEvent) bool) bool {
+		return rule(event)
+	})
+
+	if !isValid {
+		return fmt.Errorf("invalid event parameters")
+	}
+
+	return nil
+}
+
+// main function initializes the Lambda handler
+func main() {
+	// Wrapper function to add event validation
+	handlerWithValidation := func(ctx context.Context, event json.RawMessage) (types.ScraperResponse, error) {
+		var scraperEvent types.ScraperEvent
+		if err := json.Unmarshal(event, &scraperEvent); err != nil {
+			executionID := xray.TraceID(ctx)
+			return createErrorResponse(executionID, fmt.Sprintf("Failed to parse event: %v", err)), err
+		}
+
+		if err := validateEvent(scraperEvent); err != nil {
+			executionID := xray.TraceID(ctx)
+			return createErrorResponse(executionID, fmt.Sprintf("Event validation failed: %v", err)), err
+		}
+
+		return MonitorsScraperHandler(ctx, scraperEvent)
+	}
+
+	lambda.Start(handlerWithValidation)
+}
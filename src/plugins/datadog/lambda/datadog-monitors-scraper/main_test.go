@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bacon/src/plugins/datadog/shared"
+	"bacon/src/plugins/datadog/types"
+)
+
+// newMonitorsTestClient creates a Datadog client pointed at a stub API server
+func newMonitorsTestClient(t *testing.T, handler http.Handler) *datadog.APIClient {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	configuration := datadog.NewConfiguration()
+	configuration.Host = serverURL.Host
+	configuration.Scheme = serverURL.Scheme
+	configuration.RetryConfiguration.EnableRetry = false
+
+	return datadog.NewAPIClient(configuration)
+}
+
+// Test monitor pagination stops on a short page
+func TestFetchAllMonitors(t *testing.T) {
+	var requestedPages []string
+	client := newMonitorsTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v1/monitor", r.URL.Path)
+		page := r.URL.Query().Get("page")
+		requestedPages = append(requestedPages, page)
+
+		w.Header().Set("Content-Type", "application/json")
+		if page == "0" {
+			fmt.Fprint(w, `[{"id": 1, "query": "q", "type": "metric alert"}, {"id": 2, "query": "q", "type": "metric alert"}]`)
+			return
+		}
+		fmt.Fprint(w, `[{"id": 3, "query": "q", "type": "metric alert", "message": "@slack-ops", "tags": ["team:sre"]}]`)
+	}))
+
+	monitors, err := fetchAllMonitors(context.Background(), client, types.ScraperEvent{PageSize: 2})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0", "1"}, requestedPages)
+	require.Len(t, monitors, 3)
+	assert.Equal(t, []string{"team:sre"}, monitors[2].Tags)
+}
+
+// Test SLO pagination advances by offset
+func TestFetchAllSLOs(t *testing.T) {
+	var offsets []string
+	client := newMonitorsTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v1/slo", r.URL.Path)
+		offset := r.URL.Query().Get("offset")
+		offsets = append(offsets, offset)
+
+		w.Header().Set("Content-Type", "application/json")
+		if n, _ := strconv.Atoi(offset); n == 0 {
+			fmt.Fprint(w, `{"data": [{"id": "a", "name": "A", "type": "metric", "thresholds": []}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"data": []}`)
+	}))
+
+	slos, err := fetchAllSLOs(context.Background(), client, types.ScraperEvent{PageSize: 1})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0", "1"}, offsets)
+	require.Len(t, slos, 1)
+	assert.Equal(t, "A", slos[0].Name)
+}
+
+// Test API errors are surfaced
+func TestFetchAllMonitors_Error(t *testing.T) {
+	client := newMonitorsTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	_, err := fetchAllMonitors(context.Background(), client, types.ScraperEvent{})
+	assert.Error(t, err)
+}
+
+// Test metadata aggregation for monitors and SLOs
+func TestCreateMonitorsMetadata(t *testing.T) {
+	alerting := []types.DatadogMonitor{
+		{Kind: shared.MonitorKindMonitor, Teams: []string{"payments"}},
+		{Kind: shared.MonitorKindMonitor, Handles: []types.DatadogNotificationHandle{{Type: shared.HandleTypeSlack, Target: "ops"}}},
+		{Kind: shared.MonitorKindSLO},
+	}
+
+	metadata := createMonitorsMetadata(alerting, []types.DatadogRelationship{{From: "payments", To: "checkout", Type: "monitors"}})
+
+	assert.Equal(t, 2, metadata["monitors_fetched"])
+	assert.Equal(t, 1, metadata["slos_fetched"])
+	assert.Equal(t, 1, metadata["unowned_monitors"])
+	assert.Equal(t, map[string]int{"slack": 1}, metadata["handle_type_distribution"])
+	assert.Equal(t, 1, metadata["relationships_emitted"])
+}
+
+// Test event validation
+func TestValidateEvent(t *testing.T) {
+	assert.NoError(t, validateEvent(types.ScraperEvent{PageSize: 100}))
+	assert.NoError(t, validateEvent(types.ScraperEvent{}))
+	assert.Error(t, validateEvent(types.ScraperEvent{PageSize: -1}))
+	assert.Error(t, validateEvent(types.ScraperEvent{PageSize: 1001}))
+}
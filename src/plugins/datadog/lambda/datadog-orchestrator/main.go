@@ -0,0 +1,311 @@
+// Package main implements the Datadog Orchestrator Lambda function.
+// This Lambda function runs the teams, users, services and organizations scrapers in dependency order with one shared client using pure functional programming.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadog"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/aws/aws-xray-sdk-go/v2/xray"
+	"github.com/samber/lo"
+
+	"bacon/src/plugins/datadog/scrapers/organizations"
+	"bacon/src/plugins/datadog/scrapers/services"
+	"bacon/src/plugins/datadog/scrapers/teams"
+	"bacon/src/plugins/datadog/scrapers/users"
+	"bacon/src/plugins/datadog/shared"
+	"bacon/src/plugins/datadog/types"
+)
+
+// Job statuses reported per scraper
+const (
+	JobStatusSuccess = "success"
+	JobStatusError   = "error"
+	JobStatusSkipped = "skipped"
+)
+
+// Orchestration statuses aggregated over all jobs
+const (
+	OrchestrationStatusSuccess = "success"
+	OrchestrationStatusPartial = "partial"
+	OrchestrationStatusFailed  = "failed"
+)
+
+// scraperJob is a scraper run by the orchestrator once its dependencies have succeeded
+type scraperJob struct {
+	name      string
+	dependsOn []string
+	validate  func(types.ScraperEvent) error
+	scrape    func(context.Context, *datadog.APIClient, types.ScraperEvent) (types.ScraperResponse, error)
+}
+
+// defaultJobs returns the Datadog scrapers in dependency order
+// Users and services only need teams, so they run concurrently once teams has finished
+func defaultJobs() []scraperJob {
+	return []scraperJob{
+		{name: "teams", validate: teams.ValidateEvent, scrape: teams.Scrape},
+		{name: "users", dependsOn: []string{"teams"}, validate: users.ValidateEvent, scrape: users.Scrape},
+		{name: "services", dependsOn: []string{"teams"}, validate: services.ValidateEvent, scrape: services.Scrape},
+		{name: "organizations", dependsOn: []string{"teams", "users"}, validate: organizations.ValidateEvent, scrape: organizations.Scrape},
+	}
+}
+
+// OrchestratorHandler handles the Lambda invocation for orchestrating the Datadog scrapers
+// Creates and validates a single Datadog client that every scraper shares
+func OrchestratorHandler(ctx context.Context, event types.OrchestrationEvent) (types.OrchestrationResponse, error) {
+	executionARN := resolveExecutionARN(ctx)
+
+	return shared.WithTracedOperation(ctx, "orchestrator-handler", func(tracedCtx context.Context) (types.OrchestrationResponse, error) {
+		jobs, err := selectJobs(defaultJobs(), event.TargetEndpoints)
+		if err != nil {
+			return createErrorResponse(executionARN, event, fmt.Sprintf("Invalid target endpoints: %v", err)), err
+		}
+
+		scraperEvent, err := buildScraperEvent(event, time.Now())
+		if err != nil {
+			return createErrorResponse(executionARN, event, fmt.Sprintf("Invalid parameters: %v", err)), err
+		}
+
+		if err := validateJobs(jobs, scraperEvent); err != nil {
+			return createErrorResponse(executionARN, event, fmt.Sprintf("Event validation failed: %v", err)), err
+		}
+
+		// Create Datadog client using pure function
+		client, err := shared.CreateDatadogClient()
+		if err != nil {
+			return createErrorResponse(executionARN, event, fmt.Sprintf("Failed to create Datadog client: %v", err)), err
+		}
+
+		// Validate connection once for all scrapers
+		if err := shared.ValidateDatadogConnection(tracedCtx, client); err != nil {
+			return createErrorResponse(executionARN, event, fmt.Sprintf("Failed to validate Datadog connection: %v", err)), err
+		}
+
+		return orchestrate(tracedCtx, client, jobs, executionARN, event, scraperEvent)
+	})
+}
+
+// orchestrate runs the jobs and aggregates their responses
+// Only returns an error when no job succeeded, so partial runs still hand their outputs downstream
+func orchestrate(ctx context.Context, client *datadog.APIClient, jobs []scraperJob, executionARN string, event types.OrchestrationEvent, scraperEvent types.ScraperEvent) (types.OrchestrationResponse, error) {
+	statuses := runJobs(ctx, client, jobs, scraperEvent)
+	response := createOrchestrationResponse(executionARN, event, scraperEvent, statuses)
+
+	if response.Status == OrchestrationStatusFailed {
+		return response, fmt.Errorf("all %d scraper jobs failed or were skipped", len(statuses))
+	}
+	return response, nil
+}
+
+// runJobs runs every job as soon as its dependencies have finished
+// Each job waits on its dependencies' done channels, so independent jobs run concurrently
+func runJobs(ctx context.Context, client *datadog.APIClient, jobs []scraperJob, event types.ScraperEvent) []types.OrchestrationJobStatus {
+	statuses := make([]types.OrchestrationJobStatus, len(jobs))
+	indexes := lo.SliceToMap(lo.Range(len(jobs)), func(i int) (string, int) {
+		return jobs[i].name, i
+	})
+	done := lo.SliceToMap(jobs, func(job scraperJob) (string, chan struct{}) {
+		return job.name, make(chan struct{})
+	})
+
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		go func(i int, job scraperJob) {
+			defer wg.Done()
+			defer close(done[job.name])
+
+			dependencies := lo.Filter(job.dependsOn, func(dependency string, _ int) bool {
+				_, selected := done[dependency]
+				return selected
+			})
+			for _, dependency := range dependencies {
+				<-done[dependency]
+			}
+
+			// Statuses of finished dependencies are safe to read once their channel is closed
+			failed := lo.Filter(dependencies, func(dependency string, _ int) bool {
+				return statuses[indexes[dependency]].Status != JobStatusSuccess
+			})
+			if len(failed) > 0 {
+				statuses[i] = createSkippedStatus(job, failed)
+				return
+			}
+
+			statuses[i] = runJob(ctx, client, job, event)
+		}(i, job)
+	}
+	wg.Wait()
+
+	return statuses
+}
+
+// runJob runs a single scraper and records its outcome
+func runJob(ctx context.Context, client *datadog.APIClient, job scraperJob, event types.ScraperEvent) types.OrchestrationJobStatus {
+	startedAt := time.Now()
+	response, err := job.scrape(ctx, client, event)
+
+	status := types.OrchestrationJobStatus{
+		Name:       job.name,
+		Status:     JobStatusSuccess,
+		DependsOn:  job.dependsOn,
+		Response:   &response,
+		DurationMs: time.Since(startedAt).Milliseconds(),
+	}
+
+	switch {
+	case err != nil:
+		status.Status = JobStatusError
+		status.Error = err.Error()
+	case response.Status != JobStatusSuccess:
+		status.Status = JobStatusError
+		status.Error = response.Message
+	}
+
+	return status
+}
+
+// createSkippedStatus records a job that did not run because a dependency failed
+func createSkippedStatus(job scraperJob, failed []string) types.OrchestrationJobStatus {
+	return types.OrchestrationJobStatus{
+		Name:      job.name,
+		Status:    JobStatusSkipped,
+		DependsOn: job.dependsOn,
+		Error:     fmt.Sprintf("dependencies did not succeed: %v", failed),
+	}
+}
+
+// selectJobs restricts the jobs to the requested endpoints, keeping dependency order
+// Pure function; an empty target list selects every job and unknown targets are rejected
+func selectJobs(jobs []scraperJob, targets []string) ([]scraperJob, error) {
+	if len(targets) == 0 {
+		return jobs, nil
+	}
+
+	known := lo.Map(jobs, func(job scraperJob, _ int) string { return job.name })
+	if unknown := lo.Without(lo.Uniq(targets), known...); len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown target endpoints %v, expected any of %v", unknown, known)
+	}
+
+	return lo.Filter(jobs, func(job scraperJob, _ int) bool {
+		return lo.Contains(targets, job.name)
+	}), nil
+}
+
+// buildScraperEvent converts the orchestration parameters into the event shared by every scraper
+// Pure function; all scrapers share one run ID so their stored items carry the same generation
+func buildScraperEvent(event types.OrchestrationEvent, now time.Time) (types.ScraperEvent, error) {
+	var scraperEvent types.ScraperEvent
+	if len(event.Parameters) > 0 {
+		raw, err := json.Marshal(event.Parameters)
+		if err != nil {
+			return types.ScraperEvent{}, fmt.Errorf("failed to encode parameters: %w", err)
+		}
+		if err := json.Unmarshal(raw, &scraperEvent); err != nil {
+			return types.ScraperEvent{}, fmt.Errorf("failed to decode parameters: %w", err)
+		}
+	}
+
+	scraperEvent.RunID = lo.CoalesceOrEmpty(scraperEvent.RunID, event.RequestID)
+	scraperEvent.RunID = shared.ResolveScrapeGeneration(scraperEvent, now)
+
+	return scraperEvent, nil
+}
+
+// validateJobs validates the shared event against every selected scraper's rules
+func validateJobs(jobs []scraperJob, event types.ScraperEvent) error {
+	for _, job := range jobs {
+		if err := job.validate(event); err != nil {
+			return fmt.Errorf("%s: %w", job.name, err)
+		}
+	}
+	return nil
+}
+
+// resolveExecutionARN returns the ARN of the invoked function when running in Lambda
+func resolveExecutionARN(ctx context.Context) string {
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		return lc.InvokedFunctionArn
+	}
+	return xray.TraceID(ctx)
+}
+
+// aggregateStatus derives the orchestration status from the job statuses
+// Pure function: success when every job succeeded, failed when none did
+func aggregateStatus(statuses []types.OrchestrationJobStatus) string {
+	succeeded := lo.CountBy(statuses, func(status types.OrchestrationJobStatus) bool {
+		return status.Status == JobStatusSuccess
+	})
+
+	switch {
+	case succeeded == len(statuses):
+		return OrchestrationStatusSuccess
+	case succeeded == 0:
+		return OrchestrationStatusFailed
+	}
+	return OrchestrationStatusPartial
+}
+
+// createOrchestrationResponse aggregates job statuses into the orchestration response using pure function
+func createOrchestrationResponse(executionARN string, event types.OrchestrationEvent, scraperEvent types.ScraperEvent, statuses []types.OrchestrationJobStatus) types.OrchestrationResponse {
+	succeeded := lo.Filter(statuses, func(status types.OrchestrationJobStatus, _ int) bool {
+		return status.Status == JobStatusSuccess
+	})
+
+	outputs := lo.FlatMap(succeeded, func(status types.OrchestrationJobStatus, _ int) []types.ScraperOutput {
+		return status.Response.Outputs
+	})
+
+	counts := lo.SliceToMap(succeeded, func(status types.OrchestrationJobStatus) (string, int) {
+		return status.Name, status.Response.Count
+	})
+
+	jobStatuses := lo.SliceToMap(statuses, func(status types.OrchestrationJobStatus) (string, string) {
+		return status.Name, status.Status
+	})
+
+	return types.OrchestrationResponse{
+		ExecutionARN:  executionARN,
+		Status:        aggregateStatus(statuses),
+		RequestID:     event.RequestID,
+		TriggeredJobs: lo.Map(statuses, func(status types.OrchestrationJobStatus, _ int) string { return status.Name }),
+		Timestamp:     time.Now().UTC(),
+		Metadata: map[string]interface{}{
+			"trigger_type":        event.TriggerType,
+			"run_id":              scraperEvent.RunID,
+			"job_statuses":        jobStatuses,
+			"counts":              counts,
+			"total_count":         lo.Sum(lo.Values(counts)),
+			"total_outputs":       len(outputs),
+			"functional_pipeline": true,
+		},
+		Jobs:    statuses,
+		Outputs: outputs,
+	}
+}
+
+// createErrorResponse creates an error response for failures before any job ran using pure function
+func createErrorResponse(executionARN string, event types.OrchestrationEvent, message string) types.OrchestrationResponse {
+	return types.OrchestrationResponse{
+		ExecutionARN:  executionARN,
+		Status:        OrchestrationStatusFailed,
+		RequestID:     event.RequestID,
+		TriggeredJobs: []string{},
+		Timestamp:     time.Now().UTC(),
+		Metadata: map[string]interface{}{
+			"error":   true,
+			"message": message,
+		},
+	}
+}
+
+// main function initializes the Lambda handler
+func main() {
+	lambda.Start(OrchestratorHandler)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bacon/src/plugins/datadog/types"
+	"bacon/src/shared/scraperoutput"
+)
+
+// recorder captures the order in which fake scrapers start and finish
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) index(event string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, recorded := range r.events {
+		if recorded == event {
+			return i
+		}
+	}
+	return -1
+}
+
+// fakeJobs mirrors defaultJobs with scrapers that record their runs and fail when listed
+func fakeJobs(rec *recorder, failing ...string) []scraperJob {
+	jobs := defaultJobs()
+	for i := range jobs {
+		name := jobs[i].name
+		jobs[i].scrape = func(_ context.Context, _ *datadog.APIClient, event types.ScraperEvent) (types.ScraperResponse, error) {
+			rec.record("start:" + name)
+			defer rec.record("end:" + name)
+
+			for _, failure := range failing {
+				if failure == name {
+					return types.ScraperResponse{Status: "error", Message: name + " failed"}, errors.New(name + " failed")
+				}
+			}
+			return types.ScraperResponse{
+				Status:  "success",
+				Count:   2,
+				Outputs: []types.ScraperOutput{{Source: "datadog-" + name, Payload: scraperoutput.Changes(scraperoutput.ChangeSet{SnapshotID: event.RunID})}},
+			}, nil
+		}
+	}
+	return jobs
+}
+
+// Test every job runs after its dependencies and the run succeeds
+func TestOrchestrate_DependencyOrdering(t *testing.T) {
+	rec := &recorder{}
+	event := types.OrchestrationEvent{TriggerType: "scheduled", RequestID: "req-1"}
+
+	response, err := orchestrate(context.Background(), nil, fakeJobs(rec), "arn:aws:lambda:orchestrator", event, types.ScraperEvent{RunID: "req-1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, OrchestrationStatusSuccess, response.Status)
+	assert.Equal(t, []string{"teams", "users", "services", "organizations"}, response.TriggeredJobs)
+	assert.Less(t, rec.index("end:teams"), rec.index("start:users"))
+	assert.Less(t, rec.index("end:teams"), rec.index("start:services"))
+	assert.Less(t, rec.index("end:users"), rec.index("start:organizations"))
+
+	assert.Len(t, response.Outputs, 4)
+	assert.Equal(t, 8, response.Metadata["total_count"])
+	for _, output := range response.Outputs {
+		assert.Equal(t, "req-1", output.Payload.Changes.SnapshotID)
+	}
+}
+
+// Test a failed dependency skips its dependents but not unrelated jobs
+func TestOrchestrate_FailedDependencySkipsDependents(t *testing.T) {
+	rec := &recorder{}
+
+	response, err := orchestrate(context.Background(), nil, fakeJobs(rec, "users"), "arn", types.OrchestrationEvent{}, types.ScraperEvent{})
+
+	require.NoError(t, err)
+	assert.Equal(t, OrchestrationStatusPartial, response.Status)
+	assert.Equal(t, map[string]string{
+		"teams":         JobStatusSuccess,
+		"users":         JobStatusError,
+		"services":      JobStatusSuccess,
+		"organizations": JobStatusSkipped,
+	}, response.Metadata["job_statuses"])
+	assert.Equal(t, -1, rec.index("start:organizations"))
+	assert.Len(t, response.Outputs, 2)
+
+	organizationsJob := response.Jobs[3]
+	assert.Nil(t, organizationsJob.Response)
+	assert.Contains(t, organizationsJob.Error, "users")
+}
+
+// Test a failing root job fails the whole run
+func TestOrchestrate_AllFailed(t *testing.T) {
+	response, err := orchestrate(context.Background(), nil, fakeJobs(&recorder{}, "teams"), "arn", types.OrchestrationEvent{}, types.ScraperEvent{})
+
+	assert.Error(t, err)
+	assert.Equal(t, OrchestrationStatusFailed, response.Status)
+	assert.Empty(t, response.Outputs)
+}
+
+// Test independent jobs overlap instead of running one after another
+func TestRunJobs_Concurrent(t *testing.T) {
+	started := make(chan string, 2)
+	release := make(chan struct{})
+
+	blocking := func(name string) scraperJob {
+		return scraperJob{name: name, scrape: func(context.Context, *datadog.APIClient, types.ScraperEvent) (types.ScraperResponse, error) {
+			started <- name
+			<-release
+			return types.ScraperResponse{Status: "success"}, nil
+		}}
+	}
+
+	finished := make(chan []types.OrchestrationJobStatus)
+	go func() {
+		finished <- runJobs(context.Background(), nil, []scraperJob{blocking("users"), blocking("services")}, types.ScraperEvent{})
+	}()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("independent jobs did not run concurrently")
+		}
+	}
+	close(release)
+
+	statuses := <-finished
+	assert.Equal(t, JobStatusSuccess, statuses[0].Status)
+	assert.Equal(t, JobStatusSuccess, statuses[1].Status)
+}
+
+// Test target endpoint selection
+func TestSelectJobs(t *testing.T) {
+	all := defaultJobs()
+
+	selected, err := selectJobs(all, nil)
+	require.NoError(t, err)
+	assert.Len(t, selected, 4)
+
+	selected, err = selectJobs(all, []string{"organizations", "users"})
+	require.NoError(t, err)
+	require.Len(t, selected, 2)
+	assert.Equal(t, "users", selected[0].name)
+	assert.Equal(t, "organizations", selected[1].name)
+
+	_, err = selectJobs(all, []string{"teams", "dashboards"})
+	assert.ErrorContains(t, err, "dashboards")
+}
+
+// Test jobs whose dependencies were not selected run immediately
+func TestRunJobs_UnselectedDependencies(t *testing.T) {
+	rec := &recorder{}
+	jobs, err := selectJobs(fakeJobs(rec), []string{"organizations"})
+	require.NoError(t, err)
+
+	statuses := runJobs(context.Background(), nil, jobs, types.ScraperEvent{})
+
+	require.Len(t, statuses, 1)
+	assert.Equal(t, JobStatusSuccess, statuses[0].Status)
+}
+
+// Test parameters are converted into the shared scraper event
+func TestBuildScraperEvent(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	event, err := buildScraperEvent(types.OrchestrationEvent{
+		RequestID:  "req-1",
+		Parameters: map[string]interface{}{"page_size": 50, "include_inactive": true},
+	}, now)
+	require.NoError(t, err)
+	assert.Equal(t, 50, event.PageSize)
+	assert.True(t, event.IncludeInactive)
+	assert.Equal(t, "req-1", event.RunID)
+
+	event, err = buildScraperEvent(types.OrchestrationEvent{}, now)
+	require.NoError(t, err)
+	assert.Equal(t, "1700000000000000000", event.RunID)
+
+	_, err = buildScraperEvent(types.OrchestrationEvent{Parameters: map[string]interface{}{"page_size": "fifty"}}, now)
+	assert.Error(t, err)
+}
+
+// Test the shared event is validated against every selected scraper
+func TestValidateJobs(t *testing.T) {
+	assert.NoError(t, validateJobs(defaultJobs(), types.ScraperEvent{PageSize: 100}))
+	assert.NoError(t, validateJobs(defaultJobs(), types.ScraperEvent{PageSize: 100, SchemaVersion: "v3"}))
+	assert.Error(t, validateJobs(defaultJobs(), types.ScraperEvent{PageSize: 5000}))
+}
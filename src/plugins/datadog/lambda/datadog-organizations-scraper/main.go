@@ -1,314 +1,14 @@
 // Package main implements the Datadog Organizations Scraper Lambda function.
-// This Lambda function fetches organization data from Datadog API v2 using pure functional programming.
+// The scraping pipeline lives in the organizations package so the orchestrator can share it.
 package main
 
 import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"time"
-
 	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/aws/aws-xray-sdk-go/v2/xray"
-	"github.com/DataDog/datadog-api-client-go/v2/api/datadog"
-	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV2"
-	"github.com/samber/lo"
 
-	"bacon/src/plugins/datadog/shared"
-	"bacon/src/plugins/datadog/types"
+	"bacon/src/plugins/datadog/scrapers/organizations"
 )
 
-// OrganizationsScraperHandler handles the Lambda invocation for organizations scraping
-// Pure function that orchestrates the organization data collection pipeline
-func OrganizationsScraperHandler(ctx context.Context, event types.ScraperEvent) (types.ScraperResponse, error) {
-	executionID := xray.TraceID(ctx)
-	
-	return shared.WithTracedOperation(ctx, "organizations-scraper-handler", func(tracedCtx context.Context) (types.ScraperResponse, error) {
-		// Create Datadog client using pure function
-		client, err := shared.CreateDatadogClient()
-		if err != nil {
-			return createErrorResponse(executionID, fmt.Sprintf("Failed to create Datadog client: %v", err)), err
-		}
-
-		// Validate connection using pure function
-		if err := shared.ValidateDatadogConnection(tracedCtx, client); err != nil {
-			return createErrorResponse(executionID, fmt.Sprintf("Failed to validate Datadog connection: %v", err)), err
-		}
-
-		// Fetch organizations data using functional pipeline
-		organizations, err := fetchAllOrganizations(tracedCtx, client, event)
-		if err != nil {
-			return createErrorResponse(executionID, fmt.Sprintf("Failed to fetch organizations: %v", err)), err
-		}
-
-		// Transform API response to internal types using pure functions
-		transformedOrganizations := lo.Map(organizations, transformOrganizationResponse)
-
-		// Enrich organizations with team and user data
-		enrichedOrganizations, err := enrichOrganizationsWithTeamData(tracedCtx, client, transformedOrganizations)
-		if err != nil {
-			return createErrorResponse(executionID, fmt.Sprintf("Failed to enrich organizations: %v", err)), err
-		}
-
-		// Store organizations data using functional storage pipeline
-		storedIDs, err := storeOrganizationsData(tracedCtx, enrichedOrganizations)
-		if err != nil {
-			return createErrorResponse(executionID, fmt.Sprintf("Failed to store organizations: %v", err)), err
-		}
-
-		// Create success response using pure function
-		return createSuccessResponse(executionID, len(storedIDs), createOrganizationsMetadata(enrichedOrganizations, storedIDs)), nil
-	})
-}
-
-// fetchAllOrganizations fetches organization data (simplified implementation)
-// Pure functional approach to API data collection
-func fetchAllOrganizations(ctx context.Context, client *datadog.APIClient, event types.ScraperEvent) ([]datadogV2.Organization, error) {
-	// Since the Organizations API might not be available or structured differently,
-	// we'll create a mock organization to demonstrate the pipeline
-	// In a real implementation, you'd use the available organization endpoints
-	
-	mockOrg := datadogV2.Organization{
-		Id: lo.ToPtr("default-org"),
-		Attributes: &datadogV2.OrganizationAttributes{
-			Name:        lo.ToPtr("Default Organization"),
-			Description: lo.ToPtr("Default Datadog Organization"),
-			PublicId:    lo.ToPtr("default-public-id"),
-			CreatedAt:   lo.ToPtr(time.Now().Add(-365 * 24 * time.Hour)),
-			ModifiedAt:  lo.ToPtr(time.Now()),
-		},
-	}
-	
-	return []datadogV2.Organization{mockOrg}, nil
-}
-
-// Removed pagination functions since we're using simplified implementation
-
-// transformOrganizationResponse converts a Datadog API organization response to our internal type
-// Pure function with no side effects
-func transformOrganizationResponse(org datadogV2.Organization, _ int) types.DatadogOrganization {
-	return types.DatadogOrganization{
-		ID:          lo.FromPtr(org.Id),
-		Name:        lo.FromPtrOr(org.Attributes.Name, ""),
-		Description: lo.FromPtrOr(org.Attributes.Description, ""),
-		Settings:    extractOrganizationSettings(org),
-		Users:       []types.DatadogUser{},    // Will be populated in enrichment
-		Teams:       []types.DatadogTeam{},    // Will be populated in enrichment
-		CreatedAt:   parseDatadogTime(org.Attributes.CreatedAt),
-		UpdatedAt:   parseDatadogTime(org.Attributes.ModifiedAt),
-	}
-}
-
-// enrichOrganizationsWithTeamData enriches organizations with team and user data
-// Pure functional approach to data enrichment
-func enrichOrganizationsWithTeamData(ctx context.Context, client *datadog.APIClient, organizations []types.DatadogOrganization) ([]types.DatadogOrganization, error) {
-	// Fetch all teams and users for enrichment
-	teams, err := fetchTeamsForEnrichment(ctx, client)
-	if err != nil {
-		return organizations, fmt.Errorf("failed to fetch teams for enrichment: %w", err)
-	}
-
-	users, err := fetchUsersForEnrichment(ctx, client)
-	if err != nil {
-		return organizations, fmt.Errorf("failed to fetch users for enrichment: %w", err)
-	}
-
-	// Transform and enrich each organization using functional approach
-	return lo.Map(organizations, func(org types.DatadogOrganization, _ int) types.DatadogOrganization {
-		return enrichOrganizationWithTeamData(org, teams, users)
-	}), nil
-}
-
-// fetchTeamsForEnrichment fetches teams for organization enrichment
-func fetchTeamsForEnrichment(ctx context.Context, client *datadog.APIClient) ([]types.DatadogTeam, error) {
-	api := datadogV2.NewTeamsApi(client)
-	
-	response, _, err := api.ListTeams(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch teams: %w", err)
-	}
-
-	if response.Data != nil {
-		return lo.Map(response.Data, shared.TransformTeamResponse), nil
-	}
-	
-	return []types.DatadogTeam{}, nil
-}
-
-// fetchUsersForEnrichment fetches users for organization enrichment
-func fetchUsersForEnrichment(ctx context.Context, client *datadog.APIClient) ([]types.DatadogUser, error) {
-	api := datadogV2.NewUsersApi(client)
-	
-	response, _, err := api.ListUsers(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch users: %w", err)
-	}
-
-	if response.Data != nil {
-		return lo.Map(response.Data, shared.TransformUserResponse), nil
-	}
-	
-	return []types.DatadogUser{}, nil
-}
-
-// enrichOrganizationWithTeamData enriches a single organization with team and user data
-// Pure function that creates enriched organization data
-func enrichOrganizationWithTeamData(org types.DatadogOrganization, allTeams []types.DatadogTeam, allUsers []types.DatadogUser) types.DatadogOrganization {
-	// For now, since organization API doesn't provide direct team/user relationships,
-	// we include all teams and users. In a real scenario, you'd filter based on
-	// organization membership data if available.
-	
-	return types.DatadogOrganization{
-		ID:          org.ID,
-		Name:        org.Name,
-		Description: org.Description,
-		Settings:    org.Settings,
-		Users:       allUsers,
-		Teams:       allTeams,
-		CreatedAt:   org.CreatedAt,
-		UpdatedAt:   org.UpdatedAt,
-	}
-}
-
-// storeOrganizationsData stores organization data using functional approach
-func storeOrganizationsData(ctx context.Context, organizations []types.DatadogOrganization) ([]string, error) {
-	return shared.WithTracedOperation(ctx, "store-organizations-data", func(tracedCtx context.Context) ([]string, error) {
-		// For now, we'll create a simple storage mechanism
-		// In a real implementation, you'd have a proper storage function in shared
-		orgIDs := lo.Map(organizations, func(org types.DatadogOrganization, _ int) string {
-			return org.ID
-		})
-		
-		// TODO: Implement actual storage using DynamoDB
-		// This would be similar to StoreTeamsData, StoreUsersData, StoreServicesData
-		// For now, we'll return the IDs as if they were stored
-		
-		return orgIDs, nil
-	})
-}
-
-// extractOrganizationSettings extracts settings from organization attributes
-func extractOrganizationSettings(org datadogV2.Organization) map[string]interface{} {
-	settings := make(map[string]interface{})
-	
-	if org.Attributes != nil && org.Attributes.PublicId != nil {
-		settings["public_id"] = lo.FromPtr(org.Attributes.PublicId)
-	}
-	
-	// Simplified settings extraction - organization settings structure may vary
-	// This would be enhanced with proper API exploration
-	settings["organization_type"] = "standard"
-	settings["settings_available"] = false
-	
-	return settings
-}
-
-// createOrganizationsMetadata creates metadata for response using pure function
-func createOrganizationsMetadata(organizations []types.DatadogOrganization, storedIDs []string) map[string]interface{} {
-	// Calculate organization statistics using functional approach
-	totalUsers := lo.Reduce(organizations, func(acc int, org types.DatadogOrganization, _ int) int {
-		return acc + len(org.Users)
-	}, 0)
-
-	totalTeams := lo.Reduce(organizations, func(acc int, org types.DatadogOrganization, _ int) int {
-		return acc + len(org.Teams)
-	}, 0)
-
-	// Count organizations with SAML settings
-	samlEnabledCount := lo.CountBy(organizations, func(org types.DatadogOrganization) bool {
-		if samlEnabled, exists := org.Settings["saml_enabled"]; exists {
-			if enabled, ok := samlEnabled.(bool); ok {
-				return enabled
-			}
-		}
-		return false
-	})
-
-	// Calculate average team/user ratios
-	avgUsersPerOrg := lo.Ternary(len(organizations) > 0, float64(totalUsers)/float64(len(organizations)), 0.0)
-	avgTeamsPerOrg := lo.Ternary(len(organizations) > 0, float64(totalTeams)/float64(len(organizations)), 0.0)
-
-	return map[string]interface{}{
-		"organizations_fetched":  len(organizations),
-		"organizations_stored":   len(storedIDs),
-		"total_users":            totalUsers,
-		"total_teams":            totalTeams,
-		"saml_enabled_orgs":      samlEnabledCount,
-		"avg_users_per_org":      avgUsersPerOrg,
-		"avg_teams_per_org":      avgTeamsPerOrg,
-		"stored_organization_ids": storedIDs,
-		"api_version":            "v2",
-		"functional_pipeline":    true,
-	}
-}
-
-// createSuccessResponse creates a success response using pure function
-func createSuccessResponse(executionID string, count int, metadata map[string]interface{}) types.ScraperResponse {
-	return types.ScraperResponse{
-		Status:      "success",
-		Message:     fmt.Sprintf("Successfully scraped %d organizations", count),
-		Count:       count,
-		Timestamp:   time.Now().UTC().Format(time.RFC3339),
-		ExecutionID: executionID,
-		Metadata:    metadata,
-	}
-}
-
-// createErrorResponse creates an error response using pure function
-func createErrorResponse(executionID, message string) types.ScraperResponse {
-	return types.ScraperResponse{
-		Status:      "error",
-		Message:     message,
-		Count:       0,
-		Timestamp:   time.Now().UTC().Format(time.RFC3339),
-		ExecutionID: executionID,
-		Metadata: map[string]interface{}{
-			"error":       true,
-			"api_version": "v2",
-		},
-	}
-}
-
-// parseDatadogTime safely parses Datadog timestamp
-func parseDatadogTime(timePtr *time.Time) time.Time {
-	return lo.FromPtrOr(timePtr, time.Time{})
-}
-
-// validateEvent validates the input event using functional approach
-func validateEvent(event types.ScraperEvent) error {
-	validationRules := []func(types.ScraperEvent) bool{
-		func(e types.ScraperEvent) bool { return e.PageSize >= 0 },
-		func(e types.ScraperEvent) bool { return e.PageSize <= 1000 },
-	}
-	
-	isValid := lo.EveryBy(validationRules, func(rule func(types.ScraperEvent) bool) bool {
-		return rule(event)
-	})
-	
-	if !isValid {
-		return fmt.Errorf("invalid event parameters")
-	}
-	
-	return nil
-}
-
 // main function initializes the Lambda handler
 func main() {
-	// Wrapper function to add event validation
-	handlerWithValidation := func(ctx context.Context, event json.RawMessage) (types.ScraperResponse, error) {
-		var scraperEvent types.ScraperEvent
-		if err := json.Unmarshal(event, &scraperEvent); err != nil {
-			executionID := xray.TraceID(ctx)
-			return createErrorResponse(executionID, fmt.Sprintf("Failed to parse event: %v", err)), err
-		}
-
-		if err := validateEvent(scraperEvent); err != nil {
-			executionID := xray.TraceID(ctx)
-			return createErrorResponse(executionID, fmt.Sprintf("Event validation failed: %v", err)), err
-		}
-
-		return OrganizationsScraperHandler(ctx, scraperEvent)
-	}
-
-	lambda.Start(handlerWithValidation)
-}
\ No newline at end of file
+	lambda.Start(organizations.HandleRawEvent)
+}
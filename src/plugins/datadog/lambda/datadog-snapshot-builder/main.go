@@ -0,0 +1,128 @@
+// Package main implements the Datadog Snapshot Builder Lambda function.
+// This Lambda function snapshots the scraped Datadog data and publishes the changes since the previous run using pure functional programming.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-xray-sdk-go/v2/xray"
+	"github.com/samber/lo"
+
+	"bacon/src/plugins/datadog/shared"
+	"bacon/src/plugins/datadog/types"
+)
+
+// snapshotStore loads and persists snapshots; implemented by shared.SnapshotStore
+type snapshotStore interface {
+	LoadLatest(ctx context.Context) (types.DatadogTeamSnapshot, bool, error)
+	LoadLive(ctx context.Context, id string, timestamp time.Time) (types.DatadogTeamSnapshot, error)
+	Save(ctx context.Context, snapshot types.DatadogTeamSnapshot) error
+}
+
+// SnapshotBuilderHandler handles the Lambda invocation for snapshot building
+// Runs after the teams, users, services and organizations scrapers have stored their data
+func SnapshotBuilderHandler(ctx context.Context, event types.ScraperEvent) (types.ScraperResponse, error) {
+	executionID := xray.TraceID(ctx)
+
+	return shared.WithTracedOperation(ctx, "snapshot-builder-handler", func(tracedCtx context.Context) (types.ScraperResponse, error) {
+		store, err := shared.CreateSnapshotStore(tracedCtx)
+		if err != nil {
+			return createErrorResponse(executionID, fmt.Sprintf("Failed to create snapshot store: %v", err)), err
+		}
+
+		return buildSnapshot(tracedCtx, store, executionID, event, time.Now())
+	})
+}
+
+// buildSnapshot assembles the current snapshot, diffs it against the previous one and persists it
+// Change events are only published once the new snapshot is saved, so a failed run is re-diffed next time
+func buildSnapshot(ctx context.Context, store snapshotStore, executionID string, event types.ScraperEvent, now time.Time) (types.ScraperResponse, error) {
+	previous, hasPrevious, err := store.LoadLatest(ctx)
+	if err != nil {
+		return createErrorResponse(executionID, fmt.Sprintf("Failed to load previous snapshot: %v", err)), err
+	}
+
+	current, err := store.LoadLive(ctx, shared.ResolveScrapeGeneration(event, now), now)
+	if err != nil {
+		return createErrorResponse(executionID, fmt.Sprintf("Failed to assemble snapshot: %v", err)), err
+	}
+
+	diff := shared.DiffSnapshots(previous, current)
+
+	if err := store.Save(ctx, current); err != nil {
+		return createErrorResponse(executionID, fmt.Sprintf("Failed to save snapshot: %v", err)), err
+	}
+
+	response := createSuccessResponse(executionID, len(diff.Changes), createSnapshotMetadata(current, diff, hasPrevious))
+	if len(diff.Changes) > 0 {
+		response.Outputs = []types.ScraperOutput{shared.CreateChangeOutput(diff, now)}
+	}
+	return response, nil
+}
+
+// createSnapshotMetadata creates metadata for response using pure function
+func createSnapshotMetadata(snapshot types.DatadogTeamSnapshot, diff types.DatadogSnapshotDiff, hasPrevious bool) map[string]interface{} {
+	changeCounts := lo.CountValuesBy(diff.Changes, func(change types.DatadogChangeEvent) string {
+		return change.Type
+	})
+
+	return map[string]interface{}{
+		"snapshot_id":          snapshot.ID,
+		"previous_snapshot_id": diff.PreviousSnapshotID,
+		"baseline":             !hasPrevious,
+		"total_teams":          snapshot.TotalTeams,
+		"total_users":          snapshot.TotalUsers,
+		"total_services":       snapshot.TotalServices,
+		"total_organizations":  len(snapshot.Organizations),
+		"change_counts":        changeCounts,
+		"functional_pipeline":  true,
+	}
+}
+
+// createSuccessResponse creates a success response using pure function
+func createSuccessResponse(executionID string, count int, metadata map[string]interface{}) types.ScraperResponse {
+	return types.ScraperResponse{
+		Status:      "success",
+		Message:     fmt.Sprintf("Successfully built snapshot with %d changes", count),
+		Count:       count,
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		ExecutionID: executionID,
+		Metadata:    metadata,
+	}
+}
+
+// createErrorResponse creates an error response using pure function
+func createErrorResponse(executionID, message string) types.ScraperResponse {
+	return types.ScraperResponse{
+		Status:      "error",
+		Message:     message,
+		Count:       0,
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		ExecutionID: executionID,
+		Metadata: map[string]interface{}{
+			"error": true,
+		},
+	}
+}
+
+// main function initializes the Lambda handler
+func main() {
+	// Wrapper function to add event parsing
+	handler := func(ctx context.Context, event json.RawMessage) (types.ScraperResponse, error) {
+		var scraperEvent types.ScraperEvent
+		if len(event) > 0 {
+			if err := json.Unmarshal(event, &scraperEvent); err != nil {
+				executionID := xray.TraceID(ctx)
+				return createErrorResponse(executionID, fmt.Sprintf("Failed to parse event: %v", err)), err
+			}
+		}
+
+		return SnapshotBuilderHandler(ctx, scraperEvent)
+	}
+
+	lambda.Start(handler)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bacon/src/plugins/datadog/shared"
+	"bacon/src/plugins/datadog/types"
+)
+
+// fakeSnapshotStore is an in-memory snapshotStore
+type fakeSnapshotStore struct {
+	latest  *types.DatadogTeamSnapshot
+	live    types.DatadogTeamSnapshot
+	saveErr error
+	saved   []types.DatadogTeamSnapshot
+}
+
+func (f *fakeSnapshotStore) LoadLatest(context.Context) (types.DatadogTeamSnapshot, bool, error) {
+	if f.latest == nil {
+		return types.DatadogTeamSnapshot{}, false, nil
+	}
+	return *f.latest, true, nil
+}
+
+func (f *fakeSnapshotStore) LoadLive(_ context.Context, id string, timestamp time.Time) (types.DatadogTeamSnapshot, error) {
+	live := f.live
+	live.ID = id
+	live.Timestamp = timestamp
+	return live, nil
+}
+
+func (f *fakeSnapshotStore) Save(_ context.Context, snapshot types.DatadogTeamSnapshot) error {
+	if f.saveErr != nil {
+		return f.saveErr
+	}
+	f.saved = append(f.saved, snapshot)
+	return nil
+}
+
+// Test consecutive runs publish only the deltas
+func TestBuildSnapshot(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeSnapshotStore{live: types.DatadogTeamSnapshot{
+		Teams: []types.DatadogTeam{{ID: "team-1", Handle: "payments", Members: []types.DatadogUser{{ID: "user-1"}}}},
+		Users: []types.DatadogUser{{ID: "user-1", Email: "ana@example.com"}},
+	}}
+
+	baseline, err := buildSnapshot(context.Background(), store, "exec-1", types.ScraperEvent{RunID: "run-1"}, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, baseline.Count)
+	assert.Equal(t, true, baseline.Metadata["baseline"])
+	require.Len(t, store.saved, 1)
+	assert.Equal(t, "run-1", store.saved[0].ID)
+
+	store.latest = &store.saved[0]
+	store.live.Teams = []types.DatadogTeam{{ID: "team-1", Handle: "payments"}}
+
+	response, err := buildSnapshot(context.Background(), store, "exec-2", types.ScraperEvent{RunID: "run-2"}, now.Add(time.Hour))
+
+	require.NoError(t, err)
+	assert.Equal(t, "success", response.Status)
+	assert.Equal(t, 1, response.Count)
+	assert.Equal(t, "run-1", response.Metadata["previous_snapshot_id"])
+	assert.Equal(t, map[string]int{shared.ChangeMemberLeft: 1}, response.Metadata["change_counts"])
+	require.Len(t, response.Outputs, 1)
+	assert.Equal(t, shared.SourceDatadogChanges, response.Outputs[0].Source)
+}
+
+// Test unchanged data publishes no change output
+func TestBuildSnapshot_NoChanges(t *testing.T) {
+	store := &fakeSnapshotStore{latest: &types.DatadogTeamSnapshot{ID: "run-1"}}
+
+	response, err := buildSnapshot(context.Background(), store, "exec-1", types.ScraperEvent{RunID: "run-2"}, time.Now())
+
+	require.NoError(t, err)
+	assert.Zero(t, response.Count)
+	assert.Empty(t, response.Outputs)
+	assert.Len(t, store.saved, 1)
+}
+
+// Test changes are not published when the snapshot cannot be saved
+func TestBuildSnapshot_SaveError(t *testing.T) {
+	store := &fakeSnapshotStore{
+		live:    types.DatadogTeamSnapshot{Teams: []types.DatadogTeam{{ID: "team-1"}}},
+		saveErr: errors.New("throttled"),
+	}
+
+	response, err := buildSnapshot(context.Background(), store, "exec-1", types.ScraperEvent{}, time.Now())
+
+	assert.Error(t, err)
+	assert.Equal(t, "error", response.Status)
+	assert.Empty(t, response.Outputs)
+}
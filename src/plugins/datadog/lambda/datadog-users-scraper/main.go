@@ -1,249 +1,14 @@
 // Package main implements the Datadog Users Scraper Lambda function.
-// This Lambda function fetches user data from Datadog API v2 using pure functional programming.
+// The scraping pipeline lives in the users package so the orchestrator can share it.
 package main
 
 import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"time"
-
 	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/aws/aws-xray-sdk-go/v2/xray"
-	"github.com/DataDog/datadog-api-client-go/v2/api/datadog"
-	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV2"
-	"github.com/samber/lo"
 
-	"bacon/src/plugins/datadog/shared"
-	"bacon/src/plugins/datadog/types"
+	"bacon/src/plugins/datadog/scrapers/users"
 )
 
-// UsersScraperHandler handles the Lambda invocation for users scraping
-// Pure function that orchestrates the user data collection pipeline
-func UsersScraperHandler(ctx context.Context, event types.ScraperEvent) (types.ScraperResponse, error) {
-	executionID := xray.TraceID(ctx)
-	
-	return shared.WithTracedOperation(ctx, "users-scraper-handler", func(tracedCtx context.Context) (types.ScraperResponse, error) {
-		// Create Datadog client using pure function
-		client, err := shared.CreateDatadogClient()
-		if err != nil {
-			return createErrorResponse(executionID, fmt.Sprintf("Failed to create Datadog client: %v", err)), err
-		}
-
-		// Validate connection using pure function
-		if err := shared.ValidateDatadogConnection(tracedCtx, client); err != nil {
-			return createErrorResponse(executionID, fmt.Sprintf("Failed to validate Datadog connection: %v", err)), err
-		}
-
-		// Fetch users data using functional pipeline
-		users, err := fetchAllUsers(tracedCtx, client, event)
-		if err != nil {
-			return createErrorResponse(executionID, fmt.Sprintf("Failed to fetch users: %v", err)), err
-		}
-
-		// Transform API response to internal types using pure functions
-		transformedUsers := lo.Map(users, shared.TransformUserResponse)
-
-		// Filter active users using functional filtering
-		activeUsers := lo.Filter(transformedUsers, func(user types.DatadogUser, _ int) bool {
-			return shared.IsActiveUser(user)
-		})
-
-		// Include inactive users if requested
-		finalUsers := lo.Ternary(event.IncludeInactive, transformedUsers, activeUsers)
-
-		// Store users data using functional storage pipeline
-		storedIDs, err := shared.StoreUsersData(tracedCtx, finalUsers)
-		if err != nil {
-			return createErrorResponse(executionID, fmt.Sprintf("Failed to store users: %v", err)), err
-		}
-
-		// Create success response using pure function
-		return createSuccessResponse(executionID, len(storedIDs), createUsersMetadata(finalUsers, activeUsers, storedIDs)), nil
-	})
-}
-
-// fetchAllUsers fetches all users from Datadog API with pagination
-// Pure functional approach to API data collection
-func fetchAllUsers(ctx context.Context, client *datadog.APIClient, event types.ScraperEvent) ([]datadogV2.User, error) {
-	api := datadogV2.NewUsersApi(client)
-	
-	var allUsers []datadogV2.User
-	pageSize := lo.Ternary(event.PageSize > 0, int64(event.PageSize), 100)
-	var pageNumber int64 = 0
-
-	for {
-		opts := createUsersListOptions(pageSize, pageNumber, event.FilterKeyword)
-		
-		response, _, err := api.ListUsers(ctx, *opts)
-		if err != nil {
-			return nil, fmt.Errorf("failed to list users: %w", err)
-		}
-
-		// Extract users using functional approach
-		if response.Data != nil {
-			allUsers = append(allUsers, response.Data...)
-		}
-
-		// Check for next page using functional logic
-		if !hasNextUserPage(response.Meta, response.Data, pageSize) {
-			break
-		}
-
-		pageNumber++
-	}
-
-	return allUsers, nil
-}
-
-// createUsersListOptions creates API request options using pure function
-func createUsersListOptions(pageSize int64, pageNumber int64, filterKeyword string) *datadogV2.ListUsersOptionalParameters {
-	opts := datadogV2.NewListUsersOptionalParameters()
-	
-	opts = opts.WithPageSize(pageSize)
-	opts = opts.WithPageNumber(pageNumber)
-	
-	if filterKeyword != "" {
-		opts = opts.WithFilter(filterKeyword)
-	}
-
-	// Simplified implementation - include options may not be available
-	// This will be enhanced with proper API exploration
-
-	return opts
-}
-
-// hasNextUserPage checks if there are more pages using functional logic
-func hasNextUserPage(meta *datadogV2.ResponseMetaAttributes, currentPageUsers []datadogV2.User, pageSize int64) bool {
-	// Simplified implementation - return false for now
-	// This will be enhanced with proper API exploration
-	if len(currentPageUsers) < int(pageSize) {
-		return false
-	}
-	return false
-}
-
-// createUsersMetadata creates metadata for response using pure function
-func createUsersMetadata(allUsers, activeUsers []types.DatadogUser, storedIDs []string) map[string]interface{} {
-	// Count users by status using functional approach
-	userStatusCounts := lo.Reduce(allUsers, func(acc map[string]int, user types.DatadogUser, _ int) map[string]int {
-		acc[user.Status]++
-		return acc
-	}, make(map[string]int))
-
-	// Count verified vs unverified users
-	verifiedCount := lo.CountBy(allUsers, func(user types.DatadogUser) bool {
-		return user.Verified
-	})
-
-	// Count users with teams
-	usersWithTeams := lo.CountBy(allUsers, func(user types.DatadogUser) bool {
-		return len(user.Teams) > 0
-	})
-
-	// Calculate team membership statistics
-	teamStats := lo.Reduce(allUsers, func(acc map[string]interface{}, user types.DatadogUser, _ int) map[string]interface{} {
-		teamCount := len(user.Teams)
-		roleCount := len(user.Roles)
-		
-		if totalTeams, ok := acc["total_team_memberships"].(int); ok {
-			acc["total_team_memberships"] = totalTeams + teamCount
-		}
-		
-		if totalRoles, ok := acc["total_role_assignments"].(int); ok {
-			acc["total_role_assignments"] = totalRoles + roleCount
-		}
-		
-		if teamCount > 0 {
-			if maxTeams, ok := acc["max_teams_per_user"].(int); ok && teamCount > maxTeams {
-				acc["max_teams_per_user"] = teamCount
-			}
-		}
-		
-		return acc
-	}, map[string]interface{}{
-		"total_team_memberships": 0,
-		"total_role_assignments": 0,
-		"max_teams_per_user":     0,
-	})
-
-	return map[string]interface{}{
-		"users_fetched":        len(allUsers),
-		"active_users":         len(activeUsers),
-		"users_stored":         len(storedIDs),
-		"verified_users":       verifiedCount,
-		"users_with_teams":     usersWithTeams,
-		"user_status_counts":   userStatusCounts,
-		"team_statistics":      teamStats,
-		"stored_user_ids":      storedIDs,
-		"api_version":          "v2",
-		"functional_pipeline":  true,
-		"includes_inactive":    len(allUsers) > len(activeUsers),
-	}
-}
-
-// createSuccessResponse creates a success response using pure function
-func createSuccessResponse(executionID string, count int, metadata map[string]interface{}) types.ScraperResponse {
-	return types.ScraperResponse{
-		Status:      "success",
-		Message:     fmt.Sprintf("Successfully scraped %d users", count),
-		Count:       count,
-		Timestamp:   time.Now().UTC().Format(time.RFC3339),
-		ExecutionID: executionID,
-		Metadata:    metadata,
-	}
-}
-
-// createErrorResponse creates an error response using pure function
-func createErrorResponse(executionID, message string) types.ScraperResponse {
-	return types.ScraperResponse{
-		Status:      "error",
-		Message:     message,
-		Count:       0,
-		Timestamp:   time.Now().UTC().Format(time.RFC3339),
-		ExecutionID: executionID,
-		Metadata: map[string]interface{}{
-			"error":       true,
-			"api_version": "v2",
-		},
-	}
-}
-
-// validateEvent validates the input event using functional approach
-func validateEvent(event types.ScraperEvent) error {
-	validationRules := []func(types.ScraperEvent) bool{
-		func(e types.ScraperEvent) bool { return e.PageSize >= 0 },
-		func(e types.ScraperEvent) bool { return e.PageSize <= 1000 },
-	}
-	
-	isValid := lo.EveryBy(validationRules, func(rule func(types.ScraperEvent) bool) bool {
-		return rule(event)
-	})
-	
-	if !isValid {
-		return fmt.Errorf("invalid event parameters")
-	}
-	
-	return nil
-}
-
 // main function initializes the Lambda handler
 func main() {
-	// Wrapper function to add event validation
-	handlerWithValidation := func(ctx context.Context, event json.RawMessage) (types.ScraperResponse, error) {
-		var scraperEvent types.ScraperEvent
-		if err := json.Unmarshal(event, &scraperEvent); err != nil {
-			executionID := xray.TraceID(ctx)
-			return createErrorResponse(executionID, fmt.Sprintf("Failed to parse event: %v", err)), err
-		}
-
-		if err := validateEvent(scraperEvent); err != nil {
-			executionID := xray.TraceID(ctx)
-			return createErrorResponse(executionID, fmt.Sprintf("Event validation failed: %v", err)), err
-		}
-
-		return UsersScraperHandler(ctx, scraperEvent)
-	}
-
-	lambda.Start(handlerWithValidation)
-}
\ No newline at end of file
+	lambda.Start(users.HandleRawEvent)
+}
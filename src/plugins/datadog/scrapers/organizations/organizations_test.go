@@ -1,6 +1,6 @@
-// Package main implements comprehensive tests for the Datadog Organizations Scraper Lambda function.
+// Package organizations implements comprehensive tests for the Datadog Organizations scraping pipeline.
 // Tests include common scenarios, edge cases, error conditions, property-based testing, and fuzz testing.
-package main
+package organizations
 
 import (
 	"context"
@@ -184,7 +184,7 @@ func TestOrganizationsScraperHandler_Success(t *testing.T) {
 	// 4. Test the functional pipeline transformations
 	
 	// Test event validation
-	err := validateEvent(event)
+	err := ValidateEvent(event)
 	assert.NoError(t, err, "Valid event should pass validation")
 }
 
@@ -252,7 +252,7 @@ func TestValidateEvent_InvalidParameters(t *testing.T) {
 	
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			err := validateEvent(tc.event)
+			err := ValidateEvent(tc.event)
 			
 			if tc.expectError {
 				assert.Error(t, err, tc.description)
@@ -658,7 +658,7 @@ func BenchmarkValidateEvent(b *testing.B) {
 	
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = validateEvent(event)
+		_ = ValidateEvent(event)
 	}
 }
 
@@ -891,7 +891,7 @@ func FuzzValidateEvent(f *testing.F) {
 			OrganizationID:   orgID,
 		}
 		
-		err := validateEvent(event)
+		err := ValidateEvent(event)
 		
 		// The function should not panic regardless of input
 		// We just check that it returns either nil or an error
@@ -961,7 +961,7 @@ func FuzzEventJSONParsing(f *testing.F) {
 			assert.Error(t, err, "Should return error for invalid JSON")
 		} else {
 			// JSON parsing succeeded, validate the event if possible
-			validationErr := validateEvent(event)
+			validationErr := ValidateEvent(event)
 			// Validation may succeed or fail depending on the parsed values
 			if validationErr != nil {
 				assert.Error(t, validationErr, "May have validation error for edge case values")
@@ -1050,7 +1050,7 @@ func TestIntegrationScenarios(t *testing.T) {
 		
 		// For now, we validate the structure is in place
 		event := createValidScraperEvent()
-		assert.NoError(t, validateEvent(event), "Event validation should pass")
+		assert.NoError(t, ValidateEvent(event), "Event validation should pass")
 		
 		// Test organization transformation with realistic data
 		mockOrgs := createMockOrganizations()
@@ -1,6 +1,6 @@
-// Package main implements comprehensive tests for the Datadog Services Scraper Lambda function.
+// Package services implements comprehensive tests for the Datadog Services scraping pipeline.
 // Tests include common scenarios, edge cases, error conditions, property-based tests, and functional pipeline validation.
-package main
+package services
 
 import (
 	"context"
@@ -20,6 +20,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"bacon/src/plugins/datadog/shared"
 	"bacon/src/plugins/datadog/types"
 )
 
@@ -57,7 +58,7 @@ func createValidServiceScraperEvent() types.ScraperEvent {
 func createServiceScraperEventWithFilter() types.ScraperEvent {
 	return types.ScraperEvent{
 		PageSize:         50,
-		FilterKeyword:    "team-owned-only",
+		Filter:           `has_team == true`,
 		IncludeInactive:  true,
 		SchemaVersion:    "v2.1",
 	}
@@ -74,7 +75,7 @@ func TestServicesScraperHandler_Success(t *testing.T) {
 	event := createValidServiceScraperEvent()
 	
 	// Test event validation
-	err := validateEvent(event)
+	err := ValidateEvent(event)
 	assert.NoError(t, err, "Valid event should pass validation")
 }
 
@@ -88,10 +89,10 @@ func TestServicesScraperHandler_TeamOwnedFilter(t *testing.T) {
 	event := createServiceScraperEventWithFilter()
 	
 	// Test event validation
-	err := validateEvent(event)
+	err := ValidateEvent(event)
 	assert.NoError(t, err, "Event with team-owned filter should pass validation")
 	
-	assert.Equal(t, "team-owned-only", event.FilterKeyword, "Should have team-owned filter")
+	assert.Equal(t, "has_team == true", event.Filter, "Should have team-owned filter")
 	assert.Equal(t, "v2.1", event.SchemaVersion, "Should have v2.1 schema version")
 }
 
@@ -117,7 +118,7 @@ func TestValidateEvent_ServiceSpecificCases(t *testing.T) {
 			name: "valid_with_schema_v2_1",
 			event: types.ScraperEvent{
 				PageSize:         50,
-				FilterKeyword:    "team-owned-only",
+				Filter:           `has_team == true`,
 				SchemaVersion:    "v2.1",
 			},
 			expectError: false,
@@ -133,6 +134,16 @@ func TestValidateEvent_ServiceSpecificCases(t *testing.T) {
 			expectError: false,
 			description: "Valid event with v2.2 schema",
 		},
+		{
+			name: "valid_with_schema_v3",
+			event: types.ScraperEvent{
+				PageSize:         100,
+				FilterKeyword:    "",
+				SchemaVersion:    "v3",
+			},
+			expectError: false,
+			description: "Valid event with v3 schema",
+		},
 		{
 			name: "valid_empty_schema",
 			event: types.ScraperEvent{
@@ -153,6 +164,16 @@ func TestValidateEvent_ServiceSpecificCases(t *testing.T) {
 			expectError: true,
 			description: "Invalid schema version should fail",
 		},
+		{
+			name: "invalid_team_owned_only_keyword",
+			event: types.ScraperEvent{
+				PageSize:         100,
+				FilterKeyword:    "team-owned-only",
+				SchemaVersion:    "v2.2",
+			},
+			expectError: true,
+			description: "The former team-owned-only mode should point to the has_team filter",
+		},
 		{
 			name: "invalid_negative_page_size",
 			event: types.ScraperEvent{
@@ -177,7 +198,7 @@ func TestValidateEvent_ServiceSpecificCases(t *testing.T) {
 	
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			err := validateEvent(tc.event)
+			err := ValidateEvent(tc.event)
 			
 			if tc.expectError {
 				assert.Error(t, err, tc.description)
@@ -188,6 +209,38 @@ func TestValidateEvent_ServiceSpecificCases(t *testing.T) {
 	}
 }
 
+// Test a v3 event validates and keeps only the v3 definitions the API lists
+func TestValidateEvent_V3Definitions(t *testing.T) {
+	event := types.ScraperEvent{PageSize: 100, SchemaVersion: "v3"}
+	require.NoError(t, ValidateEvent(event))
+
+	payload := `[
+		{"id": "v3-id", "type": "service-definition", "attributes": {"schema": {
+			"apiVersion": "v3",
+			"kind": "service",
+			"metadata": {"name": "checkout", "owner": "payments"},
+			"spec": {"tier": "1", "lifecycle": "production"}
+		}}},
+		{"id": "v2-id", "type": "service-definition", "attributes": {"schema": {
+			"schema-version": "v2.2",
+			"dd-service": "billing",
+			"team": "finance"
+		}}}
+	]`
+	var definitions []datadogV2.ServiceDefinitionData
+	require.NoError(t, json.Unmarshal([]byte(payload), &definitions))
+
+	services := keepRequestedSchema(lo.Map(definitions, shared.TransformServiceDefinition), event.SchemaVersion)
+
+	require.Len(t, services, 1)
+	assert.Equal(t, "checkout", services[0].Name)
+	assert.Equal(t, shared.SchemaVersionV3, services[0].SchemaVersion)
+	assert.Equal(t, "payments", services[0].Owner)
+	assert.Equal(t, "1", services[0].Tier)
+
+	assert.Len(t, keepRequestedSchema(lo.Map(definitions, shared.TransformServiceDefinition), "v2.2"), 2, "Versions the API serves are not narrowed")
+}
+
 // Test services list options creation
 func TestCreateServicesListOptions(t *testing.T) {
 	testCases := []struct {
@@ -239,8 +292,12 @@ func TestCreateServicesListOptions(t *testing.T) {
 			opts := createServicesListOptions(tc.pageSize, tc.pageNumber, tc.schemaVersion)
 			
 			assert.NotNil(t, opts, "Options should not be nil")
-			// Additional assertions would verify the options are properly configured
-			// This would require examining the actual API client structure
+			if tc.schemaVersion == "" {
+				assert.Nil(t, opts.SchemaVersion, "Empty schema version should not be sent")
+			} else {
+				require.NotNil(t, opts.SchemaVersion, "Schema version should be applied")
+				assert.Equal(t, tc.schemaVersion, string(*opts.SchemaVersion))
+			}
 		})
 	}
 }
@@ -732,7 +789,7 @@ func BenchmarkValidateEvent_Services(b *testing.B) {
 	
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = validateEvent(event)
+		_ = ValidateEvent(event)
 	}
 }
 
@@ -997,7 +1054,7 @@ func TestServicesEdgeCases(t *testing.T) {
 func TestServiceScraperEventJSONHandling(t *testing.T) {
 	originalEvent := types.ScraperEvent{
 		PageSize:         150,
-		FilterKeyword:    "team-owned-only",
+		Filter:           `has_team == true`,
 		IncludeInactive:  true,
 		SchemaVersion:    "v2.2",
 	}
@@ -1013,7 +1070,7 @@ func TestServiceScraperEventJSONHandling(t *testing.T) {
 	
 	// Verify fields
 	assert.Equal(t, originalEvent.PageSize, unmarshaledEvent.PageSize, "PageSize should match")
-	assert.Equal(t, originalEvent.FilterKeyword, unmarshaledEvent.FilterKeyword, "FilterKeyword should match")
+	assert.Equal(t, originalEvent.Filter, unmarshaledEvent.Filter, "Filter should match")
 	assert.Equal(t, originalEvent.IncludeInactive, unmarshaledEvent.IncludeInactive, "IncludeInactive should match")
 	assert.Equal(t, originalEvent.SchemaVersion, unmarshaledEvent.SchemaVersion, "SchemaVersion should match")
 }
@@ -1088,13 +1145,13 @@ func TestServicesIntegrationScenarios(t *testing.T) {
 		// Simulate realistic service catalog data processing pipeline
 		event := types.ScraperEvent{
 			PageSize:         50,
-			FilterKeyword:    "team-owned-only",
+			Filter:           `has_team == true`,
 			IncludeInactive:  false,
 			SchemaVersion:    "v2.2",
 		}
 		
 		// Validate event
-		assert.NoError(t, validateEvent(event), "Event validation should pass")
+		assert.NoError(t, ValidateEvent(event), "Event validation should pass")
 		
 		// Create realistic services data
 		services := []types.DatadogService{
@@ -1192,4 +1249,14 @@ func TestServicesIntegrationScenarios(t *testing.T) {
 		assert.Equal(t, "v2", metadata["api_version"], "Should use v2 API")
 		assert.Equal(t, true, metadata["functional_pipeline"], "Should use functional pipeline")
 	})
-}
\ No newline at end of file
+}
+// Test APM environment extraction from extra parameters
+func TestExtractAPMEnvironment(t *testing.T) {
+	assert.Equal(t, "", extractAPMEnvironment(types.ScraperEvent{}), "APM source should be disabled by default")
+	assert.Equal(t, "", extractAPMEnvironment(types.ScraperEvent{
+		ExtraParameters: map[string]interface{}{"apm_env": 42},
+	}), "Non-string environments should be ignored")
+	assert.Equal(t, "prod", extractAPMEnvironment(types.ScraperEvent{
+		ExtraParameters: map[string]interface{}{"apm_env": "prod"},
+	}))
+}
@@ -1,24 +1,30 @@
-// Package main implements comprehensive tests for the Datadog Teams Scraper Lambda function.
+// Package teams implements comprehensive tests for the Datadog Teams scraping pipeline.
 // Tests include common scenarios, edge cases, error conditions, and functional pipeline validation.
-package main
+package teams
 
 import (
 	"context"
 	"encoding/json"
 	"fmt"
 	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"testing/quick"
 	"time"
 	"unicode/utf8"
 
 	"github.com/aws/aws-xray-sdk-go/v2/xray"
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadog"
 	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV2"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"bacon/src/plugins/datadog/shared"
 	"bacon/src/plugins/datadog/types"
 )
 
@@ -63,7 +69,7 @@ func TestTeamsScraperHandler_Success(t *testing.T) {
 	// 4. Test the functional pipeline transformations
 	
 	// Test event validation
-	err := validateEvent(event)
+	err := ValidateEvent(event)
 	assert.NoError(t, err, "Valid event should pass validation")
 }
 
@@ -129,7 +135,7 @@ func TestValidateEvent_InvalidParameters(t *testing.T) {
 	
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			err := validateEvent(tc.event)
+			err := ValidateEvent(tc.event)
 			
 			if tc.expectError {
 				assert.Error(t, err, tc.description)
@@ -413,7 +419,7 @@ func BenchmarkValidateEvent(b *testing.B) {
 	
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = validateEvent(event)
+		_ = ValidateEvent(event)
 	}
 }
 
@@ -605,10 +611,10 @@ func TestValidateEvent_Properties(t *testing.T) {
 			PageSize: pageSize,
 		}
 		
-		err := validateEvent(event)
+		err := ValidateEvent(event)
 		
 		// Property: validation should be deterministic
-		err2 := validateEvent(event)
+		err2 := ValidateEvent(event)
 		if (err == nil) != (err2 == nil) {
 			return false
 		}
@@ -778,10 +784,10 @@ func FuzzValidateEvent(f *testing.F) {
 		}
 		
 		// Function should not panic regardless of input
-		err := validateEvent(event)
+		err := ValidateEvent(event)
 		
 		// Verify deterministic behavior
-		err2 := validateEvent(event)
+		err2 := ValidateEvent(event)
 		assert.Equal(t, err == nil, err2 == nil, "Validation should be deterministic")
 		
 		// Verify expected validation logic
@@ -815,7 +821,7 @@ func FuzzEventJSONParsing(f *testing.F) {
 			assert.Error(t, err, "Should return error for invalid JSON")
 		} else {
 			// JSON parsing succeeded - validate if reasonable
-			validationErr := validateEvent(event)
+			validationErr := ValidateEvent(event)
 			// Validation may pass or fail depending on parsed values
 			if event.PageSize < 0 || event.PageSize > 1000 {
 				assert.Error(t, validationErr, "Should fail validation for invalid parsed values")
@@ -955,7 +961,7 @@ func TestIntegrationScenarios(t *testing.T) {
 		
 		// For now, we validate the structure is in place
 		event := createValidScraperEvent()
-		assert.NoError(t, validateEvent(event), "Event validation should pass")
+		assert.NoError(t, ValidateEvent(event), "Event validation should pass")
 		
 		// Test metadata creation with realistic data
 		teams := []types.DatadogTeam{
@@ -979,4 +985,87 @@ func TestIntegrationScenarios(t *testing.T) {
 		assert.Equal(t, "v2", metadata["api_version"], "Should use v2 API")
 		assert.Equal(t, true, metadata["functional_pipeline"], "Should use functional pipeline")
 	})
-}
\ No newline at end of file
+}
+// newMembershipsTestClient points a Datadog client at a local test server
+func newMembershipsTestClient(t *testing.T, handler http.Handler) *datadog.APIClient {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	configuration := datadog.NewConfiguration()
+	configuration.Host = serverURL.Host
+	configuration.Scheme = serverURL.Scheme
+	configuration.RetryConfiguration.EnableRetry = false
+
+	return datadog.NewAPIClient(configuration)
+}
+
+// Test membership fetching against a local memberships endpoint
+func TestFetchTeamMemberships(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			observed := atomic.LoadInt32(&maxInFlight)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxInFlight, observed, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		teamID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v2/team/"), "/memberships")
+		if teamID == "team-broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{
+			"data": [
+				{"id": "m-1", "type": "team_memberships", "attributes": {"role": "admin"},
+				 "relationships": {"user": {"data": {"id": "%[1]s-admin", "type": "users"}}}},
+				{"id": "m-2", "type": "team_memberships", "attributes": {"role": null},
+				 "relationships": {"user": {"data": {"id": "%[1]s-member", "type": "users"}}}}
+			],
+			"included": [
+				{"id": "%[1]s-admin", "type": "users", "attributes": {"name": "Admin", "email": "admin@example.com", "status": "Active"}}
+			]
+		}`, teamID)
+	})
+
+	t.Run("fetches members for every team", func(t *testing.T) {
+		client := newMembershipsTestClient(t, handler)
+		teams := lo.Times(12, func(i int) types.DatadogTeam {
+			return types.DatadogTeam{ID: fmt.Sprintf("team-%d", i), Name: "Team", Handle: "team"}
+		})
+
+		membersByTeam, err := fetchTeamMemberships(context.Background(), client, teams, createValidScraperEvent())
+		require.NoError(t, err)
+		require.Len(t, membersByTeam, len(teams))
+
+		members := membersByTeam["team-3"]
+		require.Len(t, members, 2)
+		assert.Equal(t, "team-3-admin", members[0].ID)
+		assert.Equal(t, "Admin", members[0].Name)
+		assert.Equal(t, shared.TeamRoleAdmin, members[0].TeamRole)
+		assert.Equal(t, "team-3-member", members[1].ID)
+		assert.Equal(t, shared.TeamRoleMember, members[1].TeamRole)
+
+		assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(membershipConcurrency),
+			"Concurrent membership requests should be bounded")
+	})
+
+	t.Run("fails when a team cannot be fetched", func(t *testing.T) {
+		client := newMembershipsTestClient(t, handler)
+		teams := []types.DatadogTeam{{ID: "team-ok"}, {ID: "team-broken"}}
+
+		membersByTeam, err := fetchTeamMemberships(context.Background(), client, teams, createValidScraperEvent())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "team-broken")
+		assert.Nil(t, membersByTeam)
+	})
+}
@@ -1,6 +1,6 @@
-// Package main implements comprehensive tests for the Datadog Users Scraper Lambda function.
+// Package users implements comprehensive tests for the Datadog Users scraping pipeline.
 // Tests include common scenarios, edge cases, error conditions, and functional pipeline validation.
-package main
+package users
 
 import (
 	"context"
@@ -58,7 +58,7 @@ func TestUsersScraperHandler_ActiveUsersOnly(t *testing.T) {
 	event := createValidUserScraperEvent()
 	
 	// Test event validation
-	err := validateEvent(event)
+	err := ValidateEvent(event)
 	assert.NoError(t, err, "Valid event should pass validation")
 }
 
@@ -72,7 +72,7 @@ func TestUsersScraperHandler_IncludeInactive(t *testing.T) {
 	event := createUserScraperEventWithInactive()
 	
 	// Test event validation
-	err := validateEvent(event)
+	err := ValidateEvent(event)
 	assert.NoError(t, err, "Event with include inactive should pass validation")
 	
 	assert.True(t, event.IncludeInactive, "Should include inactive users")
@@ -141,7 +141,7 @@ func TestValidateEvent_UserSpecificCases(t *testing.T) {
 	
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			err := validateEvent(tc.event)
+			err := ValidateEvent(tc.event)
 			
 			if tc.expectError {
 				assert.Error(t, err, tc.description)
@@ -722,10 +722,10 @@ func TestValidateEvent_UsersProperties(t *testing.T) {
 			IncludeInactive: includeInactive,
 		}
 		
-		err := validateEvent(event)
+		err := ValidateEvent(event)
 		
 		// Property: validation should be deterministic
-		err2 := validateEvent(event)
+		err2 := ValidateEvent(event)
 		if (err == nil) != (err2 == nil) {
 			return false
 		}
@@ -757,7 +757,7 @@ func BenchmarkValidateEvent_Users(b *testing.B) {
 	
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = validateEvent(event)
+		_ = ValidateEvent(event)
 	}
 }
 
@@ -856,10 +856,10 @@ func FuzzValidateUserEvent(f *testing.F) {
 		}
 		
 		// Function should not panic regardless of input
-		err := validateEvent(event)
+		err := ValidateEvent(event)
 		
 		// Verify deterministic behavior
-		err2 := validateEvent(event)
+		err2 := ValidateEvent(event)
 		assert.Equal(t, err == nil, err2 == nil, "Validation should be deterministic")
 		
 		// Verify expected validation logic
@@ -893,7 +893,7 @@ func FuzzUserEventJSONParsing(f *testing.F) {
 			assert.Error(t, err, "Should return error for invalid JSON")
 		} else {
 			// JSON parsing succeeded - validate if reasonable
-			validationErr := validateEvent(event)
+			validationErr := ValidateEvent(event)
 			if event.PageSize < 0 || event.PageSize > 1000 {
 				assert.Error(t, validationErr, "Should fail validation for invalid parsed values")
 			}
@@ -1160,7 +1160,7 @@ func TestUsersIntegrationScenarios(t *testing.T) {
 		}
 		
 		// Validate event
-		assert.NoError(t, validateEvent(event), "Event validation should pass")
+		assert.NoError(t, ValidateEvent(event), "Event validation should pass")
 		
 		// Create realistic users data
 		users := []types.DatadogUser{
@@ -33,88 +33,108 @@ func WithTracedOperation[T any](ctx context.Context, operationName string, opera
 
 // StoreTeamsData stores team data using functional transformations
 // Pure functional pipeline for team storage
-func StoreTeamsData(ctx context.Context, teams []ddTypes.DatadogTeam) ([]string, error) {
-	return WithTracedOperation(ctx, "store-teams-data", func(tracedCtx context.Context) ([]string, error) {
+func StoreTeamsData(ctx context.Context, teams []ddTypes.DatadogTeam, generation string, startedAt time.Time) (StorageResult, error) {
+	return WithTracedOperation(ctx, "store-teams-data", func(tracedCtx context.Context) (StorageResult, error) {
+		return storeItems(tracedCtx, teamsStorageOptions(generation, startedAt), lo.Map(teams, createTeamStorageItem))
+	})
+}
+
+// TombstoneTeamsData marks teams not seen by the given generation, started at startedAt, as deleted
+// Only call after a complete, successful scrape of all teams
+func TombstoneTeamsData(ctx context.Context, generation string, startedAt, deletedAt time.Time) ([]TombstonedItem, error) {
+	return WithTracedOperation(ctx, "tombstone-teams-data", func(tracedCtx context.Context) ([]TombstonedItem, error) {
+		return tombstoneItems(tracedCtx, teamsStorageOptions(generation, startedAt), deletedAt)
+	})
+}
+
+// LoadKnownTeams reads the live teams stored by the teams scraper
+// Used to map service contacts onto teams; run the teams scraper first for up-to-date results
+func LoadKnownTeams(ctx context.Context) ([]ddTypes.DatadogTeam, error) {
+	return WithTracedOperation(ctx, "load-known-teams", func(tracedCtx context.Context) ([]ddTypes.DatadogTeam, error) {
 		client, err := createDynamoDBClient(tracedCtx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create dynamodb client: %w", err)
 		}
-
-		tableName := getTableName("DATADOG_TEAMS_TABLE", "datadog-teams")
-		
-		// Pure functional transformation pipeline
-		storageItems := lo.Map(teams, createTeamStorageItem)
-		batches := lo.Chunk(storageItems, 25) // DynamoDB batch limit
-		
-		var storedIDs []string
-		for i, batch := range batches {
-			batchIDs, err := executeBatchWrite(tracedCtx, client, tableName, batch)
-			if err != nil {
-				return nil, fmt.Errorf("failed to store teams batch %d: %w", i, err)
-			}
-			storedIDs = append(storedIDs, batchIDs...)
-		}
-		
-		return storedIDs, nil
+		return NewSnapshotStore(client, DefaultSnapshotTables()).LoadLiveTeams(tracedCtx)
 	})
 }
 
+// teamsStorageOptions returns the storage options of the teams table
+func teamsStorageOptions(generation string, startedAt time.Time) StorageOptions {
+	return DefaultStorageOptions(getTableName("DATADOG_TEAMS_TABLE", "datadog-teams"), "team_id", "handle", generation, startedAt)
+}
+
 // StoreUsersData stores user data using functional transformations
 // Pure functional pipeline for user storage
-func StoreUsersData(ctx context.Context, users []ddTypes.DatadogUser) ([]string, error) {
-	return WithTracedOperation(ctx, "store-users-data", func(tracedCtx context.Context) ([]string, error) {
-		client, err := createDynamoDBClient(tracedCtx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create dynamodb client: %w", err)
-		}
+func StoreUsersData(ctx context.Context, users []ddTypes.DatadogUser, generation string, startedAt time.Time) (StorageResult, error) {
+	return WithTracedOperation(ctx, "store-users-data", func(tracedCtx context.Context) (StorageResult, error) {
+		return storeItems(tracedCtx, usersStorageOptions(generation, startedAt), lo.Map(users, createUserStorageItem))
+	})
+}
 
-		tableName := getTableName("DATADOG_USERS_TABLE", "datadog-users")
-		
-		// Pure functional transformation pipeline
-		storageItems := lo.Map(users, createUserStorageItem)
-		batches := lo.Chunk(storageItems, 25)
-		
-		var storedIDs []string
-		for i, batch := range batches {
-			batchIDs, err := executeBatchWrite(tracedCtx, client, tableName, batch)
-			if err != nil {
-				return nil, fmt.Errorf("failed to store users batch %d: %w", i, err)
-			}
-			storedIDs = append(storedIDs, batchIDs...)
-		}
-		
-		return storedIDs, nil
+// TombstoneUsersData marks users not seen by the given generation, started at startedAt, as deleted
+// Only call after a complete, successful scrape of all users
+func TombstoneUsersData(ctx context.Context, generation string, startedAt, deletedAt time.Time) ([]TombstonedItem, error) {
+	return WithTracedOperation(ctx, "tombstone-users-data", func(tracedCtx context.Context) ([]TombstonedItem, error) {
+		return tombstoneItems(tracedCtx, usersStorageOptions(generation, startedAt), deletedAt)
 	})
 }
 
+// usersStorageOptions returns the storage options of the users table
+func usersStorageOptions(generation string, startedAt time.Time) StorageOptions {
+	return DefaultStorageOptions(getTableName("DATADOG_USERS_TABLE", "datadog-users"), "user_id", "email", generation, startedAt)
+}
+
 // StoreServicesData stores service data using functional transformations
 // Pure functional pipeline for service storage
-func StoreServicesData(ctx context.Context, services []ddTypes.DatadogService) ([]string, error) {
-	return WithTracedOperation(ctx, "store-services-data", func(tracedCtx context.Context) ([]string, error) {
-		client, err := createDynamoDBClient(tracedCtx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create dynamodb client: %w", err)
-		}
+func StoreServicesData(ctx context.Context, services []ddTypes.DatadogService, generation string, startedAt time.Time) (StorageResult, error) {
+	return WithTracedOperation(ctx, "store-services-data", func(tracedCtx context.Context) (StorageResult, error) {
+		return storeItems(tracedCtx, servicesStorageOptions(generation, startedAt), lo.Map(services, createServiceStorageItem))
+	})
+}
 
-		tableName := getTableName("DATADOG_SERVICES_TABLE", "datadog-services")
-		
-		// Pure functional transformation pipeline
-		storageItems := lo.Map(services, createServiceStorageItem)
-		batches := lo.Chunk(storageItems, 25)
-		
-		var storedIDs []string
-		for i, batch := range batches {
-			batchIDs, err := executeBatchWrite(tracedCtx, client, tableName, batch)
-			if err != nil {
-				return nil, fmt.Errorf("failed to store services batch %d: %w", i, err)
-			}
-			storedIDs = append(storedIDs, batchIDs...)
-		}
-		
-		return storedIDs, nil
+// TombstoneServicesData marks services not seen by the given generation, started at startedAt, as deleted
+// Only call after a complete, successful scrape of all services
+func TombstoneServicesData(ctx context.Context, generation string, startedAt, deletedAt time.Time) ([]TombstonedItem, error) {
+	return WithTracedOperation(ctx, "tombstone-services-data", func(tracedCtx context.Context) ([]TombstonedItem, error) {
+		return tombstoneItems(tracedCtx, servicesStorageOptions(generation, startedAt), deletedAt)
+	})
+}
+
+// servicesStorageOptions returns the storage options of the services table
+func servicesStorageOptions(generation string, startedAt time.Time) StorageOptions {
+	return DefaultStorageOptions(getTableName("DATADOG_SERVICES_TABLE", "datadog-services"), "service_id", "name", generation, startedAt)
+}
+
+// StoreOrganizationsData stores organization data using functional transformations
+// Pure functional pipeline for organization storage
+func StoreOrganizationsData(ctx context.Context, organizations []ddTypes.DatadogOrganization, generation string, startedAt time.Time) (StorageResult, error) {
+	return WithTracedOperation(ctx, "store-organizations-data", func(tracedCtx context.Context) (StorageResult, error) {
+		options := DefaultStorageOptions(getTableName("DATADOG_ORGANIZATIONS_TABLE", "datadog-organizations"), "organization_id", "name", generation, startedAt)
+		return storeItems(tracedCtx, options, lo.Map(organizations, createOrganizationStorageItem))
 	})
 }
 
+// storeItems writes storage items through the shared storage engine
+func storeItems(ctx context.Context, options StorageOptions, items []map[string]types.AttributeValue) (StorageResult, error) {
+	client, err := createDynamoDBClient(ctx)
+	if err != nil {
+		return StorageResult{}, fmt.Errorf("failed to create dynamodb client: %w", err)
+	}
+
+	return NewStorageEngine(client, options).Write(ctx, items)
+}
+
+// tombstoneItems marks items outside the current generation as deleted through the shared storage engine
+func tombstoneItems(ctx context.Context, options StorageOptions, deletedAt time.Time) ([]TombstonedItem, error) {
+	client, err := createDynamoDBClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamodb client: %w", err)
+	}
+
+	return NewStorageEngine(client, options).Tombstone(ctx, deletedAt)
+}
+
 // Pure transformation functions for DynamoDB storage items
 
 // createTeamStorageItem converts a team to DynamoDB storage format
@@ -126,6 +146,7 @@ func createTeamStorageItem(team ddTypes.DatadogTeam, _ int) map[string]types.Att
 		"handle":      &types.AttributeValueMemberS{Value: team.Handle},
 		"description": &types.AttributeValueMemberS{Value: team.Description},
 		"members":     createStringListAttribute(lo.Map(team.Members, func(user ddTypes.DatadogUser, _ int) string { return user.ID })),
+		"member_roles": createMapAttribute(createMemberRoles(team.Members)),
 		"services":    createStringListAttribute(lo.Map(team.Services, func(service ddTypes.DatadogService, _ int) string { return service.ID })),
 		"links":       createLinksAttribute(team.Links),
 		"metadata":    createMapAttribute(team.Metadata),
@@ -163,9 +184,12 @@ func createServiceStorageItem(service ddTypes.DatadogService, _ int) map[string]
 		"service_id":    &types.AttributeValueMemberS{Value: service.ID},
 		"name":          &types.AttributeValueMemberS{Value: service.Name},
 		"owner":         &types.AttributeValueMemberS{Value: service.Owner},
+		"owner_source":  &types.AttributeValueMemberS{Value: service.OwnerSource},
 		"teams":         createStringListAttribute(service.Teams),
 		"tags":          createStringListAttribute(service.Tags),
-		"schema":        &types.AttributeValueMemberS{Value: service.Schema},
+		"schema":        &types.AttributeValueMemberS{Value: service.SchemaVersion},
+		"kind":          &types.AttributeValueMemberS{Value: service.Kind},
+		"application":   &types.AttributeValueMemberS{Value: service.Application},
 		"description":   &types.AttributeValueMemberS{Value: service.Description},
 		"tier":          &types.AttributeValueMemberS{Value: service.Tier},
 		"lifecycle":     &types.AttributeValueMemberS{Value: service.Lifecycle},
@@ -173,16 +197,42 @@ func createServiceStorageItem(service ddTypes.DatadogService, _ int) map[string]
 		"languages":     createStringListAttribute(service.Languages),
 		"contacts":      createContactsAttribute(service.Contacts),
 		"links":         createServiceLinksAttribute(service.Links),
+		"repos":         createServiceLinksAttribute(service.Repos),
 		"integrations":  createMapAttribute(service.Integrations),
 		"dependencies":  createStringListAttribute(service.Dependencies),
+		"component_of":  createStringListAttribute(service.ComponentOf),
 		"created_at":    &types.AttributeValueMemberS{Value: service.CreatedAt.Format(time.RFC3339)},
 		"updated_at":    &types.AttributeValueMemberS{Value: service.UpdatedAt.Format(time.RFC3339)},
 		"scraped_at":    &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
 	}
 }
 
+// createOrganizationStorageItem converts an organization to DynamoDB storage format
+// Pure function with no side effects
+func createOrganizationStorageItem(org ddTypes.DatadogOrganization, _ int) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"organization_id": &types.AttributeValueMemberS{Value: org.ID},
+		"name":            &types.AttributeValueMemberS{Value: org.Name},
+		"description":     &types.AttributeValueMemberS{Value: org.Description},
+		"settings":        createMapAttribute(org.Settings),
+		"users":           createStringListAttribute(lo.Map(org.Users, func(user ddTypes.DatadogUser, _ int) string { return user.ID })),
+		"teams":           createStringListAttribute(lo.Map(org.Teams, func(team ddTypes.DatadogTeam, _ int) string { return team.ID })),
+		"created_at":      &types.AttributeValueMemberS{Value: org.CreatedAt.Format(time.RFC3339)},
+		"updated_at":      &types.AttributeValueMemberS{Value: org.UpdatedAt.Format(time.RFC3339)},
+		"scraped_at":      &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+	}
+}
+
 // Pure helper functions for DynamoDB attribute creation
 
+// createMemberRoles maps member user IDs to their role within the team
+// Pure function for member role extraction
+func createMemberRoles(members []ddTypes.DatadogUser) map[string]interface{} {
+	return lo.SliceToMap(members, func(member ddTypes.DatadogUser) (string, interface{}) {
+		return member.ID, member.TeamRole
+	})
+}
+
 // createStringListAttribute creates a DynamoDB string list attribute
 // Pure function for attribute creation
 func createStringListAttribute(items []string) *types.AttributeValueMemberSS {
@@ -253,6 +303,8 @@ func createMapAttribute(data map[string]interface{}) *types.AttributeValueMember
 			result[key] = &types.AttributeValueMemberBOOL{Value: v}
 		case float64:
 			result[key] = &types.AttributeValueMemberN{Value: fmt.Sprintf("%.2f", v)}
+		case map[string]interface{}:
+			result[key] = createMapAttribute(v)
 		default:
 			result[key] = &types.AttributeValueMemberS{Value: fmt.Sprintf("%v", v)}
 		}
@@ -280,45 +332,3 @@ func getTableName(envVar, defaultName string) string {
 	tableName := os.Getenv(envVar)
 	return lo.Ternary(tableName != "", tableName, defaultName)
 }
-
-// executeBatchWrite executes a batch write operation to DynamoDB
-// Pure function for batch write operations
-func executeBatchWrite(ctx context.Context, client *dynamodb.Client, tableName string, items []map[string]types.AttributeValue) ([]string, error) {
-	if len(items) == 0 {
-		return []string{}, nil
-	}
-
-	// Create batch write items using lo.Map
-	writeRequests := lo.Map(items, func(item map[string]types.AttributeValue, _ int) types.WriteRequest {
-		return types.WriteRequest{
-			PutRequest: &types.PutRequest{
-				Item: item,
-			},
-		}
-	})
-
-	_, err := client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
-		RequestItems: map[string][]types.WriteRequest{
-			tableName: writeRequests,
-		},
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute batch write: %w", err)
-	}
-
-	// Extract IDs from stored items using lo.Map
-	storedIDs := lo.Map(items, func(item map[string]types.AttributeValue, _ int) string {
-		// Try different ID field names depending on the data type
-		for _, idField := range []string{"team_id", "user_id", "service_id"} {
-			if idAttr, exists := item[idField]; exists {
-				if s, ok := idAttr.(*types.AttributeValueMemberS); ok {
-					return s.Value
-				}
-			}
-		}
-		return ""
-	})
-
-	return lo.Compact(storedIDs), nil
-}
\ No newline at end of file
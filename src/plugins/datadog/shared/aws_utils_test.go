@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -191,7 +192,7 @@ func TestStoreServicesData(t *testing.T) {
 					Owner:        "john.doe",
 					Teams:        []string{"team-1"},
 					Tags:         []string{"api", "backend"},
-					Schema:       "v2.0",
+					SchemaVersion: "v2.0",
 					Description:  "Main API service",
 					Tier:         "critical",
 					Lifecycle:    "production",
@@ -371,7 +372,7 @@ func TestCreateServiceStorageItem(t *testing.T) {
 		Owner:        "john.doe",
 		Teams:        []string{"team-1"},
 		Tags:         []string{"api", "backend"},
-		Schema:       "v2.0",
+		SchemaVersion: "v2.0",
 		Description:  "Main API service",
 		Tier:         "critical",
 		Lifecycle:    "production",
@@ -610,19 +611,19 @@ func TestGetTableName(t *testing.T) {
 	}
 }
 
-// Test executeBatchWrite function
-func TestExecuteBatchWrite(t *testing.T) {
+// Test extractItemIDs function
+func TestExtractItemIDs(t *testing.T) {
 	testCases := []struct {
-		name        string
-		items       []map[string]types.AttributeValue
-		expectError bool
-		expectedIDs int
+		name         string
+		items        []map[string]types.AttributeValue
+		keyAttribute string
+		expectedIDs  []string
 	}{
 		{
-			name:        "empty items",
-			items:       []map[string]types.AttributeValue{},
-			expectError: false,
-			expectedIDs: 0,
+			name:         "empty items",
+			items:        []map[string]types.AttributeValue{},
+			keyAttribute: "team_id",
+			expectedIDs:  []string{},
 		},
 		{
 			name: "single item with team_id",
@@ -632,16 +633,12 @@ func TestExecuteBatchWrite(t *testing.T) {
 					"name":    &types.AttributeValueMemberS{Value: "Engineering"},
 				},
 			},
-			expectError: false,
-			expectedIDs: 1,
+			keyAttribute: "team_id",
+			expectedIDs:  []string{"team-123"},
 		},
 		{
-			name: "multiple items with different ID fields",
+			name: "items without the key attribute are dropped",
 			items: []map[string]types.AttributeValue{
-				{
-					"team_id": &types.AttributeValueMemberS{Value: "team-123"},
-					"name":    &types.AttributeValueMemberS{Value: "Engineering"},
-				},
 				{
 					"user_id": &types.AttributeValueMemberS{Value: "user-456"},
 					"email":   &types.AttributeValueMemberS{Value: "user@example.com"},
@@ -650,39 +647,21 @@ func TestExecuteBatchWrite(t *testing.T) {
 					"service_id": &types.AttributeValueMemberS{Value: "service-789"},
 					"name":       &types.AttributeValueMemberS{Value: "API Service"},
 				},
+				{
+					"user_id": &types.AttributeValueMemberN{Value: "42"},
+				},
 			},
-			expectError: false,
-			expectedIDs: 3,
+			keyAttribute: "user_id",
+			expectedIDs:  []string{"user-456"},
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			// We can't easily test the actual DynamoDB call without mocking
-			// but we can test the ID extraction logic by testing with mock data
-			
-			// Test the ID extraction logic directly
-			if len(tc.items) > 0 {
-				// Extract IDs manually to test the logic
-				var extractedIDs []string
-				for _, item := range tc.items {
-					for _, idField := range []string{"team_id", "user_id", "service_id"} {
-						if idAttr, exists := item[idField]; exists {
-							if s, ok := idAttr.(*types.AttributeValueMemberS); ok {
-								extractedIDs = append(extractedIDs, s.Value)
-								break
-							}
-						}
-					}
-				}
-
-				if len(extractedIDs) != tc.expectedIDs {
-					t.Errorf("Expected %d IDs, got %d", tc.expectedIDs, len(extractedIDs))
-				}
+			result := extractItemIDs(tc.items, tc.keyAttribute)
+			if !reflect.DeepEqual(result, tc.expectedIDs) {
+				t.Errorf("Expected %v, got %v", tc.expectedIDs, result)
 			}
-
-			// The actual executeBatchWrite would fail in test environment
-			// due to missing AWS credentials, which is expected behavior
 		})
 	}
 }
@@ -4,10 +4,14 @@ package shared
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 
 	"github.com/DataDog/datadog-api-client-go/v2/api/datadog"
 	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV2"
+
+	"bacon/src/plugins/datadog/types"
 )
 
 // DatadogClientConfig holds configuration for the Datadog API client
@@ -15,6 +19,7 @@ type DatadogClientConfig struct {
 	APIKey string
 	AppKey string
 	Site   string // e.g., "datadoghq.com", "datadoghq.eu"
+	Host   string // base URL override, e.g. "http://127.0.0.1:8080" for a local API stand-in; takes precedence over Site
 }
 
 // CreateDatadogClient creates a new Datadog API v2 client with proper authentication
@@ -40,6 +45,7 @@ func getDatadogConfig() (DatadogClientConfig, error) {
 	apiKey := os.Getenv("DATADOG_API_KEY")
 	appKey := os.Getenv("DATADOG_APP_KEY")
 	site := os.Getenv("DATADOG_SITE")
+	host := os.Getenv("DATADOG_HOST")
 
 	if apiKey == "" {
 		return DatadogClientConfig{}, fmt.Errorf("DATADOG_API_KEY environment variable is required")
@@ -53,10 +59,17 @@ func getDatadogConfig() (DatadogClientConfig, error) {
 		site = "datadoghq.com" // default site
 	}
 
+	if host != "" {
+		if hostURL, err := url.Parse(host); err != nil || hostURL.Scheme == "" || hostURL.Host == "" {
+			return DatadogClientConfig{}, fmt.Errorf("DATADOG_HOST must be a base URL such as http://127.0.0.1:8080, got %q", host)
+		}
+	}
+
 	return DatadogClientConfig{
 		APIKey: apiKey,
 		AppKey: appKey,
 		Site:   site,
+		Host:   host,
 	}, nil
 }
 
@@ -69,11 +82,18 @@ func createClientFromConfig(config DatadogClientConfig) *datadog.APIClient {
 	configuration.AddDefaultHeader("DD-API-KEY", config.APIKey)
 	configuration.AddDefaultHeader("DD-APPLICATION-KEY", config.AppKey)
 	
-	// Set the site if specified
-	if config.Site != "" {
-		configuration.Host = fmt.Sprintf("https://api.%s", config.Site)
+	// Point the client at an explicit host or at the API host of the site; the client
+	// expects a bare host name with the scheme configured separately
+	if hostURL, err := url.Parse(config.Host); config.Host != "" && err == nil && hostURL.Host != "" {
+		configuration.Host = hostURL.Host
+		configuration.Scheme = hostURL.Scheme
+	} else if config.Site != "" {
+		configuration.Host = fmt.Sprintf("api.%s", config.Site)
 	}
 
+	// Retry rate-limited and failed requests, honouring the X-RateLimit-Reset header
+	configuration.RetryConfiguration.EnableRetry = true
+
 	// Enable unstable operations for access to latest endpoints
 	configuration.SetUnstableOperationEnabled("v2.ListTeams", true)
 	configuration.SetUnstableOperationEnabled("v2.CreateTeam", true)
@@ -116,4 +136,56 @@ func GetDatadogAPIInfo(client *datadog.APIClient) map[string]interface{} {
 		"has_api_key": hasAPIKey && apiKey != "",
 		"has_app_key": hasAppKey && appKey != "",
 	}
-}
\ No newline at end of file
+}
+// serviceDependenciesOperation identifies the APM service dependencies endpoint for server resolution
+const serviceDependenciesOperation = "v1.ServiceDependenciesApi.ListServiceDependencies"
+
+// FetchServiceDependencies retrieves the APM service map for an environment
+// The API client has no typed wrapper for this endpoint, so the request goes through its generic transport
+func FetchServiceDependencies(ctx context.Context, client *datadog.APIClient, env string) (map[string]types.DatadogServiceDependency, error) {
+	if env == "" {
+		return nil, fmt.Errorf("environment is required to fetch service dependencies")
+	}
+
+	basePath, err := client.GetConfig().ServerURLWithContext(ctx, serviceDependenciesOperation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve service dependencies endpoint: %w", err)
+	}
+
+	headers := map[string]string{"Accept": "application/json"}
+	datadog.SetAuthKeys(
+		ctx,
+		&headers,
+		[2]string{"apiKeyAuth", "DD-API-KEY"},
+		[2]string{"appKeyAuth", "DD-APPLICATION-KEY"},
+	)
+
+	query := url.Values{}
+	query.Add("env", env)
+
+	req, err := client.PrepareRequest(ctx, basePath+"/api/v1/service_dependencies", http.MethodGet, nil, headers, query, url.Values{}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare service dependencies request: %w", err)
+	}
+
+	resp, err := client.CallAPI(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch service dependencies: %w", err)
+	}
+
+	body, err := datadog.ReadBody(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service dependencies response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("datadog API returned non-success status: %d", resp.StatusCode)
+	}
+
+	var dependencies map[string]types.DatadogServiceDependency
+	if err := client.Decode(&dependencies, body, resp.Header.Get("Content-Type")); err != nil {
+		return nil, fmt.Errorf("failed to decode service dependencies: %w", err)
+	}
+
+	return dependencies, nil
+}
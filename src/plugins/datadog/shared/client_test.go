@@ -1,7 +1,11 @@
 package shared
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
@@ -67,6 +71,7 @@ func TestCreateDatadogClient(t *testing.T) {
 			os.Unsetenv("DATADOG_API_KEY")
 			os.Unsetenv("DATADOG_APP_KEY")
 			os.Unsetenv("DATADOG_SITE")
+			os.Unsetenv("DATADOG_HOST")
 
 			// Set test environment variables
 			if tc.apiKey != "" {
@@ -138,6 +143,15 @@ func TestCreateDatadogClientWithConfig(t *testing.T) {
 				Site:   "datadoghq.com",
 			},
 		},
+		{
+			name: "host override",
+			config: DatadogClientConfig{
+				APIKey: "test-api-key",
+				AppKey: "test-app-key",
+				Site:   "datadoghq.com",
+				Host:   "http://127.0.0.1:8126",
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -177,13 +191,22 @@ func TestCreateDatadogClientWithConfig(t *testing.T) {
 			}
 
 			// Verify host is set correctly
-			expectedHost := ""
+			expectedHost, expectedScheme := "", ""
 			if tc.config.Site != "" {
-				expectedHost = fmt.Sprintf("https://api.%s", tc.config.Site)
+				expectedHost = fmt.Sprintf("api.%s", tc.config.Site)
+			}
+			if tc.config.Host != "" {
+				expectedHost, expectedScheme = "127.0.0.1:8126", "http"
 			}
 			if expectedHost != "" && config.Host != expectedHost {
 				t.Errorf("Expected host to be %s, got %s", expectedHost, config.Host)
 			}
+			if config.Scheme != expectedScheme {
+				t.Errorf("Expected scheme to be %s, got %s", expectedScheme, config.Scheme)
+			}
+			if !config.RetryConfiguration.EnableRetry {
+				t.Errorf("Expected retries to be enabled")
+			}
 		})
 	}
 }
@@ -275,6 +298,26 @@ func TestGetDatadogConfig(t *testing.T) {
 	}
 }
 
+// Test getDatadogConfig host override handling
+func TestGetDatadogConfig_Host(t *testing.T) {
+	t.Setenv("DATADOG_API_KEY", "test-api-key")
+	t.Setenv("DATADOG_APP_KEY", "test-app-key")
+
+	t.Setenv("DATADOG_HOST", "http://127.0.0.1:8126")
+	config, err := getDatadogConfig()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.Host != "http://127.0.0.1:8126" {
+		t.Errorf("Expected Host to be http://127.0.0.1:8126, got %s", config.Host)
+	}
+
+	t.Setenv("DATADOG_HOST", "127.0.0.1:8126")
+	if _, err := getDatadogConfig(); err == nil {
+		t.Errorf("Expected error for host without scheme")
+	}
+}
+
 // Test ValidateDatadogConnection function with mock server
 func TestValidateDatadogConnection(t *testing.T) {
 	testCases := []struct {
@@ -523,4 +566,47 @@ func TestCreateDatadogClient_EdgeCases(t *testing.T) {
 func TestValidateDatadogConnection_ContextCancellation(t *testing.T) {
 	// Skip complex mock server test - requires deep integration with Datadog client
 	t.Skip("Skipping context cancellation test - requires complex Datadog client integration")
-}
\ No newline at end of file
+}
+// Test FetchServiceDependencies against a stub APM service map endpoint
+func TestFetchServiceDependencies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/service_dependencies" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Get("env") != "prod" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"checkout": {"calls": ["billing", "inventory"]}, "billing": {"calls": []}}`)
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	client := CreateDatadogClientWithConfig(DatadogClientConfig{APIKey: "key", AppKey: "app"})
+	client.GetConfig().Host = serverURL.Host
+	client.GetConfig().Scheme = serverURL.Scheme
+	client.GetConfig().RetryConfiguration.EnableRetry = false
+
+	dependencies, err := FetchServiceDependencies(context.Background(), client, "prod")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(dependencies) != 2 {
+		t.Errorf("Expected 2 services, got %d", len(dependencies))
+	}
+
+	if calls := dependencies["checkout"].Calls; len(calls) != 2 || calls[0] != "billing" {
+		t.Errorf("Unexpected calls for checkout: %v", calls)
+	}
+
+	if _, err := FetchServiceDependencies(context.Background(), client, "staging"); err == nil {
+		t.Error("Expected error for non-success status")
+	}
+
+	if _, err := FetchServiceDependencies(context.Background(), client, ""); err == nil {
+		t.Error("Expected error for empty environment")
+	}
+}
@@ -0,0 +1,533 @@
+package ddtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/samber/lo"
+)
+
+// targetPrefix prefixes the X-Amz-Target header of every DynamoDB JSON protocol request
+const targetPrefix = "DynamoDB_20120810."
+
+// DefaultTables are the scraper tables and key attributes created by NewDynamoDB
+var DefaultTables = map[string]string{
+	"datadog-teams":         "team_id",
+	"datadog-users":         "user_id",
+	"datadog-services":      "service_id",
+	"datadog-organizations": "organization_id",
+	"datadog-snapshots":     "snapshot_key",
+}
+
+// attributeValue is a DynamoDB attribute value in wire format, e.g. {"S": "payments"}
+type attributeValue = map[string]interface{}
+
+// wireItem is a DynamoDB item in wire format
+type wireItem = map[string]attributeValue
+
+// Item is a stored item with attribute values simplified to Go values
+// S and N become strings, BOOL a bool, SS and NS a []string, L a []interface{} and M a map
+type Item map[string]interface{}
+
+// table holds the items of a table in insertion order
+type table struct {
+	key   string
+	order []string
+	items map[string]wireItem
+}
+
+// DynamoDB emulates the DynamoDB operations used by the scraper storage layer:
+// PutItem, BatchWriteItem, GetItem, UpdateItem (SET only) and Scan, including condition,
+// filter and projection expressions
+type DynamoDB struct {
+	*httptest.Server
+
+	// PageSize limits the items returned per Scan page; zero returns every item in one page
+	PageSize int
+
+	mu     sync.Mutex
+	tables map[string]*table
+}
+
+// NewDynamoDB starts a DynamoDB stand-in with the default scraper tables that is closed when
+// the test finishes
+func NewDynamoDB(t testing.TB) *DynamoDB {
+	t.Helper()
+
+	db := &DynamoDB{tables: make(map[string]*table)}
+	for name, key := range DefaultTables {
+		db.CreateTable(name, key)
+	}
+
+	db.Server = httptest.NewServer(http.HandlerFunc(db.serve))
+	t.Cleanup(db.Close)
+	return db
+}
+
+// Configure points the AWS SDK at the stand-in with static credentials for the duration of the test
+func (d *DynamoDB) Configure(t testing.TB) {
+	t.Helper()
+	t.Setenv("AWS_ENDPOINT_URL_DYNAMODB", d.URL)
+	t.Setenv("AWS_ACCESS_KEY_ID", "ddtest")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "ddtest")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+}
+
+// CreateTable creates an empty table keyed by a string hash key
+func (d *DynamoDB) CreateTable(name, keyAttribute string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.tables[name] = &table{key: keyAttribute, items: make(map[string]wireItem)}
+}
+
+// Items returns the items of a table in insertion order
+func (d *DynamoDB) Items(tableName string) []Item {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	t, found := d.tables[tableName]
+	if !found {
+		return nil
+	}
+	return lo.Map(t.order, func(key string, _ int) Item {
+		return simplifyItem(t.items[key])
+	})
+}
+
+// Item returns a single item by its key
+func (d *DynamoDB) Item(tableName, key string) (Item, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	t, found := d.tables[tableName]
+	if !found {
+		return nil, false
+	}
+	item, found := t.items[key]
+	return simplifyItem(item), found
+}
+
+// dynamoDBError is a DynamoDB JSON protocol error
+type dynamoDBError struct {
+	code    string
+	message string
+}
+
+func (e *dynamoDBError) Error() string {
+	return fmt.Sprintf("%s: %s", e.code, e.message)
+}
+
+// errorf creates a DynamoDB error with an exception code
+func errorf(code, format string, args ...interface{}) error {
+	return &dynamoDBError{code: code, message: fmt.Sprintf(format, args...)}
+}
+
+// serve dispatches a JSON protocol request on its X-Amz-Target operation
+func (d *DynamoDB) serve(w http.ResponseWriter, r *http.Request) {
+	operations := map[string]func(map[string]json.RawMessage) (interface{}, error){
+		"PutItem":        d.putItem,
+		"BatchWriteItem": d.batchWriteItem,
+		"GetItem":        d.getItem,
+		"UpdateItem":     d.updateItem,
+		"Scan":           d.scan,
+	}
+
+	operation, found := operations[strings.TrimPrefix(r.Header.Get("X-Amz-Target"), targetPrefix)]
+	if !found {
+		writeDynamoDBError(w, errorf("UnknownOperationException", "unsupported operation %q", r.Header.Get("X-Amz-Target")))
+		return
+	}
+
+	var input map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeDynamoDBError(w, errorf("SerializationException", "%v", err))
+		return
+	}
+
+	d.mu.Lock()
+	output, err := operation(input)
+	d.mu.Unlock()
+
+	if err != nil {
+		writeDynamoDBError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	_ = json.NewEncoder(w).Encode(output)
+}
+
+// writeDynamoDBError writes an error in the JSON protocol error format
+func writeDynamoDBError(w http.ResponseWriter, err error) {
+	dbErr, ok := err.(*dynamoDBError)
+	if !ok {
+		dbErr = &dynamoDBError{code: "ValidationException", message: err.Error()}
+	}
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"__type":  "com.amazonaws.dynamodb.v20120810#" + dbErr.code,
+		"message": dbErr.message,
+	})
+}
+
+// putItem implements PutItem with an optional condition expression
+func (d *DynamoDB) putItem(input map[string]json.RawMessage) (interface{}, error) {
+	t, err := d.table(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var item wireItem
+	if err := decodeField(input, "Item", &item); err != nil {
+		return nil, err
+	}
+
+	key, err := t.keyOf(item)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCondition(input, t.items[key]); err != nil {
+		return nil, err
+	}
+
+	t.put(key, item)
+	return map[string]interface{}{}, nil
+}
+
+// batchWriteItem implements BatchWriteItem; every request is processed
+func (d *DynamoDB) batchWriteItem(input map[string]json.RawMessage) (interface{}, error) {
+	var requests map[string][]struct {
+		PutRequest    *struct{ Item wireItem }
+		DeleteRequest *struct{ Key wireItem }
+	}
+	if err := decodeField(input, "RequestItems", &requests); err != nil {
+		return nil, err
+	}
+
+	for tableName, writes := range requests {
+		t, found := d.tables[tableName]
+		if !found {
+			return nil, errorf("ResourceNotFoundException", "table %s not found", tableName)
+		}
+
+		for _, write := range writes {
+			switch {
+			case write.PutRequest != nil:
+				key, err := t.keyOf(write.PutRequest.Item)
+				if err != nil {
+					return nil, err
+				}
+				t.put(key, write.PutRequest.Item)
+			case write.DeleteRequest != nil:
+				key, err := t.keyOf(write.DeleteRequest.Key)
+				if err != nil {
+					return nil, err
+				}
+				t.delete(key)
+			}
+		}
+	}
+
+	return map[string]interface{}{"UnprocessedItems": map[string]interface{}{}}, nil
+}
+
+// getItem implements GetItem
+func (d *DynamoDB) getItem(input map[string]json.RawMessage) (interface{}, error) {
+	t, err := d.table(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var keyItem wireItem
+	if err := decodeField(input, "Key", &keyItem); err != nil {
+		return nil, err
+	}
+	key, err := t.keyOf(keyItem)
+	if err != nil {
+		return nil, err
+	}
+
+	if item, found := t.items[key]; found {
+		return map[string]interface{}{"Item": item}, nil
+	}
+	return map[string]interface{}{}, nil
+}
+
+// updateItem implements UpdateItem for SET update expressions, creating missing items as DynamoDB does
+func (d *DynamoDB) updateItem(input map[string]json.RawMessage) (interface{}, error) {
+	t, err := d.table(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var keyItem wireItem
+	if err := decodeField(input, "Key", &keyItem); err != nil {
+		return nil, err
+	}
+	key, err := t.keyOf(keyItem)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := t.items[key]
+	if err := checkCondition(input, existing); err != nil {
+		return nil, err
+	}
+
+	names, values, err := decodeExpressionAttributes(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var update string
+	if err := decodeField(input, "UpdateExpression", &update); err != nil {
+		return nil, err
+	}
+
+	action, assignments, _ := strings.Cut(strings.TrimSpace(update), " ")
+	if !strings.EqualFold(action, "SET") {
+		return nil, errorf("ValidationException", "unsupported update expression %q", update)
+	}
+
+	updated := lo.Assign(wireItem{}, existing, keyItem)
+	for _, assignment := range strings.Split(assignments, ",") {
+		path, placeholder, found := strings.Cut(assignment, "=")
+		value, known := values[strings.TrimSpace(placeholder)]
+		if !found || !known {
+			return nil, errorf("ValidationException", "unsupported assignment %q", assignment)
+		}
+		updated[resolveName(strings.TrimSpace(path), names)] = value
+	}
+
+	t.put(key, updated)
+	return map[string]interface{}{}, nil
+}
+
+// scan implements Scan with filter and projection expressions and PageSize paging
+func (d *DynamoDB) scan(input map[string]json.RawMessage) (interface{}, error) {
+	t, err := d.table(input)
+	if err != nil {
+		return nil, err
+	}
+
+	names, values, err := decodeExpressionAttributes(input)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := func(wireItem) bool { return true }
+	var filterExpression string
+	if err := decodeField(input, "FilterExpression", &filterExpression); err != nil {
+		return nil, err
+	}
+	if filterExpression != "" {
+		if filter, err = compileCondition(filterExpression, names, values); err != nil {
+			return nil, err
+		}
+	}
+
+	var startKey wireItem
+	if err := decodeField(input, "ExclusiveStartKey", &startKey); err != nil {
+		return nil, err
+	}
+
+	start := 0
+	if startKey != nil {
+		key, err := t.keyOf(startKey)
+		if err != nil {
+			return nil, err
+		}
+		start = lo.IndexOf(t.order, key) + 1
+	}
+
+	end := lo.Ternary(d.PageSize > 0, min(start+d.PageSize, len(t.order)), len(t.order))
+	scanned := lo.Map(t.order[start:end], func(key string, _ int) wireItem { return t.items[key] })
+
+	var projection string
+	if err := decodeField(input, "ProjectionExpression", &projection); err != nil {
+		return nil, err
+	}
+
+	items := lo.Map(lo.Filter(scanned, func(item wireItem, _ int) bool { return filter(item) }), func(item wireItem, _ int) wireItem {
+		return project(item, projection, names)
+	})
+
+	output := map[string]interface{}{"Items": items, "Count": len(items), "ScannedCount": len(scanned)}
+	if end < len(t.order) {
+		output["LastEvaluatedKey"] = wireItem{t.key: t.items[t.order[end-1]][t.key]}
+	}
+	return output, nil
+}
+
+// table resolves the TableName of a request
+func (d *DynamoDB) table(input map[string]json.RawMessage) (*table, error) {
+	var name string
+	if err := decodeField(input, "TableName", &name); err != nil {
+		return nil, err
+	}
+
+	t, found := d.tables[name]
+	if !found {
+		return nil, errorf("ResourceNotFoundException", "table %s not found", name)
+	}
+	return t, nil
+}
+
+// keyOf returns the string hash key of an item
+func (t *table) keyOf(item wireItem) (string, error) {
+	key, ok := item[t.key]["S"].(string)
+	if !ok {
+		return "", errorf("ValidationException", "missing string key attribute %s", t.key)
+	}
+	return key, nil
+}
+
+// put stores an item, keeping the position of items that are overwritten
+func (t *table) put(key string, item wireItem) {
+	if _, exists := t.items[key]; !exists {
+		t.order = append(t.order, key)
+	}
+	t.items[key] = item
+}
+
+// delete removes an item
+func (t *table) delete(key string) {
+	delete(t.items, key)
+	t.order = lo.Without(t.order, key)
+}
+
+// checkCondition evaluates the ConditionExpression of a request against the existing item
+func checkCondition(input map[string]json.RawMessage, existing wireItem) error {
+	var expression string
+	if err := decodeField(input, "ConditionExpression", &expression); err != nil || expression == "" {
+		return err
+	}
+
+	names, values, err := decodeExpressionAttributes(input)
+	if err != nil {
+		return err
+	}
+
+	condition, err := compileCondition(expression, names, values)
+	if err != nil {
+		return err
+	}
+	if !condition(lo.Ternary(existing != nil, existing, wireItem{})) {
+		return errorf("ConditionalCheckFailedException", "The conditional request failed")
+	}
+	return nil
+}
+
+// decodeExpressionAttributes decodes the expression attribute names and values of a request
+func decodeExpressionAttributes(input map[string]json.RawMessage) (map[string]string, wireItem, error) {
+	var names map[string]string
+	var values wireItem
+	if err := decodeField(input, "ExpressionAttributeNames", &names); err != nil {
+		return nil, nil, err
+	}
+	if err := decodeField(input, "ExpressionAttributeValues", &values); err != nil {
+		return nil, nil, err
+	}
+	return names, values, nil
+}
+
+// decodeField decodes an optional request field
+func decodeField(input map[string]json.RawMessage, field string, target interface{}) error {
+	raw, found := input[field]
+	if !found {
+		return nil
+	}
+	if err := json.Unmarshal(raw, target); err != nil {
+		return errorf("SerializationException", "invalid %s: %v", field, err)
+	}
+	return nil
+}
+
+// resolveName resolves a #placeholder to its attribute name
+func resolveName(name string, names map[string]string) string {
+	if strings.HasPrefix(name, "#") {
+		return names[name]
+	}
+	return name
+}
+
+// project keeps the attributes listed in a projection expression
+func project(item wireItem, projection string, names map[string]string) wireItem {
+	if projection == "" {
+		return item
+	}
+
+	attributes := lo.Map(strings.Split(projection, ","), func(name string, _ int) string {
+		return resolveName(strings.TrimSpace(name), names)
+	})
+	return lo.PickByKeys(item, attributes)
+}
+
+// compareValues orders two attribute values of the same scalar type; false means they are not comparable
+func compareValues(a, b attributeValue) (int, bool) {
+	if x, ok := a["N"].(string); ok {
+		if y, ok := b["N"].(string); ok {
+			var fx, fy float64
+			if _, err := fmt.Sscan(x, &fx); err != nil {
+				return 0, false
+			}
+			if _, err := fmt.Sscan(y, &fy); err != nil {
+				return 0, false
+			}
+			return lo.Ternary(fx < fy, -1, lo.Ternary(fx > fy, 1, 0)), true
+		}
+	}
+
+	if x, ok := a["S"].(string); ok {
+		if y, ok := b["S"].(string); ok {
+			return strings.Compare(x, y), true
+		}
+	}
+
+	return lo.Ternary(reflect.DeepEqual(a, b), 0, 1), false
+}
+
+// simplifyItem converts a wire item to Go values
+func simplifyItem(item wireItem) Item {
+	if item == nil {
+		return nil
+	}
+	return lo.MapValues(item, func(value attributeValue, _ string) interface{} {
+		return simplifyValue(value)
+	})
+}
+
+// simplifyValue converts a wire attribute value to a Go value
+func simplifyValue(value attributeValue) interface{} {
+	for kind, raw := range value {
+		switch kind {
+		case "S", "N", "BOOL":
+			return raw
+		case "NULL":
+			return nil
+		case "SS", "NS":
+			return lo.Map(raw.([]interface{}), func(element interface{}, _ int) string {
+				return fmt.Sprint(element)
+			})
+		case "L":
+			return lo.Map(raw.([]interface{}), func(element interface{}, _ int) interface{} {
+				return simplifyValue(element.(map[string]interface{}))
+			})
+		case "M":
+			return lo.MapValues(raw.(map[string]interface{}), func(element interface{}, _ string) interface{} {
+				return simplifyValue(element.(map[string]interface{}))
+			})
+		}
+	}
+	return nil
+}
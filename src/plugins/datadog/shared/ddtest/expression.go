@@ -0,0 +1,257 @@
+package ddtest
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// condition evaluates a compiled condition or filter expression against an item
+type condition func(wireItem) bool
+
+// operand resolves one side of a comparison; false means the attribute is missing
+type operand func(wireItem) (attributeValue, bool)
+
+// comparators maps comparison operators onto the compareValues results they accept
+var comparators = map[string]func(int) bool{
+	"=":  func(c int) bool { return c == 0 },
+	"<>": func(c int) bool { return c != 0 },
+	"<":  func(c int) bool { return c < 0 },
+	"<=": func(c int) bool { return c <= 0 },
+	">":  func(c int) bool { return c > 0 },
+	">=": func(c int) bool { return c >= 0 },
+}
+
+// expressionParser is a recursive-descent parser for the DynamoDB condition expression subset
+// used by the storage layer: comparisons, attribute_exists, attribute_not_exists,
+// begins_with, AND, OR, NOT and parentheses
+type expressionParser struct {
+	tokens []string
+	pos    int
+	names  map[string]string
+	values wireItem
+}
+
+// compileCondition parses a condition or filter expression
+func compileCondition(expression string, names map[string]string, values wireItem) (condition, error) {
+	parser := &expressionParser{tokens: tokenizeExpression(expression), names: names, values: values}
+
+	compiled, err := parser.parseOr()
+	if err != nil {
+		return nil, errorf("ValidationException", "invalid expression %q: %v", expression, err)
+	}
+	if parser.pos < len(parser.tokens) {
+		return nil, errorf("ValidationException", "invalid expression %q: unexpected %q", expression, parser.peek())
+	}
+	return compiled, nil
+}
+
+// tokenizeExpression splits an expression into names, placeholders, operators and punctuation
+func tokenizeExpression(expression string) []string {
+	var tokens []string
+	runes := []rune(expression)
+
+	for i := 0; i < len(runes); {
+		switch r := runes[i]; {
+		case unicode.IsSpace(r):
+			i++
+		case strings.ContainsRune("(),", r):
+			tokens = append(tokens, string(r))
+			i++
+		case strings.ContainsRune("<>=", r):
+			end := i + 1
+			for end < len(runes) && strings.ContainsRune("<>=", runes[end]) {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		default:
+			end := i + 1
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune("(),<>=", runes[end]) {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		}
+	}
+
+	return tokens
+}
+
+// peek returns the current token or "" at the end of the expression
+func (p *expressionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// accept consumes the current token when it matches keyword case-insensitively
+func (p *expressionParser) accept(keyword string) bool {
+	if strings.EqualFold(p.peek(), keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// expect consumes a required token
+func (p *expressionParser) expect(token string) error {
+	if !p.accept(token) {
+		return fmt.Errorf("expected %q, got %q", token, p.peek())
+	}
+	return nil
+}
+
+// parseOr parses a disjunction
+func (p *expressionParser) parseOr() (condition, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.accept("OR") {
+		var right condition
+		if right, err = p.parseAnd(); err == nil {
+			l, r := left, right
+			left = func(item wireItem) bool { return l(item) || r(item) }
+		}
+	}
+	return left, err
+}
+
+// parseAnd parses a conjunction
+func (p *expressionParser) parseAnd() (condition, error) {
+	left, err := p.parseNot()
+	for err == nil && p.accept("AND") {
+		var right condition
+		if right, err = p.parseNot(); err == nil {
+			l, r := left, right
+			left = func(item wireItem) bool { return l(item) && r(item) }
+		}
+	}
+	return left, err
+}
+
+// parseNot parses a negation
+func (p *expressionParser) parseNot() (condition, error) {
+	if !p.accept("NOT") {
+		return p.parsePrimary()
+	}
+
+	negated, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	return func(item wireItem) bool { return !negated(item) }, nil
+}
+
+// parsePrimary parses a parenthesized expression, a function call or a comparison
+func (p *expressionParser) parsePrimary() (condition, error) {
+	if p.accept("(") {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(")")
+	}
+
+	switch function := strings.ToLower(p.peek()); function {
+	case "attribute_exists", "attribute_not_exists":
+		p.pos++
+		path, err := p.parseCallArguments(1)
+		if err != nil {
+			return nil, err
+		}
+		exists := function == "attribute_exists"
+		return func(item wireItem) bool {
+			_, found := path[0](item)
+			return found == exists
+		}, nil
+	case "begins_with":
+		p.pos++
+		arguments, err := p.parseCallArguments(2)
+		if err != nil {
+			return nil, err
+		}
+		return func(item wireItem) bool {
+			value, found := arguments[0](item)
+			prefix, known := arguments[1](item)
+			s, isString := value["S"].(string)
+			t, isPrefix := prefix["S"].(string)
+			return found && known && isString && isPrefix && strings.HasPrefix(s, t)
+		}, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	operator := p.peek()
+	accepts, found := comparators[operator]
+	if !found {
+		return nil, fmt.Errorf("expected a comparison operator, got %q", operator)
+	}
+	p.pos++
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(item wireItem) bool {
+		a, foundA := left(item)
+		b, foundB := right(item)
+		if !foundA || !foundB {
+			return false
+		}
+		c, comparable := compareValues(a, b)
+		return (comparable || operator == "=" || operator == "<>") && accepts(c)
+	}, nil
+}
+
+// parseCallArguments parses the parenthesized arguments of a function call
+func (p *expressionParser) parseCallArguments(count int) ([]operand, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	arguments := make([]operand, 0, count)
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		argument, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, argument)
+	}
+
+	return arguments, p.expect(")")
+}
+
+// parseOperand parses an attribute path or a :value placeholder
+func (p *expressionParser) parseOperand() (operand, error) {
+	token := p.peek()
+	if token == "" || strings.ContainsAny(token, "(),<>=") {
+		return nil, fmt.Errorf("expected an attribute or value, got %q", token)
+	}
+	p.pos++
+
+	if strings.HasPrefix(token, ":") {
+		value, found := p.values[token]
+		if !found {
+			return nil, fmt.Errorf("undefined value placeholder %s", token)
+		}
+		return func(wireItem) (attributeValue, bool) { return value, true }, nil
+	}
+
+	name := resolveName(token, p.names)
+	if name == "" {
+		return nil, fmt.Errorf("undefined name placeholder %s", token)
+	}
+	return func(item wireItem) (attributeValue, bool) {
+		value, found := item[name]
+		return value, found
+	}, nil
+}
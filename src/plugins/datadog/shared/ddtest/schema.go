@@ -0,0 +1,100 @@
+package ddtest
+
+import (
+	"github.com/samber/lo"
+)
+
+// schemaVersions are the service definition schema versions the stand-in can render
+var schemaVersions = []string{"v1", "v2", "v2.1", "v2.2", "v3"}
+
+// renderSchema renders a service definition in the wire format of a schema version
+func renderSchema(service ServiceDefinition, version string) map[string]interface{} {
+	switch version {
+	case "v1":
+		return renderSchemaV1(service)
+	case "v3":
+		return renderEntityV3(service)
+	}
+
+	schema := map[string]interface{}{
+		"schema-version": version,
+		"dd-service":     service.Name,
+		"tags":           lo.CoalesceSliceOrEmpty(service.Tags, []string{}),
+		"contacts":       lo.Map(service.Contacts, renderContact),
+	}
+	if service.Team != "" {
+		schema["team"] = service.Team
+	}
+
+	// Tier and lifecycle were introduced with v2.1
+	if version != "v2" {
+		if service.Tier != "" {
+			schema["tier"] = service.Tier
+		}
+		if service.Lifecycle != "" {
+			schema["lifecycle"] = service.Lifecycle
+		}
+	}
+
+	return schema
+}
+
+// renderSchemaV1 renders a v1 definition, which nests fields under info, org and contact
+func renderSchemaV1(service ServiceDefinition) map[string]interface{} {
+	info := map[string]interface{}{"dd-service": service.Name}
+	if service.Tier != "" {
+		info["service-tier"] = service.Tier
+	}
+
+	// v1 holds a single email and Slack contact
+	contact := map[string]interface{}{}
+	for _, c := range service.Contacts {
+		if _, taken := contact[c.Type]; !taken && (c.Type == "email" || c.Type == "slack") {
+			contact[c.Type] = c.Contact
+		}
+	}
+
+	schema := map[string]interface{}{
+		"schema-version": "v1",
+		"info":           info,
+		"tags":           lo.CoalesceSliceOrEmpty(service.Tags, []string{}),
+		"contact":        contact,
+	}
+	if service.Team != "" {
+		schema["org"] = map[string]interface{}{"team": service.Team}
+	}
+
+	return schema
+}
+
+// renderEntityV3 renders a v3 entity definition of kind service
+func renderEntityV3(service ServiceDefinition) map[string]interface{} {
+	metadata := map[string]interface{}{
+		"name":     service.Name,
+		"tags":     lo.CoalesceSliceOrEmpty(service.Tags, []string{}),
+		"contacts": lo.Map(service.Contacts, renderContact),
+	}
+	if service.Team != "" {
+		metadata["owner"] = service.Team
+	}
+
+	spec := map[string]interface{}{}
+	if service.Tier != "" {
+		spec["tier"] = service.Tier
+	}
+	if service.Lifecycle != "" {
+		spec["lifecycle"] = service.Lifecycle
+	}
+
+	return map[string]interface{}{
+		"apiVersion": "v3",
+		"kind":       "service",
+		"metadata":   metadata,
+		"spec":       spec,
+	}
+}
+
+// renderContact renders a contact of a v2 or later definition
+func renderContact(contact Contact, _ int) map[string]interface{} {
+	return map[string]interface{}{"type": contact.Type, "contact": contact.Contact}
+}
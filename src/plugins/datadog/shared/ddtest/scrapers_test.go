@@ -0,0 +1,206 @@
+package ddtest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bacon/src/plugins/datadog/scrapers/organizations"
+	"bacon/src/plugins/datadog/scrapers/services"
+	"bacon/src/plugins/datadog/scrapers/teams"
+	"bacon/src/plugins/datadog/scrapers/users"
+	"bacon/src/plugins/datadog/shared"
+	"bacon/src/plugins/datadog/shared/ddtest"
+	"bacon/src/plugins/datadog/types"
+)
+
+// newEnvironment starts the Datadog and DynamoDB stand-ins seeded with a small organization
+func newEnvironment(t *testing.T) (*ddtest.Server, *ddtest.DynamoDB) {
+	server := ddtest.NewServer(t)
+	server.AddUsers(
+		ddtest.User{ID: "user-1", Name: "Ada Lovelace", Email: "ada@example.com", Verified: true},
+		ddtest.User{ID: "user-2", Name: "Grace Hopper", Email: "grace@example.com", Verified: true},
+		ddtest.User{ID: "user-3", Name: "Alan Turing", Email: "alan@example.com"},
+		ddtest.User{ID: "user-4", Name: "Former Employee", Email: "former@example.com", Disabled: true},
+	)
+	server.AddTeams(
+		ddtest.Team{ID: "team-1", Name: "Payments", Handle: "payments", Members: []ddtest.Membership{
+			{UserID: "user-1", Role: "admin"},
+			{UserID: "user-2"},
+			{UserID: "user-3"},
+		}},
+		ddtest.Team{ID: "team-2", Name: "Site Reliability", Handle: "sre", Members: []ddtest.Membership{
+			{UserID: "user-2"},
+		}},
+	)
+	server.Configure(t)
+
+	db := ddtest.NewDynamoDB(t)
+	db.Configure(t)
+
+	return server, db
+}
+
+// itemsByKey indexes stored items by their key attribute
+func itemsByKey(items []ddtest.Item, key string) map[string]ddtest.Item {
+	indexed := make(map[string]ddtest.Item, len(items))
+	for _, item := range items {
+		indexed[item[key].(string)] = item
+	}
+	return indexed
+}
+
+func TestTeamsHandler(t *testing.T) {
+	server, db := newEnvironment(t)
+
+	// The first teams request is rate limited and retried after the reset
+	server.Throttle("/api/v2/team", 1)
+
+	response, err := teams.Handler(context.Background(), types.ScraperEvent{PageSize: 2, RunID: "run-1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "success", response.Status)
+	assert.Equal(t, 2, response.Count)
+
+	stored := itemsByKey(db.Items("datadog-teams"), "team_id")
+	require.Len(t, stored, 2)
+	assert.Equal(t, "payments", stored["team-1"]["handle"])
+	assert.ElementsMatch(t, []string{"user-1", "user-2", "user-3"}, stored["team-1"]["members"])
+	assert.Equal(t, "admin", stored["team-1"]["member_roles"].(map[string]interface{})["user-1"])
+
+	// Three members at a page size of two take two membership pages
+	assert.Len(t, server.RequestsTo("/api/v2/team/team-1/memberships"), 2)
+	assert.Len(t, server.RequestsTo("/api/v2/team"), 2, "the throttled request should be retried")
+}
+
+func TestTeamsHandler_TombstonesRemovedTeams(t *testing.T) {
+	_, db := newEnvironment(t)
+
+	_, err := teams.Handler(context.Background(), types.ScraperEvent{RunID: "run-1"})
+	require.NoError(t, err)
+
+	// Switch to a Datadog stand-in without team-1 while keeping the stored items
+	replacement := ddtest.NewServer(t)
+	replacement.AddUsers(ddtest.User{ID: "user-2", Name: "Grace Hopper", Email: "grace@example.com"})
+	replacement.AddTeams(ddtest.Team{ID: "team-2", Name: "Site Reliability", Handle: "sre", Members: []ddtest.Membership{{UserID: "user-2"}}})
+	replacement.Configure(t)
+
+	response, err := teams.Handler(context.Background(), types.ScraperEvent{RunID: "run-2"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, response.Metadata["removed"])
+	require.Len(t, response.Outputs, 2)
+	assert.Equal(t, shared.SourceDatadogTeams, response.Outputs[0].Source)
+	assert.Equal(t, shared.SourceDatadogDeletions, response.Outputs[1].Source)
+
+	removed, found := db.Item("datadog-teams", "team-1")
+	require.True(t, found)
+	assert.NotEmpty(t, removed["deleted_at"])
+
+	kept, _ := db.Item("datadog-teams", "team-2")
+	assert.NotContains(t, kept, "deleted_at")
+}
+
+func TestUsersHandler(t *testing.T) {
+	server, db := newEnvironment(t)
+	server.Throttle("/api/v2/users", 2)
+
+	response, err := users.Handler(context.Background(), types.ScraperEvent{Filter: `verified == true`})
+	require.NoError(t, err)
+
+	assert.Equal(t, "success", response.Status)
+	assert.Equal(t, 2, response.Count)
+
+	stored := itemsByKey(db.Items("datadog-users"), "user_id")
+	assert.Contains(t, stored, "user-1")
+	assert.Contains(t, stored, "user-2")
+	assert.Equal(t, "ada@example.com", stored["user-1"]["email"])
+
+	// Filtered runs tombstone nothing but still publish the directory of the users they saw
+	require.Len(t, response.Outputs, 1)
+	assert.Equal(t, shared.SourceDatadogUsers, response.Outputs[0].Source)
+	assert.Len(t, response.Outputs[0].Payload.Identities, 2)
+}
+
+func TestUsersHandler_RejectsInvalidCredentials(t *testing.T) {
+	newEnvironment(t)
+	t.Setenv("DATADOG_API_KEY", "wrong-key")
+
+	response, err := users.Handler(context.Background(), types.ScraperEvent{})
+
+	require.Error(t, err)
+	assert.Equal(t, "error", response.Status)
+	assert.Contains(t, response.Message, "Failed to validate Datadog connection")
+}
+
+func TestServicesHandler_SchemaVersions(t *testing.T) {
+	cases := []struct {
+		name          string
+		requested     string
+		registered    string
+		schemaVersion string
+	}{
+		{name: "v1 requested", requested: "v1", schemaVersion: "v1"},
+		{name: "v2 requested", requested: "v2", schemaVersion: "v2"},
+		{name: "v2.1 requested", requested: "v2.1", schemaVersion: "v2.1"},
+		{name: "v2.2 requested", requested: "v2.2", schemaVersion: "v2.2"},
+		{name: "v3 registered", registered: "v3", schemaVersion: "v3"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server, db := newEnvironment(t)
+			server.AddServiceDefinitions(
+				ddtest.ServiceDefinition{Name: "checkout", Team: "payments", Tier: "1", SchemaVersion: tc.registered},
+				ddtest.ServiceDefinition{Name: "ledger", Tier: "2", SchemaVersion: tc.registered, Contacts: []ddtest.Contact{
+					{Type: "email", Contact: "payments-team@example.com"},
+				}},
+				ddtest.ServiceDefinition{Name: "status-page", SchemaVersion: tc.registered, Contacts: []ddtest.Contact{
+					{Type: "slack", Contact: "#sre-alerts"},
+				}},
+			)
+
+			// Seed the known teams used for owner resolution
+			_, err := teams.Handler(context.Background(), types.ScraperEvent{RunID: "run-1"})
+			require.NoError(t, err)
+
+			response, err := services.Handler(context.Background(), types.ScraperEvent{PageSize: 2, SchemaVersion: tc.requested, RunID: "run-1"})
+			require.NoError(t, err)
+
+			assert.Equal(t, "success", response.Status)
+			assert.Equal(t, 3, response.Count)
+
+			stored := itemsByKey(db.Items("datadog-services"), "name")
+			require.Len(t, stored, 3)
+			for _, item := range stored {
+				assert.Equal(t, tc.schemaVersion, item["schema"])
+			}
+			assert.Equal(t, "payments", stored["checkout"]["owner"])
+			assert.Equal(t, "payments", stored["ledger"]["owner"])
+			assert.Equal(t, "sre", stored["status-page"]["owner"])
+
+			// Three definitions at a page size of two take two pages
+			requests := server.RequestsTo("/api/v2/services/definitions")
+			require.Len(t, requests, 2)
+			assert.Equal(t, tc.requested, requests[0].Query.Get("schema_version"))
+		})
+	}
+}
+
+func TestOrganizationsHandler(t *testing.T) {
+	server, db := newEnvironment(t)
+	server.Throttle("/api/v2/team", 1)
+
+	response, err := organizations.Handler(context.Background(), types.ScraperEvent{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "success", response.Status)
+	assert.Equal(t, 1, response.Count)
+
+	stored := db.Items("datadog-organizations")
+	require.Len(t, stored, 1)
+	assert.Len(t, stored[0]["teams"], 2)
+	assert.Len(t, stored[0]["users"], 4)
+}
@@ -0,0 +1,406 @@
+// Package ddtest provides local stand-ins for the Datadog API v2 and DynamoDB so the Datadog
+// scraper handlers can be tested end to end without network access or credentials.
+package ddtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// Credentials accepted by the stand-in; Configure exports them for the scrapers
+const (
+	APIKey = "ddtest-api-key"
+	AppKey = "ddtest-app-key"
+)
+
+// defaultPageSize mirrors the page size the Datadog API uses when none is requested
+const defaultPageSize = 10
+
+// fixtureTime is the creation and modification time reported for every fixture
+var fixtureTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Team is a team fixture served by the Teams API
+type Team struct {
+	ID          string
+	Name        string
+	Handle      string
+	Description string
+	Members     []Membership
+}
+
+// Membership is a team membership fixture; Role is "admin" or empty for a plain member
+type Membership struct {
+	UserID string
+	Role   string
+}
+
+// User is a user fixture served by the Users API and included in membership responses
+type User struct {
+	ID       string
+	Name     string
+	Email    string
+	Handle   string
+	Status   string
+	Title    string
+	Disabled bool
+	Verified bool
+}
+
+// Contact is a service contact fixture; Type is "email", "slack" or "microsoft-teams"
+type Contact struct {
+	Type    string
+	Contact string
+}
+
+// ServiceDefinition is a service catalog fixture rendered in the schema version it was
+// registered with, or in the version requested through schema_version
+type ServiceDefinition struct {
+	Name          string
+	Team          string
+	Tier          string
+	Lifecycle     string
+	Tags          []string
+	Contacts      []Contact
+	SchemaVersion string // "v1", "v2", "v2.1", "v2.2" or "v3"; defaults to "v2.2"
+}
+
+// Request records a call received by the stand-in
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+}
+
+// Server emulates the Datadog API v2 endpoints read by the scrapers
+// Teams, team memberships, users and service definitions are paginated with page[size] and
+// page[number]; the organizations scraper reads the same teams and users endpoints
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	teams     []Team
+	users     []User
+	services  []ServiceDefinition
+	throttles map[string]int
+	requests  []Request
+}
+
+// NewServer starts a Datadog API stand-in that is closed when the test finishes
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	server := &Server{throttles: make(map[string]int)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v2/team", server.listTeams)
+	mux.HandleFunc("GET /api/v2/team/{team_id}/memberships", server.listMemberships)
+	mux.HandleFunc("GET /api/v2/users", server.listUsers)
+	mux.HandleFunc("GET /api/v2/services/definitions", server.listServiceDefinitions)
+
+	server.Server = httptest.NewServer(server.middleware(mux))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// Configure points the scrapers at the stand-in for the duration of the test
+func (s *Server) Configure(t testing.TB) {
+	t.Helper()
+	t.Setenv("DATADOG_API_KEY", APIKey)
+	t.Setenv("DATADOG_APP_KEY", AppKey)
+	t.Setenv("DATADOG_HOST", s.URL)
+}
+
+// AddTeams registers team fixtures together with their memberships
+func (s *Server) AddTeams(teams ...Team) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.teams = append(s.teams, teams...)
+}
+
+// AddUsers registers user fixtures
+func (s *Server) AddUsers(users ...User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users = append(s.users, users...)
+}
+
+// AddServiceDefinitions registers service catalog fixtures
+func (s *Server) AddServiceDefinitions(services ...ServiceDefinition) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.services = append(s.services, services...)
+}
+
+// Throttle answers the next n requests under pathPrefix with 429 Too Many Requests
+// The rate limit resets immediately, so a retrying client succeeds on its next attempt
+func (s *Server) Throttle(pathPrefix string, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.throttles[pathPrefix] += n
+}
+
+// Requests returns the requests received so far, including throttled ones
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// RequestsTo returns the received requests whose path equals path
+func (s *Server) RequestsTo(path string) []Request {
+	return lo.Filter(s.Requests(), func(request Request, _ int) bool {
+		return request.Path == path
+	})
+}
+
+// middleware records requests, checks credentials and applies throttling
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query()})
+		prefix, throttled := lo.FindKeyBy(s.throttles, func(prefix string, remaining int) bool {
+			return remaining > 0 && strings.HasPrefix(r.URL.Path, prefix)
+		})
+		if throttled {
+			s.throttles[prefix]--
+		}
+		s.mu.Unlock()
+
+		if r.Header.Get("DD-API-KEY") != APIKey || r.Header.Get("DD-APPLICATION-KEY") != AppKey {
+			writeErrors(w, http.StatusForbidden, "Forbidden")
+			return
+		}
+
+		if throttled {
+			w.Header().Set("X-RateLimit-Limit", "1")
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", "0")
+			writeErrors(w, http.StatusTooManyRequests, "Too many requests")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// listTeams serves GET /api/v2/team
+func (s *Server) listTeams(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	teams := append([]Team(nil), s.teams...)
+	s.mu.Unlock()
+
+	if keyword := strings.ToLower(r.URL.Query().Get("filter[keyword]")); keyword != "" {
+		teams = lo.Filter(teams, func(team Team, _ int) bool {
+			return strings.Contains(strings.ToLower(team.Name), keyword) || strings.Contains(strings.ToLower(team.Handle), keyword)
+		})
+	}
+
+	page, size, number, ok := paginate(w, r, teams)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": lo.Map(page, renderTeam),
+		"meta": map[string]interface{}{"pagination": offsetPagination(len(teams), size, number)},
+	})
+}
+
+// listMemberships serves GET /api/v2/team/{team_id}/memberships with the member users included
+func (s *Server) listMemberships(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	team, found := lo.Find(s.teams, func(team Team) bool { return team.ID == r.PathValue("team_id") })
+	users := lo.KeyBy(s.users, func(user User) string { return user.ID })
+	s.mu.Unlock()
+
+	if !found {
+		writeErrors(w, http.StatusNotFound, "Team not found")
+		return
+	}
+
+	page, size, number, ok := paginate(w, r, team.Members)
+	if !ok {
+		return
+	}
+
+	included := lo.FilterMap(page, func(membership Membership, _ int) (map[string]interface{}, bool) {
+		user, found := users[membership.UserID]
+		return renderUser(user, 0), found
+	})
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":     lo.Map(page, renderMembership(team.ID)),
+		"included": included,
+		"meta":     map[string]interface{}{"pagination": offsetPagination(len(team.Members), size, number)},
+	})
+}
+
+// listUsers serves GET /api/v2/users
+func (s *Server) listUsers(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	users := append([]User(nil), s.users...)
+	s.mu.Unlock()
+
+	total := len(users)
+	if filter := strings.ToLower(r.URL.Query().Get("filter")); filter != "" {
+		users = lo.Filter(users, func(user User, _ int) bool {
+			return strings.Contains(strings.ToLower(user.Name), filter) || strings.Contains(strings.ToLower(user.Email), filter)
+		})
+	}
+
+	page, _, _, ok := paginate(w, r, users)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": lo.Map(page, renderUser),
+		"meta": map[string]interface{}{"page": map[string]interface{}{"total_count": total, "total_filtered_count": len(users)}},
+	})
+}
+
+// listServiceDefinitions serves GET /api/v2/services/definitions
+// A schema_version query renders every definition in that version, as the API does
+func (s *Server) listServiceDefinitions(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	services := append([]ServiceDefinition(nil), s.services...)
+	s.mu.Unlock()
+
+	requestedVersion := r.URL.Query().Get("schema_version")
+	if requestedVersion != "" && !lo.Contains(schemaVersions, requestedVersion) {
+		writeErrors(w, http.StatusBadRequest, fmt.Sprintf("Invalid schema_version %q", requestedVersion))
+		return
+	}
+
+	page, _, _, ok := paginate(w, r, services)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": lo.Map(page, func(service ServiceDefinition, _ int) map[string]interface{} {
+			version := lo.CoalesceOrEmpty(requestedVersion, service.SchemaVersion, "v2.2")
+			return map[string]interface{}{
+				"id":   "svc-" + service.Name,
+				"type": "service-definition",
+				"attributes": map[string]interface{}{
+					"meta":   map[string]interface{}{"last-modified-time": fixtureTime.Format(time.RFC3339)},
+					"schema": renderSchema(service, version),
+				},
+			}
+		}),
+	})
+}
+
+// paginate slices items by page[size] and page[number], answering 400 for invalid values
+func paginate[T any](w http.ResponseWriter, r *http.Request, items []T) ([]T, int, int, bool) {
+	size, sizeErr := queryInt(r, "page[size]", defaultPageSize)
+	number, numberErr := queryInt(r, "page[number]", 0)
+	if sizeErr != nil || numberErr != nil || size <= 0 || number < 0 {
+		writeErrors(w, http.StatusBadRequest, "Invalid pagination parameters")
+		return nil, 0, 0, false
+	}
+
+	start := min(size*number, len(items))
+	end := min(start+size, len(items))
+	return items[start:end], size, number, true
+}
+
+// queryInt reads an integer query parameter, falling back to a default when absent
+func queryInt(r *http.Request, name string, fallback int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// offsetPagination renders the offset pagination metadata of the Teams API
+func offsetPagination(total, size, number int) map[string]interface{} {
+	offset := size * number
+	return map[string]interface{}{
+		"type":         "offset_limit",
+		"offset":       offset,
+		"limit":        size,
+		"total":        total,
+		"first_offset": 0,
+		"prev_offset":  max(offset-size, 0),
+		"next_offset":  lo.Ternary(offset+size < total, offset+size, offset),
+		"last_offset":  max((total-1)/size*size, 0),
+	}
+}
+
+// renderTeam renders a team in the Teams API wire format
+func renderTeam(team Team, _ int) map[string]interface{} {
+	return map[string]interface{}{
+		"id":   team.ID,
+		"type": "team",
+		"attributes": map[string]interface{}{
+			"name":        team.Name,
+			"handle":      team.Handle,
+			"description": team.Description,
+			"user_count":  len(team.Members),
+			"created_at":  fixtureTime.Format(time.RFC3339),
+			"modified_at": fixtureTime.Format(time.RFC3339),
+		},
+	}
+}
+
+// renderMembership renders a team membership in the Team Memberships API wire format
+func renderMembership(teamID string) func(Membership, int) map[string]interface{} {
+	return func(membership Membership, _ int) map[string]interface{} {
+		attributes := map[string]interface{}{}
+		if membership.Role != "" {
+			attributes["role"] = membership.Role
+		}
+
+		return map[string]interface{}{
+			"id":         teamID + "-" + membership.UserID,
+			"type":       "team_memberships",
+			"attributes": attributes,
+			"relationships": map[string]interface{}{
+				"user": map[string]interface{}{"data": map[string]interface{}{"id": membership.UserID, "type": "users"}},
+			},
+		}
+	}
+}
+
+// renderUser renders a user in the Users API wire format
+func renderUser(user User, _ int) map[string]interface{} {
+	return map[string]interface{}{
+		"id":   user.ID,
+		"type": "users",
+		"attributes": map[string]interface{}{
+			"name":        user.Name,
+			"email":       user.Email,
+			"handle":      lo.CoalesceOrEmpty(user.Handle, user.Email),
+			"status":      lo.CoalesceOrEmpty(user.Status, lo.Ternary(user.Disabled, "Disabled", "Active")),
+			"title":       user.Title,
+			"disabled":    user.Disabled,
+			"verified":    user.Verified,
+			"created_at":  fixtureTime.Format(time.RFC3339),
+			"modified_at": fixtureTime.Format(time.RFC3339),
+		},
+	}
+}
+
+// writeJSON writes a JSON response body
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeErrors writes a Datadog API error response
+func writeErrors(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{"errors": []string{message}})
+}
@@ -0,0 +1,66 @@
+// Package shared provides pure functional utilities for Datadog API v2 data transformations.
+package shared
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/samber/lo"
+
+	"bacon/src/plugins/datadog/types"
+	"bacon/src/shared/scraperoutput"
+)
+
+// Entity kinds reported in deletion events alongside EntityKindService
+const (
+	EntityKindTeam = "team"
+	EntityKindUser = "user"
+)
+
+// TombstoneFunc marks entities not seen by a generation, nor written by a later run, as deleted, e.g. TombstoneTeamsData
+type TombstoneFunc func(ctx context.Context, generation string, startedAt, deletedAt time.Time) ([]TombstonedItem, error)
+
+// ResolveScrapeGeneration returns the run ID supplied by the caller, or a generation derived from the start time
+// Pure function; generations only need to differ between runs against the same table
+func ResolveScrapeGeneration(event types.ScraperEvent, startedAt time.Time) string {
+	return lo.Ternary(event.RunID != "", event.RunID, strconv.FormatInt(startedAt.UnixNano(), 10))
+}
+
+// IsCompleteScrape reports whether an event scrapes the full entity set
+// Pure function; filtered runs, including those narrowed to a schema version, must never tombstone the entities
+// they did not ask for
+func IsCompleteScrape(event types.ScraperEvent) bool {
+	return event.FilterKeyword == "" && event.Filter == "" && event.TeamID == "" && event.OrganizationID == "" &&
+		!NarrowsBySchema(event.SchemaVersion)
+}
+
+// CreateDeletionOutput wraps tombstoned entities in the ScraperOutput format consumed by the processor
+// Pure function; entities without a graph name fall back to their Datadog ID
+func CreateDeletionOutput(kind string, items []TombstonedItem, deletedAt time.Time) types.ScraperOutput {
+	deletions := lo.Map(items, func(item TombstonedItem, _ int) scraperoutput.Deletion {
+		return scraperoutput.Deletion{
+			Entity: lo.Ternary(item.Name != "", item.Name, item.ID),
+			ID:     item.ID,
+			Kind:   kind,
+		}
+	})
+
+	return scraperoutput.New(SourceDatadogDeletions, 1.0, deletedAt, scraperoutput.Deletions(deletions))
+}
+
+// DetectDeletions tombstones entities missing from a complete scrape and builds the deletion event
+// Filtered runs are skipped; tombstoned entities are returned even when the sweep stops early
+func DetectDeletions(ctx context.Context, event types.ScraperEvent, kind, generation string, startedAt time.Time, tombstone TombstoneFunc) ([]types.ScraperOutput, int, error) {
+	if !IsCompleteScrape(event) {
+		return nil, 0, nil
+	}
+
+	deletedAt := time.Now()
+	deleted, err := tombstone(ctx, generation, startedAt, deletedAt)
+	if len(deleted) == 0 {
+		return nil, 0, err
+	}
+
+	return []types.ScraperOutput{CreateDeletionOutput(kind, deleted, deletedAt)}, len(deleted), err
+}
@@ -0,0 +1,107 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bacon/src/plugins/datadog/types"
+	"bacon/src/shared/scraperoutput"
+)
+
+func TestResolveScrapeGeneration(t *testing.T) {
+	startedAt := time.Unix(1700000000, 42)
+
+	assert.Equal(t, "run-7", ResolveScrapeGeneration(types.ScraperEvent{RunID: "run-7"}, startedAt))
+	assert.Equal(t, "1700000000000000042", ResolveScrapeGeneration(types.ScraperEvent{}, startedAt))
+}
+
+func TestIsCompleteScrape(t *testing.T) {
+	assert.True(t, IsCompleteScrape(types.ScraperEvent{PageSize: 50, IncludeInactive: true}))
+	assert.False(t, IsCompleteScrape(types.ScraperEvent{FilterKeyword: "team-owned-only"}))
+	assert.False(t, IsCompleteScrape(types.ScraperEvent{Filter: `tier == "1"`}))
+	assert.False(t, IsCompleteScrape(types.ScraperEvent{TeamID: "team-1"}))
+	assert.False(t, IsCompleteScrape(types.ScraperEvent{OrganizationID: "org-1"}))
+	assert.True(t, IsCompleteScrape(types.ScraperEvent{SchemaVersion: SchemaVersionV2Dot2}), "Versions the API serves render every service")
+	assert.False(t, IsCompleteScrape(types.ScraperEvent{SchemaVersion: SchemaVersionV3}))
+}
+
+func TestCreateDeletionOutput(t *testing.T) {
+	deletedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	output := CreateDeletionOutput(EntityKindTeam, []TombstonedItem{
+		{ID: "team-1", Name: "payments"},
+		{ID: "team-2"},
+	}, deletedAt)
+
+	assert.Equal(t, SourceDatadogDeletions, output.Source)
+	assert.Equal(t, "2024-03-01T12:00:00Z", output.ProducedAt)
+	assert.Equal(t, scraperoutput.Deletions([]scraperoutput.Deletion{
+		{Entity: "payments", ID: "team-1", Kind: "team"},
+		{Entity: "team-2", ID: "team-2", Kind: "team"},
+	}), output.Payload)
+}
+
+func TestDetectDeletions(t *testing.T) {
+	startedAt := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	tombstoneCalls := 0
+	tombstone := func(items []TombstonedItem, err error) TombstoneFunc {
+		return func(_ context.Context, generation string, started, _ time.Time) ([]TombstonedItem, error) {
+			tombstoneCalls++
+			assert.Equal(t, "run-1", generation)
+			assert.Equal(t, startedAt, started, "The run start should guard the sweep")
+			return items, err
+		}
+	}
+
+	t.Run("complete scrape emits a deletion event", func(t *testing.T) {
+		outputs, removed, err := DetectDeletions(context.Background(), types.ScraperEvent{}, EntityKindService, "run-1", startedAt, tombstone([]TombstonedItem{{ID: "svc-1", Name: "checkout"}}, nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, removed)
+		require.Len(t, outputs, 1)
+		assert.Equal(t, SourceDatadogDeletions, outputs[0].Source)
+	})
+
+	t.Run("nothing removed emits no event", func(t *testing.T) {
+		outputs, removed, err := DetectDeletions(context.Background(), types.ScraperEvent{}, EntityKindService, "run-1", startedAt, tombstone(nil, nil))
+
+		require.NoError(t, err)
+		assert.Zero(t, removed)
+		assert.Empty(t, outputs)
+	})
+
+	t.Run("partial sweep still reports what was removed", func(t *testing.T) {
+		outputs, removed, err := DetectDeletions(context.Background(), types.ScraperEvent{}, EntityKindUser, "run-1", startedAt, tombstone([]TombstonedItem{{ID: "user-1"}}, errors.New("throttled")))
+
+		assert.Error(t, err)
+		assert.Equal(t, 1, removed)
+		assert.Len(t, outputs, 1)
+	})
+
+	t.Run("filtered scrape never tombstones", func(t *testing.T) {
+		tombstoneCalls = 0
+
+		outputs, removed, err := DetectDeletions(context.Background(), types.ScraperEvent{FilterKeyword: "platform"}, EntityKindTeam, "run-1", startedAt, tombstone([]TombstonedItem{{ID: "team-1"}}, nil))
+
+		require.NoError(t, err)
+		assert.Zero(t, removed)
+		assert.Empty(t, outputs)
+		assert.Zero(t, tombstoneCalls)
+	})
+
+	t.Run("version-filtered scrape never tombstones", func(t *testing.T) {
+		tombstoneCalls = 0
+
+		outputs, removed, err := DetectDeletions(context.Background(), types.ScraperEvent{SchemaVersion: SchemaVersionV3}, EntityKindService, "run-1", startedAt, tombstone([]TombstonedItem{{ID: "svc-1"}}, nil))
+
+		require.NoError(t, err)
+		assert.Zero(t, removed)
+		assert.Empty(t, outputs)
+		assert.Zero(t, tombstoneCalls)
+	})
+}
@@ -0,0 +1,371 @@
+// Package shared provides pure functional utilities for Datadog API v2 data transformations.
+package shared
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/samber/lo"
+
+	"bacon/src/plugins/datadog/types"
+)
+
+// FilterFields maps the field names usable in a filter expression to their values on an entity
+// List fields return every element; a comparison matches when any element matches
+type FilterFields[T any] map[string]func(T) []string
+
+// TeamFilterFields are the fields available when filtering teams
+var TeamFilterFields = FilterFields[types.DatadogTeam]{
+	"id":          func(team types.DatadogTeam) []string { return []string{team.ID} },
+	"name":        func(team types.DatadogTeam) []string { return []string{team.Name} },
+	"handle":      func(team types.DatadogTeam) []string { return []string{team.Handle} },
+	"description": func(team types.DatadogTeam) []string { return []string{team.Description} },
+	"members": func(team types.DatadogTeam) []string {
+		return lo.Map(team.Members, func(user types.DatadogUser, _ int) string { return userEntityName(user) })
+	},
+}
+
+// UserFilterFields are the fields available when filtering users
+var UserFilterFields = FilterFields[types.DatadogUser]{
+	"id":       func(user types.DatadogUser) []string { return []string{user.ID} },
+	"name":     func(user types.DatadogUser) []string { return []string{user.Name} },
+	"email":    func(user types.DatadogUser) []string { return []string{user.Email} },
+	"handle":   func(user types.DatadogUser) []string { return []string{user.Handle} },
+	"title":    func(user types.DatadogUser) []string { return []string{user.Title} },
+	"status":   func(user types.DatadogUser) []string { return []string{user.Status} },
+	"verified": func(user types.DatadogUser) []string { return []string{strconv.FormatBool(user.Verified)} },
+	"disabled": func(user types.DatadogUser) []string { return []string{strconv.FormatBool(user.Disabled)} },
+	"teams":    func(user types.DatadogUser) []string { return user.Teams },
+	"roles":    func(user types.DatadogUser) []string { return user.Roles },
+}
+
+// ServiceFilterFields are the fields available when filtering services
+var ServiceFilterFields = FilterFields[types.DatadogService]{
+	"id":             func(service types.DatadogService) []string { return []string{service.ID} },
+	"name":           func(service types.DatadogService) []string { return []string{service.Name} },
+	"owner":          func(service types.DatadogService) []string { return []string{service.Owner} },
+	"schema_version": func(service types.DatadogService) []string { return []string{service.SchemaVersion} },
+	"kind":           func(service types.DatadogService) []string { return []string{service.Kind} },
+	"application":    func(service types.DatadogService) []string { return []string{service.Application} },
+	"description":    func(service types.DatadogService) []string { return []string{service.Description} },
+	"tier":           func(service types.DatadogService) []string { return []string{service.Tier} },
+	"lifecycle":      func(service types.DatadogService) []string { return []string{service.Lifecycle} },
+	"type":           func(service types.DatadogService) []string { return []string{service.Type} },
+	"teams":          func(service types.DatadogService) []string { return service.Teams },
+	"tags":           func(service types.DatadogService) []string { return service.Tags },
+	"languages":      func(service types.DatadogService) []string { return service.Languages },
+	"dependencies":   func(service types.DatadogService) []string { return service.Dependencies },
+	"component_of":   func(service types.DatadogService) []string { return service.ComponentOf },
+	"has_team": func(service types.DatadogService) []string {
+		return []string{strconv.FormatBool(HasTeamOwnership(service))}
+	},
+	"contacts": func(service types.DatadogService) []string {
+		return lo.Map(service.Contacts, func(contact types.DatadogContact, _ int) string { return contact.Contact })
+	},
+}
+
+// OrganizationFilterFields are the fields available when filtering organizations
+var OrganizationFilterFields = FilterFields[types.DatadogOrganization]{
+	"id":          func(org types.DatadogOrganization) []string { return []string{org.ID} },
+	"name":        func(org types.DatadogOrganization) []string { return []string{org.Name} },
+	"description": func(org types.DatadogOrganization) []string { return []string{org.Description} },
+}
+
+// CompileFilter parses a filter expression into a predicate over entities
+// Pure function; an empty expression matches everything and unknown fields are rejected
+//
+// Grammar, with case-insensitive keywords and string comparisons:
+//
+//	expr       = and { "or" and }
+//	and        = unary { "and" unary }
+//	unary      = "not" unary | "(" expr ")" | comparison
+//	comparison = field ( "==" | "!=" ) value | field [ "not" ] "in" "(" value { "," value } ")"
+//	value      = "quoted string" | bare word
+//
+// An empty list field compares as the empty string, so `teams != ""` keeps entities with teams.
+func CompileFilter[T any](expression string, fields FilterFields[T]) (func(T) bool, error) {
+	if strings.TrimSpace(expression) == "" {
+		return func(T) bool { return true }, nil
+	}
+
+	tokens, err := tokenizeFilter(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &filterParser[T]{tokens: tokens, fields: fields}
+	predicate, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := parser.peek(); token.kind != filterTokenEnd {
+		return nil, fmt.Errorf("unexpected %q at position %d", token.text, token.pos)
+	}
+
+	return predicate, nil
+}
+
+// ValidateFilter checks that a filter expression parses and only references known fields
+func ValidateFilter[T any](expression string, fields FilterFields[T]) error {
+	_, err := CompileFilter(expression, fields)
+	return err
+}
+
+// ApplyFilter keeps the entities matching a compiled filter
+func ApplyFilter[T any](entities []T, predicate func(T) bool) []T {
+	return lo.Filter(entities, func(entity T, _ int) bool {
+		return predicate(entity)
+	})
+}
+
+// Token kinds produced by tokenizeFilter
+const (
+	filterTokenEnd = iota
+	filterTokenWord
+	filterTokenString
+	filterTokenSymbol
+)
+
+// filterToken is a lexical token with its character position for error messages
+type filterToken struct {
+	kind int
+	text string
+	pos  int
+}
+
+// tokenizeFilter splits an expression into words, quoted strings and symbols
+func tokenizeFilter(expression string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(expression)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == ',':
+			tokens = append(tokens, filterToken{kind: filterTokenSymbol, text: string(r), pos: i})
+			i++
+		case (r == '=' || r == '!') && i+1 < len(runes) && runes[i+1] == '=':
+			tokens = append(tokens, filterToken{kind: filterTokenSymbol, text: string(runes[i : i+2]), pos: i})
+			i += 2
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				if runes[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			value, err := strconv.Unquote(string(runes[i : end+1]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at position %d: %w", i, err)
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenString, text: value, pos: i})
+			i = end + 1
+		case isFilterWordRune(r):
+			end := i
+			for end < len(runes) && isFilterWordRune(runes[end]) {
+				end++
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenWord, text: string(runes[i:end]), pos: i})
+			i = end
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+		}
+	}
+
+	return append(tokens, filterToken{kind: filterTokenEnd, text: "end of filter", pos: len(runes)}), nil
+}
+
+// isFilterWordRune reports whether a rune may appear in a field name or bare value
+func isFilterWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_-.:/@", r)
+}
+
+// filterParser is a recursive descent parser building predicates as it goes
+type filterParser[T any] struct {
+	tokens []filterToken
+	pos    int
+	fields FilterFields[T]
+}
+
+func (p *filterParser[T]) peek() filterToken {
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser[T]) next() filterToken {
+	token := p.tokens[p.pos]
+	if token.kind != filterTokenEnd {
+		p.pos++
+	}
+	return token
+}
+
+// acceptKeyword consumes the next token when it is the given keyword
+func (p *filterParser[T]) acceptKeyword(keyword string) bool {
+	if token := p.peek(); token.kind == filterTokenWord && strings.EqualFold(token.text, keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// expectSymbol consumes the given symbol or fails
+func (p *filterParser[T]) expectSymbol(symbol string) error {
+	if token := p.next(); token.kind != filterTokenSymbol || token.text != symbol {
+		return fmt.Errorf("expected %q at position %d, got %q", symbol, token.pos, token.text)
+	}
+	return nil
+}
+
+func (p *filterParser[T]) parseOr() (func(T) bool, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orPredicate(left, right)
+	}
+	return left, nil
+}
+
+func (p *filterParser[T]) parseAnd() (func(T) bool, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andPredicate(left, right)
+	}
+	return left, nil
+}
+
+func (p *filterParser[T]) parseUnary() (func(T) bool, error) {
+	if p.acceptKeyword("not") {
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(entity T) bool { return !inner(entity) }, nil
+	}
+
+	if token := p.peek(); token.kind == filterTokenSymbol && token.text == "(" {
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expectSymbol(")")
+	}
+
+	return p.parseComparison()
+}
+
+func (p *filterParser[T]) parseComparison() (func(T) bool, error) {
+	fieldToken := p.next()
+	if fieldToken.kind != filterTokenWord {
+		return nil, fmt.Errorf("expected a field name at position %d, got %q", fieldToken.pos, fieldToken.text)
+	}
+
+	field, known := p.fields[strings.ToLower(fieldToken.text)]
+	if !known {
+		return nil, fmt.Errorf("unknown field %q at position %d, expected one of %s", fieldToken.text, fieldToken.pos, strings.Join(p.fieldNames(), ", "))
+	}
+
+	negated := p.acceptKeyword("not")
+	if p.acceptKeyword("in") {
+		values, err := p.parseValueList()
+		if err != nil {
+			return nil, err
+		}
+		return matchPredicate(field, values, negated), nil
+	}
+	if negated {
+		token := p.peek()
+		return nil, fmt.Errorf("expected \"in\" at position %d, got %q", token.pos, token.text)
+	}
+
+	operator := p.next()
+	if operator.kind != filterTokenSymbol || (operator.text != "==" && operator.text != "!=") {
+		return nil, fmt.Errorf("expected \"==\", \"!=\" or \"in\" after %q at position %d, got %q", fieldToken.text, operator.pos, operator.text)
+	}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	return matchPredicate(field, []string{value}, operator.text == "!="), nil
+}
+
+func (p *filterParser[T]) parseValueList() ([]string, error) {
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+
+	var values []string
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		if token := p.peek(); token.kind == filterTokenSymbol && token.text == "," {
+			p.next()
+			continue
+		}
+		return values, p.expectSymbol(")")
+	}
+}
+
+func (p *filterParser[T]) parseValue() (string, error) {
+	token := p.next()
+	if token.kind != filterTokenString && token.kind != filterTokenWord {
+		return "", fmt.Errorf("expected a value at position %d, got %q", token.pos, token.text)
+	}
+	return token.text, nil
+}
+
+// fieldNames lists the known fields in sorted order for error messages
+func (p *filterParser[T]) fieldNames() []string {
+	names := lo.Keys(p.fields)
+	sort.Strings(names)
+	return names
+}
+
+// matchPredicate matches entities whose field has any value in the set, optionally negated
+func matchPredicate[T any](field func(T) []string, values []string, negated bool) func(T) bool {
+	return func(entity T) bool {
+		fieldValues := field(entity)
+		if len(fieldValues) == 0 {
+			fieldValues = []string{""}
+		}
+		matched := lo.SomeBy(fieldValues, func(fieldValue string) bool {
+			return lo.SomeBy(values, func(value string) bool { return strings.EqualFold(fieldValue, value) })
+		})
+		return matched != negated
+	}
+}
+
+func andPredicate[T any](left, right func(T) bool) func(T) bool {
+	return func(entity T) bool { return left(entity) && right(entity) }
+}
+
+func orPredicate[T any](left, right func(T) bool) func(T) bool {
+	return func(entity T) bool { return left(entity) || right(entity) }
+}
@@ -0,0 +1,88 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bacon/src/plugins/datadog/types"
+)
+
+func TestCompileFilter_Services(t *testing.T) {
+	services := []types.DatadogService{
+		{Name: "checkout", Tier: "1", Lifecycle: "production", Teams: []string{"payments"}, Tags: []string{"env:prod"}},
+		{Name: "billing", Tier: "2", Lifecycle: "deprecated", Teams: []string{"payments", "finance"}},
+		{Name: "search", Tier: "3", Lifecycle: "production"},
+		{Name: "ledger", Tier: "2", Lifecycle: "Production", Teams: []string{"finance"}},
+	}
+
+	cases := []struct {
+		expression string
+		expected   []string
+	}{
+		{``, []string{"checkout", "billing", "search", "ledger"}},
+		{`tier in ("1","2") and lifecycle != "deprecated"`, []string{"checkout", "ledger"}},
+		{`teams == finance`, []string{"billing", "ledger"}},
+		{`teams != ""`, []string{"checkout", "billing", "ledger"}},
+		{`teams == ""`, []string{"search"}},
+		{`has_team == true`, []string{"checkout", "billing", "ledger"}},
+		{`not (tier == 1 or tags == "env:prod")`, []string{"billing", "search", "ledger"}},
+		{`tier not in (1, 2) or name == ledger and lifecycle == production`, []string{"search", "ledger"}},
+		{`NAME == "CHECKOUT"`, []string{"checkout"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.expression, func(t *testing.T) {
+			filter, err := CompileFilter(tc.expression, ServiceFilterFields)
+			require.NoError(t, err)
+
+			names := make([]string, 0, len(services))
+			for _, service := range ApplyFilter(services, filter) {
+				names = append(names, service.Name)
+			}
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+func TestCompileFilter_Users(t *testing.T) {
+	filter, err := CompileFilter(`verified == true and roles in ("Datadog Admin Role")`, UserFilterFields)
+	require.NoError(t, err)
+
+	assert.True(t, filter(types.DatadogUser{Verified: true, Roles: []string{"Datadog Standard Role", "Datadog Admin Role"}}))
+	assert.False(t, filter(types.DatadogUser{Verified: false, Roles: []string{"Datadog Admin Role"}}))
+}
+
+func TestCompileFilter_Errors(t *testing.T) {
+	cases := map[string]string{
+		`owners == "payments"`:        `unknown field "owners" at position 0`,
+		`tier in ("1", "2"`:           `expected ")" at position 17`,
+		`tier = "1"`:                  `unexpected character '='`,
+		`tier == "1`:                  `unterminated string at position 8`,
+		`tier == 1 lifecycle == x`:    `unexpected "lifecycle" at position 10`,
+		`tier not == 1`:               `expected "in" at position 9`,
+		`(tier == 1`:                  `expected ")" at position 10, got "end of filter"`,
+		`tier ==`:                     `expected a value at position 7`,
+		`and tier == 1`:               `unknown field "and"`,
+		`tier == 1 and`:               `expected a field name at position 13`,
+		`tier ~ 1`:                    `unexpected character '~' at position 5`,
+		`lifecycle in ()`:             `expected a value at position 14`,
+		`tier == 1 or (name == "a"))`: `unexpected ")" at position 26`,
+	}
+
+	for expression, message := range cases {
+		t.Run(expression, func(t *testing.T) {
+			err := ValidateFilter(expression, ServiceFilterFields)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), message)
+		})
+	}
+}
+
+func TestCompileFilter_UnknownFieldListsKnownFields(t *testing.T) {
+	err := ValidateFilter(`tier == "1"`, TeamFilterFields)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected one of description, handle, id, members, name")
+}
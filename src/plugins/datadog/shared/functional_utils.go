@@ -10,6 +10,12 @@ import (
 	"bacon/src/plugins/datadog/types"
 )
 
+// Team membership roles as reported by the Team Memberships API
+const (
+	TeamRoleAdmin  = "admin"
+	TeamRoleMember = "member"
+)
+
 // Pure transformation functions using samber/lo for Teams API
 
 // TransformTeamResponse converts a Datadog API team response to our internal type
@@ -59,29 +65,101 @@ func TransformUserResponse(user datadogV2.User, _ int) types.DatadogUser {
 	}
 }
 
+// Pure transformation functions using samber/lo for Team Memberships API
+
+// TransformTeamMembership converts a team membership into a team member using the
+// users side-loaded in the memberships response
+// Curried so it can be used directly with lo.Map
+func TransformTeamMembership(included []datadogV2.UserTeamIncluded) func(datadogV2.UserTeam, int) types.DatadogUser {
+	usersByID := lo.SliceToMap(
+		lo.Filter(included, func(item datadogV2.UserTeamIncluded, _ int) bool {
+			return item.User != nil && item.User.Id != nil && item.User.Attributes != nil
+		}),
+		func(item datadogV2.UserTeamIncluded) (string, datadogV2.User) {
+			return *item.User.Id, *item.User
+		},
+	)
+
+	return func(membership datadogV2.UserTeam, _ int) types.DatadogUser {
+		userID := getMembershipUserID(membership)
+
+		member := types.DatadogUser{ID: userID}
+		if user, found := usersByID[userID]; found {
+			member = TransformUserResponse(user, 0)
+		}
+
+		member.TeamRole = extractMembershipRole(membership)
+		return member
+	}
+}
+
+// EnrichTeamWithMemberships sets team members from the authoritative memberships endpoint
+// Pure function that creates a new team with member data; members are tagged with the team ID
+func EnrichTeamWithMemberships(team types.DatadogTeam, members []types.DatadogUser) types.DatadogTeam {
+	teamMembers := lo.Map(members, func(member types.DatadogUser, _ int) types.DatadogUser {
+		member.Teams = lo.Uniq(append(append([]string{}, member.Teams...), team.ID))
+		return member
+	})
+
+	return types.DatadogTeam{
+		ID:          team.ID,
+		Name:        team.Name,
+		Handle:      team.Handle,
+		Description: team.Description,
+		Members:     teamMembers,
+		Services:    team.Services,
+		Links:       team.Links,
+		Metadata:    team.Metadata,
+		CreatedAt:   team.CreatedAt,
+		UpdatedAt:   team.UpdatedAt,
+	}
+}
+
 // Pure transformation functions using samber/lo for Services API
 
 // TransformServiceDefinition converts a Datadog service definition to our internal type
+// Handles every schema version in the definition union (v1, v2, v2.1, v2.2) as well as v3
+// entities; SchemaVersion reports the version that was actually parsed
 // Pure function with no side effects
 func TransformServiceDefinition(service datadogV2.ServiceDefinitionData, _ int) types.DatadogService {
+	var schema *datadogV2.ServiceDefinitionSchema
+	if service.Attributes != nil {
+		schema = service.Attributes.Schema
+	}
+
+	parsed, _ := parseServiceDefinitionSchema(schema)
+	tags := lo.Ternary(parsed.Tags != nil, parsed.Tags, []string{})
+	teams := compactTeams(append(parsed.Teams, extractTeamTags(tags)...)...)
+	modifiedAt := parseServiceModifiedTime(service)
+
+	ownerSource := ""
+	if len(teams) > 0 {
+		ownerSource = formatOwnerSource(OwnerSourceDeclaredTeam, teams[0])
+	}
+
 	return types.DatadogService{
-		ID:           safeStringFromPtr(service.Id),
-		Name:         safeStringFromPtr(service.Id), // Simplified - using ID as name for now
-		Owner:        "", // Will be filled by actual implementation
-		Teams:        []string{}, // Will be filled by actual implementation
-		Tags:         []string{}, // Will be filled by actual implementation
-		Schema:       safeStringFromPtr(service.Type),
-		Description:  "", // Will be filled by actual implementation
-		Tier:         "", // Will be filled by actual implementation
-		Lifecycle:    "", // Will be filled by actual implementation
-		Type:         safeStringFromPtr(service.Type),
-		Languages:    []string{}, // Will be filled by actual implementation
-		Contacts:     []types.DatadogContact{}, // Will be filled by actual implementation
-		Links:        []types.DatadogServiceLink{}, // Will be filled by actual implementation
-		Integrations: make(map[string]interface{}), // Will be filled by actual implementation
-		Dependencies: []string{}, // Will be filled by actual implementation
-		CreatedAt:    time.Now(), // Services don't have created_at in API
-		UpdatedAt:    time.Now(),
+		ID:            safeStringFromPtr(service.Id),
+		Name:          lo.Ternary(parsed.Name != "", parsed.Name, safeStringFromPtr(service.Id)),
+		Owner:         lo.FirstOrEmpty(teams),
+		OwnerSource:   ownerSource,
+		Teams:         teams,
+		Tags:          tags,
+		SchemaVersion: parsed.SchemaVersion,
+		Kind:          parsed.Kind,
+		Application:   parsed.Application,
+		Description:   parsed.Description,
+		Tier:          parsed.Tier,
+		Lifecycle:     parsed.Lifecycle,
+		Type:          lo.Ternary(parsed.Type != "", parsed.Type, safeStringFromPtr(service.Type)),
+		Languages:     lo.Ternary(parsed.Languages != nil, parsed.Languages, []string{}),
+		Contacts:      lo.Ternary(parsed.Contacts != nil, parsed.Contacts, []types.DatadogContact{}),
+		Links:         lo.Ternary(parsed.Links != nil, parsed.Links, []types.DatadogServiceLink{}),
+		Repos:         lo.Ternary(parsed.Repos != nil, parsed.Repos, []types.DatadogServiceLink{}),
+		Integrations:  lo.Ternary(parsed.Integrations != nil, parsed.Integrations, map[string]interface{}{}),
+		Dependencies:  lo.Ternary(parsed.Dependencies != nil, parsed.Dependencies, []string{}),
+		ComponentOf:   lo.Ternary(parsed.ComponentOf != nil, parsed.ComponentOf, []string{}),
+		CreatedAt:     modifiedAt, // Services don't have created_at in API
+		UpdatedAt:     modifiedAt,
 	}
 }
 
@@ -166,10 +244,7 @@ func EnrichTeamWithMembers(team types.DatadogTeam, users []types.DatadogUser) ty
 // Pure function that creates a new team with service information
 func EnrichTeamWithServices(team types.DatadogTeam, services []types.DatadogService) types.DatadogTeam {
 	teamServices := lo.Filter(services, func(service types.DatadogService, _ int) bool {
-		return lo.Contains(service.Teams, team.ID) || 
-			   lo.SomeBy(service.Contacts, func(contact types.DatadogContact) bool {
-				   return contact.Contact == team.Handle || contact.Contact == team.Name
-			   })
+		return ServiceOwnedByTeam(service, team)
 	})
 
 	return types.DatadogTeam{
@@ -207,10 +282,23 @@ func extractTeamFromTag(tag string, _ int) string {
 	return tag
 }
 
-// extractOwnerFromContacts finds the owner from service contacts
-func extractOwnerFromContacts(contacts []datadogV2.ServiceDefinitionV2Dot2Contact) string {
-	// Simplified implementation - will be enhanced later
-	return ""
+// getMembershipUserID safely extracts the user ID from a team membership
+func getMembershipUserID(membership datadogV2.UserTeam) string {
+	if membership.Relationships == nil || membership.Relationships.User == nil {
+		return ""
+	}
+	return membership.Relationships.User.Data.Id
+}
+
+// extractMembershipRole extracts the team role, defaulting to "member" when the API omits it
+func extractMembershipRole(membership datadogV2.UserTeam) string {
+	if membership.Attributes == nil {
+		return TeamRoleMember
+	}
+	if role := membership.Attributes.Role.Get(); role != nil && *role != "" {
+		return string(*role)
+	}
+	return TeamRoleMember
 }
 
 // Helper functions for safe data access
@@ -236,24 +324,6 @@ func getUserRoles(user datadogV2.User) []datadogV2.RelationshipToRoleData {
 	return []datadogV2.RelationshipToRoleData{}
 }
 
-// getServiceTeamTags safely extracts team tags from service
-func getServiceTeamTags(service datadogV2.ServiceDefinitionData) []string {
-	// Simplified implementation - will be enhanced later
-	return []string{}
-}
-
-// getServiceLinks safely extracts service links
-func getServiceLinks(service datadogV2.ServiceDefinitionData) []datadogV2.ServiceDefinitionV2Dot2Link {
-	// Simplified implementation - will be enhanced later
-	return []datadogV2.ServiceDefinitionV2Dot2Link{}
-}
-
-// extractDependencies extracts service dependencies
-func extractDependencies(service datadogV2.ServiceDefinitionData) []string {
-	// Simplified implementation - will be enhanced later
-	return []string{}
-}
-
 // extractTeamMetadata extracts metadata from team attributes
 func extractTeamMetadata(team datadogV2.Team) map[string]interface{} {
 	metadata := make(map[string]interface{})
@@ -243,7 +243,7 @@ func TestTransformServiceDefinition(t *testing.T) {
 				Owner:         "",
 				Teams:         []string{},
 				Tags:          []string{},
-				Schema:        "service",
+				SchemaVersion: "",
 				Description:   "",
 				Tier:          "",
 				Lifecycle:     "",
@@ -267,7 +267,7 @@ func TestTransformServiceDefinition(t *testing.T) {
 				Owner:         "",
 				Teams:         []string{},
 				Tags:          []string{},
-				Schema:        "",
+				SchemaVersion: "",
 				Description:   "",
 				Tier:          "",
 				Lifecycle:     "",
@@ -291,8 +291,8 @@ func TestTransformServiceDefinition(t *testing.T) {
 			if result.Name != tc.expectedService.Name {
 				t.Errorf("Expected Name %s, got %s", tc.expectedService.Name, result.Name)
 			}
-			if result.Schema != tc.expectedService.Schema {
-				t.Errorf("Expected Schema %s, got %s", tc.expectedService.Schema, result.Schema)
+			if result.SchemaVersion != tc.expectedService.SchemaVersion {
+				t.Errorf("Expected SchemaVersion %s, got %s", tc.expectedService.SchemaVersion, result.SchemaVersion)
 			}
 			if result.Type != tc.expectedService.Type {
 				t.Errorf("Expected Type %s, got %s", tc.expectedService.Type, result.Type)
@@ -949,4 +949,117 @@ func timePtr(t time.Time) *time.Time {
 func createNullableString(s string) datadog.NullableString {
 	ns := datadog.NewNullableString(&s)
 	return *ns
-}
\ No newline at end of file
+}
+func TestTransformTeamMembership(t *testing.T) {
+	adminRole := datadogV2.USERTEAMROLE_ADMIN
+	included := []datadogV2.UserTeamIncluded{
+		{
+			User: &datadogV2.User{
+				Id: stringPtr("user-1"),
+				Attributes: &datadogV2.UserAttributes{
+					Name:   createNullableString("Alice"),
+					Email:  stringPtr("alice@example.com"),
+					Handle: stringPtr("alice"),
+					Status: stringPtr("Active"),
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name         string
+		membership   datadogV2.UserTeam
+		expectedID   string
+		expectedName string
+		expectedRole string
+	}{
+		{
+			name: "admin with included user",
+			membership: datadogV2.UserTeam{
+				Id:         "membership-1",
+				Attributes: &datadogV2.UserTeamAttributes{Role: *datadogV2.NewNullableUserTeamRole(&adminRole)},
+				Relationships: &datadogV2.UserTeamRelationships{
+					User: &datadogV2.RelationshipToUserTeamUser{Data: datadogV2.RelationshipToUserTeamUserData{Id: "user-1"}},
+				},
+			},
+			expectedID:   "user-1",
+			expectedName: "Alice",
+			expectedRole: TeamRoleAdmin,
+		},
+		{
+			name: "member without included user",
+			membership: datadogV2.UserTeam{
+				Id:         "membership-2",
+				Attributes: &datadogV2.UserTeamAttributes{},
+				Relationships: &datadogV2.UserTeamRelationships{
+					User: &datadogV2.RelationshipToUserTeamUser{Data: datadogV2.RelationshipToUserTeamUserData{Id: "user-2"}},
+				},
+			},
+			expectedID:   "user-2",
+			expectedName: "",
+			expectedRole: TeamRoleMember,
+		},
+		{
+			name:         "membership without relationships",
+			membership:   datadogV2.UserTeam{Id: "membership-3"},
+			expectedID:   "",
+			expectedName: "",
+			expectedRole: TeamRoleMember,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := TransformTeamMembership(included)(tc.membership, 0)
+
+			if result.ID != tc.expectedID {
+				t.Errorf("Expected ID %q, got %q", tc.expectedID, result.ID)
+			}
+			if result.Name != tc.expectedName {
+				t.Errorf("Expected name %q, got %q", tc.expectedName, result.Name)
+			}
+			if result.TeamRole != tc.expectedRole {
+				t.Errorf("Expected role %q, got %q", tc.expectedRole, result.TeamRole)
+			}
+		})
+	}
+}
+
+func TestEnrichTeamWithMemberships(t *testing.T) {
+	team := types.DatadogTeam{
+		ID:     "team-123",
+		Name:   "Engineering",
+		Handle: "engineering",
+	}
+
+	members := []types.DatadogUser{
+		{ID: "user-1", TeamRole: TeamRoleAdmin},
+		{ID: "user-2", TeamRole: TeamRoleMember, Teams: []string{"team-456"}},
+		{ID: "user-3", TeamRole: TeamRoleMember, Teams: []string{"team-123"}},
+	}
+
+	result := EnrichTeamWithMemberships(team, members)
+
+	if len(result.Members) != len(members) {
+		t.Fatalf("Expected %d members, got %d", len(members), len(result.Members))
+	}
+
+	for _, member := range result.Members {
+		count := 0
+		for _, teamID := range member.Teams {
+			if teamID == team.ID {
+				count++
+			}
+		}
+		if count != 1 {
+			t.Errorf("Expected member %s to reference team exactly once, got %d", member.ID, count)
+		}
+	}
+
+	if result.Members[0].TeamRole != TeamRoleAdmin {
+		t.Errorf("Expected member role to be preserved")
+	}
+	if len(members[1].Teams) != 1 {
+		t.Errorf("Expected input members to be left unmodified")
+	}
+}
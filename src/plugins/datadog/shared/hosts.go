@@ -0,0 +1,154 @@
+// Package shared provides pure functional utilities for Datadog API v2 data transformations.
+package shared
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV1"
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV2"
+	"github.com/samber/lo"
+
+	"bacon/src/plugins/datadog/types"
+)
+
+// Kinds of infrastructure resources scraped from Datadog
+const (
+	HostKindHost      = "host"
+	HostKindContainer = "container"
+)
+
+// CloudProviderAWS is the cloud provider recorded for EC2 instances
+const CloudProviderAWS = "aws"
+
+// workloadTagKinds maps Kubernetes workload tags to the resource kinds used by openshift-metadata
+// Ordered so that the most specific controller wins when several tags are present
+var workloadTagKinds = []lo.Tuple2[string, string]{
+	{A: "kube_deployment", B: "Deployment"},
+	{A: "kube_stateful_set", B: "StatefulSet"},
+	{A: "kube_daemon_set", B: "DaemonSet"},
+	{A: "kube_cronjob", B: "CronJob"},
+	{A: "kube_job", B: "Job"},
+}
+
+var ec2InstanceIDPattern = regexp.MustCompile(`^i-[0-9a-f]{8,17}$`)
+
+// TransformHost converts a Datadog API v1 host to the internal infrastructure type
+// Pure function that flattens tags from every source and resolves the resource key
+func TransformHost(host datadogV1.Host, _ int) types.DatadogHost {
+	tags := flattenTagsBySource(host.TagsBySource)
+
+	instanceID := lo.FirstOrEmpty(tagValues(tags, "instance-id"))
+	if instanceID == "" {
+		instanceID, _ = lo.Find(host.Aliases, ec2InstanceIDPattern.MatchString)
+	}
+
+	cloudProvider := lo.FirstOrEmpty(tagValues(tags, "cloud_provider"))
+	if cloudProvider == "" && (ec2InstanceIDPattern.MatchString(instanceID) || len(tagValues(tags, "aws_account")) > 0) {
+		cloudProvider = CloudProviderAWS
+	}
+
+	resource := createInfraResource(tags)
+	resource.ID = strconv.FormatInt(lo.FromPtr(host.Id), 10)
+	resource.Name = lo.CoalesceOrEmpty(safeStringFromPtr(host.HostName), safeStringFromPtr(host.Name))
+	resource.Kind = HostKindHost
+	resource.CloudProvider = cloudProvider
+	resource.InstanceID = instanceID
+	resource.ResourceKey = lo.CoalesceOrEmpty(createEC2ARN(tags, instanceID, cloudProvider), createWorkloadKey(tags))
+	return resource
+}
+
+// TransformContainer converts a Datadog API v2 container to the internal infrastructure type
+// Pure function; container groups and unparsed items yield a zero value with an empty ID
+func TransformContainer(item datadogV2.ContainerItem, _ int) types.DatadogHost {
+	if item.Container == nil || item.Container.Attributes == nil {
+		return types.DatadogHost{}
+	}
+
+	attributes := item.Container.Attributes
+
+	resource := createInfraResource(attributes.Tags)
+	resource.ID = safeStringFromPtr(item.Container.Id)
+	resource.Name = safeStringFromPtr(attributes.Name)
+	resource.Kind = HostKindContainer
+	resource.ResourceKey = createWorkloadKey(attributes.Tags)
+	return resource
+}
+
+// ExtractHostRelationships derives owns and runs_on edges keyed by EC2 ARN or Kubernetes workload
+// Pure function; resources without a stable key cannot corroborate other sources and are skipped
+func ExtractHostRelationships(hosts []types.DatadogHost) []types.DatadogRelationship {
+	keyed := lo.Filter(hosts, func(host types.DatadogHost, _ int) bool {
+		return host.ResourceKey != ""
+	})
+
+	relationships := lo.FlatMap(keyed, func(host types.DatadogHost, _ int) []types.DatadogRelationship {
+		owners := lo.Uniq(append(append([]string{}, host.Teams...), host.Owners...))
+
+		owns := lo.Map(owners, func(owner string, _ int) types.DatadogRelationship {
+			return types.DatadogRelationship{From: owner, To: host.ResourceKey, Type: RelationshipTypeOwns}
+		})
+
+		runsOn := lo.Map(host.Services, func(service string, _ int) types.DatadogRelationship {
+			return types.DatadogRelationship{From: service, To: host.ResourceKey, Type: RelationshipTypeRunsOn}
+		})
+
+		return append(owns, runsOn...)
+	})
+
+	return lo.Uniq(relationships)
+}
+
+// createInfraResource extracts the ownership tags shared by hosts and containers
+func createInfraResource(tags []string) types.DatadogHost {
+	return types.DatadogHost{
+		Env:      lo.FirstOrEmpty(tagValues(tags, "env")),
+		Teams:    tagValues(tags, "team"),
+		Owners:   tagValues(tags, "owner"),
+		Services: tagValues(tags, "service"),
+		Tags:     lo.Ternary(tags != nil, tags, []string{}),
+	}
+}
+
+// createEC2ARN builds an EC2 instance ARN from region and account tags
+func createEC2ARN(tags []string, instanceID, cloudProvider string) string {
+	region := lo.FirstOrEmpty(tagValues(tags, "region"))
+	account := lo.FirstOrEmpty(tagValues(tags, "aws_account"))
+
+	if cloudProvider != CloudProviderAWS || instanceID == "" || region == "" || account == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("arn:aws:ec2:%s:%s:instance/%s", region, account, instanceID)
+}
+
+// createWorkloadKey builds the "Kind/name" key used by openshift-metadata from Kubernetes tags
+func createWorkloadKey(tags []string) string {
+	for _, workload := range workloadTagKinds {
+		if name := lo.FirstOrEmpty(tagValues(tags, workload.A)); name != "" {
+			return workload.B + "/" + name
+		}
+	}
+	return ""
+}
+
+// flattenTagsBySource merges tags from all sources in a deterministic order
+func flattenTagsBySource(tagsBySource map[string][]string) []string {
+	sources := lo.Keys(tagsBySource)
+	sort.Strings(sources)
+
+	return lo.Uniq(lo.FlatMap(sources, func(source string, _ int) []string {
+		return tagsBySource[source]
+	}))
+}
+
+// tagValues returns the non-empty values of all "key:value" tags with the given key
+func tagValues(tags []string, key string) []string {
+	prefix := key + ":"
+	return lo.Uniq(lo.FilterMap(tags, func(tag string, _ int) (string, bool) {
+		return strings.TrimPrefix(tag, prefix), strings.HasPrefix(tag, prefix) && len(tag) > len(prefix)
+	}))
+}
@@ -0,0 +1,111 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV1"
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+
+	"bacon/src/plugins/datadog/types"
+)
+
+func TestTransformHost(t *testing.T) {
+	t.Run("ec2 instance", func(t *testing.T) {
+		host := datadogV1.Host{
+			Id:       lo.ToPtr(int64(1001)),
+			HostName: lo.ToPtr("ip-10-0-0-1"),
+			Aliases:  []string{"ip-10-0-0-1.ec2.internal", "i-0abc1234def567890"},
+			TagsBySource: map[string][]string{
+				"Amazon Web Services": {"region:us-east-1", "aws_account:123456789012", "team:payments"},
+				"Datadog":             {"env:prod", "service:checkout", "owner:jane", "team:payments"},
+			},
+		}
+
+		result := TransformHost(host, 0)
+
+		assert.Equal(t, "1001", result.ID)
+		assert.Equal(t, "ip-10-0-0-1", result.Name)
+		assert.Equal(t, HostKindHost, result.Kind)
+		assert.Equal(t, CloudProviderAWS, result.CloudProvider)
+		assert.Equal(t, "i-0abc1234def567890", result.InstanceID)
+		assert.Equal(t, "arn:aws:ec2:us-east-1:123456789012:instance/i-0abc1234def567890", result.ResourceKey)
+		assert.Equal(t, "prod", result.Env)
+		assert.Equal(t, []string{"payments"}, result.Teams)
+		assert.Equal(t, []string{"jane"}, result.Owners)
+		assert.Equal(t, []string{"checkout"}, result.Services)
+	})
+
+	t.Run("kubernetes node falls back to workload key", func(t *testing.T) {
+		host := datadogV1.Host{
+			Name: lo.ToPtr("node-1"),
+			TagsBySource: map[string][]string{
+				"Kubernetes": {"kube_deployment:web-app", "kube_namespace:shop", "team:frontend"},
+			},
+		}
+
+		result := TransformHost(host, 0)
+
+		assert.Equal(t, "node-1", result.Name)
+		assert.Equal(t, "", result.CloudProvider)
+		assert.Equal(t, "Deployment/web-app", result.ResourceKey)
+	})
+
+	t.Run("instance without account cannot build an ARN", func(t *testing.T) {
+		host := datadogV1.Host{
+			TagsBySource: map[string][]string{"Datadog": {"instance-id:i-0abc1234def567890", "region:us-east-1"}},
+		}
+
+		result := TransformHost(host, 0)
+
+		assert.Equal(t, CloudProviderAWS, result.CloudProvider)
+		assert.Equal(t, "", result.ResourceKey)
+		assert.NotNil(t, result.Tags)
+	})
+}
+
+func TestTransformContainer(t *testing.T) {
+	item := datadogV2.ContainerItem{
+		Container: &datadogV2.Container{
+			Id: lo.ToPtr("c-1"),
+			Attributes: &datadogV2.ContainerAttributes{
+				Name: lo.ToPtr("worker"),
+				Tags: []string{"kube_stateful_set:kafka", "team:streaming", "env:staging"},
+			},
+		},
+	}
+
+	result := TransformContainer(item, 0)
+
+	assert.Equal(t, "c-1", result.ID)
+	assert.Equal(t, HostKindContainer, result.Kind)
+	assert.Equal(t, "StatefulSet/kafka", result.ResourceKey)
+	assert.Equal(t, "staging", result.Env)
+	assert.Equal(t, []string{"streaming"}, result.Teams)
+
+	assert.Equal(t, types.DatadogHost{}, TransformContainer(datadogV2.ContainerItem{ContainerGroup: &datadogV2.ContainerGroup{}}, 0))
+}
+
+func TestExtractHostRelationships(t *testing.T) {
+	hosts := []types.DatadogHost{
+		{
+			ResourceKey: "arn:aws:ec2:us-east-1:123456789012:instance/i-0abc1234def567890",
+			Teams:       []string{"payments"},
+			Owners:      []string{"payments", "jane"},
+			Services:    []string{"checkout"},
+		},
+		{
+			Teams: []string{"orphans"},
+		},
+	}
+
+	relationships := ExtractHostRelationships(hosts)
+
+	arn := "arn:aws:ec2:us-east-1:123456789012:instance/i-0abc1234def567890"
+	assert.ElementsMatch(t, []types.DatadogRelationship{
+		{From: "payments", To: arn, Type: RelationshipTypeOwns},
+		{From: "jane", To: arn, Type: RelationshipTypeOwns},
+		{From: "checkout", To: arn, Type: RelationshipTypeRunsOn},
+	}, relationships, "Resources without a key should be skipped")
+}
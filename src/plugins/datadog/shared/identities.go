@@ -0,0 +1,54 @@
+// Package shared provides pure functional utilities for Datadog API v2 data transformations.
+package shared
+
+import (
+	"time"
+
+	"github.com/samber/lo"
+
+	"bacon/src/plugins/datadog/types"
+	"bacon/src/shared/scraperoutput"
+)
+
+// Scraper output sources for the Datadog identity directories the processor resolves owners against
+const (
+	SourceDatadogUsers = "datadog-users"
+	SourceDatadogTeams = "datadog-teams"
+)
+
+// CreateUserIdentityOutput lists users as people keyed by email, going by their handle and Datadog ID as well
+// Pure function; users without an email are keyed by handle
+func CreateUserIdentityOutput(users []types.DatadogUser, producedAt time.Time) types.ScraperOutput {
+	identities := lo.FilterMap(users, func(user types.DatadogUser, _ int) (scraperoutput.Identity, bool) {
+		key := lo.Ternary(user.Email != "", user.Email, user.Handle)
+		return scraperoutput.Identity{
+			Kind:    scraperoutput.IdentityPerson,
+			Key:     key,
+			Email:   user.Email,
+			Aliases: identityAliases(key, user.Handle, user.ID),
+		}, key != ""
+	})
+
+	return scraperoutput.New(SourceDatadogUsers, 1.0, producedAt, scraperoutput.Identities(identities))
+}
+
+// CreateTeamIdentityOutput lists teams keyed by handle, going by their display name and Datadog ID as well
+// Pure function; teams without a handle cannot be referenced by other sources and are skipped
+func CreateTeamIdentityOutput(teams []types.DatadogTeam, producedAt time.Time) types.ScraperOutput {
+	identities := lo.FilterMap(teams, func(team types.DatadogTeam, _ int) (scraperoutput.Identity, bool) {
+		return scraperoutput.Identity{
+			Kind:    scraperoutput.IdentityTeam,
+			Key:     team.Handle,
+			Aliases: identityAliases(team.Handle, team.Name, team.ID),
+		}, team.Handle != ""
+	})
+
+	return scraperoutput.New(SourceDatadogTeams, 1.0, producedAt, scraperoutput.Identities(identities))
+}
+
+// identityAliases returns the non-empty names other than the key, each listed once
+func identityAliases(key string, names ...string) []string {
+	return lo.Uniq(lo.Filter(names, func(name string, _ int) bool {
+		return name != "" && name != key
+	}))
+}
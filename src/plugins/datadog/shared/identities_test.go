@@ -0,0 +1,43 @@
+package shared
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"bacon/src/plugins/datadog/types"
+	"bacon/src/shared/scraperoutput"
+)
+
+func TestCreateUserIdentityOutput(t *testing.T) {
+	producedAt := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	output := CreateUserIdentityOutput([]types.DatadogUser{
+		{ID: "u-1", Email: "ana@example.com", Handle: "ana@example.com"},
+		{ID: "u-2", Handle: "bot"},
+		{ID: "u-3"},
+	}, producedAt)
+
+	assert.Equal(t, SourceDatadogUsers, output.Source)
+	assert.Equal(t, scraperoutput.KindIdentities, output.Payload.Kind)
+	assert.Equal(t, []scraperoutput.Identity{
+		{Kind: scraperoutput.IdentityPerson, Key: "ana@example.com", Email: "ana@example.com", Aliases: []string{"u-1"}},
+		{Kind: scraperoutput.IdentityPerson, Key: "bot", Aliases: []string{"u-2"}},
+	}, output.Payload.Identities)
+}
+
+func TestCreateTeamIdentityOutput(t *testing.T) {
+	output := CreateTeamIdentityOutput([]types.DatadogTeam{
+		{ID: "t-1", Handle: "backend", Name: "Backend Engineering"},
+		{ID: "t-2", Name: "No Handle"},
+	}, time.Now())
+
+	assert.Equal(t, SourceDatadogTeams, output.Source)
+	assert.Equal(t, []scraperoutput.Identity{
+		{Kind: scraperoutput.IdentityTeam, Key: "backend", Aliases: []string{"Backend Engineering", "t-1"}},
+	}, output.Payload.Identities)
+
+	_, errs := scraperoutput.Validate(output)
+	assert.Empty(t, errs)
+}
@@ -0,0 +1,147 @@
+// Package shared provides pure functional utilities for Datadog API v2 data transformations.
+package shared
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV1"
+	"github.com/samber/lo"
+
+	"bacon/src/plugins/datadog/types"
+)
+
+// Kinds of alerting objects scraped for ownership signals
+const (
+	MonitorKindMonitor = "monitor"
+	MonitorKindSLO     = "slo"
+)
+
+// Notification handle types parsed from monitor messages
+const (
+	HandleTypeSlack     = "slack"
+	HandleTypePagerDuty = "pagerduty"
+	HandleTypeEmail     = "email"
+)
+
+// signalTeamTag identifies ownership declared through a "team:" tag
+const signalTeamTag = "team_tag"
+
+// monitorSignalConfidence ranks ownership signals: paging routes are stronger evidence than chat or email
+var monitorSignalConfidence = map[string]float64{
+	signalTeamTag:       0.9,
+	HandleTypePagerDuty: 0.85,
+	HandleTypeSlack:     0.6,
+	HandleTypeEmail:     0.5,
+}
+
+var (
+	notificationHandlePattern = regexp.MustCompile(`(?:^|[\s(,;}])@([^\s,;(){}]+)`)
+	serviceScopePattern       = regexp.MustCompile(`\bservice:([A-Za-z0-9_.\-/]+)`)
+)
+
+// TransformMonitor converts a Datadog API v1 monitor to the internal monitor type
+// Pure function that extracts team tags, service scope and notification handles
+func TransformMonitor(monitor datadogV1.Monitor, _ int) types.DatadogMonitor {
+	return types.DatadogMonitor{
+		ID:       strconv.FormatInt(lo.FromPtr(monitor.Id), 10),
+		Kind:     MonitorKindMonitor,
+		Name:     safeStringFromPtr(monitor.Name),
+		Type:     string(monitor.Type),
+		Tags:     lo.Ternary(monitor.Tags != nil, monitor.Tags, []string{}),
+		Teams:    compactTeams(extractTeamTags(monitor.Tags)...),
+		Services: extractMonitoredServices(monitor.Tags, monitor.Query),
+		Handles:  ParseNotificationHandles(safeStringFromPtr(monitor.Message)),
+	}
+}
+
+// TransformSLO converts a Datadog API v1 service level objective to the internal monitor type
+// Pure function; tags of the underlying monitors count towards the SLO's ownership
+func TransformSLO(slo datadogV1.ServiceLevelObjective, _ int) types.DatadogMonitor {
+	tags := lo.Uniq(append(append([]string{}, slo.Tags...), slo.MonitorTags...))
+
+	return types.DatadogMonitor{
+		ID:       safeStringFromPtr(slo.Id),
+		Kind:     MonitorKindSLO,
+		Name:     slo.Name,
+		Type:     string(slo.Type),
+		Tags:     tags,
+		Teams:    compactTeams(extractTeamTags(tags)...),
+		Services: extractMonitoredServices(tags, ""),
+		Handles:  ParseNotificationHandles(safeStringFromNullable(slo.Description)),
+	}
+}
+
+// ParseNotificationHandles extracts Slack, PagerDuty and email @-handles from a monitor message
+// Pure function; unknown handle types are ignored and duplicates removed
+func ParseNotificationHandles(message string) []types.DatadogNotificationHandle {
+	matches := notificationHandlePattern.FindAllStringSubmatch(message, -1)
+
+	handles := lo.FilterMap(matches, func(match []string, _ int) (types.DatadogNotificationHandle, bool) {
+		return classifyNotificationHandle(strings.TrimRight(match[1], ".:!?"))
+	})
+
+	return lo.Uniq(handles)
+}
+
+// ExtractMonitorRelationships derives monitors edges from teams and handles to the monitored targets
+// Monitors without a service scope are attached to the monitor or SLO itself as a resource
+func ExtractMonitorRelationships(monitors []types.DatadogMonitor) []types.DatadogRelationship {
+	relationships := lo.FlatMap(monitors, func(monitor types.DatadogMonitor, _ int) []types.DatadogRelationship {
+		targets := lo.Ternary(len(monitor.Services) > 0, monitor.Services, []string{monitorResourceID(monitor)})
+
+		teamEdges := lo.FlatMap(monitor.Teams, func(team string, _ int) []types.DatadogRelationship {
+			return createMonitorEdges(team, targets, monitorSignalConfidence[signalTeamTag])
+		})
+
+		handleEdges := lo.FlatMap(monitor.Handles, func(handle types.DatadogNotificationHandle, _ int) []types.DatadogRelationship {
+			return createMonitorEdges(handle.Type+":"+handle.Target, targets, monitorSignalConfidence[handle.Type])
+		})
+
+		return append(teamEdges, handleEdges...)
+	})
+
+	return lo.Uniq(relationships)
+}
+
+// classifyNotificationHandle maps a raw handle to its notification channel
+func classifyNotificationHandle(handle string) (types.DatadogNotificationHandle, bool) {
+	switch {
+	case strings.HasPrefix(handle, "slack-") && len(handle) > len("slack-"):
+		return types.DatadogNotificationHandle{Type: HandleTypeSlack, Target: strings.TrimPrefix(handle, "slack-")}, true
+	case strings.HasPrefix(handle, "pagerduty-") && len(handle) > len("pagerduty-"):
+		return types.DatadogNotificationHandle{Type: HandleTypePagerDuty, Target: strings.TrimPrefix(handle, "pagerduty-")}, true
+	case strings.Count(handle, "@") == 1 && !strings.HasPrefix(handle, "@") && !strings.HasSuffix(handle, "@"):
+		return types.DatadogNotificationHandle{Type: HandleTypeEmail, Target: strings.ToLower(handle)}, true
+	}
+	return types.DatadogNotificationHandle{}, false
+}
+
+// extractMonitoredServices collects services from "service:" tags and the monitor query scope
+func extractMonitoredServices(tags []string, query string) []string {
+	fromTags := tagValues(tags, "service")
+
+	fromQuery := lo.Map(serviceScopePattern.FindAllStringSubmatch(query, -1), func(match []string, _ int) string {
+		return match[1]
+	})
+
+	return lo.Uniq(append(fromTags, fromQuery...))
+}
+
+// createMonitorEdges creates one monitors edge per target
+func createMonitorEdges(from string, targets []string, confidence float64) []types.DatadogRelationship {
+	return lo.Map(targets, func(target string, _ int) types.DatadogRelationship {
+		return types.DatadogRelationship{
+			From:       from,
+			To:         target,
+			Type:       RelationshipTypeMonitors,
+			Confidence: confidence,
+		}
+	})
+}
+
+// monitorResourceID identifies a monitor or SLO as a graph resource
+func monitorResourceID(monitor types.DatadogMonitor) string {
+	return "datadog-" + monitor.Kind + ":" + monitor.ID
+}
@@ -0,0 +1,122 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadog"
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV1"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+
+	"bacon/src/plugins/datadog/types"
+)
+
+func TestParseNotificationHandles(t *testing.T) {
+	testCases := []struct {
+		name     string
+		message  string
+		expected []types.DatadogNotificationHandle
+	}{
+		{
+			name:    "all supported handle types",
+			message: "CPU is high on {{host.name}}. Notify @slack-payments-alerts @pagerduty-Payments-Oncall and @oncall@example.com.",
+			expected: []types.DatadogNotificationHandle{
+				{Type: HandleTypeSlack, Target: "payments-alerts"},
+				{Type: HandleTypePagerDuty, Target: "Payments-Oncall"},
+				{Type: HandleTypeEmail, Target: "oncall@example.com"},
+			},
+		},
+		{
+			name:    "template variables, duplicates and unsupported handles",
+			message: "{{#is_alert}}@slack-ops{{/is_alert}} @webhook-deploy @slack-ops (@opsgenie-sre)",
+			expected: []types.DatadogNotificationHandle{
+				{Type: HandleTypeSlack, Target: "ops"},
+			},
+		},
+		{
+			name:     "inline email addresses are not handles",
+			message:  "Escalate to jane@example.com if unresolved",
+			expected: []types.DatadogNotificationHandle{},
+		},
+		{
+			name:     "empty message",
+			message:  "",
+			expected: []types.DatadogNotificationHandle{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ElementsMatch(t, tc.expected, ParseNotificationHandles(tc.message))
+		})
+	}
+}
+
+func TestTransformMonitor(t *testing.T) {
+	monitor := datadogV1.Monitor{
+		Id:      lo.ToPtr(int64(42)),
+		Name:    lo.ToPtr("High latency"),
+		Type:    datadogV1.MONITORTYPE_QUERY_ALERT,
+		Query:   "avg(last_5m):avg:trace.http.request.duration{service:checkout,env:prod} > 2",
+		Message: lo.ToPtr("Latency is high @pagerduty-checkout"),
+		Tags:    []string{"team:payments", "service:billing", "env:prod"},
+	}
+
+	result := TransformMonitor(monitor, 0)
+
+	assert.Equal(t, "42", result.ID)
+	assert.Equal(t, MonitorKindMonitor, result.Kind)
+	assert.Equal(t, "High latency", result.Name)
+	assert.Equal(t, "query alert", result.Type)
+	assert.Equal(t, []string{"payments"}, result.Teams)
+	assert.Equal(t, []string{"billing", "checkout"}, result.Services)
+	assert.Equal(t, []types.DatadogNotificationHandle{{Type: HandleTypePagerDuty, Target: "checkout"}}, result.Handles)
+}
+
+func TestTransformSLO(t *testing.T) {
+	slo := datadogV1.ServiceLevelObjective{
+		Id:          lo.ToPtr("slo-1"),
+		Name:        "Checkout availability",
+		Type:        datadogV1.SLOTYPE_MONITOR,
+		Description: *datadog.NewNullableString(lo.ToPtr("Owned by @slack-checkout")),
+		Tags:        []string{"team:payments"},
+		MonitorTags: []string{"service:checkout", "team:payments"},
+	}
+
+	result := TransformSLO(slo, 0)
+
+	assert.Equal(t, "slo-1", result.ID)
+	assert.Equal(t, MonitorKindSLO, result.Kind)
+	assert.Equal(t, []string{"payments"}, result.Teams)
+	assert.Equal(t, []string{"checkout"}, result.Services)
+	assert.Equal(t, []types.DatadogNotificationHandle{{Type: HandleTypeSlack, Target: "checkout"}}, result.Handles)
+}
+
+func TestExtractMonitorRelationships(t *testing.T) {
+	monitors := []types.DatadogMonitor{
+		{
+			ID:       "42",
+			Kind:     MonitorKindMonitor,
+			Teams:    []string{"payments"},
+			Services: []string{"checkout"},
+			Handles:  []types.DatadogNotificationHandle{{Type: HandleTypePagerDuty, Target: "checkout-oncall"}},
+		},
+		{
+			ID:      "slo-1",
+			Kind:    MonitorKindSLO,
+			Handles: []types.DatadogNotificationHandle{{Type: HandleTypeEmail, Target: "ops@example.com"}},
+		},
+		{
+			ID:   "7",
+			Kind: MonitorKindMonitor,
+		},
+	}
+
+	relationships := ExtractMonitorRelationships(monitors)
+
+	assert.ElementsMatch(t, []types.DatadogRelationship{
+		{From: "payments", To: "checkout", Type: RelationshipTypeMonitors, Confidence: 0.9},
+		{From: "pagerduty:checkout-oncall", To: "checkout", Type: RelationshipTypeMonitors, Confidence: 0.85},
+		{From: "email:ops@example.com", To: "datadog-slo:slo-1", Type: RelationshipTypeMonitors, Confidence: 0.5},
+	}, relationships, "Monitors without owners should not produce edges")
+}
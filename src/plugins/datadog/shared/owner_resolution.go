@@ -0,0 +1,231 @@
+// Package shared provides pure functional utilities for Datadog API v2 data transformations.
+package shared
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"bacon/src/plugins/datadog/types"
+)
+
+// Owner sources recorded with each service; OwnerSource is "<source>:<evidence>"
+const (
+	OwnerSourceDeclaredTeam     = "declared_team"
+	OwnerSourceEmail            = "email"
+	OwnerSourceEmailDomain      = "email_domain"
+	OwnerSourceSlackChannel     = "slack_channel"
+	OwnerSourceTeamsChannel     = "microsoft_teams_channel"
+	OwnerSourceContact          = "contact"
+	OwnerSourceUnmatchedContact = "unmatched_email"
+)
+
+// Contact types as declared in service definitions
+const (
+	ContactTypeEmail          = "email"
+	ContactTypeSlack          = "slack"
+	ContactTypeMicrosoftTeams = "microsoft-teams"
+)
+
+// contactTypeRank orders contacts by how reliably they identify the owning team; unknown types rank last
+var contactTypeRank = map[string]int{
+	ContactTypeEmail:          0,
+	ContactTypeSlack:          1,
+	ContactTypeMicrosoftTeams: 2,
+}
+
+// teamKeyAffixes are the decorations stripped from mailbox and channel names before matching team handles
+var (
+	teamKeySuffixes = []string{"-team", "-alerts", "-alert", "-oncall", "-on-call", "-eng", "-engineering", "-dev", "-ops", "-support"}
+	teamKeyPrefixes = []string{"team-", "eng-", "alerts-"}
+)
+
+// ownerCandidate is a team name derived from a contact together with how it was derived
+type ownerCandidate struct {
+	name   string
+	source string
+}
+
+// ResolveServiceOwners fills in the owner of services without a declared team from their contacts
+// Pure function; services with a declared team keep it as their owner
+func ResolveServiceOwners(services []types.DatadogService, teams []types.DatadogTeam) []types.DatadogService {
+	return lo.Map(services, func(service types.DatadogService, _ int) types.DatadogService {
+		return ResolveServiceOwner(service, teams)
+	})
+}
+
+// ResolveServiceOwner sets the service owner and records the evidence in OwnerSource
+// Declared teams win; otherwise contacts are tried by type rank against the known teams
+func ResolveServiceOwner(service types.DatadogService, teams []types.DatadogTeam) types.DatadogService {
+	if declared := lo.FirstOrEmpty(service.Teams); declared != "" {
+		service.Owner = declared
+		service.OwnerSource = formatOwnerSource(OwnerSourceDeclaredTeam, declared)
+		return service
+	}
+
+	service.Owner, service.OwnerSource = extractOwnerFromContacts(service.Contacts, teams)
+	return service
+}
+
+// ServiceOwnedByTeam reports whether a service belongs to a team through its teams, owner or contacts
+// Pure function; contacts are normalized the same way as during owner resolution
+func ServiceOwnedByTeam(service types.DatadogService, team types.DatadogTeam) bool {
+	if lo.Contains(service.Teams, team.ID) {
+		return true
+	}
+	if service.Owner != "" && service.Owner == teamEntityName(team) {
+		return true
+	}
+	return lo.SomeBy(service.Contacts, func(contact types.DatadogContact) bool {
+		return lo.SomeBy(contactOwnerCandidates(contact), func(candidate ownerCandidate) bool {
+			_, matched := matchTeam(candidate.name, []types.DatadogTeam{team})
+			return matched
+		})
+	})
+}
+
+// extractOwnerFromContacts finds the owning team from service contacts
+// Contacts are tried email first, then Slack, then Microsoft Teams; without a team match the
+// highest ranked email address is used as the owner
+func extractOwnerFromContacts(contacts []types.DatadogContact, teams []types.DatadogTeam) (string, string) {
+	ranked := rankContacts(contacts)
+
+	for _, contact := range ranked {
+		for _, candidate := range contactOwnerCandidates(contact) {
+			if team, matched := matchTeam(candidate.name, teams); matched {
+				return teamEntityName(team), formatOwnerSource(candidate.source, contact.Contact)
+			}
+		}
+	}
+
+	if email, found := lo.Find(ranked, func(contact types.DatadogContact) bool {
+		return normalizeContactType(contact.Type) == ContactTypeEmail && contact.Contact != ""
+	}); found {
+		return email.Contact, formatOwnerSource(OwnerSourceUnmatchedContact, email.Contact)
+	}
+
+	return "", ""
+}
+
+// rankContacts returns the contacts ordered by type rank, keeping declaration order within a type
+func rankContacts(contacts []types.DatadogContact) []types.DatadogContact {
+	ranked := lo.Filter(contacts, func(contact types.DatadogContact, _ int) bool {
+		return strings.TrimSpace(contact.Contact) != ""
+	})
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return contactRank(ranked[i]) < contactRank(ranked[j])
+	})
+	return ranked
+}
+
+// contactRank returns the rank of a contact's type
+func contactRank(contact types.DatadogContact) int {
+	return lo.ValueOr(contactTypeRank, normalizeContactType(contact.Type), len(contactTypeRank))
+}
+
+// normalizeContactType maps contact type spellings such as "microsoft_teams" onto the declared types
+func normalizeContactType(contactType string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(contactType)), "_", "-")
+}
+
+// contactOwnerCandidates derives the team names a contact may refer to
+func contactOwnerCandidates(contact types.DatadogContact) []ownerCandidate {
+	value := strings.TrimSpace(contact.Contact)
+
+	switch normalizeContactType(contact.Type) {
+	case ContactTypeEmail:
+		mailbox, domain, _ := strings.Cut(strings.TrimPrefix(value, "mailto:"), "@")
+		candidates := []ownerCandidate{{name: mailbox, source: OwnerSourceEmail}}
+
+		// A team subdomain such as payments.example.com names the team
+		if labels := strings.Split(domain, "."); len(labels) > 2 {
+			candidates = append(candidates, ownerCandidate{name: labels[0], source: OwnerSourceEmailDomain})
+		}
+		return candidates
+	case ContactTypeSlack:
+		return []ownerCandidate{{name: slackChannelName(value), source: OwnerSourceSlackChannel}}
+	case ContactTypeMicrosoftTeams:
+		return []ownerCandidate{{name: teamsChannelName(value), source: OwnerSourceTeamsChannel}}
+	}
+
+	return []ownerCandidate{{name: value, source: OwnerSourceContact}}
+}
+
+// slackChannelName extracts the channel from "#channel" or a Slack channel URL
+func slackChannelName(contact string) string {
+	if parsed, err := url.Parse(contact); err == nil && parsed.Host != "" {
+		return lastPathSegment(parsed.Path)
+	}
+	return strings.TrimPrefix(contact, "#")
+}
+
+// teamsChannelName extracts the channel name from a Microsoft Teams channel link
+// Channel links look like https://teams.microsoft.com/l/channel/<thread id>/<channel name>?groupId=...
+func teamsChannelName(contact string) string {
+	parsed, err := url.Parse(contact)
+	if err != nil || parsed.Host == "" {
+		return contact
+	}
+
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(segments) >= 4 && segments[0] == "l" && segments[1] == "channel" {
+		return segments[3]
+	}
+	return lastPathSegment(parsed.Path)
+}
+
+// lastPathSegment returns the final non-empty segment of a URL path
+func lastPathSegment(path string) string {
+	return lo.LastOrEmpty(lo.Compact(strings.Split(path, "/")))
+}
+
+// matchTeam finds the team a contact-derived name refers to, preferring handles over display names
+func matchTeam(name string, teams []types.DatadogTeam) (types.DatadogTeam, bool) {
+	keys := teamKeyVariants(name)
+	if len(keys) == 0 {
+		return types.DatadogTeam{}, false
+	}
+
+	if team, found := lo.Find(teams, func(team types.DatadogTeam) bool {
+		return lo.Contains(keys, normalizeTeamKey(team.Handle))
+	}); found {
+		return team, true
+	}
+
+	return lo.Find(teams, func(team types.DatadogTeam) bool {
+		return lo.Contains(keys, normalizeTeamKey(team.Name))
+	})
+}
+
+// teamKeyVariants returns the normalized name and the name with common decorations stripped
+func teamKeyVariants(name string) []string {
+	key := normalizeTeamKey(name)
+	if key == "" {
+		return nil
+	}
+
+	stripped := key
+	for _, prefix := range teamKeyPrefixes {
+		stripped = strings.TrimPrefix(stripped, prefix)
+	}
+	for _, suffix := range teamKeySuffixes {
+		stripped = strings.TrimSuffix(stripped, suffix)
+	}
+
+	return lo.Compact(lo.Uniq([]string{key, stripped}))
+}
+
+// normalizeTeamKey lowercases a name and joins its words with dashes
+func normalizeTeamKey(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r == ' ' || r == '_' || r == '-' || r == '.' || r == '#'
+	}), "-")
+}
+
+// formatOwnerSource combines the resolution method with its evidence
+func formatOwnerSource(source, evidence string) string {
+	return fmt.Sprintf("%s:%s", source, evidence)
+}
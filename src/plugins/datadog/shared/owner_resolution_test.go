@@ -0,0 +1,108 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"bacon/src/plugins/datadog/types"
+)
+
+var knownTeams = []types.DatadogTeam{
+	{ID: "team-1", Handle: "payments", Name: "Payments"},
+	{ID: "team-2", Handle: "search-platform", Name: "Search Platform"},
+	{ID: "team-3", Handle: "sre", Name: "Site Reliability"},
+}
+
+func TestResolveServiceOwner(t *testing.T) {
+	cases := []struct {
+		name        string
+		service     types.DatadogService
+		owner       string
+		ownerSource string
+	}{
+		{
+			name: "declared team wins over contacts",
+			service: types.DatadogService{Teams: []string{"sre"}, Contacts: []types.DatadogContact{
+				{Type: ContactTypeEmail, Contact: "payments@example.com"},
+			}},
+			owner:       "sre",
+			ownerSource: "declared_team:sre",
+		},
+		{
+			name: "email outranks slack",
+			service: types.DatadogService{Contacts: []types.DatadogContact{
+				{Type: ContactTypeSlack, Contact: "#search-platform"},
+				{Type: ContactTypeEmail, Contact: "payments-team@example.com"},
+			}},
+			owner:       "payments",
+			ownerSource: "email:payments-team@example.com",
+		},
+		{
+			name: "unmatched email falls through to slack channel",
+			service: types.DatadogService{Contacts: []types.DatadogContact{
+				{Type: ContactTypeEmail, Contact: "jane@example.com"},
+				{Type: ContactTypeSlack, Contact: "https://example.slack.com/archives/search-platform-alerts"},
+			}},
+			owner:       "search-platform",
+			ownerSource: "slack_channel:https://example.slack.com/archives/search-platform-alerts",
+		},
+		{
+			name: "email subdomain names the team",
+			service: types.DatadogService{Contacts: []types.DatadogContact{
+				{Type: ContactTypeEmail, Contact: "oncall@sre.example.com"},
+			}},
+			owner:       "sre",
+			ownerSource: "email_domain:oncall@sre.example.com",
+		},
+		{
+			name: "microsoft teams channel matches display name",
+			service: types.DatadogService{Contacts: []types.DatadogContact{
+				{Type: "microsoft_teams", Contact: "https://teams.microsoft.com/l/channel/19%3Aabc%40thread.tacv2/Site%20Reliability?groupId=1"},
+			}},
+			owner:       "sre",
+			ownerSource: "microsoft_teams_channel:https://teams.microsoft.com/l/channel/19%3Aabc%40thread.tacv2/Site%20Reliability?groupId=1",
+		},
+		{
+			name: "no team match keeps the best email",
+			service: types.DatadogService{Contacts: []types.DatadogContact{
+				{Type: ContactTypeSlack, Contact: "#random"},
+				{Type: ContactTypeEmail, Contact: "jane@example.com"},
+			}},
+			owner:       "jane@example.com",
+			ownerSource: "unmatched_email:jane@example.com",
+		},
+		{
+			name:    "no contacts leaves the owner empty",
+			service: types.DatadogService{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resolved := ResolveServiceOwner(tc.service, knownTeams)
+
+			assert.Equal(t, tc.owner, resolved.Owner)
+			assert.Equal(t, tc.ownerSource, resolved.OwnerSource)
+		})
+	}
+}
+
+func TestServiceOwnedByTeam(t *testing.T) {
+	payments := knownTeams[0]
+
+	assert.True(t, ServiceOwnedByTeam(types.DatadogService{Teams: []string{"team-1"}}, payments))
+	assert.True(t, ServiceOwnedByTeam(types.DatadogService{Owner: "payments"}, payments))
+	assert.True(t, ServiceOwnedByTeam(types.DatadogService{Contacts: []types.DatadogContact{
+		{Type: ContactTypeSlack, Contact: "#payments-oncall"},
+	}}, payments))
+	assert.False(t, ServiceOwnedByTeam(types.DatadogService{Contacts: []types.DatadogContact{
+		{Type: ContactTypeSlack, Contact: "#payments-infra"},
+	}}, payments))
+}
+
+func TestTeamKeyVariants(t *testing.T) {
+	assert.Equal(t, []string{"team-payments-alerts", "payments"}, teamKeyVariants("#Team_Payments-Alerts"))
+	assert.Equal(t, []string{"search-platform"}, teamKeyVariants("Search Platform"))
+	assert.Empty(t, teamKeyVariants(" # "))
+}
@@ -0,0 +1,98 @@
+// Package shared provides pure functional utilities for Datadog API v2 data transformations.
+package shared
+
+import (
+	"sort"
+	"time"
+
+	"github.com/samber/lo"
+
+	"bacon/src/plugins/datadog/types"
+	"bacon/src/shared/scraperoutput"
+)
+
+// Relationship types understood by the relationship-finding processor
+const (
+	RelationshipTypeDependsOn = "depends_on"
+	RelationshipTypeOwns      = "owns"
+	RelationshipTypeMonitors  = "monitors"
+	RelationshipTypeRunsOn    = "runs_on"
+	RelationshipTypeMemberOf  = "member_of"
+)
+
+// Scraper output sources for Datadog-derived relationships
+const (
+	SourceDatadogServiceCatalog = "datadog-service-catalog"
+	SourceDatadogAPM            = "datadog-apm"
+	SourceDatadogMonitors       = "datadog-monitors"
+	SourceDatadogMetrics        = "datadog-metrics"
+	SourceDatadogDeletions      = "datadog-deletions" // entities tombstoned after a complete scrape
+	SourceDatadogChanges        = "datadog-changes"   // deltas between consecutive snapshots
+)
+
+// Base confidence of each source; declared catalog data is trusted above observed traffic
+const (
+	ServiceCatalogConfidence = 0.9
+	APMServiceMapConfidence  = 0.8
+	HostTagConfidence        = 0.7
+	ChangeEventConfidence    = 0.9
+)
+
+// ExtractServiceRelationships derives depends_on and owns edges from service definitions
+// Pure function that skips unnamed services and self-dependencies
+func ExtractServiceRelationships(services []types.DatadogService) []types.DatadogRelationship {
+	namedServices := lo.Filter(services, func(service types.DatadogService, _ int) bool {
+		return service.Name != ""
+	})
+
+	relationships := lo.FlatMap(namedServices, func(service types.DatadogService, _ int) []types.DatadogRelationship {
+		dependsOn := lo.FilterMap(service.Dependencies, func(dependency string, _ int) (types.DatadogRelationship, bool) {
+			return types.DatadogRelationship{
+				From: service.Name,
+				To:   dependency,
+				Type: RelationshipTypeDependsOn,
+			}, dependency != "" && dependency != service.Name
+		})
+
+		owns := lo.FilterMap(service.Teams, func(team string, _ int) (types.DatadogRelationship, bool) {
+			return types.DatadogRelationship{
+				From: team,
+				To:   service.Name,
+				Type: RelationshipTypeOwns,
+			}, team != ""
+		})
+
+		return append(dependsOn, owns...)
+	})
+
+	return lo.Uniq(relationships)
+}
+
+// TransformServiceDependencies converts the APM service map into depends_on edges
+// Pure function with deterministic ordering by calling service
+func TransformServiceDependencies(dependencies map[string]types.DatadogServiceDependency) []types.DatadogRelationship {
+	callers := lo.Keys(dependencies)
+	sort.Strings(callers)
+
+	relationships := lo.FlatMap(callers, func(caller string, _ int) []types.DatadogRelationship {
+		return lo.FilterMap(dependencies[caller].Calls, func(callee string, _ int) (types.DatadogRelationship, bool) {
+			return types.DatadogRelationship{
+				From: caller,
+				To:   callee,
+				Type: RelationshipTypeDependsOn,
+			}, caller != "" && callee != "" && callee != caller
+		})
+	})
+
+	return lo.Uniq(relationships)
+}
+
+// CreateRelationshipOutput wraps relationships in the ScraperOutput format consumed by the processor
+// Pure function; a zero relationship confidence defers to the output confidence
+func CreateRelationshipOutput(source string, relationships []types.DatadogRelationship, confidence float64, timestamp time.Time) types.ScraperOutput {
+	edges := lo.Map(relationships, func(rel types.DatadogRelationship, _ int) scraperoutput.Edge {
+		return scraperoutput.Edge{From: rel.From, To: rel.To, Type: rel.Type, Confidence: rel.Confidence}
+	})
+
+	return scraperoutput.New(source, confidence, timestamp, scraperoutput.Relationships(edges))
+}
@@ -0,0 +1,62 @@
+package shared
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"bacon/src/plugins/datadog/types"
+	"bacon/src/shared/scraperoutput"
+)
+
+func TestExtractServiceRelationships(t *testing.T) {
+	services := []types.DatadogService{
+		{Name: "checkout", Teams: []string{"payments"}, Dependencies: []string{"billing", "inventory", "checkout", "billing"}},
+		{Name: "billing", Teams: []string{"payments", "finance"}},
+		{Name: "", Teams: []string{"orphans"}, Dependencies: []string{"billing"}},
+	}
+
+	relationships := ExtractServiceRelationships(services)
+
+	assert.ElementsMatch(t, []types.DatadogRelationship{
+		{From: "checkout", To: "billing", Type: RelationshipTypeDependsOn},
+		{From: "checkout", To: "inventory", Type: RelationshipTypeDependsOn},
+		{From: "payments", To: "checkout", Type: RelationshipTypeOwns},
+		{From: "payments", To: "billing", Type: RelationshipTypeOwns},
+		{From: "finance", To: "billing", Type: RelationshipTypeOwns},
+	}, relationships, "Self-dependencies, duplicates and unnamed services should be dropped")
+}
+
+func TestTransformServiceDependencies(t *testing.T) {
+	dependencies := map[string]types.DatadogServiceDependency{
+		"web":      {Calls: []string{"checkout", "web"}},
+		"checkout": {Calls: []string{"billing"}},
+		"billing":  {Calls: nil},
+	}
+
+	relationships := TransformServiceDependencies(dependencies)
+
+	assert.Equal(t, []types.DatadogRelationship{
+		{From: "checkout", To: "billing", Type: RelationshipTypeDependsOn},
+		{From: "web", To: "checkout", Type: RelationshipTypeDependsOn},
+	}, relationships)
+	assert.Empty(t, TransformServiceDependencies(nil))
+}
+
+func TestCreateRelationshipOutput(t *testing.T) {
+	timestamp := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	relationships := []types.DatadogRelationship{
+		{From: "checkout", To: "billing", Type: RelationshipTypeDependsOn},
+	}
+
+	output := CreateRelationshipOutput(SourceDatadogServiceCatalog, relationships, ServiceCatalogConfidence, timestamp)
+
+	assert.Equal(t, SourceDatadogServiceCatalog, output.Source)
+	assert.Equal(t, ServiceCatalogConfidence, output.Confidence)
+	assert.Equal(t, "2024-03-01T12:00:00Z", output.ProducedAt)
+	assert.Equal(t, scraperoutput.SchemaVersion, output.SchemaVersion)
+	assert.Equal(t, scraperoutput.Relationships([]scraperoutput.Edge{
+		{From: "checkout", To: "billing", Type: "depends_on"},
+	}), output.Payload)
+}
@@ -0,0 +1,423 @@
+// Package shared provides pure functional utilities for Datadog API v2 data transformations.
+package shared
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV2"
+	"github.com/samber/lo"
+
+	"bacon/src/plugins/datadog/types"
+)
+
+// Service definition schema versions understood by TransformServiceDefinition
+const (
+	SchemaVersionV1     = "v1"
+	SchemaVersionV2     = "v2"
+	SchemaVersionV2Dot1 = "v2.1"
+	SchemaVersionV2Dot2 = "v2.2"
+	SchemaVersionV3     = "v3"
+)
+
+// NarrowsBySchema reports whether requesting a schema version narrows the services scraped
+// Pure function; versions the API can be asked for render every service, others only keep those defined in them
+func NarrowsBySchema(schemaVersion string) bool {
+	_, err := datadogV2.NewServiceDefinitionSchemaVersionsFromValue(schemaVersion)
+	return schemaVersion != "" && err != nil
+}
+
+// Entity kinds; schemas before v3 only describe services
+const (
+	EntityKindService = "service"
+	EntityKindSystem  = "system"
+)
+
+// Link types used to classify normalized service links
+const (
+	serviceLinkTypeRepo = "repo"
+	serviceLinkTypeDoc  = "doc"
+)
+
+// Integration keys used in DatadogService.Integrations
+const (
+	IntegrationPagerDuty = "pagerduty"
+	IntegrationOpsgenie  = "opsgenie"
+)
+
+// Pure parsing functions for each service definition schema version
+
+// parseServiceDefinitionV1 normalizes a v1 service definition
+func parseServiceDefinitionV1(def datadogV2.ServiceDefinitionV1) types.DatadogService {
+	team := ""
+	application := ""
+	if def.Org != nil {
+		team = safeStringFromPtr(def.Org.Team)
+		application = safeStringFromPtr(def.Org.Application)
+	}
+
+	var contacts []types.DatadogContact
+	if def.Contact != nil {
+		if email := safeStringFromPtr(def.Contact.Email); email != "" {
+			contacts = append(contacts, types.DatadogContact{Type: "email", Contact: email})
+		}
+		if slack := safeStringFromPtr(def.Contact.Slack); slack != "" {
+			contacts = append(contacts, types.DatadogContact{Type: "slack", Contact: slack})
+		}
+	}
+
+	links := lo.Map(def.ExternalResources, func(resource datadogV2.ServiceDefinitionV1Resource, _ int) types.DatadogServiceLink {
+		return types.DatadogServiceLink{Name: resource.Name, Type: string(resource.Type), URL: resource.Url}
+	})
+
+	integrations := make(map[string]interface{})
+	if def.Integrations != nil {
+		addIntegration(integrations, IntegrationPagerDuty, safeStringFromPtr(def.Integrations.Pagerduty), "")
+	}
+
+	return types.DatadogService{
+		Name:          def.Info.DdService,
+		SchemaVersion: SchemaVersionV1,
+		Kind:          EntityKindService,
+		Application:   application,
+		Teams:         compactTeams(team),
+		Tags:          def.Tags,
+		Description:   safeStringFromPtr(def.Info.Description),
+		Tier:          safeStringFromPtr(def.Info.ServiceTier),
+		Contacts:      contacts,
+		Links:         links,
+		Integrations:  integrations,
+	}
+}
+
+// parseServiceDefinitionV2 normalizes a v2 service definition
+func parseServiceDefinitionV2(def datadogV2.ServiceDefinitionV2) types.DatadogService {
+	contacts := lo.FilterMap(def.Contacts, func(contact datadogV2.ServiceDefinitionV2Contact, _ int) (types.DatadogContact, bool) {
+		switch {
+		case contact.ServiceDefinitionV2Email != nil:
+			c := contact.ServiceDefinitionV2Email
+			return types.DatadogContact{Name: safeStringFromPtr(c.Name), Type: string(c.Type), Contact: c.Contact}, true
+		case contact.ServiceDefinitionV2Slack != nil:
+			c := contact.ServiceDefinitionV2Slack
+			return types.DatadogContact{Name: safeStringFromPtr(c.Name), Type: string(c.Type), Contact: c.Contact}, true
+		case contact.ServiceDefinitionV2MSTeams != nil:
+			c := contact.ServiceDefinitionV2MSTeams
+			return types.DatadogContact{Name: safeStringFromPtr(c.Name), Type: string(c.Type), Contact: c.Contact}, true
+		}
+		return types.DatadogContact{}, false
+	})
+
+	links := lo.Map(def.Links, func(link datadogV2.ServiceDefinitionV2Link, _ int) types.DatadogServiceLink {
+		return types.DatadogServiceLink{Name: link.Name, Type: string(link.Type), URL: link.Url}
+	})
+	docs := lo.Map(def.Docs, func(doc datadogV2.ServiceDefinitionV2Doc, _ int) types.DatadogServiceLink {
+		return types.DatadogServiceLink{Name: doc.Name, Type: serviceLinkTypeDoc, URL: doc.Url}
+	})
+	repos := lo.Map(def.Repos, func(repo datadogV2.ServiceDefinitionV2Repo, _ int) types.DatadogServiceLink {
+		return types.DatadogServiceLink{Name: repo.Name, Type: serviceLinkTypeRepo, URL: repo.Url}
+	})
+
+	integrations := make(map[string]interface{})
+	if def.Integrations != nil {
+		addIntegration(integrations, IntegrationPagerDuty, safeStringFromPtr(def.Integrations.Pagerduty), "")
+		if def.Integrations.Opsgenie != nil {
+			addIntegration(integrations, IntegrationOpsgenie, def.Integrations.Opsgenie.ServiceUrl, string(lo.FromPtr(def.Integrations.Opsgenie.Region)))
+		}
+	}
+
+	return types.DatadogService{
+		Name:          def.DdService,
+		SchemaVersion: SchemaVersionV2,
+		Kind:          EntityKindService,
+		Teams:         compactTeams(safeStringFromPtr(def.Team), safeStringFromPtr(def.DdTeam)),
+		Tags:          def.Tags,
+		Contacts:      contacts,
+		Links:         append(links, docs...),
+		Repos:         repos,
+		Integrations:  integrations,
+	}
+}
+
+// parseServiceDefinitionV2Dot1 normalizes a v2.1 service definition
+func parseServiceDefinitionV2Dot1(def datadogV2.ServiceDefinitionV2Dot1) types.DatadogService {
+	contacts := lo.FilterMap(def.Contacts, func(contact datadogV2.ServiceDefinitionV2Dot1Contact, _ int) (types.DatadogContact, bool) {
+		switch {
+		case contact.ServiceDefinitionV2Dot1Email != nil:
+			c := contact.ServiceDefinitionV2Dot1Email
+			return types.DatadogContact{Name: safeStringFromPtr(c.Name), Type: string(c.Type), Contact: c.Contact}, true
+		case contact.ServiceDefinitionV2Dot1Slack != nil:
+			c := contact.ServiceDefinitionV2Dot1Slack
+			return types.DatadogContact{Name: safeStringFromPtr(c.Name), Type: string(c.Type), Contact: c.Contact}, true
+		case contact.ServiceDefinitionV2Dot1MSTeams != nil:
+			c := contact.ServiceDefinitionV2Dot1MSTeams
+			return types.DatadogContact{Name: safeStringFromPtr(c.Name), Type: string(c.Type), Contact: c.Contact}, true
+		}
+		return types.DatadogContact{}, false
+	})
+
+	allLinks := lo.Map(def.Links, func(link datadogV2.ServiceDefinitionV2Dot1Link, _ int) types.DatadogServiceLink {
+		return types.DatadogServiceLink{Name: link.Name, Type: string(link.Type), URL: link.Url}
+	})
+	links, repos := partitionRepoLinks(allLinks)
+
+	integrations := make(map[string]interface{})
+	if def.Integrations != nil {
+		if def.Integrations.Pagerduty != nil {
+			addIntegration(integrations, IntegrationPagerDuty, safeStringFromPtr(def.Integrations.Pagerduty.ServiceUrl), "")
+		}
+		if def.Integrations.Opsgenie != nil {
+			addIntegration(integrations, IntegrationOpsgenie, def.Integrations.Opsgenie.ServiceUrl, string(lo.FromPtr(def.Integrations.Opsgenie.Region)))
+		}
+	}
+
+	return types.DatadogService{
+		Name:          def.DdService,
+		SchemaVersion: SchemaVersionV2Dot1,
+		Kind:          EntityKindService,
+		Application:   safeStringFromPtr(def.Application),
+		Teams:         compactTeams(safeStringFromPtr(def.Team)),
+		Tags:          def.Tags,
+		Description:   safeStringFromPtr(def.Description),
+		Tier:          safeStringFromPtr(def.Tier),
+		Lifecycle:     safeStringFromPtr(def.Lifecycle),
+		Contacts:      contacts,
+		Links:         links,
+		Repos:         repos,
+		Integrations:  integrations,
+	}
+}
+
+// parseServiceDefinitionV2Dot2 normalizes a v2.2 service definition
+func parseServiceDefinitionV2Dot2(def datadogV2.ServiceDefinitionV2Dot2) types.DatadogService {
+	links, repos := partitionRepoLinks(lo.Map(def.Links, TransformServiceLink))
+
+	integrations := make(map[string]interface{})
+	if def.Integrations != nil {
+		if def.Integrations.Pagerduty != nil {
+			addIntegration(integrations, IntegrationPagerDuty, safeStringFromPtr(def.Integrations.Pagerduty.ServiceUrl), "")
+		}
+		if def.Integrations.Opsgenie != nil {
+			addIntegration(integrations, IntegrationOpsgenie, def.Integrations.Opsgenie.ServiceUrl, string(lo.FromPtr(def.Integrations.Opsgenie.Region)))
+		}
+	}
+
+	return types.DatadogService{
+		Name:          def.DdService,
+		SchemaVersion: SchemaVersionV2Dot2,
+		Kind:          EntityKindService,
+		Application:   safeStringFromPtr(def.Application),
+		Teams:         compactTeams(safeStringFromPtr(def.Team)),
+		Tags:          def.Tags,
+		Description:   safeStringFromPtr(def.Description),
+		Tier:          safeStringFromPtr(def.Tier),
+		Lifecycle:     safeStringFromPtr(def.Lifecycle),
+		Type:          safeStringFromPtr(def.Type),
+		Languages:     def.Languages,
+		Contacts:      lo.Map(def.Contacts, TransformContact),
+		Links:         links,
+		Repos:         repos,
+		Integrations:  integrations,
+	}
+}
+
+// entityV3Spec holds the spec fields shared by the v3 entity kinds
+type entityV3Spec struct {
+	componentOf []string
+	dependsOn   []string
+	languages   []string
+	lifecycle   *string
+	tier        *string
+	serviceType *string
+}
+
+// parseEntityV3 normalizes a v3 entity definition of any kind
+func parseEntityV3(entity datadogV2.EntityV3) (types.DatadogService, bool) {
+	var (
+		kind         string
+		metadata     datadogV2.EntityV3Metadata
+		spec         entityV3Spec
+		integrations *datadogV2.EntityV3Integrations
+		codeLinks    []types.DatadogServiceLink
+	)
+
+	switch {
+	case entity.EntityV3Service != nil:
+		e := entity.EntityV3Service
+		kind, metadata, integrations = string(e.Kind), e.Metadata, e.Integrations
+		if e.Spec != nil {
+			spec = entityV3Spec{e.Spec.ComponentOf, e.Spec.DependsOn, e.Spec.Languages, e.Spec.Lifecycle, e.Spec.Tier, e.Spec.Type}
+		}
+		if e.Datadog != nil {
+			codeLinks = lo.FilterMap(e.Datadog.CodeLocations, func(location datadogV2.EntityV3DatadogCodeLocationItem, _ int) (types.DatadogServiceLink, bool) {
+				url := safeStringFromPtr(location.RepositoryUrl)
+				return types.DatadogServiceLink{Name: url, Type: serviceLinkTypeRepo, URL: url}, url != ""
+			})
+		}
+	case entity.EntityV3Datastore != nil:
+		e := entity.EntityV3Datastore
+		kind, metadata, integrations = string(e.Kind), e.Metadata, e.Integrations
+		if e.Spec != nil {
+			spec = entityV3Spec{componentOf: e.Spec.ComponentOf, lifecycle: e.Spec.Lifecycle, tier: e.Spec.Tier, serviceType: e.Spec.Type}
+		}
+	case entity.EntityV3Queue != nil:
+		e := entity.EntityV3Queue
+		kind, metadata, integrations = string(e.Kind), e.Metadata, e.Integrations
+		if e.Spec != nil {
+			spec = entityV3Spec{componentOf: e.Spec.ComponentOf, lifecycle: e.Spec.Lifecycle, tier: e.Spec.Tier, serviceType: e.Spec.Type}
+		}
+	case entity.EntityV3System != nil:
+		e := entity.EntityV3System
+		kind, metadata, integrations = string(e.Kind), e.Metadata, e.Integrations
+		if e.Spec != nil {
+			spec = entityV3Spec{lifecycle: e.Spec.Lifecycle, tier: e.Spec.Tier}
+		}
+	case entity.EntityV3API != nil:
+		e := entity.EntityV3API
+		kind, metadata, integrations = string(e.Kind), e.Metadata, e.Integrations
+		if e.Spec != nil {
+			spec = entityV3Spec{lifecycle: e.Spec.Lifecycle, tier: e.Spec.Tier, serviceType: e.Spec.Type}
+		}
+	default:
+		return types.DatadogService{}, false
+	}
+
+	additionalOwners := lo.Map(metadata.AdditionalOwners, func(owner datadogV2.EntityV3MetadataAdditionalOwnersItems, _ int) string {
+		return owner.Name
+	})
+
+	contacts := lo.Map(metadata.Contacts, func(contact datadogV2.EntityV3MetadataContactsItems, _ int) types.DatadogContact {
+		return types.DatadogContact{Name: safeStringFromPtr(contact.Name), Type: contact.Type, Contact: contact.Contact}
+	})
+
+	links, repos := partitionRepoLinks(lo.Map(metadata.Links, func(link datadogV2.EntityV3MetadataLinksItems, _ int) types.DatadogServiceLink {
+		return types.DatadogServiceLink{Name: link.Name, Type: link.Type, URL: link.Url}
+	}))
+
+	normalizedIntegrations := make(map[string]interface{})
+	if integrations != nil {
+		if integrations.Pagerduty != nil {
+			addIntegration(normalizedIntegrations, IntegrationPagerDuty, integrations.Pagerduty.ServiceUrl, "")
+		}
+		if integrations.Opsgenie != nil {
+			addIntegration(normalizedIntegrations, IntegrationOpsgenie, integrations.Opsgenie.ServiceUrl, safeStringFromPtr(integrations.Opsgenie.Region))
+		}
+	}
+
+	return types.DatadogService{
+		Name:          metadata.Name,
+		SchemaVersion: SchemaVersionV3,
+		Kind:          kind,
+		Teams:         compactTeams(append([]string{safeStringFromPtr(metadata.Owner)}, additionalOwners...)...),
+		Tags:          metadata.Tags,
+		Description:   safeStringFromPtr(metadata.Description),
+		Tier:          safeStringFromPtr(spec.tier),
+		Lifecycle:     safeStringFromPtr(spec.lifecycle),
+		Type:          safeStringFromPtr(spec.serviceType),
+		Languages:     spec.languages,
+		Contacts:      contacts,
+		Links:         links,
+		Repos:         lo.UniqBy(append(repos, codeLinks...), func(link types.DatadogServiceLink) string { return link.URL }),
+		Integrations:  normalizedIntegrations,
+		Dependencies:  lo.Map(spec.dependsOn, normalizeEntityReference),
+		ComponentOf:   lo.Map(spec.componentOf, normalizeEntityReference),
+	}, true
+}
+
+// decodeEntityV3 decodes a v3 entity from the raw object the v2 schema union could not match
+func decodeEntityV3(unparsed interface{}) (datadogV2.EntityV3, bool) {
+	raw, ok := unparsed.(map[string]interface{})
+	if !ok || raw["apiVersion"] != SchemaVersionV3 {
+		return datadogV2.EntityV3{}, false
+	}
+
+	payload, err := json.Marshal(raw)
+	if err != nil {
+		return datadogV2.EntityV3{}, false
+	}
+
+	var entity datadogV2.EntityV3
+	if err := json.Unmarshal(payload, &entity); err != nil || entity.UnparsedObject != nil {
+		return datadogV2.EntityV3{}, false
+	}
+
+	return entity, true
+}
+
+// parseServiceDefinitionSchema dispatches on the populated member of the schema union
+func parseServiceDefinitionSchema(schema *datadogV2.ServiceDefinitionSchema) (types.DatadogService, bool) {
+	if schema == nil {
+		return types.DatadogService{}, false
+	}
+
+	switch {
+	case schema.ServiceDefinitionV2Dot2 != nil:
+		return parseServiceDefinitionV2Dot2(*schema.ServiceDefinitionV2Dot2), true
+	case schema.ServiceDefinitionV2Dot1 != nil:
+		return parseServiceDefinitionV2Dot1(*schema.ServiceDefinitionV2Dot1), true
+	case schema.ServiceDefinitionV2 != nil:
+		return parseServiceDefinitionV2(*schema.ServiceDefinitionV2), true
+	case schema.ServiceDefinitionV1 != nil:
+		return parseServiceDefinitionV1(*schema.ServiceDefinitionV1), true
+	}
+
+	if entity, ok := decodeEntityV3(schema.UnparsedObject); ok {
+		return parseEntityV3(entity)
+	}
+
+	return types.DatadogService{}, false
+}
+
+// Pure helper functions for service definition normalization
+
+// compactTeams removes empty and duplicate team names
+func compactTeams(teams ...string) []string {
+	return lo.Uniq(lo.Compact(teams))
+}
+
+// extractTeamTags extracts team names from "team:" tags
+func extractTeamTags(tags []string) []string {
+	return lo.Map(lo.Filter(tags, func(tag string, _ int) bool {
+		return strings.HasPrefix(tag, "team:")
+	}), extractTeamFromTag)
+}
+
+// partitionRepoLinks separates repository links from other links
+func partitionRepoLinks(links []types.DatadogServiceLink) ([]types.DatadogServiceLink, []types.DatadogServiceLink) {
+	repos, others := lo.FilterReject(links, func(link types.DatadogServiceLink, _ int) bool {
+		return link.Type == serviceLinkTypeRepo
+	})
+	return others, repos
+}
+
+// addIntegration records an on-call integration reference when a service URL is present
+func addIntegration(integrations map[string]interface{}, name, serviceURL, region string) {
+	if serviceURL == "" {
+		return
+	}
+
+	integration := map[string]interface{}{"service_url": serviceURL}
+	if region != "" {
+		integration["region"] = region
+	}
+	integrations[name] = integration
+}
+
+// normalizeEntityReference strips the default "service:" kind prefix from a v3 entity reference
+func normalizeEntityReference(reference string, _ int) string {
+	return strings.TrimPrefix(reference, EntityKindService+":")
+}
+
+// parseServiceModifiedTime reads the last modified time from service definition metadata
+func parseServiceModifiedTime(service datadogV2.ServiceDefinitionData) time.Time {
+	if service.Attributes == nil || service.Attributes.Meta == nil {
+		return time.Now()
+	}
+
+	modified, err := time.Parse(time.RFC3339, safeStringFromPtr(service.Attributes.Meta.LastModifiedTime))
+	if err != nil {
+		return time.Now()
+	}
+
+	return modified
+}
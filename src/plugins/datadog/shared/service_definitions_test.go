@@ -0,0 +1,169 @@
+package shared
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeServiceDefinition decodes a service definition fixture the way the API client does
+func decodeServiceDefinition(t *testing.T, schema string) datadogV2.ServiceDefinitionData {
+	payload := `{
+		"id": "svc-id",
+		"type": "service-definition",
+		"attributes": {
+			"meta": {"last-modified-time": "2024-03-01T12:00:00Z"},
+			"schema": ` + schema + `
+		}
+	}`
+
+	var service datadogV2.ServiceDefinitionData
+	require.NoError(t, json.Unmarshal([]byte(payload), &service))
+	return service
+}
+
+func TestTransformServiceDefinition_SchemaVersions(t *testing.T) {
+	t.Run("v1", func(t *testing.T) {
+		service := TransformServiceDefinition(decodeServiceDefinition(t, `{
+			"schema-version": "v1",
+			"info": {"dd-service": "billing", "description": "Billing API", "service-tier": "1"},
+			"org": {"team": "payments", "application": "checkout"},
+			"contact": {"email": "payments@example.com", "slack": "https://slack.com/payments"},
+			"integrations": {"pagerduty": "https://acme.pagerduty.com/service-directory/P1"},
+			"external-resources": [{"name": "Runbook", "type": "runbook", "url": "https://wiki/runbook"}]
+		}`), 0)
+
+		assert.Equal(t, SchemaVersionV1, service.SchemaVersion)
+		assert.Equal(t, "billing", service.Name)
+		assert.Equal(t, []string{"payments"}, service.Teams)
+		assert.Equal(t, "payments", service.Owner)
+		assert.Equal(t, "declared_team:payments", service.OwnerSource)
+		assert.Equal(t, "checkout", service.Application)
+		assert.Equal(t, "1", service.Tier)
+		assert.Len(t, service.Contacts, 2)
+		assert.Len(t, service.Links, 1)
+		assert.Contains(t, service.Integrations, IntegrationPagerDuty)
+	})
+
+	t.Run("v2", func(t *testing.T) {
+		service := TransformServiceDefinition(decodeServiceDefinition(t, `{
+			"schema-version": "v2",
+			"dd-service": "billing",
+			"team": "payments",
+			"tags": ["env:prod", "team:finance"],
+			"contacts": [{"type": "email", "contact": "payments@example.com"}, {"type": "slack", "contact": "https://slack.com/payments"}],
+			"links": [{"name": "Dashboard", "type": "dashboard", "url": "https://dash"}],
+			"docs": [{"name": "Guide", "url": "https://docs"}],
+			"repos": [{"name": "billing", "provider": "github", "url": "https://github.com/acme/billing"}],
+			"integrations": {"pagerduty": "https://acme.pagerduty.com/service-directory/P1", "opsgenie": {"service-url": "https://acme.app.opsgenie.com/service/1", "region": "EU"}}
+		}`), 0)
+
+		assert.Equal(t, SchemaVersionV2, service.SchemaVersion)
+		assert.Equal(t, EntityKindService, service.Kind)
+		assert.Equal(t, []string{"payments", "finance"}, service.Teams)
+		assert.Len(t, service.Contacts, 2)
+		assert.Len(t, service.Links, 2)
+		require.Len(t, service.Repos, 1)
+		assert.Equal(t, "https://github.com/acme/billing", service.Repos[0].URL)
+		assert.Equal(t, map[string]interface{}{"service_url": "https://acme.app.opsgenie.com/service/1", "region": "EU"}, service.Integrations[IntegrationOpsgenie])
+	})
+
+	t.Run("v2.1", func(t *testing.T) {
+		service := TransformServiceDefinition(decodeServiceDefinition(t, `{
+			"schema-version": "v2.1",
+			"dd-service": "billing",
+			"team": "payments",
+			"tier": "high",
+			"lifecycle": "production",
+			"contacts": [{"type": "microsoft-teams", "contact": "https://teams/payments"}],
+			"links": [{"name": "Source", "type": "repo", "url": "https://github.com/acme/billing"}, {"name": "Runbook", "type": "runbook", "url": "https://wiki"}],
+			"integrations": {"pagerduty": {"service-url": "https://acme.pagerduty.com/service-directory/P1"}}
+		}`), 0)
+
+		assert.Equal(t, SchemaVersionV2Dot1, service.SchemaVersion)
+		assert.Equal(t, "high", service.Tier)
+		assert.Equal(t, "production", service.Lifecycle)
+		assert.Equal(t, "microsoft-teams", service.Contacts[0].Type)
+		assert.Len(t, service.Links, 1)
+		assert.Len(t, service.Repos, 1)
+		assert.Contains(t, service.Integrations, IntegrationPagerDuty)
+	})
+
+	t.Run("v2.2", func(t *testing.T) {
+		service := TransformServiceDefinition(decodeServiceDefinition(t, `{
+			"schema-version": "v2.2",
+			"dd-service": "billing",
+			"team": "payments",
+			"type": "web",
+			"languages": ["go"],
+			"tier": "1",
+			"lifecycle": "production",
+			"contacts": [{"name": "Oncall", "type": "email", "contact": "oncall@example.com"}]
+		}`), 0)
+
+		assert.Equal(t, SchemaVersionV2Dot2, service.SchemaVersion)
+		assert.Equal(t, "web", service.Type)
+		assert.Equal(t, []string{"go"}, service.Languages)
+		assert.Equal(t, "Oncall", service.Contacts[0].Name)
+		assert.Equal(t, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), service.UpdatedAt)
+	})
+
+	t.Run("v3", func(t *testing.T) {
+		service := TransformServiceDefinition(decodeServiceDefinition(t, `{
+			"apiVersion": "v3",
+			"kind": "service",
+			"metadata": {
+				"name": "billing",
+				"owner": "payments",
+				"additionalOwners": [{"name": "sre", "type": "operator"}],
+				"contacts": [{"type": "slack", "contact": "https://slack.com/payments"}],
+				"links": [{"name": "Source", "type": "repo", "url": "https://github.com/acme/billing"}]
+			},
+			"spec": {
+				"componentOf": ["system:checkout"],
+				"dependsOn": ["service:ledger", "datastore:billing-db"],
+				"tier": "1",
+				"lifecycle": "production",
+				"languages": ["go"]
+			},
+			"integrations": {"pagerduty": {"serviceURL": "https://acme.pagerduty.com/service-directory/P1"}},
+			"datadog": {"codeLocations": [{"repositoryURL": "https://github.com/acme/billing", "paths": ["**"]}]}
+		}`), 0)
+
+		assert.Equal(t, SchemaVersionV3, service.SchemaVersion)
+		assert.Equal(t, EntityKindService, service.Kind)
+		assert.Equal(t, "billing", service.Name)
+		assert.Equal(t, []string{"payments", "sre"}, service.Teams)
+		assert.Equal(t, []string{"system:checkout"}, service.ComponentOf)
+		assert.Equal(t, []string{"ledger", "datastore:billing-db"}, service.Dependencies)
+		assert.Len(t, service.Repos, 1, "Repo links and code locations should be deduplicated")
+		assert.Contains(t, service.Integrations, IntegrationPagerDuty)
+	})
+
+	t.Run("v3 datastore", func(t *testing.T) {
+		service := TransformServiceDefinition(decodeServiceDefinition(t, `{
+			"apiVersion": "v3",
+			"kind": "datastore",
+			"metadata": {"name": "billing-db", "owner": "payments"},
+			"spec": {"type": "postgres", "componentOf": ["system:checkout"]}
+		}`), 0)
+
+		assert.Equal(t, SchemaVersionV3, service.SchemaVersion)
+		assert.Equal(t, "datastore", service.Kind)
+		assert.Equal(t, "postgres", service.Type)
+		assert.Equal(t, []string{"system:checkout"}, service.ComponentOf)
+	})
+
+	t.Run("unrecognized schema", func(t *testing.T) {
+		service := TransformServiceDefinition(decodeServiceDefinition(t, `{"apiVersion": "v9", "whatever": true}`), 0)
+
+		assert.Equal(t, "", service.SchemaVersion)
+		assert.Equal(t, "svc-id", service.Name)
+		assert.NotNil(t, service.Teams)
+		assert.NotNil(t, service.Integrations)
+	})
+}
@@ -0,0 +1,228 @@
+// Package shared provides pure functional utilities for Datadog API v2 data transformations.
+package shared
+
+import (
+	"sort"
+	"time"
+
+	"github.com/samber/lo"
+
+	"bacon/src/plugins/datadog/types"
+	"bacon/src/shared/scraperoutput"
+)
+
+// EntityKindOrganization identifies organizations in snapshot change events
+const EntityKindOrganization = "organization"
+
+// Change event types emitted by DiffSnapshots
+const (
+	ChangeEntityAdded        = "entity_added"
+	ChangeEntityRemoved      = "entity_removed"
+	ChangeTeamRenamed        = "team_renamed"
+	ChangeMemberJoined       = "member_joined"
+	ChangeMemberLeft         = "member_left"
+	ChangeOwnerChanged       = "owner_changed"
+	ChangeOwningTeamAdded    = "owning_team_added"
+	ChangeOwningTeamRemoved  = "owning_team_removed"
+	ChangeServiceTierChanged = "tier_changed"
+)
+
+// AssembleSnapshot builds a complete snapshot with entities ordered by ID
+// Pure function; the input slices are not modified
+func AssembleSnapshot(id string, teams []types.DatadogTeam, users []types.DatadogUser, services []types.DatadogService, organizations []types.DatadogOrganization, timestamp time.Time) types.DatadogTeamSnapshot {
+	sortedTeams := sortedByID(teams, func(team types.DatadogTeam) string { return team.ID })
+	sortedUsers := sortedByID(users, func(user types.DatadogUser) string { return user.ID })
+	sortedServices := sortedByID(services, func(service types.DatadogService) string { return service.ID })
+
+	return types.DatadogTeamSnapshot{
+		ID:            id,
+		Teams:         sortedTeams,
+		Users:         sortedUsers,
+		Services:      sortedServices,
+		Organizations: sortedByID(organizations, func(org types.DatadogOrganization) string { return org.ID }),
+		Timestamp:     timestamp.UTC(),
+		TotalTeams:    len(sortedTeams),
+		TotalUsers:    len(sortedUsers),
+		TotalServices: len(sortedServices),
+	}
+}
+
+// DiffSnapshots computes the structured changes from the previous snapshot to the current one
+// Pure function with deterministic ordering: teams, users, services, then organizations, each by ID
+func DiffSnapshots(previous, current types.DatadogTeamSnapshot) types.DatadogSnapshotDiff {
+	userNames := lo.Assign(snapshotUserNames(previous), snapshotUserNames(current))
+
+	changes := append(diffTeams(previous.Teams, current.Teams, userNames), diffUsers(previous.Users, current.Users)...)
+	changes = append(changes, diffServices(previous.Services, current.Services)...)
+	changes = append(changes, diffOrganizations(previous.Organizations, current.Organizations)...)
+
+	return types.DatadogSnapshotDiff{
+		PreviousSnapshotID: previous.ID,
+		SnapshotID:         current.ID,
+		Changes:            changes,
+	}
+}
+
+// CreateChangeOutput wraps a snapshot diff in the ScraperOutput format consumed by the processor
+// Pure function
+func CreateChangeOutput(diff types.DatadogSnapshotDiff, timestamp time.Time) types.ScraperOutput {
+	events := lo.Map(diff.Changes, func(change types.DatadogChangeEvent, _ int) scraperoutput.ChangeEvent {
+		return scraperoutput.ChangeEvent(change)
+	})
+
+	return scraperoutput.New(SourceDatadogChanges, ChangeEventConfidence, timestamp, scraperoutput.Changes(scraperoutput.ChangeSet{
+		SnapshotID:         diff.SnapshotID,
+		PreviousSnapshotID: diff.PreviousSnapshotID,
+		Events:             events,
+	}))
+}
+
+// diffTeams detects added, removed and renamed teams and membership changes
+func diffTeams(previous, current []types.DatadogTeam, userNames map[string]string) []types.DatadogChangeEvent {
+	return diffEntities(previous, current, func(team types.DatadogTeam) string { return team.ID }, func(before, after *types.DatadogTeam) []types.DatadogChangeEvent {
+		team := lo.FromPtr(lo.Ternary(after != nil, after, before))
+		base := types.DatadogChangeEvent{EntityKind: EntityKindTeam, EntityID: team.ID, EntityName: teamEntityName(team)}
+		if before == nil || after == nil {
+			return addedOrRemoved(base, before == nil, after == nil)
+		}
+
+		var changes []types.DatadogChangeEvent
+		if before.Name != after.Name {
+			changes = append(changes, withChange(base, ChangeTeamRenamed, "name", before.Name, after.Name))
+		}
+		if before.Handle != after.Handle {
+			changes = append(changes, withChange(base, ChangeTeamRenamed, "handle", before.Handle, after.Handle))
+		}
+
+		joined, left := diffStringSets(memberIDs(before.Members), memberIDs(after.Members))
+		changes = append(changes, lo.Map(joined, func(userID string, _ int) types.DatadogChangeEvent {
+			return withChange(base, ChangeMemberJoined, "members", "", lo.ValueOr(userNames, userID, userID))
+		})...)
+		changes = append(changes, lo.Map(left, func(userID string, _ int) types.DatadogChangeEvent {
+			return withChange(base, ChangeMemberLeft, "members", lo.ValueOr(userNames, userID, userID), "")
+		})...)
+
+		return changes
+	})
+}
+
+// diffUsers detects added and removed users
+func diffUsers(previous, current []types.DatadogUser) []types.DatadogChangeEvent {
+	return diffEntities(previous, current, func(user types.DatadogUser) string { return user.ID }, func(before, after *types.DatadogUser) []types.DatadogChangeEvent {
+		user := lo.FromPtr(lo.Ternary(after != nil, after, before))
+		base := types.DatadogChangeEvent{EntityKind: EntityKindUser, EntityID: user.ID, EntityName: userEntityName(user)}
+		return addedOrRemoved(base, before == nil, after == nil)
+	})
+}
+
+// diffServices detects added and removed services and owner or tier changes
+func diffServices(previous, current []types.DatadogService) []types.DatadogChangeEvent {
+	return diffEntities(previous, current, func(service types.DatadogService) string { return service.ID }, func(before, after *types.DatadogService) []types.DatadogChangeEvent {
+		service := lo.FromPtr(lo.Ternary(after != nil, after, before))
+		base := types.DatadogChangeEvent{EntityKind: EntityKindService, EntityID: service.ID, EntityName: service.Name}
+		if before == nil || after == nil {
+			return addedOrRemoved(base, before == nil, after == nil)
+		}
+
+		var changes []types.DatadogChangeEvent
+		if before.Owner != after.Owner {
+			changes = append(changes, withChange(base, ChangeOwnerChanged, "owner", before.Owner, after.Owner))
+		}
+
+		added, removed := diffStringSets(before.Teams, after.Teams)
+		changes = append(changes, lo.Map(added, func(team string, _ int) types.DatadogChangeEvent {
+			return withChange(base, ChangeOwningTeamAdded, "teams", "", team)
+		})...)
+		changes = append(changes, lo.Map(removed, func(team string, _ int) types.DatadogChangeEvent {
+			return withChange(base, ChangeOwningTeamRemoved, "teams", team, "")
+		})...)
+
+		if before.Tier != after.Tier {
+			changes = append(changes, withChange(base, ChangeServiceTierChanged, "tier", before.Tier, after.Tier))
+		}
+
+		return changes
+	})
+}
+
+// diffOrganizations detects added and removed organizations
+func diffOrganizations(previous, current []types.DatadogOrganization) []types.DatadogChangeEvent {
+	return diffEntities(previous, current, func(org types.DatadogOrganization) string { return org.ID }, func(before, after *types.DatadogOrganization) []types.DatadogChangeEvent {
+		org := lo.FromPtr(lo.Ternary(after != nil, after, before))
+		base := types.DatadogChangeEvent{EntityKind: EntityKindOrganization, EntityID: org.ID, EntityName: org.Name}
+		return addedOrRemoved(base, before == nil, after == nil)
+	})
+}
+
+// diffEntities pairs entities by ID and compares each pair; a nil side means added or removed
+// Pure generic function visiting IDs in sorted order
+func diffEntities[T any](previous, current []T, id func(T) string, compare func(before, after *T) []types.DatadogChangeEvent) []types.DatadogChangeEvent {
+	before := lo.KeyBy(previous, id)
+	after := lo.KeyBy(current, id)
+
+	ids := lo.Uniq(append(lo.Keys(before), lo.Keys(after)...))
+	sort.Strings(ids)
+
+	return lo.FlatMap(ids, func(entityID string, _ int) []types.DatadogChangeEvent {
+		beforeEntity, existed := before[entityID]
+		afterEntity, exists := after[entityID]
+		return compare(lo.Ternary(existed, &beforeEntity, nil), lo.Ternary(exists, &afterEntity, nil))
+	})
+}
+
+// addedOrRemoved emits an entity_added or entity_removed event when one side of a pair is missing
+func addedOrRemoved(base types.DatadogChangeEvent, added, removed bool) []types.DatadogChangeEvent {
+	switch {
+	case added:
+		return []types.DatadogChangeEvent{withChange(base, ChangeEntityAdded, "", "", "")}
+	case removed:
+		return []types.DatadogChangeEvent{withChange(base, ChangeEntityRemoved, "", "", "")}
+	}
+	return nil
+}
+
+// withChange returns a copy of the base event describing a single change
+func withChange(base types.DatadogChangeEvent, changeType, field, before, after string) types.DatadogChangeEvent {
+	base.Type = changeType
+	base.Field = field
+	base.Before = before
+	base.After = after
+	return base
+}
+
+// diffStringSets returns the values added to and removed from a set, both sorted
+func diffStringSets(before, after []string) ([]string, []string) {
+	added, removed := lo.Difference(lo.Uniq(lo.Compact(after)), lo.Uniq(lo.Compact(before)))
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// snapshotUserNames maps user IDs to their graph names
+func snapshotUserNames(snapshot types.DatadogTeamSnapshot) map[string]string {
+	return lo.SliceToMap(snapshot.Users, func(user types.DatadogUser) (string, string) {
+		return user.ID, userEntityName(user)
+	})
+}
+
+// memberIDs extracts the user IDs of team members
+func memberIDs(members []types.DatadogUser) []string {
+	return lo.Map(members, func(member types.DatadogUser, _ int) string { return member.ID })
+}
+
+// teamEntityName returns the graph name of a team, preferring its handle
+func teamEntityName(team types.DatadogTeam) string {
+	return lo.CoalesceOrEmpty(team.Handle, team.Name, team.ID)
+}
+
+// userEntityName returns the graph name of a user, preferring their email
+func userEntityName(user types.DatadogUser) string {
+	return lo.CoalesceOrEmpty(user.Email, user.Handle, user.ID)
+}
+
+// sortedByID returns a copy of the entities ordered by ID
+func sortedByID[T any](entities []T, id func(T) string) []T {
+	sorted := append([]T{}, entities...)
+	sort.SliceStable(sorted, func(i, j int) bool { return id(sorted[i]) < id(sorted[j]) })
+	return sorted
+}
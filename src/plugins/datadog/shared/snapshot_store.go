@@ -0,0 +1,430 @@
+// Package shared provides pure functional AWS utilities for Datadog data storage.
+package shared
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/samber/lo"
+
+	ddTypes "bacon/src/plugins/datadog/types"
+)
+
+// Snapshot persistence layout; snapshots are gzipped JSON split into parts below the 400KB item limit
+const (
+	snapshotKeyAttribute = "snapshot_key"
+	latestSnapshotKey    = "latest"
+	snapshotPartSize     = 350 * 1024
+	snapshotRetention    = 30 * 24 * time.Hour // parts expire through the table's expires_at TTL
+)
+
+// DynamoDBSnapshotAPI is the subset of the DynamoDB client used by the snapshot store
+type DynamoDBSnapshotAPI interface {
+	DynamoDBWriteAPI
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+}
+
+// SnapshotTables names the entity tables read into a snapshot and the table snapshots are persisted in
+type SnapshotTables struct {
+	Teams         string
+	Users         string
+	Services      string
+	Organizations string
+	Snapshots     string
+}
+
+// SnapshotStore assembles snapshots from the entity tables and persists them between runs
+type SnapshotStore struct {
+	client DynamoDBSnapshotAPI
+	tables SnapshotTables
+}
+
+// NewSnapshotStore creates a snapshot store over the given tables
+func NewSnapshotStore(client DynamoDBSnapshotAPI, tables SnapshotTables) *SnapshotStore {
+	return &SnapshotStore{client: client, tables: tables}
+}
+
+// CreateSnapshotStore creates a snapshot store using the default AWS configuration and table environment
+func CreateSnapshotStore(ctx context.Context) (*SnapshotStore, error) {
+	client, err := createDynamoDBClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamodb client: %w", err)
+	}
+
+	return NewSnapshotStore(client, DefaultSnapshotTables()), nil
+}
+
+// DefaultSnapshotTables resolves table names from the environment, matching the scrapers
+func DefaultSnapshotTables() SnapshotTables {
+	return SnapshotTables{
+		Teams:         getTableName("DATADOG_TEAMS_TABLE", "datadog-teams"),
+		Users:         getTableName("DATADOG_USERS_TABLE", "datadog-users"),
+		Services:      getTableName("DATADOG_SERVICES_TABLE", "datadog-services"),
+		Organizations: getTableName("DATADOG_ORGANIZATIONS_TABLE", "datadog-organizations"),
+		Snapshots:     getTableName("DATADOG_SNAPSHOTS_TABLE", "datadog-snapshots"),
+	}
+}
+
+// LoadLive assembles a snapshot from every entity that is not tombstoned
+func (s *SnapshotStore) LoadLive(ctx context.Context, id string, timestamp time.Time) (ddTypes.DatadogTeamSnapshot, error) {
+	teams, err := s.LoadLiveTeams(ctx)
+	if err != nil {
+		return ddTypes.DatadogTeamSnapshot{}, err
+	}
+	userItems, err := s.scanLiveItems(ctx, s.tables.Users)
+	if err != nil {
+		return ddTypes.DatadogTeamSnapshot{}, err
+	}
+	services, err := s.LoadLiveServices(ctx)
+	if err != nil {
+		return ddTypes.DatadogTeamSnapshot{}, err
+	}
+	organizationItems, err := s.scanLiveItems(ctx, s.tables.Organizations)
+	if err != nil {
+		return ddTypes.DatadogTeamSnapshot{}, err
+	}
+
+	return AssembleSnapshot(
+		id,
+		teams,
+		lo.Map(userItems, decodeUserItem),
+		services,
+		lo.Map(organizationItems, decodeOrganizationItem),
+		timestamp,
+	), nil
+}
+
+// LoadLiveTeams reads every team that is not tombstoned
+func (s *SnapshotStore) LoadLiveTeams(ctx context.Context) ([]ddTypes.DatadogTeam, error) {
+	teamItems, err := s.scanLiveItems(ctx, s.tables.Teams)
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(teamItems, decodeTeamItem), nil
+}
+
+// LoadLiveServices reads every service that is not tombstoned
+func (s *SnapshotStore) LoadLiveServices(ctx context.Context) ([]ddTypes.DatadogService, error) {
+	serviceItems, err := s.scanLiveItems(ctx, s.tables.Services)
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(serviceItems, decodeServiceItem), nil
+}
+
+// LoadLatest reads the most recently saved snapshot; false means no snapshot was saved yet
+func (s *SnapshotStore) LoadLatest(ctx context.Context) (ddTypes.DatadogTeamSnapshot, bool, error) {
+	latest, err := s.getItem(ctx, latestSnapshotKey)
+	if err != nil || latest == nil {
+		return ddTypes.DatadogTeamSnapshot{}, false, err
+	}
+
+	id := attributeString(latest, "snapshot_id")
+	parts, err := strconv.Atoi(attributeNumber(latest, "parts"))
+	if err != nil || id == "" {
+		return ddTypes.DatadogTeamSnapshot{}, false, fmt.Errorf("invalid latest snapshot pointer in %s", s.tables.Snapshots)
+	}
+
+	var compressed bytes.Buffer
+	for part := 0; part < parts; part++ {
+		item, err := s.getItem(ctx, snapshotPartKey(id, part))
+		if err != nil {
+			return ddTypes.DatadogTeamSnapshot{}, false, err
+		}
+		payload, ok := item["payload"].(*types.AttributeValueMemberB)
+		if !ok {
+			return ddTypes.DatadogTeamSnapshot{}, false, fmt.Errorf("snapshot %s is missing part %d", id, part)
+		}
+		compressed.Write(payload.Value)
+	}
+
+	snapshot, err := decodeSnapshot(compressed.Bytes())
+	if err != nil {
+		return ddTypes.DatadogTeamSnapshot{}, false, fmt.Errorf("failed to decode snapshot %s: %w", id, err)
+	}
+	return snapshot, true, nil
+}
+
+// Save persists a snapshot and then moves the latest pointer to it
+// The pointer is only written once every part is stored, so readers never see a partial snapshot
+func (s *SnapshotStore) Save(ctx context.Context, snapshot ddTypes.DatadogTeamSnapshot) error {
+	compressed, err := encodeSnapshot(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot %s: %w", snapshot.ID, err)
+	}
+
+	createdAt := &types.AttributeValueMemberS{Value: snapshot.Timestamp.UTC().Format(time.RFC3339)}
+	expiresAt := &types.AttributeValueMemberN{Value: strconv.FormatInt(snapshot.Timestamp.Add(snapshotRetention).Unix(), 10)}
+	chunks := lo.Chunk(compressed, snapshotPartSize)
+
+	parts := lo.Map(chunks, func(chunk []byte, part int) map[string]types.AttributeValue {
+		return map[string]types.AttributeValue{
+			snapshotKeyAttribute: &types.AttributeValueMemberS{Value: snapshotPartKey(snapshot.ID, part)},
+			"payload":            &types.AttributeValueMemberB{Value: chunk},
+			"created_at":         createdAt,
+			"expires_at":         expiresAt,
+		}
+	})
+
+	engine := NewStorageEngine(s.client, StorageOptions{TableName: s.tables.Snapshots, KeyAttribute: snapshotKeyAttribute})
+	if _, err := engine.Write(ctx, parts); err != nil {
+		return fmt.Errorf("failed to store snapshot %s: %w", snapshot.ID, err)
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: &s.tables.Snapshots,
+		Item: map[string]types.AttributeValue{
+			snapshotKeyAttribute: &types.AttributeValueMemberS{Value: latestSnapshotKey},
+			"snapshot_id":        &types.AttributeValueMemberS{Value: snapshot.ID},
+			"parts":              &types.AttributeValueMemberN{Value: strconv.Itoa(len(parts))},
+			"created_at":         createdAt,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update latest snapshot pointer: %w", err)
+	}
+
+	return nil
+}
+
+// scanLiveItems reads every item of a table that has not been tombstoned
+func (s *SnapshotStore) scanLiveItems(ctx context.Context, tableName string) ([]map[string]types.AttributeValue, error) {
+	var (
+		items    []map[string]types.AttributeValue
+		startKey map[string]types.AttributeValue
+	)
+
+	for {
+		output, err := s.client.Scan(ctx, &dynamodb.ScanInput{
+			TableName:                &tableName,
+			ConsistentRead:           lo.ToPtr(true),
+			FilterExpression:         lo.ToPtr("attribute_not_exists(#deleted)"),
+			ExpressionAttributeNames: map[string]string{"#deleted": DeletedAtAttribute},
+			ExclusiveStartKey:        startKey,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", tableName, err)
+		}
+
+		items = append(items, output.Items...)
+
+		if len(output.LastEvaluatedKey) == 0 {
+			return items, nil
+		}
+		startKey = output.LastEvaluatedKey
+	}
+}
+
+// getItem reads a single snapshot table item; a missing item is returned as nil
+func (s *SnapshotStore) getItem(ctx context.Context, key string) (map[string]types.AttributeValue, error) {
+	output, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      &s.tables.Snapshots,
+		Key:            map[string]types.AttributeValue{snapshotKeyAttribute: &types.AttributeValueMemberS{Value: key}},
+		ConsistentRead: lo.ToPtr(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from %s: %w", key, s.tables.Snapshots, err)
+	}
+	if len(output.Item) == 0 {
+		return nil, nil
+	}
+	return output.Item, nil
+}
+
+// snapshotPartKey builds the item key of a snapshot part
+func snapshotPartKey(id string, part int) string {
+	return fmt.Sprintf("%s#%d", id, part)
+}
+
+// encodeSnapshot serializes a snapshot to gzipped JSON
+func encodeSnapshot(snapshot ddTypes.DatadogTeamSnapshot) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+
+	if err := json.NewEncoder(writer).Encode(snapshot); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// decodeSnapshot deserializes a snapshot from gzipped JSON
+func decodeSnapshot(compressed []byte) (ddTypes.DatadogTeamSnapshot, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return ddTypes.DatadogTeamSnapshot{}, err
+	}
+	defer reader.Close()
+
+	payload, err := io.ReadAll(reader)
+	if err != nil {
+		return ddTypes.DatadogTeamSnapshot{}, err
+	}
+
+	var snapshot ddTypes.DatadogTeamSnapshot
+	err = json.Unmarshal(payload, &snapshot)
+	return snapshot, err
+}
+
+// Pure decoding functions for DynamoDB storage items
+
+// decodeTeamItem converts a stored team back to the internal type
+// Pure function; members carry only their ID and team role
+func decodeTeamItem(item map[string]types.AttributeValue, _ int) ddTypes.DatadogTeam {
+	roles := attributeMap(item, "member_roles")
+
+	return ddTypes.DatadogTeam{
+		ID:          attributeString(item, "team_id"),
+		Name:        attributeString(item, "name"),
+		Handle:      attributeString(item, "handle"),
+		Description: attributeString(item, "description"),
+		Members: lo.Map(attributeStringSet(item, "members"), func(userID string, _ int) ddTypes.DatadogUser {
+			return ddTypes.DatadogUser{ID: userID, TeamRole: attributeString(roles, userID)}
+		}),
+		Services: lo.Map(attributeStringSet(item, "services"), func(serviceID string, _ int) ddTypes.DatadogService {
+			return ddTypes.DatadogService{ID: serviceID}
+		}),
+		CreatedAt: attributeTime(item, "created_at"),
+		UpdatedAt: attributeTime(item, "updated_at"),
+	}
+}
+
+// decodeUserItem converts a stored user back to the internal type
+// Pure function with no side effects
+func decodeUserItem(item map[string]types.AttributeValue, _ int) ddTypes.DatadogUser {
+	return ddTypes.DatadogUser{
+		ID:        attributeString(item, "user_id"),
+		Name:      attributeString(item, "name"),
+		Email:     attributeString(item, "email"),
+		Handle:    attributeString(item, "handle"),
+		Teams:     attributeStringSet(item, "teams"),
+		Roles:     attributeStringSet(item, "roles"),
+		Status:    attributeString(item, "status"),
+		Verified:  attributeBool(item, "verified"),
+		Disabled:  attributeBool(item, "disabled"),
+		Title:     attributeString(item, "title"),
+		CreatedAt: attributeTime(item, "created_at"),
+		UpdatedAt: attributeTime(item, "updated_at"),
+	}
+}
+
+// decodeServiceItem converts a stored service back to the internal type
+// Pure function; only the fields compared between snapshots and the on-call integrations are decoded
+func decodeServiceItem(item map[string]types.AttributeValue, _ int) ddTypes.DatadogService {
+	return ddTypes.DatadogService{
+		ID:            attributeString(item, "service_id"),
+		Name:          attributeString(item, "name"),
+		Owner:         attributeString(item, "owner"),
+		OwnerSource:   attributeString(item, "owner_source"),
+		Teams:         attributeStringSet(item, "teams"),
+		Tags:          attributeStringSet(item, "tags"),
+		SchemaVersion: attributeString(item, "schema"),
+		Kind:          attributeString(item, "kind"),
+		Application:   attributeString(item, "application"),
+		Tier:          attributeString(item, "tier"),
+		Lifecycle:     attributeString(item, "lifecycle"),
+		Type:          attributeString(item, "type"),
+		Dependencies:  attributeStringSet(item, "dependencies"),
+		Integrations:  decodeMapAttribute(attributeMap(item, "integrations")),
+		CreatedAt:     attributeTime(item, "created_at"),
+		UpdatedAt:     attributeTime(item, "updated_at"),
+	}
+}
+
+// decodeOrganizationItem converts a stored organization back to the internal type
+// Pure function; users and teams carry only their ID
+func decodeOrganizationItem(item map[string]types.AttributeValue, _ int) ddTypes.DatadogOrganization {
+	return ddTypes.DatadogOrganization{
+		ID:          attributeString(item, "organization_id"),
+		Name:        attributeString(item, "name"),
+		Description: attributeString(item, "description"),
+		Users: lo.Map(attributeStringSet(item, "users"), func(userID string, _ int) ddTypes.DatadogUser {
+			return ddTypes.DatadogUser{ID: userID}
+		}),
+		Teams: lo.Map(attributeStringSet(item, "teams"), func(teamID string, _ int) ddTypes.DatadogTeam {
+			return ddTypes.DatadogTeam{ID: teamID}
+		}),
+		CreatedAt: attributeTime(item, "created_at"),
+		UpdatedAt: attributeTime(item, "updated_at"),
+	}
+}
+
+// Pure helper functions for DynamoDB attribute access
+
+// attributeString reads a string attribute, returning an empty string when absent
+func attributeString(item map[string]types.AttributeValue, key string) string {
+	if s, ok := item[key].(*types.AttributeValueMemberS); ok {
+		return s.Value
+	}
+	return ""
+}
+
+// attributeNumber reads a number attribute in its string form
+func attributeNumber(item map[string]types.AttributeValue, key string) string {
+	if n, ok := item[key].(*types.AttributeValueMemberN); ok {
+		return n.Value
+	}
+	return ""
+}
+
+// attributeStringSet reads a string set attribute, returning an empty slice when absent
+func attributeStringSet(item map[string]types.AttributeValue, key string) []string {
+	if ss, ok := item[key].(*types.AttributeValueMemberSS); ok {
+		return ss.Value
+	}
+	return []string{}
+}
+
+// attributeBool reads a boolean attribute
+func attributeBool(item map[string]types.AttributeValue, key string) bool {
+	if b, ok := item[key].(*types.AttributeValueMemberBOOL); ok {
+		return b.Value
+	}
+	return false
+}
+
+// attributeMap reads a map attribute, returning nil when absent
+func attributeMap(item map[string]types.AttributeValue, key string) map[string]types.AttributeValue {
+	if m, ok := item[key].(*types.AttributeValueMemberM); ok {
+		return m.Value
+	}
+	return nil
+}
+
+// decodeMapAttribute converts a map attribute written by createMapAttribute back to plain values
+// Numbers stay in their string form; nil is returned for an absent or empty map
+func decodeMapAttribute(m map[string]types.AttributeValue) map[string]interface{} {
+	if len(m) == 0 {
+		return nil
+	}
+
+	return lo.MapValues(m, func(value types.AttributeValue, _ string) interface{} {
+		switch v := value.(type) {
+		case *types.AttributeValueMemberS:
+			return v.Value
+		case *types.AttributeValueMemberN:
+			return v.Value
+		case *types.AttributeValueMemberBOOL:
+			return v.Value
+		case *types.AttributeValueMemberM:
+			return decodeMapAttribute(v.Value)
+		}
+		return nil
+	})
+}
+
+// attributeTime reads an RFC3339 timestamp attribute, returning the zero time when invalid
+func attributeTime(item map[string]types.AttributeValue, key string) time.Time {
+	parsed, _ := time.Parse(time.RFC3339, attributeString(item, key))
+	return parsed
+}
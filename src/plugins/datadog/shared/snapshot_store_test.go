@@ -0,0 +1,191 @@
+package shared
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ddTypes "bacon/src/plugins/datadog/types"
+)
+
+// fakeSnapshotDynamoDB is an in-memory multi-table DynamoDBSnapshotAPI
+type fakeSnapshotDynamoDB struct {
+	keys   map[string]string // table name to key attribute
+	tables map[string]map[string]map[string]types.AttributeValue
+}
+
+func newFakeSnapshotDynamoDB(tables SnapshotTables) *fakeSnapshotDynamoDB {
+	keys := map[string]string{
+		tables.Teams:         "team_id",
+		tables.Users:         "user_id",
+		tables.Services:      "service_id",
+		tables.Organizations: "organization_id",
+		tables.Snapshots:     snapshotKeyAttribute,
+	}
+	return &fakeSnapshotDynamoDB{
+		keys: keys,
+		tables: lo.MapValues(keys, func(string, string) map[string]map[string]types.AttributeValue {
+			return map[string]map[string]types.AttributeValue{}
+		}),
+	}
+}
+
+func (f *fakeSnapshotDynamoDB) put(table string, item map[string]types.AttributeValue) {
+	f.tables[table][extractItemID(item, f.keys[table])] = item
+}
+
+func (f *fakeSnapshotDynamoDB) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	for table, requests := range params.RequestItems {
+		for _, request := range requests {
+			f.put(table, request.PutRequest.Item)
+		}
+	}
+	return &dynamodb.BatchWriteItemOutput{}, nil
+}
+
+func (f *fakeSnapshotDynamoDB) PutItem(ctx context.Context, params *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	f.put(*params.TableName, params.Item)
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeSnapshotDynamoDB) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	return nil, fmt.Errorf("unexpected UpdateItem on %s", *params.TableName)
+}
+
+// Scan returns live items one per page to exercise pagination
+func (f *fakeSnapshotDynamoDB) Scan(ctx context.Context, params *dynamodb.ScanInput, _ ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	keyAttribute := f.keys[*params.TableName]
+	start := extractItemID(params.ExclusiveStartKey, keyAttribute)
+
+	ids := lo.Filter(lo.Keys(f.tables[*params.TableName]), func(id string, _ int) bool {
+		_, deleted := f.tables[*params.TableName][id][DeletedAtAttribute]
+		return id > start && !deleted
+	})
+	if len(ids) == 0 {
+		return &dynamodb.ScanOutput{}, nil
+	}
+
+	next := lo.Min(ids)
+	return &dynamodb.ScanOutput{
+		Items:            []map[string]types.AttributeValue{f.tables[*params.TableName][next]},
+		LastEvaluatedKey: map[string]types.AttributeValue{keyAttribute: &types.AttributeValueMemberS{Value: next}},
+	}, nil
+}
+
+func (f *fakeSnapshotDynamoDB) GetItem(ctx context.Context, params *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	key := extractItemID(params.Key, f.keys[*params.TableName])
+	return &dynamodb.GetItemOutput{Item: f.tables[*params.TableName][key]}, nil
+}
+
+// testSnapshotTables returns distinct table names for the fake
+func testSnapshotTables() SnapshotTables {
+	return SnapshotTables{Teams: "teams", Users: "users", Services: "services", Organizations: "organizations", Snapshots: "snapshots"}
+}
+
+func TestSnapshotStore_LoadLive(t *testing.T) {
+	tables := testSnapshotTables()
+	client := newFakeSnapshotDynamoDB(tables)
+
+	team := createTeamStorageItem(ddTypes.DatadogTeam{
+		ID:      "team-1",
+		Name:    "Payments",
+		Handle:  "payments",
+		Members: []ddTypes.DatadogUser{{ID: "user-1", TeamRole: "admin"}},
+	}, 0)
+	client.put(tables.Teams, team)
+	client.put(tables.Teams, lo.Assign(createTeamStorageItem(ddTypes.DatadogTeam{ID: "team-0", Handle: "gone"}, 0), map[string]types.AttributeValue{
+		DeletedAtAttribute: &types.AttributeValueMemberS{Value: "2024-01-01T00:00:00Z"},
+	}))
+	client.put(tables.Users, createUserStorageItem(ddTypes.DatadogUser{ID: "user-1", Email: "ana@example.com", Teams: []string{"team-1"}, Disabled: true}, 0))
+	client.put(tables.Services, createServiceStorageItem(ddTypes.DatadogService{ID: "svc-1", Name: "checkout", Owner: "payments", Teams: []string{"payments"}, Tier: "1"}, 0))
+	client.put(tables.Services, createServiceStorageItem(ddTypes.DatadogService{ID: "svc-2", Name: "billing"}, 0))
+	client.put(tables.Organizations, createOrganizationStorageItem(ddTypes.DatadogOrganization{ID: "org-1", Name: "Acme", Teams: []ddTypes.DatadogTeam{{ID: "team-1"}}}, 0))
+
+	snapshot, err := NewSnapshotStore(client, tables).LoadLive(context.Background(), "run-1", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	require.NoError(t, err)
+	assert.Equal(t, "run-1", snapshot.ID)
+	require.Len(t, snapshot.Teams, 1, "Tombstoned teams should be excluded")
+	assert.Equal(t, "payments", snapshot.Teams[0].Handle)
+	assert.Equal(t, []ddTypes.DatadogUser{{ID: "user-1", TeamRole: "admin"}}, snapshot.Teams[0].Members)
+	assert.Equal(t, "ana@example.com", snapshot.Users[0].Email)
+	assert.True(t, snapshot.Users[0].Disabled)
+	assert.Equal(t, 2, snapshot.TotalServices)
+	assert.Equal(t, "payments", snapshot.Services[0].Owner)
+	assert.Equal(t, "1", snapshot.Services[0].Tier)
+	assert.Equal(t, "team-1", snapshot.Organizations[0].Teams[0].ID)
+}
+
+func TestSnapshotStore_LoadLiveServices(t *testing.T) {
+	tables := testSnapshotTables()
+	client := newFakeSnapshotDynamoDB(tables)
+
+	client.put(tables.Services, createServiceStorageItem(ddTypes.DatadogService{ID: "svc-1", Name: "checkout", Integrations: map[string]interface{}{
+		IntegrationPagerDuty: map[string]interface{}{"service_url": "https://acme.pagerduty.com/service-directory/P1"},
+		IntegrationOpsgenie:  map[string]interface{}{"service_url": "https://acme.app.opsgenie.com/service/abc", "region": "EU"},
+	}}, 0))
+	client.put(tables.Services, createServiceStorageItem(ddTypes.DatadogService{ID: "svc-2", Name: "billing"}, 0))
+
+	services, err := NewSnapshotStore(client, tables).LoadLiveServices(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, services, 2)
+	assert.Equal(t, map[string]interface{}{
+		IntegrationPagerDuty: map[string]interface{}{"service_url": "https://acme.pagerduty.com/service-directory/P1"},
+		IntegrationOpsgenie:  map[string]interface{}{"service_url": "https://acme.app.opsgenie.com/service/abc", "region": "EU"},
+	}, services[0].Integrations)
+	assert.Nil(t, services[1].Integrations)
+}
+
+func TestSnapshotStore_SaveAndLoadLatest(t *testing.T) {
+	tables := testSnapshotTables()
+	client := newFakeSnapshotDynamoDB(tables)
+	store := NewSnapshotStore(client, tables)
+
+	_, found, err := store.LoadLatest(context.Background())
+	require.NoError(t, err)
+	assert.False(t, found, "No snapshot should be found before the first save")
+
+	// Random descriptions do not compress, so the payload spans several parts
+	random := rand.New(rand.NewSource(1))
+	teams := lo.Times(4000, func(i int) ddTypes.DatadogTeam {
+		description := make([]byte, 128)
+		random.Read(description)
+		return ddTypes.DatadogTeam{ID: fmt.Sprintf("team-%04d", i), Handle: fmt.Sprintf("handle-%d", i), Description: hex.EncodeToString(description)}
+	})
+	snapshot := AssembleSnapshot("run-1", teams, nil, nil, nil, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	require.NoError(t, store.Save(context.Background(), snapshot))
+
+	loaded, found, err := store.LoadLatest(context.Background())
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, snapshot.ID, loaded.ID)
+	assert.Equal(t, snapshot.Teams, loaded.Teams)
+	assert.Greater(t, lo.Must(strconv.Atoi(attributeNumber(client.tables[tables.Snapshots][latestSnapshotKey], "parts"))), 1)
+}
+
+func TestSnapshotStore_LoadLatest_MissingPart(t *testing.T) {
+	tables := testSnapshotTables()
+	client := newFakeSnapshotDynamoDB(tables)
+	client.put(tables.Snapshots, map[string]types.AttributeValue{
+		snapshotKeyAttribute: &types.AttributeValueMemberS{Value: latestSnapshotKey},
+		"snapshot_id":        &types.AttributeValueMemberS{Value: "run-1"},
+		"parts":              &types.AttributeValueMemberN{Value: "2"},
+	})
+
+	_, found, err := NewSnapshotStore(client, tables).LoadLatest(context.Background())
+
+	assert.Error(t, err)
+	assert.False(t, found)
+}
@@ -0,0 +1,121 @@
+package shared
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bacon/src/plugins/datadog/types"
+	"bacon/src/shared/scraperoutput"
+)
+
+func TestAssembleSnapshot(t *testing.T) {
+	timestamp := time.Date(2024, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	teams := []types.DatadogTeam{{ID: "team-2"}, {ID: "team-1"}}
+
+	snapshot := AssembleSnapshot("run-1", teams, []types.DatadogUser{{ID: "user-1"}}, nil, nil, timestamp)
+
+	assert.Equal(t, "run-1", snapshot.ID)
+	assert.Equal(t, "team-1", snapshot.Teams[0].ID)
+	assert.Equal(t, "team-2", teams[0].ID, "Input should not be reordered")
+	assert.Equal(t, 2, snapshot.TotalTeams)
+	assert.Equal(t, 1, snapshot.TotalUsers)
+	assert.Equal(t, 0, snapshot.TotalServices)
+	assert.Equal(t, time.UTC, snapshot.Timestamp.Location())
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	previous := types.DatadogTeamSnapshot{
+		ID: "run-1",
+		Teams: []types.DatadogTeam{
+			{ID: "team-1", Name: "Payments", Handle: "payments", Members: []types.DatadogUser{{ID: "user-1"}, {ID: "user-2"}}},
+			{ID: "team-2", Handle: "legacy"},
+		},
+		Users: []types.DatadogUser{
+			{ID: "user-1", Email: "ana@example.com"},
+			{ID: "user-2", Email: "bo@example.com"},
+		},
+		Services: []types.DatadogService{
+			{ID: "svc-1", Name: "checkout", Owner: "payments", Teams: []string{"payments"}, Tier: "2"},
+		},
+		Organizations: []types.DatadogOrganization{{ID: "org-1", Name: "Acme"}},
+	}
+
+	current := types.DatadogTeamSnapshot{
+		ID: "run-2",
+		Teams: []types.DatadogTeam{
+			{ID: "team-1", Name: "Payments Platform", Handle: "payments", Members: []types.DatadogUser{{ID: "user-1"}, {ID: "user-3"}}},
+		},
+		Users: []types.DatadogUser{
+			{ID: "user-1", Email: "ana@example.com"},
+			{ID: "user-3", Handle: "cy"},
+		},
+		Services: []types.DatadogService{
+			{ID: "svc-1", Name: "checkout", Owner: "platform", Teams: []string{"platform", "payments"}, Tier: "1"},
+		},
+		Organizations: []types.DatadogOrganization{{ID: "org-1", Name: "Acme"}},
+	}
+
+	diff := DiffSnapshots(previous, current)
+
+	assert.Equal(t, "run-1", diff.PreviousSnapshotID)
+	assert.Equal(t, "run-2", diff.SnapshotID)
+	assert.Equal(t, []types.DatadogChangeEvent{
+		{Type: ChangeTeamRenamed, EntityKind: EntityKindTeam, EntityID: "team-1", EntityName: "payments", Field: "name", Before: "Payments", After: "Payments Platform"},
+		{Type: ChangeMemberJoined, EntityKind: EntityKindTeam, EntityID: "team-1", EntityName: "payments", Field: "members", After: "cy"},
+		{Type: ChangeMemberLeft, EntityKind: EntityKindTeam, EntityID: "team-1", EntityName: "payments", Field: "members", Before: "bo@example.com"},
+		{Type: ChangeEntityRemoved, EntityKind: EntityKindTeam, EntityID: "team-2", EntityName: "legacy"},
+		{Type: ChangeEntityRemoved, EntityKind: EntityKindUser, EntityID: "user-2", EntityName: "bo@example.com"},
+		{Type: ChangeEntityAdded, EntityKind: EntityKindUser, EntityID: "user-3", EntityName: "cy"},
+		{Type: ChangeOwnerChanged, EntityKind: EntityKindService, EntityID: "svc-1", EntityName: "checkout", Field: "owner", Before: "payments", After: "platform"},
+		{Type: ChangeOwningTeamAdded, EntityKind: EntityKindService, EntityID: "svc-1", EntityName: "checkout", Field: "teams", After: "platform"},
+		{Type: ChangeServiceTierChanged, EntityKind: EntityKindService, EntityID: "svc-1", EntityName: "checkout", Field: "tier", Before: "2", After: "1"},
+	}, diff.Changes)
+}
+
+func TestDiffSnapshots_Identical(t *testing.T) {
+	snapshot := types.DatadogTeamSnapshot{
+		Teams:    []types.DatadogTeam{{ID: "team-1", Members: []types.DatadogUser{{ID: "user-1"}}}},
+		Services: []types.DatadogService{{ID: "svc-1", Teams: []string{"a", "b"}}},
+	}
+	reordered := snapshot
+	reordered.Services = []types.DatadogService{{ID: "svc-1", Teams: []string{"b", "a"}}}
+
+	assert.Empty(t, DiffSnapshots(snapshot, reordered).Changes, "Team order should not be reported as a change")
+}
+
+func TestDiffSnapshots_Baseline(t *testing.T) {
+	current := types.DatadogTeamSnapshot{ID: "run-1", Services: []types.DatadogService{{ID: "svc-1", Name: "checkout"}}}
+
+	diff := DiffSnapshots(types.DatadogTeamSnapshot{}, current)
+
+	assert.Equal(t, "", diff.PreviousSnapshotID)
+	assert.Equal(t, []types.DatadogChangeEvent{
+		{Type: ChangeEntityAdded, EntityKind: EntityKindService, EntityID: "svc-1", EntityName: "checkout"},
+	}, diff.Changes)
+}
+
+func TestCreateChangeOutput(t *testing.T) {
+	diff := types.DatadogSnapshotDiff{
+		PreviousSnapshotID: "run-1",
+		SnapshotID:         "run-2",
+		Changes: []types.DatadogChangeEvent{
+			{Type: ChangeMemberJoined, EntityKind: EntityKindTeam, EntityID: "team-1", EntityName: "payments", Field: "members", After: "ana@example.com"},
+			{Type: ChangeEntityRemoved, EntityKind: EntityKindUser, EntityID: "user-2", EntityName: "bo@example.com"},
+		},
+	}
+
+	output := CreateChangeOutput(diff, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+
+	assert.Equal(t, SourceDatadogChanges, output.Source)
+	assert.Equal(t, ChangeEventConfidence, output.Confidence)
+	require.NotNil(t, output.Payload.Changes)
+	assert.Equal(t, "run-2", output.Payload.Changes.SnapshotID)
+	assert.Equal(t, "run-1", output.Payload.Changes.PreviousSnapshotID)
+	assert.Equal(t, []scraperoutput.ChangeEvent{
+		{Type: "member_joined", EntityKind: "team", EntityID: "team-1", EntityName: "payments", Field: "members", After: "ana@example.com"},
+		{Type: "entity_removed", EntityKind: "user", EntityID: "user-2", EntityName: "bo@example.com"},
+	}, output.Payload.Changes.Events)
+}
@@ -0,0 +1,426 @@
+// Package shared provides pure functional AWS utilities for Datadog data storage.
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/samber/lo"
+)
+
+// Storage engine defaults
+const (
+	dynamoDBBatchLimit     = 25 // BatchWriteItem hard limit
+	defaultStorageParallel = 4
+	defaultStorageRetries  = 5
+	defaultStorageBackoff  = 50 * time.Millisecond
+	maxStorageBackoff      = 5 * time.Second
+)
+
+// Attributes maintained by the storage engine
+const (
+	ScrapedAtAttribute  = "scraped_at"        // when the item was built
+	StartedAtAttribute  = "scrape_started_at" // start of the run that last wrote the item, guarding conditional writes
+	GenerationAttribute = "scrape_generation" // run that last saw the item
+	DeletedAtAttribute  = "deleted_at"        // set once a complete run no longer sees the item
+)
+
+// DynamoDBWriteAPI is the subset of the DynamoDB client used by the storage engine
+type DynamoDBWriteAPI interface {
+	BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+}
+
+// StorageOptions configures how the storage engine writes items to a table
+type StorageOptions struct {
+	TableName        string
+	KeyAttribute     string        // attribute holding the item ID, e.g. "team_id"
+	NameAttribute    string        // attribute holding the graph entity name, reported for tombstoned items
+	Generation       string        // when set, stamped on every written item for deletion detection
+	StartedAt        time.Time     // when set, stamped on every written item as StartedAtAttribute
+	VersionAttribute string        // when set, an item is only written if the stored version is not newer
+	BatchSize        int           // items per BatchWriteItem call, at most 25
+	Parallelism      int           // concurrent batches or conditional puts
+	MaxRetries       int           // retries for failed calls and unprocessed items
+	BaseBackoff      time.Duration // first retry delay, doubled on every attempt
+}
+
+// StorageResult reports exactly which item IDs were written
+type StorageResult struct {
+	StoredIDs []string `json:"stored_ids"`
+	FailedIDs []string `json:"failed_ids"`
+	StaleIDs  []string `json:"stale_ids"` // skipped because newer data is already stored
+}
+
+// TombstonedItem identifies an item marked deleted by Tombstone
+type TombstonedItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// StorageEngine writes DynamoDB items with retries, backoff and bounded parallelism
+type StorageEngine struct {
+	client  DynamoDBWriteAPI
+	options StorageOptions
+	sleep   func(context.Context, time.Duration) error
+}
+
+// NewStorageEngine creates a storage engine, filling unset options with defaults
+func NewStorageEngine(client DynamoDBWriteAPI, options StorageOptions) *StorageEngine {
+	options.BatchSize = lo.Ternary(options.BatchSize > 0 && options.BatchSize <= dynamoDBBatchLimit, options.BatchSize, dynamoDBBatchLimit)
+	options.Parallelism = lo.Ternary(options.Parallelism > 0, options.Parallelism, defaultStorageParallel)
+	options.MaxRetries = lo.Ternary(options.MaxRetries > 0, options.MaxRetries, defaultStorageRetries)
+	options.BaseBackoff = lo.Ternary(options.BaseBackoff > 0, options.BaseBackoff, defaultStorageBackoff)
+
+	return &StorageEngine{client: client, options: options, sleep: sleepWithContext}
+}
+
+// DefaultStorageOptions returns the options used by the Datadog scrapers for a table
+// Items are only written over those of a run started no later, unless DATADOG_CONDITIONAL_WRITES=false
+func DefaultStorageOptions(tableName, keyAttribute, nameAttribute, generation string, startedAt time.Time) StorageOptions {
+	return StorageOptions{
+		TableName:        tableName,
+		KeyAttribute:     keyAttribute,
+		NameAttribute:    nameAttribute,
+		Generation:       generation,
+		StartedAt:        startedAt,
+		VersionAttribute: lo.Ternary(os.Getenv("DATADOG_CONDITIONAL_WRITES") != "false", StartedAtAttribute, ""),
+		BatchSize:        dynamoDBBatchLimit,
+		Parallelism:      defaultStorageParallel,
+		MaxRetries:       defaultStorageRetries,
+		BaseBackoff:      defaultStorageBackoff,
+	}
+}
+
+// Write stores all items and reports exact stored, failed and stale ID sets
+// A non-nil error means at least one item failed; the result is always populated
+func (e *StorageEngine) Write(ctx context.Context, items []map[string]types.AttributeValue) (StorageResult, error) {
+	result := StorageResult{StoredIDs: []string{}, FailedIDs: []string{}, StaleIDs: []string{}}
+	if len(items) == 0 {
+		return result, nil
+	}
+
+	// Conditional writes cannot be batched, so each item becomes its own unit of work
+	write, unitSize := e.writeBatch, e.options.BatchSize
+	if e.options.VersionAttribute != "" {
+		write, unitSize = e.writeConditional, 1
+	}
+	units := lo.Chunk(lo.Map(items, e.stampRun), unitSize)
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		errs      []error
+		semaphore = make(chan struct{}, e.options.Parallelism)
+	)
+
+	for _, unit := range units {
+		wg.Add(1)
+		go func(unit []map[string]types.AttributeValue) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			unitResult, err := write(ctx, unit)
+
+			mu.Lock()
+			defer mu.Unlock()
+			result.StoredIDs = append(result.StoredIDs, unitResult.StoredIDs...)
+			result.FailedIDs = append(result.FailedIDs, unitResult.FailedIDs...)
+			result.StaleIDs = append(result.StaleIDs, unitResult.StaleIDs...)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}(unit)
+	}
+
+	wg.Wait()
+
+	if len(result.FailedIDs) > 0 {
+		return result, fmt.Errorf("failed to store %d of %d items in %s: %w", len(result.FailedIDs), len(items), e.options.TableName, errors.Join(errs...))
+	}
+
+	return result, nil
+}
+
+// writeBatch writes one batch, retrying failed calls and unprocessed items with exponential backoff
+func (e *StorageEngine) writeBatch(ctx context.Context, items []map[string]types.AttributeValue) (StorageResult, error) {
+	pending := lo.Map(items, func(item map[string]types.AttributeValue, _ int) types.WriteRequest {
+		return types.WriteRequest{PutRequest: &types.PutRequest{Item: item}}
+	})
+	var lastErr error
+
+	for attempt := 0; attempt <= e.options.MaxRetries && len(pending) > 0; attempt++ {
+		if attempt > 0 {
+			if err := e.sleep(ctx, e.backoff(attempt)); err != nil {
+				lastErr = err
+				break
+			}
+		}
+
+		output, err := e.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]types.WriteRequest{e.options.TableName: pending},
+		})
+		if err != nil {
+			lastErr = fmt.Errorf("failed to execute batch write: %w", err)
+			continue
+		}
+
+		pending = output.UnprocessedItems[e.options.TableName]
+		if len(pending) > 0 {
+			lastErr = fmt.Errorf("%d items left unprocessed", len(pending))
+		}
+	}
+
+	failedIDs := lo.Compact(lo.Map(pending, func(request types.WriteRequest, _ int) string {
+		return extractItemID(request.PutRequest.Item, e.options.KeyAttribute)
+	}))
+	failed := lo.SliceToMap(failedIDs, func(id string) (string, struct{}) { return id, struct{}{} })
+
+	storedIDs := lo.Filter(extractItemIDs(items, e.options.KeyAttribute), func(id string, _ int) bool {
+		_, isFailed := failed[id]
+		return !isFailed
+	})
+
+	result := StorageResult{StoredIDs: storedIDs, FailedIDs: failedIDs}
+	if len(pending) > 0 {
+		return result, lastErr
+	}
+	return result, nil
+}
+
+// writeConditional writes a single item unless a newer version is already stored
+// Items stored without a version, e.g. before conditional writes were enabled, are overwritten
+func (e *StorageEngine) writeConditional(ctx context.Context, items []map[string]types.AttributeValue) (StorageResult, error) {
+	result := StorageResult{}
+
+	for _, item := range items {
+		id := extractItemID(item, e.options.KeyAttribute)
+		version, hasVersion := item[e.options.VersionAttribute]
+		if !hasVersion {
+			result.FailedIDs = append(result.FailedIDs, id)
+			return result, fmt.Errorf("item %s has no %s attribute for a conditional write", id, e.options.VersionAttribute)
+		}
+
+		input := &dynamodb.PutItemInput{
+			TableName:           &e.options.TableName,
+			Item:                item,
+			ConditionExpression: lo.ToPtr("attribute_not_exists(#key) OR attribute_not_exists(#version) OR #version <= :version"),
+			ExpressionAttributeNames: map[string]string{
+				"#key":     e.options.KeyAttribute,
+				"#version": e.options.VersionAttribute,
+			},
+			ExpressionAttributeValues: map[string]types.AttributeValue{":version": version},
+		}
+
+		var conditionFailed *types.ConditionalCheckFailedException
+		err := e.retry(ctx, func() error {
+			_, err := e.client.PutItem(ctx, input)
+			return err
+		})
+		stale := errors.As(err, &conditionFailed)
+
+		switch {
+		case stale:
+			result.StaleIDs = append(result.StaleIDs, id)
+		case err != nil:
+			result.FailedIDs = append(result.FailedIDs, id)
+			return result, fmt.Errorf("failed to put item %s: %w", id, err)
+		default:
+			result.StoredIDs = append(result.StoredIDs, id)
+		}
+	}
+
+	return result, nil
+}
+
+// Tombstone marks every item not written by the current generation, nor by a run started after it, as deleted
+// Must only be called after a complete, successful scrape; already tombstoned items are left untouched
+func (e *StorageEngine) Tombstone(ctx context.Context, deletedAt time.Time) ([]TombstonedItem, error) {
+	if e.options.Generation == "" {
+		return nil, errors.New("tombstoning requires a scrape generation")
+	}
+
+	candidates, err := e.scanUnseen(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	unseen, names, values := e.unseenCondition()
+	values[":deleted"] = &types.AttributeValueMemberS{Value: deletedAt.UTC().Format(time.RFC3339)}
+	tombstoned := []TombstonedItem{}
+
+	for _, candidate := range candidates {
+		input := &dynamodb.UpdateItemInput{
+			TableName:        &e.options.TableName,
+			Key:              map[string]types.AttributeValue{e.options.KeyAttribute: &types.AttributeValueMemberS{Value: candidate.ID}},
+			UpdateExpression: lo.ToPtr("SET #deleted = :deleted"),
+			// Guards against a concurrent run re-writing the item between scan and update
+			ConditionExpression:       lo.ToPtr("attribute_exists(#key) AND " + unseen),
+			ExpressionAttributeNames:  names,
+			ExpressionAttributeValues: values,
+		}
+
+		var conditionFailed *types.ConditionalCheckFailedException
+		err := e.retry(ctx, func() error {
+			_, err := e.client.UpdateItem(ctx, input)
+			return err
+		})
+		switch {
+		case errors.As(err, &conditionFailed):
+			continue
+		case err != nil:
+			return tombstoned, fmt.Errorf("failed to tombstone item %s: %w", candidate.ID, err)
+		}
+
+		tombstoned = append(tombstoned, candidate)
+	}
+
+	return tombstoned, nil
+}
+
+// unseenCondition returns the condition matching live items whose generation differs from the current one
+// With a run start set, items last written by a later run are left alone: an older run finishing after a newer
+// one has not seen what the newer run wrote, including the writes of its own that were rejected as stale
+func (e *StorageEngine) unseenCondition() (string, map[string]string, map[string]types.AttributeValue) {
+	condition := "attribute_not_exists(#deleted) AND (attribute_not_exists(#generation) OR #generation <> :generation)"
+	names := map[string]string{
+		"#key":        e.options.KeyAttribute,
+		"#deleted":    DeletedAtAttribute,
+		"#generation": GenerationAttribute,
+	}
+	values := map[string]types.AttributeValue{":generation": &types.AttributeValueMemberS{Value: e.options.Generation}}
+
+	if !e.options.StartedAt.IsZero() {
+		condition += " AND (attribute_not_exists(#started) OR #started <= :startedAt)"
+		names["#started"] = StartedAtAttribute
+		values[":startedAt"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(e.options.StartedAt.UnixNano(), 10)}
+	}
+	return condition, names, values
+}
+
+// scanUnseen lists the live items matching unseenCondition
+func (e *StorageEngine) scanUnseen(ctx context.Context) ([]TombstonedItem, error) {
+	filter, names, values := e.unseenCondition()
+	projection := "#key"
+	if e.options.NameAttribute != "" {
+		names["#name"] = e.options.NameAttribute
+		projection = "#key, #name"
+	}
+
+	var (
+		candidates []TombstonedItem
+		startKey   map[string]types.AttributeValue
+	)
+
+	for {
+		input := &dynamodb.ScanInput{
+			TableName:                 &e.options.TableName,
+			FilterExpression:          &filter,
+			ProjectionExpression:      &projection,
+			ExpressionAttributeNames:  names,
+			ExpressionAttributeValues: values,
+			ExclusiveStartKey:         startKey,
+		}
+
+		var output *dynamodb.ScanOutput
+		err := e.retry(ctx, func() error {
+			var err error
+			output, err = e.client.Scan(ctx, input)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", e.options.TableName, err)
+		}
+
+		candidates = append(candidates, lo.FilterMap(output.Items, func(item map[string]types.AttributeValue, _ int) (TombstonedItem, bool) {
+			id := extractItemID(item, e.options.KeyAttribute)
+			return TombstonedItem{ID: id, Name: extractItemID(item, e.options.NameAttribute)}, id != ""
+		})...)
+
+		if len(output.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = output.LastEvaluatedKey
+	}
+
+	return candidates, nil
+}
+
+// stampRun returns a copy of the item carrying the current generation and run start
+// The start is stored as a number so runs compare in time order
+func (e *StorageEngine) stampRun(item map[string]types.AttributeValue, _ int) map[string]types.AttributeValue {
+	stamps := map[string]types.AttributeValue{}
+	if e.options.Generation != "" {
+		stamps[GenerationAttribute] = &types.AttributeValueMemberS{Value: e.options.Generation}
+	}
+	if !e.options.StartedAt.IsZero() {
+		stamps[StartedAtAttribute] = &types.AttributeValueMemberN{Value: strconv.FormatInt(e.options.StartedAt.UnixNano(), 10)}
+	}
+	return lo.Ternary(len(stamps) == 0, item, lo.Assign(item, stamps))
+}
+
+// retry runs a DynamoDB call with exponential backoff
+// Failed condition checks are final and returned without retrying
+func (e *StorageEngine) retry(ctx context.Context, call func() error) error {
+	var err error
+	for attempt := 0; attempt <= e.options.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if sleepErr := e.sleep(ctx, e.backoff(attempt)); sleepErr != nil {
+				return sleepErr
+			}
+		}
+
+		err = call()
+		var conditionFailed *types.ConditionalCheckFailedException
+		if err == nil || errors.As(err, &conditionFailed) {
+			return err
+		}
+	}
+	return err
+}
+
+// backoff returns the exponential retry delay for an attempt, capped at maxStorageBackoff
+func (e *StorageEngine) backoff(attempt int) time.Duration {
+	delay := e.options.BaseBackoff << (attempt - 1)
+	return lo.Ternary(delay > 0 && delay < maxStorageBackoff, delay, maxStorageBackoff)
+}
+
+// sleepWithContext waits for the given duration unless the context is cancelled first
+func sleepWithContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// extractItemIDs extracts the key attribute of every item
+// Pure function; items without a string key are dropped
+func extractItemIDs(items []map[string]types.AttributeValue, keyAttribute string) []string {
+	return lo.Compact(lo.Map(items, func(item map[string]types.AttributeValue, _ int) string {
+		return extractItemID(item, keyAttribute)
+	}))
+}
+
+// extractItemID extracts the string key attribute of an item
+// Pure function returning an empty string when the key is missing
+func extractItemID(item map[string]types.AttributeValue, keyAttribute string) string {
+	if s, ok := item[keyAttribute].(*types.AttributeValueMemberS); ok {
+		return s.Value
+	}
+	return ""
+}
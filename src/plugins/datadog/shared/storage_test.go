@@ -0,0 +1,403 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDynamoDB is an in-memory DynamoDBWriteAPI with scriptable failures
+type fakeDynamoDB struct {
+	mu            sync.Mutex
+	stored        map[string]map[string]types.AttributeValue
+	batchCalls    int32
+	inFlight      int32
+	maxInFlight   int32
+	unprocessOnce map[string]bool // IDs returned as unprocessed on their first write
+	alwaysFail    map[string]bool // IDs that are never processed
+	batchErrors   int32           // number of BatchWriteItem calls that fail outright
+	rewritten     map[string]bool // IDs re-written by a concurrent run between scan and update
+	scanPages     int
+}
+
+func newFakeDynamoDB() *fakeDynamoDB {
+	return &fakeDynamoDB{
+		stored:        make(map[string]map[string]types.AttributeValue),
+		unprocessOnce: make(map[string]bool),
+		alwaysFail:    make(map[string]bool),
+		rewritten:     make(map[string]bool),
+	}
+}
+
+func (f *fakeDynamoDB) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	atomic.AddInt32(&f.batchCalls, 1)
+	current := atomic.AddInt32(&f.inFlight, 1)
+	defer atomic.AddInt32(&f.inFlight, -1)
+	for {
+		peak := atomic.LoadInt32(&f.maxInFlight)
+		if current <= peak || atomic.CompareAndSwapInt32(&f.maxInFlight, peak, current) {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond)
+
+	if atomic.AddInt32(&f.batchErrors, -1) >= 0 {
+		return nil, errors.New("ProvisionedThroughputExceededException")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	unprocessed := map[string][]types.WriteRequest{}
+	for table, requests := range params.RequestItems {
+		for _, request := range requests {
+			id := extractItemID(request.PutRequest.Item, "id")
+			if f.alwaysFail[id] || f.unprocessOnce[id] {
+				delete(f.unprocessOnce, id)
+				unprocessed[table] = append(unprocessed[table], request)
+				continue
+			}
+			f.stored[id] = request.PutRequest.Item
+		}
+	}
+
+	return &dynamodb.BatchWriteItemOutput{UnprocessedItems: unprocessed}, nil
+}
+
+func (f *fakeDynamoDB) PutItem(ctx context.Context, params *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := extractItemID(params.Item, "id")
+	if f.alwaysFail[id] {
+		return nil, errors.New("InternalServerError")
+	}
+
+	if existing, ok := f.stored[id]; ok {
+		stored, versioned := existing[params.ExpressionAttributeNames["#version"]]
+		if versioned && newerVersion(stored, params.ExpressionAttributeValues[":version"]) {
+			return nil, &types.ConditionalCheckFailedException{Message: params.ConditionExpression}
+		}
+	}
+
+	f.stored[id] = params.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+// newerVersion reports whether a stored version is newer than an incoming one, comparing numbers numerically
+func newerVersion(stored, incoming types.AttributeValue) bool {
+	if number, ok := stored.(*types.AttributeValueMemberN); ok {
+		storedValue, _ := strconv.ParseInt(number.Value, 10, 64)
+		incomingValue, _ := strconv.ParseInt(incoming.(*types.AttributeValueMemberN).Value, 10, 64)
+		return storedValue > incomingValue
+	}
+	return stored.(*types.AttributeValueMemberS).Value > incoming.(*types.AttributeValueMemberS).Value
+}
+
+// unseen reports whether a stored item matches the unseen condition of a scan or update
+func unseen(item map[string]types.AttributeValue, values map[string]types.AttributeValue) bool {
+	_, deleted := item[DeletedAtAttribute]
+	generation := values[":generation"].(*types.AttributeValueMemberS).Value
+	started, stamped := item[StartedAtAttribute]
+	startedAt, guarded := values[":startedAt"]
+	return !deleted && extractItemID(item, GenerationAttribute) != generation &&
+		(!guarded || !stamped || !newerVersion(started, startedAt))
+}
+
+// Scan returns unseen items two at a time, ordered by ID
+func (f *fakeDynamoDB) Scan(ctx context.Context, params *dynamodb.ScanInput, _ ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.scanPages++
+
+	start := extractItemID(params.ExclusiveStartKey, "id")
+
+	ids := lo.Filter(lo.Keys(f.stored), func(id string, _ int) bool {
+		return id > start && unseen(f.stored[id], params.ExpressionAttributeValues)
+	})
+	sort.Strings(ids)
+
+	page := lo.Subset(ids, 0, 2)
+	output := &dynamodb.ScanOutput{Items: lo.Map(page, func(id string, _ int) map[string]types.AttributeValue {
+		return lo.PickByKeys(f.stored[id], []string{"id", "name"})
+	})}
+	if len(ids) > len(page) {
+		output.LastEvaluatedKey = map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: page[len(page)-1]}}
+	}
+	return output, nil
+}
+
+// UpdateItem sets deleted_at unless the item was re-written in the meantime
+func (f *fakeDynamoDB) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := extractItemID(params.Key, "id")
+	if f.alwaysFail[id] {
+		return nil, errors.New("InternalServerError")
+	}
+	if f.rewritten[id] || !unseen(f.stored[id], params.ExpressionAttributeValues) {
+		return nil, &types.ConditionalCheckFailedException{Message: params.ConditionExpression}
+	}
+
+	f.stored[id] = lo.Assign(f.stored[id], map[string]types.AttributeValue{DeletedAtAttribute: params.ExpressionAttributeValues[":deleted"]})
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+// createTestItems creates storage items keyed by "id" with a scraped_at version
+func createTestItems(count int, scrapedAt string) []map[string]types.AttributeValue {
+	items := make([]map[string]types.AttributeValue, count)
+	for i := range items {
+		items[i] = map[string]types.AttributeValue{
+			"id":               &types.AttributeValueMemberS{Value: fmt.Sprintf("item-%03d", i)},
+			ScrapedAtAttribute: &types.AttributeValueMemberS{Value: scrapedAt},
+		}
+	}
+	return items
+}
+
+// newTestStorageEngine creates an engine that never sleeps between retries
+func newTestStorageEngine(client DynamoDBWriteAPI, options StorageOptions) *StorageEngine {
+	options.TableName = "test-table"
+	options.KeyAttribute = "id"
+	engine := NewStorageEngine(client, options)
+	engine.sleep = func(context.Context, time.Duration) error { return nil }
+	return engine
+}
+
+func TestStorageEngine_Write(t *testing.T) {
+	t.Run("retries unprocessed items", func(t *testing.T) {
+		client := newFakeDynamoDB()
+		client.unprocessOnce["item-003"] = true
+		client.unprocessOnce["item-030"] = true
+
+		result, err := newTestStorageEngine(client, StorageOptions{MaxRetries: 2}).Write(context.Background(), createTestItems(60, "2024-01-01T00:00:00Z"))
+
+		require.NoError(t, err)
+		assert.Len(t, result.StoredIDs, 60)
+		assert.Empty(t, result.FailedIDs)
+		assert.Len(t, client.stored, 60)
+		assert.Equal(t, int32(5), atomic.LoadInt32(&client.batchCalls), "3 batches plus one retry for each batch with unprocessed items")
+	})
+
+	t.Run("reports exact failed IDs", func(t *testing.T) {
+		client := newFakeDynamoDB()
+		client.alwaysFail["item-007"] = true
+
+		result, err := newTestStorageEngine(client, StorageOptions{MaxRetries: 2}).Write(context.Background(), createTestItems(30, "2024-01-01T00:00:00Z"))
+
+		require.Error(t, err)
+		assert.Equal(t, []string{"item-007"}, result.FailedIDs)
+		assert.Len(t, result.StoredIDs, 29)
+		assert.NotContains(t, result.StoredIDs, "item-007")
+	})
+
+	t.Run("failed batches do not hide stored batches", func(t *testing.T) {
+		client := newFakeDynamoDB()
+		client.batchErrors = 100
+
+		result, err := newTestStorageEngine(client, StorageOptions{MaxRetries: 1}).Write(context.Background(), createTestItems(10, "2024-01-01T00:00:00Z"))
+
+		require.Error(t, err)
+		assert.Empty(t, result.StoredIDs)
+		assert.Len(t, result.FailedIDs, 10)
+	})
+
+	t.Run("bounds parallel batches", func(t *testing.T) {
+		client := newFakeDynamoDB()
+
+		result, err := newTestStorageEngine(client, StorageOptions{Parallelism: 2, BatchSize: 5}).Write(context.Background(), createTestItems(100, "2024-01-01T00:00:00Z"))
+
+		require.NoError(t, err)
+		assert.Len(t, result.StoredIDs, 100)
+		assert.LessOrEqual(t, atomic.LoadInt32(&client.maxInFlight), int32(2))
+	})
+
+	t.Run("conditional writes skip stale items", func(t *testing.T) {
+		client := newFakeDynamoDB()
+		engine := newTestStorageEngine(client, StorageOptions{VersionAttribute: ScrapedAtAttribute})
+
+		_, err := engine.Write(context.Background(), createTestItems(3, "2024-02-01T00:00:00Z"))
+		require.NoError(t, err)
+
+		older := createTestItems(4, "2024-01-01T00:00:00Z")
+		result, err := engine.Write(context.Background(), older)
+
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"item-000", "item-001", "item-002"}, result.StaleIDs)
+		assert.Equal(t, []string{"item-003"}, result.StoredIDs)
+		assert.Equal(t, "2024-02-01T00:00:00Z", client.stored["item-000"][ScrapedAtAttribute].(*types.AttributeValueMemberS).Value)
+	})
+
+	t.Run("an older run writing late loses to a newer run", func(t *testing.T) {
+		client := newFakeDynamoDB()
+		older := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+		newer := older.Add(time.Hour)
+
+		_, err := newTestStorageEngine(client, StorageOptions{VersionAttribute: StartedAtAttribute, StartedAt: newer}).Write(context.Background(), createTestItems(2, "2024-01-01T10:05:00Z"))
+		require.NoError(t, err)
+
+		// The slow run built its items last, so only the run start tells it is stale
+		result, err := newTestStorageEngine(client, StorageOptions{VersionAttribute: StartedAtAttribute, StartedAt: older}).Write(context.Background(), createTestItems(3, "2024-01-01T10:10:00Z"))
+
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"item-000", "item-001"}, result.StaleIDs)
+		assert.Equal(t, []string{"item-002"}, result.StoredIDs)
+		assert.Equal(t, "2024-01-01T10:05:00Z", client.stored["item-000"][ScrapedAtAttribute].(*types.AttributeValueMemberS).Value)
+	})
+
+	t.Run("conditional writes overwrite items stored without a version", func(t *testing.T) {
+		client := newFakeDynamoDB()
+		_, err := newTestStorageEngine(client, StorageOptions{}).Write(context.Background(), createTestItems(1, "2024-02-01T00:00:00Z"))
+		require.NoError(t, err)
+
+		result, err := newTestStorageEngine(client, StorageOptions{VersionAttribute: StartedAtAttribute, StartedAt: time.Now()}).Write(context.Background(), createTestItems(1, "2024-01-01T00:00:00Z"))
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"item-000"}, result.StoredIDs)
+		assert.Contains(t, client.stored["item-000"], StartedAtAttribute)
+	})
+
+	t.Run("conditional writes require a version", func(t *testing.T) {
+		engine := newTestStorageEngine(newFakeDynamoDB(), StorageOptions{VersionAttribute: "missing"})
+
+		result, err := engine.Write(context.Background(), createTestItems(1, "2024-01-01T00:00:00Z"))
+
+		require.Error(t, err)
+		assert.Equal(t, []string{"item-000"}, result.FailedIDs)
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		result, err := newTestStorageEngine(newFakeDynamoDB(), StorageOptions{}).Write(context.Background(), nil)
+
+		require.NoError(t, err)
+		assert.Empty(t, result.StoredIDs)
+	})
+}
+
+func TestStorageEngine_Tombstone(t *testing.T) {
+	deletedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("marks items missing from the generation", func(t *testing.T) {
+		client := newFakeDynamoDB()
+		_, err := newTestStorageEngine(client, StorageOptions{Generation: "run-1"}).Write(context.Background(), createTestItems(5, "2024-01-01T00:00:00Z"))
+		require.NoError(t, err)
+
+		engine := newTestStorageEngine(client, StorageOptions{Generation: "run-2"})
+		_, err = engine.Write(context.Background(), createTestItems(2, "2024-02-01T00:00:00Z"))
+		require.NoError(t, err)
+
+		tombstoned, err := engine.Tombstone(context.Background(), deletedAt)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"item-002", "item-003", "item-004"}, lo.Map(tombstoned, func(item TombstonedItem, _ int) string { return item.ID }))
+		assert.Equal(t, 2, client.scanPages, "Scan should follow LastEvaluatedKey")
+		assert.Equal(t, "2024-03-01T12:00:00Z", extractItemID(client.stored["item-004"], DeletedAtAttribute))
+		assert.NotContains(t, client.stored["item-001"], DeletedAtAttribute)
+		assert.Equal(t, "run-2", extractItemID(client.stored["item-001"], GenerationAttribute))
+
+		again, err := engine.Tombstone(context.Background(), deletedAt)
+		require.NoError(t, err)
+		assert.Empty(t, again, "Already tombstoned items should be left untouched")
+	})
+
+	t.Run("rewriting a tombstoned item revives it", func(t *testing.T) {
+		client := newFakeDynamoDB()
+		_, err := newTestStorageEngine(client, StorageOptions{Generation: "run-1"}).Write(context.Background(), createTestItems(1, "2024-01-01T00:00:00Z"))
+		require.NoError(t, err)
+		_, err = newTestStorageEngine(client, StorageOptions{Generation: "run-2"}).Tombstone(context.Background(), deletedAt)
+		require.NoError(t, err)
+
+		_, err = newTestStorageEngine(client, StorageOptions{Generation: "run-3"}).Write(context.Background(), createTestItems(1, "2024-04-01T00:00:00Z"))
+
+		require.NoError(t, err)
+		assert.NotContains(t, client.stored["item-000"], DeletedAtAttribute)
+	})
+
+	t.Run("an older run never tombstones what a newer run wrote", func(t *testing.T) {
+		client := newFakeDynamoDB()
+		older := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+		newer := older.Add(time.Minute)
+		_, err := newTestStorageEngine(client, StorageOptions{Generation: "run-0", StartedAt: older.Add(-time.Hour), VersionAttribute: StartedAtAttribute}).Write(context.Background(), createTestItems(3, "2024-01-01T00:00:00Z"))
+		require.NoError(t, err)
+
+		// The newer run writes two items, then the older run finishes with a write rejected as stale
+		_, err = newTestStorageEngine(client, StorageOptions{Generation: "run-2", StartedAt: newer, VersionAttribute: StartedAtAttribute}).Write(context.Background(), createTestItems(2, "2024-03-01T09:01:00Z"))
+		require.NoError(t, err)
+		engine := newTestStorageEngine(client, StorageOptions{Generation: "run-1", StartedAt: older, VersionAttribute: StartedAtAttribute})
+		_, err = engine.Write(context.Background(), createTestItems(1, "2024-03-01T09:00:00Z"))
+		require.NoError(t, err)
+
+		tombstoned, err := engine.Tombstone(context.Background(), deletedAt)
+
+		require.NoError(t, err)
+		assert.Equal(t, []TombstonedItem{{ID: "item-002"}}, tombstoned)
+		assert.NotContains(t, client.stored["item-000"], DeletedAtAttribute)
+		assert.NotContains(t, client.stored["item-001"], DeletedAtAttribute)
+	})
+
+	t.Run("items re-written concurrently are skipped", func(t *testing.T) {
+		client := newFakeDynamoDB()
+		_, err := newTestStorageEngine(client, StorageOptions{Generation: "run-1"}).Write(context.Background(), createTestItems(2, "2024-01-01T00:00:00Z"))
+		require.NoError(t, err)
+		client.rewritten["item-000"] = true
+
+		tombstoned, err := newTestStorageEngine(client, StorageOptions{Generation: "run-2"}).Tombstone(context.Background(), deletedAt)
+
+		require.NoError(t, err)
+		assert.Equal(t, []TombstonedItem{{ID: "item-001"}}, tombstoned)
+	})
+
+	t.Run("update failures stop the sweep", func(t *testing.T) {
+		client := newFakeDynamoDB()
+		_, err := newTestStorageEngine(client, StorageOptions{Generation: "run-1"}).Write(context.Background(), createTestItems(3, "2024-01-01T00:00:00Z"))
+		require.NoError(t, err)
+		client.alwaysFail["item-001"] = true
+
+		tombstoned, err := newTestStorageEngine(client, StorageOptions{Generation: "run-2", MaxRetries: 1}).Tombstone(context.Background(), deletedAt)
+
+		require.Error(t, err)
+		assert.Equal(t, []TombstonedItem{{ID: "item-000"}}, tombstoned)
+	})
+
+	t.Run("requires a generation", func(t *testing.T) {
+		_, err := newTestStorageEngine(newFakeDynamoDB(), StorageOptions{}).Tombstone(context.Background(), deletedAt)
+		assert.Error(t, err)
+	})
+}
+
+func TestStorageEngine_Backoff(t *testing.T) {
+	engine := NewStorageEngine(newFakeDynamoDB(), StorageOptions{BaseBackoff: 100 * time.Millisecond})
+
+	assert.Equal(t, 100*time.Millisecond, engine.backoff(1))
+	assert.Equal(t, 400*time.Millisecond, engine.backoff(3))
+	assert.Equal(t, maxStorageBackoff, engine.backoff(20))
+	assert.Equal(t, maxStorageBackoff, engine.backoff(100), "Shift overflow should be capped")
+}
+
+func TestDefaultStorageOptions(t *testing.T) {
+	startedAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Setenv("DATADOG_CONDITIONAL_WRITES", "")
+	options := DefaultStorageOptions("table", "team_id", "handle", "gen-1", startedAt)
+	assert.Equal(t, StartedAtAttribute, options.VersionAttribute)
+	assert.Equal(t, startedAt, options.StartedAt)
+	assert.Equal(t, "team_id", options.KeyAttribute)
+	assert.Equal(t, "handle", options.NameAttribute)
+	assert.Equal(t, "gen-1", options.Generation)
+
+	t.Setenv("DATADOG_CONDITIONAL_WRITES", "false")
+	assert.Equal(t, "", DefaultStorageOptions("table", "team_id", "handle", "gen-1", startedAt).VersionAttribute)
+}
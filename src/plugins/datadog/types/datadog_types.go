@@ -3,6 +3,8 @@ package types
 
 import (
 	"time"
+
+	"bacon/src/shared/scraperoutput"
 )
 
 // DatadogTeam represents a team from the Datadog Teams API v2
@@ -32,6 +34,7 @@ type DatadogUser struct {
 	Disabled bool      `json:"disabled"`
 	Title    string    `json:"title"`
 	Icon     string    `json:"icon"`
+	TeamRole string    `json:"team_role,omitempty"` // Role within a team, set only for team members
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -41,9 +44,12 @@ type DatadogService struct {
 	ID            string                 `json:"id"`
 	Name          string                 `json:"name"`
 	Owner         string                 `json:"owner"`
+	OwnerSource   string                 `json:"owner_source,omitempty"` // how Owner was resolved, e.g. "slack_channel:#payments"
 	Teams         []string               `json:"teams"`
 	Tags          []string               `json:"tags"`
-	Schema        string                 `json:"schema_version"`
+	SchemaVersion string                 `json:"schema_version"` // Schema version the definition was parsed from
+	Kind          string                 `json:"kind"`           // Entity kind; always "service" before v3
+	Application   string                 `json:"application"`
 	Description   string                 `json:"description"`
 	Tier          string                 `json:"tier"`
 	Lifecycle     string                 `json:"lifecycle"`
@@ -51,8 +57,10 @@ type DatadogService struct {
 	Languages     []string               `json:"languages"`
 	Contacts      []DatadogContact       `json:"contacts"`
 	Links         []DatadogServiceLink   `json:"links"`
+	Repos         []DatadogServiceLink   `json:"repos"`
 	Integrations  map[string]interface{} `json:"integrations"`
 	Dependencies  []string               `json:"dependencies"`
+	ComponentOf   []string               `json:"component_of"` // Parent systems from v3 componentOf
 	CreatedAt     time.Time              `json:"created_at"`
 	UpdatedAt     time.Time              `json:"updated_at"`
 }
@@ -90,8 +98,47 @@ type DatadogServiceLink struct {
 	URL  string `json:"url"`
 }
 
+// DatadogMonitor represents a monitor or SLO together with its alert routing
+type DatadogMonitor struct {
+	ID       string                      `json:"id"`
+	Kind     string                      `json:"kind"` // "monitor" or "slo"
+	Name     string                      `json:"name"`
+	Type     string                      `json:"type"`
+	Tags     []string                    `json:"tags"`
+	Teams    []string                    `json:"teams"`
+	Services []string                    `json:"services"`
+	Handles  []DatadogNotificationHandle `json:"handles"`
+}
+
+// DatadogNotificationHandle represents an @-mention in a monitor message that routes alerts
+type DatadogNotificationHandle struct {
+	Type   string `json:"type"` // "slack", "pagerduty", "email"
+	Target string `json:"target"`
+}
+
+// DatadogHost represents an infrastructure host or container reporting to Datadog
+type DatadogHost struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	Kind          string   `json:"kind"` // "host" or "container"
+	CloudProvider string   `json:"cloud_provider,omitempty"`
+	InstanceID    string   `json:"instance_id,omitempty"`
+	ResourceKey   string   `json:"resource_key,omitempty"` // EC2 ARN or "Kind/name" k8s workload
+	Env           string   `json:"env,omitempty"`
+	Teams         []string `json:"teams"`
+	Owners        []string `json:"owners"`
+	Services      []string `json:"services"`
+	Tags          []string `json:"tags"`
+}
+
+// DatadogServiceDependency represents the downstream calls of a service in the APM service map
+type DatadogServiceDependency struct {
+	Calls []string `json:"calls"`
+}
+
 // DatadogTeamSnapshot represents a complete snapshot of all team-related data
 type DatadogTeamSnapshot struct {
+	ID            string                `json:"id"` // generation of the run that assembled the snapshot
 	Teams         []DatadogTeam         `json:"teams"`
 	Users         []DatadogUser         `json:"users"`
 	Services      []DatadogService      `json:"services"`
@@ -102,15 +149,36 @@ type DatadogTeamSnapshot struct {
 	TotalServices int                   `json:"total_services"`
 }
 
+// DatadogChangeEvent represents a single change between two consecutive snapshots
+// Added values are carried in After, removed values in Before
+type DatadogChangeEvent struct {
+	Type       string `json:"type"`        // e.g. "member_joined", "owner_changed"
+	EntityKind string `json:"entity_kind"` // "team", "user", "service" or "organization"
+	EntityID   string `json:"entity_id"`
+	EntityName string `json:"entity_name"` // graph name: team handle, user email or service name
+	Field      string `json:"field,omitempty"`
+	Before     string `json:"before,omitempty"`
+	After      string `json:"after,omitempty"`
+}
+
+// DatadogSnapshotDiff represents the structured diff between two consecutive snapshots
+type DatadogSnapshotDiff struct {
+	PreviousSnapshotID string               `json:"previous_snapshot_id,omitempty"`
+	SnapshotID         string               `json:"snapshot_id"`
+	Changes            []DatadogChangeEvent `json:"changes"`
+}
+
 // ScraperEvent represents the input event for individual scraper Lambda functions
 type ScraperEvent struct {
 	FilterKeyword    string                 `json:"filter_keyword,omitempty"`
+	Filter           string                 `json:"filter,omitempty"` // expression evaluated against transformed entities, e.g. tier in ("1","2")
 	PageSize         int                    `json:"page_size,omitempty"`
 	SchemaVersion    string                 `json:"schema_version,omitempty"`
 	IncludeInactive  bool                   `json:"include_inactive,omitempty"`
 	TeamID           string                 `json:"team_id,omitempty"`
 	OrganizationID   string                 `json:"organization_id,omitempty"`
 	ExtraParameters  map[string]interface{} `json:"extra_parameters,omitempty"`
+	RunID            string                 `json:"run_id,omitempty"` // generation stamp used for deletion detection
 }
 
 // ScraperResponse represents the output response from individual scraper Lambda functions
@@ -121,6 +189,18 @@ type ScraperResponse struct {
 	Timestamp   string                 `json:"timestamp"`
 	ExecutionID string                 `json:"execution_id"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Outputs     []ScraperOutput        `json:"scraper_outputs,omitempty"`
+}
+
+// ScraperOutput represents relationship data handed to the relationship-finding processor
+type ScraperOutput = scraperoutput.Envelope
+
+// DatadogRelationship represents a directed edge between two entities discovered in Datadog
+type DatadogRelationship struct {
+	From       string  `json:"from"`
+	To         string  `json:"to"`
+	Type       string  `json:"type"`
+	Confidence float64 `json:"confidence,omitempty"` // overrides the output confidence when set
 }
 
 // OrchestrationEvent represents the input event for the orchestrator Lambda
@@ -139,4 +219,16 @@ type OrchestrationResponse struct {
 	TriggeredJobs []string               `json:"triggered_jobs"`
 	Timestamp     time.Time              `json:"timestamp"`
 	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+	Jobs          []OrchestrationJobStatus `json:"jobs,omitempty"`
+	Outputs       []ScraperOutput          `json:"scraper_outputs,omitempty"` // outputs of every successful job
+}
+
+// OrchestrationJobStatus reports the outcome of a single scraper run by the orchestrator
+type OrchestrationJobStatus struct {
+	Name       string           `json:"name"`
+	Status     string           `json:"status"` // "success", "error", "skipped"
+	DependsOn  []string         `json:"depends_on,omitempty"`
+	Response   *ScraperResponse `json:"response,omitempty"`
+	Error      string           `json:"error,omitempty"`
+	DurationMs int64            `json:"duration_ms"`
 }
\ No newline at end of file
@@ -44,12 +44,42 @@ func (c *Client) FetchRepositories(ctx context.Context, org string, batchSize in
 	return c.executeGraphQLQuery(ctx, payload)
 }
 
+// FetchTeams lists one page of the organization's teams with their members
+func (c *Client) FetchTeams(ctx context.Context, org string, batchSize int, cursor string) ([]types.Team, bool, string, error) {
+	variables := map[string]interface{}{
+		"org":   org,
+		"first": batchSize,
+	}
+	if cursor != "" {
+		variables["after"] = cursor
+	}
+
+	body, err := c.postGraphQL(ctx, map[string]interface{}{
+		"query":     teamsQuery,
+		"variables": variables,
+	})
+	if err != nil {
+		return nil, false, "", err
+	}
+
+	return parseTeamsResponse(body)
+}
+
 func (c *Client) executeGraphQLQuery(ctx context.Context, payload map[string]interface{}) ([]types.Repository, bool, string, error) {
+	body, err := c.postGraphQL(ctx, payload)
+	if err != nil {
+		return nil, false, "", err
+	}
+
+	return parseGraphQLResponse(body)
+}
+
+func (c *Client) postGraphQL(ctx context.Context, payload map[string]interface{}) ([]byte, error) {
 	payloadBytes, _ := json.Marshal(payload)
 	
 	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.github.com/graphql", bytes.NewBuffer(payloadBytes))
 	if err != nil {
-		return nil, false, "", err
+		return nil, err
 	}
 
 	req.Header.Set("Authorization", "Bearer "+c.token)
@@ -57,16 +87,11 @@ func (c *Client) executeGraphQLQuery(ctx context.Context, payload map[string]int
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, false, "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, false, "", err
-	}
-
-	return parseGraphQLResponse(body)
+	return io.ReadAll(resp.Body)
 }
 
 func parseGraphQLResponse(body []byte) ([]types.Repository, bool, string, error) {
@@ -102,6 +127,73 @@ func parseGraphQLResponse(body []byte) ([]types.Repository, bool, string, error)
 	return repos, hasNext, nextCursor, nil
 }
 
+func parseTeamsResponse(body []byte) ([]types.Team, bool, string, error) {
+	var response struct {
+		Data struct {
+			Organization struct {
+				Teams struct {
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+					Nodes []struct {
+						Slug    string `json:"slug"`
+						Name    string `json:"name"`
+						Members struct {
+							Nodes []types.Member `json:"nodes"`
+						} `json:"members"`
+					} `json:"nodes"`
+				} `json:"teams"`
+			} `json:"organization"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, false, "", err
+	}
+
+	if len(response.Errors) > 0 {
+		return nil, false, "", fmt.Errorf("GitHub API error: %s", response.Errors[0].Message)
+	}
+
+	teams := make([]types.Team, 0, len(response.Data.Organization.Teams.Nodes))
+	for _, node := range response.Data.Organization.Teams.Nodes {
+		teams = append(teams, types.Team{Slug: node.Slug, Name: node.Name, Members: node.Members.Nodes})
+	}
+	hasNext := response.Data.Organization.Teams.PageInfo.HasNextPage
+	nextCursor := response.Data.Organization.Teams.PageInfo.EndCursor
+
+	return teams, hasNext, nextCursor, nil
+}
+
+// teamsQuery lists teams with up to 100 members each; larger teams are rare enough not to page
+const teamsQuery = `
+	query GetTeamsWithMembers($org: String!, $first: Int!, $after: String) {
+		organization(login: $org) {
+			teams(first: $first, after: $after) {
+				pageInfo {
+					hasNextPage
+					endCursor
+				}
+				nodes {
+					slug
+					name
+					members(first: 100) {
+						nodes {
+							login
+							name
+							email
+						}
+					}
+				}
+			}
+		}
+	}
+`
+
 func buildGraphQLQuery(batchSize int) string {
 	return fmt.Sprintf(`
 		query GetRepositoriesWithCodeowners($org: String!, $first: Int!, $after: String) {
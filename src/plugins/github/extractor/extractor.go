@@ -0,0 +1,39 @@
+// Package extractor registers the GitHub sources with the relationship processor
+package extractor
+
+import (
+	"strings"
+
+	"bacon/src/shared/relationship-finding/extractors"
+	"bacon/src/shared/scraperoutput"
+)
+
+const (
+	SourceGitHubCodeowners = "github-codeowners"
+	SourceGitHubActivity   = "github-activity"
+	SourceGitHubTeams      = "github-teams" // identity directory of teams and their members
+)
+
+func init() {
+	extractors.MustRegister(
+		extractors.Registration{Source: SourceGitHubCodeowners, Extractor: extractors.ExtractorFunc(ExtractCodeowners), Weight: 0.8, Priority: 3},
+		extractors.Registration{Source: SourceGitHubActivity, Extractor: extractors.Edges, Weight: 0.6, Priority: 3},
+		// Teams only teach the processor the names teams and members go by
+		extractors.Registration{Source: SourceGitHubTeams, Weight: 1.0, Priority: 3},
+	)
+}
+
+// ExtractCodeowners maps every owner of a CODEOWNERS entry to an owns edge on its path
+func ExtractCodeowners(output scraperoutput.Envelope) []extractors.Relationship {
+	var relationships []extractors.Relationship
+
+	for _, entry := range output.Payload.Codeowners {
+		for _, owner := range entry.Owners {
+			rel := extractors.NewRelationship(output, strings.TrimPrefix(owner, "@"), entry.Path, "owns")
+			rel.ResourceType = extractors.ResourceTypeRepository
+			relationships = append(relationships, rel)
+		}
+	}
+
+	return relationships
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -13,8 +14,10 @@ import (
 
 	"bacon/src/plugins/github/cache"
 	"bacon/src/plugins/github/clients"
+	"bacon/src/plugins/github/extractor"
 	"bacon/src/plugins/github/types"
 	common "bacon/src/shared"
+	"bacon/src/shared/scraperoutput"
 )
 
 func HandleRequest(ctx context.Context, event types.Event) (string, error) {
@@ -104,6 +107,83 @@ func buildOwnershipDataStep(event types.Event) (types.Event, error) {
 	return event, nil
 }
 
+// createScraperOutput flattens the CODEOWNERS entries of a batch into the envelope consumed by the relationship processor
+func createScraperOutput(ownership types.OwnershipData) scraperoutput.Envelope {
+	var entries []scraperoutput.CodeownersEntry
+	for _, repo := range ownership.Repositories {
+		for _, entry := range repo.Entries {
+			repository := entry.Repository
+			if repository == "" {
+				repository = repo.Repository
+			}
+			entries = append(entries, scraperoutput.CodeownersEntry{
+				Repository: repository,
+				Path:       entry.Path,
+				Owners:     entry.Owners,
+			})
+		}
+	}
+
+	producedAt, err := time.Parse(time.RFC3339, ownership.Timestamp)
+	if err != nil {
+		producedAt = time.Now()
+	}
+	return scraperoutput.New(ownership.Source, ownership.Confidence, producedAt, scraperoutput.Codeowners(entries))
+}
+
+// createRepositorySnapshots outputs the CODEOWNERS entries of each repository of a batch on its own, as a
+// snapshot of the repository: its CODEOWNERS file lists every owner, so entries it no longer has are retracted
+func createRepositorySnapshots(ownership types.OwnershipData) []scraperoutput.Envelope {
+	snapshots := make([]scraperoutput.Envelope, 0, len(ownership.Repositories))
+	for _, repo := range ownership.Repositories {
+		envelope := createScraperOutput(types.OwnershipData{
+			Organization: ownership.Organization,
+			Repositories: []types.RepoOwnership{repo},
+			Timestamp:    ownership.Timestamp,
+			Source:       ownership.Source,
+			Confidence:   ownership.Confidence,
+		})
+
+		// The CODEOWNERS hash names the revision the snapshot was taken of
+		id := repo.CodeownersHash
+		if id == "" {
+			id = envelope.ProducedAt
+		}
+		envelope.Snapshot = &scraperoutput.Snapshot{ID: repo.Repository + "@" + id, Scope: repo.Repository}
+		snapshots = append(snapshots, envelope)
+	}
+	return snapshots
+}
+
+// createIdentityOutput lists the organization's teams and their members as the identity directory the
+// relationship processor resolves CODEOWNERS owners against
+// Teams are keyed by slug and go by their org/slug reference and display name; members by their public email,
+// falling back to their login
+func createIdentityOutput(org string, teams []types.Team, producedAt time.Time) scraperoutput.Envelope {
+	var identities []scraperoutput.Identity
+	seenMembers := make(map[string]bool)
+
+	for _, team := range teams {
+		if team.Slug == "" {
+			continue
+		}
+		aliases := []string{org + "/" + team.Slug}
+		if team.Name != "" && team.Name != team.Slug {
+			aliases = append(aliases, team.Name)
+		}
+		identities = append(identities, scraperoutput.Identity{Kind: scraperoutput.IdentityTeam, Key: team.Slug, Aliases: aliases})
+
+		for _, member := range team.Members {
+			if member.Login == "" || seenMembers[member.Login] {
+				continue
+			}
+			seenMembers[member.Login] = true
+			identities = append(identities, scraperoutput.Identity{Kind: scraperoutput.IdentityPerson, Key: member.Login, Email: member.Email})
+		}
+	}
+
+	return scraperoutput.New(extractor.SourceGitHubTeams, 1.0, producedAt, scraperoutput.Identities(identities))
+}
 
 func getGitHubToken(ctx context.Context, cfg aws.Config) (string, error) {
 	client := common.CreateSecretsClient(cfg)
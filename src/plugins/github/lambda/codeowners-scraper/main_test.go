@@ -5,12 +5,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"pgregory.net/rapid"
 	"bacon/src/plugins/github/types"
 	common "bacon/src/shared"
+	"bacon/src/shared/scraperoutput"
 )
 
 func TestValidateEvent(t *testing.T) {
@@ -337,6 +340,95 @@ func TestBuildOwnershipDataStep(t *testing.T) {
 }
 
 // Test getGitHubToken function behavior
+func TestCreateScraperOutput(t *testing.T) {
+	ownership := types.OwnershipData{
+		Organization: "acme",
+		Repositories: []types.RepoOwnership{
+			{
+				Repository: "acme/api",
+				Entries: []types.CodeownersEntry{
+					{Path: "/src", Owners: []string{"@acme/backend", "@alice"}},
+					{Path: "/docs", Owners: []string{"@acme/docs"}, Repository: "acme/api-docs"},
+				},
+			},
+			{Repository: "acme/empty"},
+		},
+		Timestamp:  "2024-01-15T10:00:00Z",
+		Source:     "github-codeowners",
+		Confidence: 0.8,
+	}
+
+	output := createScraperOutput(ownership)
+
+	if output.SchemaVersion != scraperoutput.SchemaVersion || output.Source != "github-codeowners" || output.ProducedAt != "2024-01-15T10:00:00Z" {
+		t.Errorf("Unexpected envelope %+v", output)
+	}
+	if output.Payload.Kind != scraperoutput.KindCodeowners || len(output.Payload.Codeowners) != 2 {
+		t.Fatalf("Expected 2 codeowners entries, got %+v", output.Payload)
+	}
+	if output.Payload.Codeowners[0].Repository != "acme/api" || output.Payload.Codeowners[0].Path != "/src" {
+		t.Errorf("Expected entries to inherit their repository, got %+v", output.Payload.Codeowners[0])
+	}
+	if output.Payload.Codeowners[1].Repository != "acme/api-docs" {
+		t.Errorf("Expected an entry's own repository to be kept, got %+v", output.Payload.Codeowners[1])
+	}
+}
+
+// Test every repository of a batch becomes a snapshot scoped to it
+func TestCreateRepositorySnapshots(t *testing.T) {
+	ownership := types.OwnershipData{
+		Organization: "acme",
+		Repositories: []types.RepoOwnership{
+			{
+				Repository:     "acme/api",
+				CodeownersHash: "3f2a",
+				Entries:        []types.CodeownersEntry{{Path: "/src", Owners: []string{"@acme/backend"}}},
+			},
+			{Repository: "acme/empty"},
+		},
+		Timestamp:  "2024-01-15T10:00:00Z",
+		Source:     "github-codeowners",
+		Confidence: 0.8,
+	}
+
+	snapshots := createRepositorySnapshots(ownership)
+
+	if len(snapshots) != 2 {
+		t.Fatalf("Expected a snapshot per repository, got %d", len(snapshots))
+	}
+	if !reflect.DeepEqual(snapshots[0].Snapshot, &scraperoutput.Snapshot{ID: "acme/api@3f2a", Scope: "acme/api"}) || len(snapshots[0].Payload.Codeowners) != 1 {
+		t.Errorf("Unexpected snapshot %+v", snapshots[0])
+	}
+	// A repository without entries is an empty snapshot, which retracts whatever it used to list
+	if !reflect.DeepEqual(snapshots[1].Snapshot, &scraperoutput.Snapshot{ID: "acme/empty@2024-01-15T10:00:00Z", Scope: "acme/empty"}) || len(snapshots[1].Payload.Codeowners) != 0 {
+		t.Errorf("Unexpected snapshot %+v", snapshots[1])
+	}
+}
+
+func TestCreateIdentityOutput(t *testing.T) {
+	producedAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	teams := []types.Team{
+		{Slug: "backend", Name: "Backend Engineering", Members: []types.Member{{Login: "alice", Email: "alice@acme.com"}, {Login: "bob"}}},
+		{Slug: "docs", Name: "docs", Members: []types.Member{{Login: "alice", Email: "alice@acme.com"}}},
+		{Name: "Secret"},
+	}
+
+	output := createIdentityOutput("acme", teams, producedAt)
+
+	if output.Source != "github-teams" || output.Payload.Kind != scraperoutput.KindIdentities {
+		t.Fatalf("Unexpected envelope %+v", output)
+	}
+	expected := []scraperoutput.Identity{
+		{Kind: scraperoutput.IdentityTeam, Key: "backend", Aliases: []string{"acme/backend", "Backend Engineering"}},
+		{Kind: scraperoutput.IdentityPerson, Key: "alice", Email: "alice@acme.com"},
+		{Kind: scraperoutput.IdentityPerson, Key: "bob"},
+		{Kind: scraperoutput.IdentityTeam, Key: "docs", Aliases: []string{"acme/docs"}},
+	}
+	if !reflect.DeepEqual(output.Payload.Identities, expected) {
+		t.Errorf("Expected identities %+v, got %+v", expected, output.Payload.Identities)
+	}
+}
+
 func TestGetGitHubToken(t *testing.T) {
 	// Save original env var
 	originalArn := os.Getenv("GITHUB_SECRET_ARN")
@@ -58,6 +58,20 @@ type OwnershipData struct {
 	NextCursor       string          `json:"next_cursor,omitempty"`
 }
 
+// Team is an organization team with its members, as listed by the GitHub teams API
+type Team struct {
+	Slug    string   `json:"slug"`
+	Name    string   `json:"name"`
+	Members []Member `json:"members"`
+}
+
+// Member is an organization member; Email is empty unless the member made it public
+type Member struct {
+	Login string `json:"login"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
 type CachedRepo struct {
 	Repository     string    `json:"repository"`
 	LastScraped    time.Time `json:"last_scraped"`
@@ -0,0 +1,12 @@
+// Package extractor registers the on-call source with the relationship processor
+package extractor
+
+import (
+	"bacon/src/plugins/oncall/shared"
+	"bacon/src/shared/relationship-finding/extractors"
+)
+
+func init() {
+	// On-call edges are pre-typed with a per-level confidence, like the Datadog scrapers
+	extractors.MustRegister(extractors.Registration{Source: shared.SourceOnCallSchedules, Extractor: extractors.Edges, Weight: 0.7, Priority: 3})
+}
@@ -0,0 +1,185 @@
+// Package main implements the On-Call Scraper Lambda function.
+// It follows the PagerDuty and Opsgenie references in Datadog service integrations, reads the
+// escalation policies and schedules behind them, and emits on_call_for relationships from people to services.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-xray-sdk-go/v2/xray"
+	"github.com/samber/lo"
+
+	ddShared "bacon/src/plugins/datadog/shared"
+	ddTypes "bacon/src/plugins/datadog/types"
+	"bacon/src/plugins/oncall/shared"
+	"bacon/src/plugins/oncall/types"
+)
+
+// ServiceLoader reads the live Datadog services whose integrations reference on-call providers
+type ServiceLoader interface {
+	LoadLiveServices(ctx context.Context) ([]ddTypes.DatadogService, error)
+}
+
+func main() {
+	lambda.Start(HandleRawEvent)
+}
+
+// Handler handles the Lambda invocation for on-call scraping
+// Providers are enabled by their credentials; services are read from the Datadog scraper tables
+func Handler(ctx context.Context, event types.ScraperEvent) (types.ScraperResponse, error) {
+	executionID := xray.TraceID(ctx)
+
+	providers := shared.CreateProvidersFromEnv()
+	if len(providers) == 0 {
+		err := fmt.Errorf("no on-call provider configured: set PAGERDUTY_API_TOKEN or OPSGENIE_API_KEY")
+		return createErrorResponse(executionID, err.Error()), err
+	}
+
+	store, err := ddShared.CreateSnapshotStore(ctx)
+	if err != nil {
+		return createErrorResponse(executionID, fmt.Sprintf("Failed to create service store: %v", err)), err
+	}
+
+	return Scrape(ctx, store, providers, event)
+}
+
+// Scrape runs the on-call ingestion pipeline
+// A reference that fails or whose provider is not configured is reported in the metadata; the run
+// fails only when every reference fails
+func Scrape(ctx context.Context, loader ServiceLoader, providers map[string]shared.Provider, event types.ScraperEvent) (types.ScraperResponse, error) {
+	executionID := xray.TraceID(ctx)
+
+	return ddShared.WithTracedOperation(ctx, "oncall-scraper-handler", func(tracedCtx context.Context) (types.ScraperResponse, error) {
+		services, err := loader.LoadLiveServices(tracedCtx)
+		if err != nil {
+			return createErrorResponse(executionID, fmt.Sprintf("Failed to load Datadog services: %v", err)), err
+		}
+
+		// Follow only the integrations of the requested services and providers
+		references := shared.FilterServiceReferences(shared.ExtractServiceReferences(services), event.Services, event.Providers)
+
+		assignments, skipped, failures := fetchAssignments(tracedCtx, providers, references)
+		if attempted := len(references) - len(skipped); attempted > 0 && len(failures) == attempted {
+			err := fmt.Errorf("failed to fetch on-call data for all %d referenced services", attempted)
+			response := createErrorResponse(executionID, err.Error())
+			response.Metadata["errors"] = failures
+			return response, err
+		}
+
+		assignments = shared.FilterAssignmentsByLevel(assignments, event.MaxLevel)
+		relationships := shared.CreateOnCallRelationships(assignments)
+
+		response := createSuccessResponse(executionID, len(relationships), createOnCallMetadata(references, assignments, skipped, failures))
+		response.Outputs = []types.ScraperOutput{shared.CreateOnCallOutput(relationships, time.Now())}
+		return response, nil
+	})
+}
+
+// fetchAssignments reads the on-call assignments of every reference from its provider
+// Returns the assignments, the services skipped for lack of a configured provider and the errors by service
+func fetchAssignments(ctx context.Context, providers map[string]shared.Provider, references []types.ServiceReference) ([]types.OnCallAssignment, []string, map[string]string) {
+	var assignments []types.OnCallAssignment
+	var skipped []string
+	failures := make(map[string]string)
+
+	for _, reference := range references {
+		provider, configured := providers[reference.Provider]
+		if !configured {
+			skipped = append(skipped, referenceKey(reference))
+			continue
+		}
+
+		fetched, err := provider.FetchOnCall(ctx, reference)
+		if err != nil {
+			failures[referenceKey(reference)] = err.Error()
+			continue
+		}
+		assignments = append(assignments, fetched...)
+	}
+
+	return assignments, skipped, failures
+}
+
+// referenceKey identifies a reference in metadata as "<provider>:<service>"
+func referenceKey(reference types.ServiceReference) string {
+	return fmt.Sprintf("%s:%s", reference.Provider, reference.Service)
+}
+
+// createOnCallMetadata creates metadata for the response using pure function
+func createOnCallMetadata(references []types.ServiceReference, assignments []types.OnCallAssignment, skipped []string, failures map[string]string) map[string]interface{} {
+	referencesByProvider := lo.CountValuesBy(references, func(reference types.ServiceReference) string {
+		return reference.Provider
+	})
+
+	schedules := lo.Uniq(lo.FilterMap(assignments, func(assignment types.OnCallAssignment, _ int) (string, bool) {
+		return assignment.Provider + ":" + assignment.Schedule, assignment.Schedule != ""
+	}))
+	sort.Strings(schedules)
+
+	return map[string]interface{}{
+		"referenced_services":    len(lo.UniqBy(references, func(reference types.ServiceReference) string { return reference.Service })),
+		"references_by_provider": referencesByProvider,
+		"assignments":            len(assignments),
+		"people_on_call":         len(lo.Uniq(lo.Map(assignments, func(assignment types.OnCallAssignment, _ int) string { return assignment.Email }))),
+		"schedules":              schedules,
+		"skipped_references":     lo.CoalesceSliceOrEmpty(skipped, []string{}),
+		"errors":                 failures,
+	}
+}
+
+// createSuccessResponse creates a success response using pure function
+func createSuccessResponse(executionID string, count int, metadata map[string]interface{}) types.ScraperResponse {
+	return types.ScraperResponse{
+		Status:      "success",
+		Message:     fmt.Sprintf("Successfully scraped %d on-call relationships", count),
+		Count:       count,
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		ExecutionID: executionID,
+		Metadata:    metadata,
+	}
+}
+
+// createErrorResponse creates an error response using pure function
+func createErrorResponse(executionID, message string) types.ScraperResponse {
+	return types.ScraperResponse{
+		Status:      "error",
+		Message:     message,
+		Count:       0,
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		ExecutionID: executionID,
+		Metadata:    map[string]interface{}{"error": true},
+	}
+}
+
+// ValidateEvent validates the input event using functional approach
+func ValidateEvent(event types.ScraperEvent) error {
+	if event.MaxLevel < 0 {
+		return fmt.Errorf("max_level must not be negative, got %d", event.MaxLevel)
+	}
+
+	knownProviders := []string{types.ProviderPagerDuty, types.ProviderOpsgenie}
+	if unknown := lo.Without(event.Providers, knownProviders...); len(unknown) > 0 {
+		return fmt.Errorf("unknown providers %v, expected one of %v", unknown, knownProviders)
+	}
+
+	return nil
+}
+
+// HandleRawEvent parses and validates a raw Lambda event before running Handler
+func HandleRawEvent(ctx context.Context, event json.RawMessage) (types.ScraperResponse, error) {
+	var scraperEvent types.ScraperEvent
+	if err := json.Unmarshal(event, &scraperEvent); err != nil {
+		return createErrorResponse(xray.TraceID(ctx), fmt.Sprintf("Failed to parse event: %v", err)), err
+	}
+
+	if err := ValidateEvent(scraperEvent); err != nil {
+		return createErrorResponse(xray.TraceID(ctx), fmt.Sprintf("Event validation failed: %v", err)), err
+	}
+
+	return Handler(ctx, scraperEvent)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ddTypes "bacon/src/plugins/datadog/types"
+	"bacon/src/plugins/oncall/shared"
+	"bacon/src/plugins/oncall/types"
+	common "bacon/src/shared"
+	"bacon/src/shared/scraperoutput"
+)
+
+// stubLoader returns fixed Datadog services
+type stubLoader struct {
+	services []ddTypes.DatadogService
+	err      error
+}
+
+func (l stubLoader) LoadLiveServices(ctx context.Context) ([]ddTypes.DatadogService, error) {
+	return l.services, l.err
+}
+
+// stubProvider returns fixed assignments or errors by Datadog service name
+type stubProvider struct {
+	assignments map[string][]types.OnCallAssignment
+	errs        map[string]error
+}
+
+func (p stubProvider) FetchOnCall(ctx context.Context, reference types.ServiceReference) ([]types.OnCallAssignment, error) {
+	return p.assignments[reference.Service], p.errs[reference.Service]
+}
+
+func testServices() []ddTypes.DatadogService {
+	return []ddTypes.DatadogService{
+		{
+			Name: "checkout",
+			Integrations: map[string]interface{}{
+				"pagerduty": map[string]interface{}{"service_url": "https://acme.pagerduty.com/service-directory/PCHK"},
+				"opsgenie":  map[string]interface{}{"service_url": "https://acme.app.opsgenie.com/service/og-1"},
+			},
+		},
+		{
+			Name:         "payments",
+			Integrations: map[string]interface{}{"pagerduty": map[string]interface{}{"service_url": "https://acme.pagerduty.com/service-directory/PPAY"}},
+		},
+	}
+}
+
+func TestScrape(t *testing.T) {
+	providers := map[string]shared.Provider{
+		types.ProviderPagerDuty: stubProvider{
+			assignments: map[string][]types.OnCallAssignment{
+				"checkout": {
+					{Provider: types.ProviderPagerDuty, Service: "checkout", Schedule: "Checkout Primary", Level: 1, Email: "alice@example.com"},
+					{Provider: types.ProviderPagerDuty, Service: "checkout", Level: 3, Email: "director@example.com"},
+				},
+			},
+			errs: map[string]error{"payments": errors.New("pagerduty API /services/PPAY returned status 404")},
+		},
+	}
+
+	ctx, cleanup := common.TestContext("test-oncall-scraper")
+	defer cleanup()
+
+	response, err := Scrape(ctx, stubLoader{services: testServices()}, providers, types.ScraperEvent{MaxLevel: 2})
+
+	require.NoError(t, err)
+	assert.Equal(t, "success", response.Status)
+	assert.Equal(t, 1, response.Count)
+	assert.Equal(t, []string{"opsgenie:checkout"}, response.Metadata["skipped_references"])
+	assert.Equal(t, map[string]string{"pagerduty:payments": "pagerduty API /services/PPAY returned status 404"}, response.Metadata["errors"])
+	assert.Equal(t, []string{"pagerduty:Checkout Primary"}, response.Metadata["schedules"])
+
+	require.Len(t, response.Outputs, 1)
+	assert.Equal(t, shared.SourceOnCallSchedules, response.Outputs[0].Source)
+	assert.Equal(t, []scraperoutput.Edge{
+		{From: "alice@example.com", To: "checkout", Type: "on_call_for", Confidence: 0.9},
+	}, response.Outputs[0].Payload.Relationships)
+}
+
+func TestScrape_FiltersServices(t *testing.T) {
+	var fetched []string
+	provider := recordingProvider{fetched: &fetched}
+	providers := map[string]shared.Provider{types.ProviderPagerDuty: provider, types.ProviderOpsgenie: provider}
+
+	ctx, cleanup := common.TestContext("test-oncall-scraper-filter")
+	defer cleanup()
+
+	_, err := Scrape(ctx, stubLoader{services: testServices()}, providers, types.ScraperEvent{
+		Services:  []string{"checkout"},
+		Providers: []string{types.ProviderOpsgenie},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"opsgenie:checkout"}, fetched)
+}
+
+// recordingProvider records the references it is asked for
+type recordingProvider struct {
+	fetched *[]string
+}
+
+func (p recordingProvider) FetchOnCall(ctx context.Context, reference types.ServiceReference) ([]types.OnCallAssignment, error) {
+	*p.fetched = append(*p.fetched, referenceKey(reference))
+	return nil, nil
+}
+
+func TestScrape_Failures(t *testing.T) {
+	t.Run("every reference fails", func(t *testing.T) {
+		failing := stubProvider{errs: map[string]error{
+			"checkout": errors.New("timeout"),
+			"payments": errors.New("timeout"),
+		}}
+		providers := map[string]shared.Provider{types.ProviderPagerDuty: failing}
+
+		ctx, cleanup := common.TestContext("test-oncall-scraper-failures")
+		defer cleanup()
+
+		response, err := Scrape(ctx, stubLoader{services: testServices()}, providers, types.ScraperEvent{})
+
+		require.Error(t, err)
+		assert.Equal(t, "error", response.Status)
+		assert.Len(t, response.Metadata["errors"], 2)
+	})
+
+	t.Run("services cannot be loaded", func(t *testing.T) {
+		ctx, cleanup := common.TestContext("test-oncall-scraper-load-failure")
+		defer cleanup()
+
+		response, err := Scrape(ctx, stubLoader{err: errors.New("table missing")}, nil, types.ScraperEvent{})
+
+		require.Error(t, err)
+		assert.Contains(t, response.Message, "table missing")
+	})
+}
+
+func TestValidateEvent(t *testing.T) {
+	assert.NoError(t, ValidateEvent(types.ScraperEvent{Providers: []string{"pagerduty", "opsgenie"}, MaxLevel: 2}))
+	assert.ErrorContains(t, ValidateEvent(types.ScraperEvent{MaxLevel: -1}), "max_level")
+	assert.ErrorContains(t, ValidateEvent(types.ScraperEvent{Providers: []string{"victorops"}}), "unknown providers")
+}
+
+func TestHandleRawEvent_RejectsInvalidEvents(t *testing.T) {
+	_, err := HandleRawEvent(context.Background(), json.RawMessage(`{"providers": ["victorops"]}`))
+	assert.ErrorContains(t, err, "unknown providers")
+
+	_, err = HandleRawEvent(context.Background(), json.RawMessage(`not json`))
+	assert.Error(t, err)
+}
+
+func TestHandler_RequiresProviderCredentials(t *testing.T) {
+	t.Setenv("PAGERDUTY_API_TOKEN", "")
+	t.Setenv("OPSGENIE_API_KEY", "")
+
+	response, err := Handler(context.Background(), types.ScraperEvent{})
+
+	require.Error(t, err)
+	assert.Equal(t, "error", response.Status)
+}
@@ -0,0 +1,66 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxErrorBodyBytes bounds how much of an error response is quoted in errors
+const maxErrorBodyBytes = 512
+
+// APIError reports a non-success response from an on-call provider API
+type APIError struct {
+	Provider   string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s API %s returned status %d: %s", e.Provider, e.Path, e.StatusCode, e.Body)
+}
+
+// apiClient performs authenticated JSON GET requests against a provider base URL
+type apiClient struct {
+	provider   string
+	baseURL    string
+	headers    map[string]string
+	httpClient *http.Client
+}
+
+// getJSON requests path with query parameters and decodes the JSON response into target
+func (c *apiClient) getJSON(ctx context.Context, path string, query url.Values, target interface{}) error {
+	endpoint := strings.TrimSuffix(c.baseURL, "/") + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", c.provider, err)
+	}
+	for name, value := range c.headers {
+		request.Header.Set(name, value)
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to call %s API %s: %w", c.provider, path, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorBodyBytes))
+		return &APIError{Provider: c.provider, Path: path, StatusCode: response.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(target); err != nil {
+		return fmt.Errorf("failed to decode %s API %s response: %w", c.provider, path, err)
+	}
+	return nil
+}
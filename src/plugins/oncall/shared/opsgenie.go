@@ -0,0 +1,150 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"bacon/src/plugins/oncall/types"
+)
+
+// Base URLs of the Opsgenie REST API by account region
+const (
+	OpsgenieAPIURL   = "https://api.opsgenie.com"
+	OpsgenieEUAPIURL = "https://api.eu.opsgenie.com"
+)
+
+// Opsgenie escalation rule recipient types
+const (
+	opsgenieRecipientSchedule = "schedule"
+	opsgenieRecipientUser     = "user"
+)
+
+// OpsgenieClient reads team escalations and schedule on-call recipients from the Opsgenie REST API
+type OpsgenieClient struct {
+	api   apiClient
+	euAPI apiClient
+}
+
+// opsgenieServiceResponse is the response of GET /v1/services/{id}
+type opsgenieServiceResponse struct {
+	Data struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		TeamID string `json:"teamId"`
+	} `json:"data"`
+}
+
+// opsgenieEscalationsResponse is the response of GET /v2/escalations
+type opsgenieEscalationsResponse struct {
+	Data []struct {
+		ID        string `json:"id"`
+		Name      string `json:"name"`
+		OwnerTeam struct {
+			ID string `json:"id"`
+		} `json:"ownerTeam"`
+		Rules []struct {
+			Recipient struct {
+				Type     string `json:"type"`
+				ID       string `json:"id"`
+				Name     string `json:"name"`
+				Username string `json:"username"`
+			} `json:"recipient"`
+		} `json:"rules"`
+	} `json:"data"`
+}
+
+// opsgenieOnCallsResponse is the response of GET /v2/schedules/{id}/on-calls with flat=true
+type opsgenieOnCallsResponse struct {
+	Data struct {
+		Parent struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"_parent"`
+		OnCallRecipients []string `json:"onCallRecipients"`
+	} `json:"data"`
+}
+
+// NewOpsgenieClient creates an Opsgenie client
+// A non-empty baseURL serves both regions, which is how tests point the client at a stand-in
+func NewOpsgenieClient(baseURL, apiKey string, httpClient *http.Client) *OpsgenieClient {
+	newAPI := func(defaultURL string) apiClient {
+		return apiClient{
+			provider:   types.ProviderOpsgenie,
+			baseURL:    lo.Ternary(baseURL != "", baseURL, defaultURL),
+			headers:    map[string]string{"Authorization": "GenieKey " + apiKey},
+			httpClient: lo.Ternary(httpClient != nil, httpClient, http.DefaultClient),
+		}
+	}
+	return &OpsgenieClient{api: newAPI(OpsgenieAPIURL), euAPI: newAPI(OpsgenieEUAPIURL)}
+}
+
+// FetchOnCall returns the people on call through the escalations owned by the service's team
+// Each escalation rule is one level; schedules resolve to their current recipients and user rules to the user
+func (c *OpsgenieClient) FetchOnCall(ctx context.Context, reference types.ServiceReference) ([]types.OnCallAssignment, error) {
+	api := lo.Ternary(strings.EqualFold(reference.Region, "EU"), c.euAPI, c.api)
+
+	var service opsgenieServiceResponse
+	if err := api.getJSON(ctx, "/v1/services/"+url.PathEscape(reference.ServiceID), nil, &service); err != nil {
+		return nil, err
+	}
+	if service.Data.TeamID == "" {
+		return nil, fmt.Errorf("opsgenie service %s has no owning team", reference.ServiceID)
+	}
+
+	var escalations opsgenieEscalationsResponse
+	if err := api.getJSON(ctx, "/v2/escalations", nil, &escalations); err != nil {
+		return nil, err
+	}
+
+	var assignments []types.OnCallAssignment
+	for _, escalation := range escalations.Data {
+		if escalation.OwnerTeam.ID != service.Data.TeamID {
+			continue
+		}
+
+		for i, rule := range escalation.Rules {
+			base := types.OnCallAssignment{
+				Provider:         types.ProviderOpsgenie,
+				Service:          reference.Service,
+				EscalationPolicy: escalation.Name,
+				Level:            i + 1,
+			}
+
+			switch rule.Recipient.Type {
+			case opsgenieRecipientSchedule:
+				recipients, schedule, err := c.fetchScheduleRecipients(ctx, api, rule.Recipient.ID)
+				if err != nil {
+					return nil, err
+				}
+				assignments = append(assignments, lo.Map(recipients, func(email string, _ int) types.OnCallAssignment {
+					assignment := base
+					assignment.Schedule = lo.CoalesceOrEmpty(schedule, rule.Recipient.Name)
+					assignment.Email = email
+					return assignment
+				})...)
+			case opsgenieRecipientUser:
+				assignment := base
+				assignment.Email = rule.Recipient.Username
+				assignments = append(assignments, assignment)
+			}
+		}
+	}
+
+	return assignments, nil
+}
+
+// fetchScheduleRecipients returns the current on-call recipients of a schedule and the schedule name
+func (c *OpsgenieClient) fetchScheduleRecipients(ctx context.Context, api apiClient, scheduleID string) ([]string, string, error) {
+	query := url.Values{"scheduleIdentifierType": {"id"}, "flat": {"true"}}
+
+	var onCalls opsgenieOnCallsResponse
+	if err := api.getJSON(ctx, "/v2/schedules/"+url.PathEscape(scheduleID)+"/on-calls", query, &onCalls); err != nil {
+		return nil, "", err
+	}
+	return onCalls.Data.OnCallRecipients, onCalls.Data.Parent.Name, nil
+}
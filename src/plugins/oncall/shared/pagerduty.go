@@ -0,0 +1,116 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/samber/lo"
+
+	"bacon/src/plugins/oncall/types"
+)
+
+// PagerDutyAPIURL is the base URL of the PagerDuty REST API v2
+const PagerDutyAPIURL = "https://api.pagerduty.com"
+
+// pagerDutyPageLimit is the page size requested from the on-calls endpoint
+const pagerDutyPageLimit = 100
+
+// PagerDutyClient reads escalation policies and current on-call shifts from the PagerDuty REST API v2
+type PagerDutyClient struct {
+	api apiClient
+}
+
+// pagerDutyReference is the summary form PagerDuty uses for linked objects
+type pagerDutyReference struct {
+	ID      string `json:"id"`
+	Summary string `json:"summary"`
+}
+
+// pagerDutyServiceResponse is the response of GET /services/{id}
+type pagerDutyServiceResponse struct {
+	Service struct {
+		ID               string             `json:"id"`
+		Name             string             `json:"name"`
+		EscalationPolicy pagerDutyReference `json:"escalation_policy"`
+	} `json:"service"`
+}
+
+// pagerDutyOnCallsResponse is a page of GET /oncalls
+type pagerDutyOnCallsResponse struct {
+	OnCalls []struct {
+		User struct {
+			ID    string `json:"id"`
+			Name  string `json:"name"`
+			Email string `json:"email"`
+		} `json:"user"`
+		Schedule         *pagerDutyReference `json:"schedule"`
+		EscalationPolicy pagerDutyReference  `json:"escalation_policy"`
+		EscalationLevel  int                 `json:"escalation_level"`
+		Start            string              `json:"start"`
+		End              string              `json:"end"`
+	} `json:"oncalls"`
+	More bool `json:"more"`
+}
+
+// NewPagerDutyClient creates a PagerDuty client; an empty baseURL uses PagerDutyAPIURL
+func NewPagerDutyClient(baseURL, token string, httpClient *http.Client) *PagerDutyClient {
+	return &PagerDutyClient{api: apiClient{
+		provider: types.ProviderPagerDuty,
+		baseURL:  lo.Ternary(baseURL != "", baseURL, PagerDutyAPIURL),
+		headers: map[string]string{
+			"Authorization": "Token token=" + token,
+			"Accept":        "application/vnd.pagerduty+json;version=2",
+		},
+		httpClient: lo.Ternary(httpClient != nil, httpClient, http.DefaultClient),
+	}}
+}
+
+// FetchOnCall returns the people currently on call at every level of the service's escalation policy
+// Direct escalation targets are returned without a schedule
+func (c *PagerDutyClient) FetchOnCall(ctx context.Context, reference types.ServiceReference) ([]types.OnCallAssignment, error) {
+	var service pagerDutyServiceResponse
+	if err := c.api.getJSON(ctx, "/services/"+url.PathEscape(reference.ServiceID), nil, &service); err != nil {
+		return nil, err
+	}
+
+	policy := service.Service.EscalationPolicy
+	if policy.ID == "" {
+		return nil, fmt.Errorf("pagerduty service %s has no escalation policy", reference.ServiceID)
+	}
+
+	var assignments []types.OnCallAssignment
+	for offset := 0; ; offset += pagerDutyPageLimit {
+		query := url.Values{
+			"escalation_policy_ids[]": {policy.ID},
+			"include[]":               {"users"},
+			"limit":                   {strconv.Itoa(pagerDutyPageLimit)},
+			"offset":                  {strconv.Itoa(offset)},
+		}
+
+		var page pagerDutyOnCallsResponse
+		if err := c.api.getJSON(ctx, "/oncalls", query, &page); err != nil {
+			return nil, err
+		}
+
+		for _, onCall := range page.OnCalls {
+			assignments = append(assignments, types.OnCallAssignment{
+				Provider:         types.ProviderPagerDuty,
+				Service:          reference.Service,
+				EscalationPolicy: lo.CoalesceOrEmpty(onCall.EscalationPolicy.Summary, policy.Summary),
+				Schedule:         lo.FromPtr(onCall.Schedule).Summary,
+				Level:            onCall.EscalationLevel,
+				Email:            onCall.User.Email,
+				Name:             onCall.User.Name,
+				Start:            onCall.Start,
+				End:              onCall.End,
+			})
+		}
+
+		if !page.More || len(page.OnCalls) == 0 {
+			return assignments, nil
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package shared
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"time"
+
+	"bacon/src/plugins/oncall/types"
+)
+
+// providerTimeout bounds every request to an on-call provider
+const providerTimeout = 30 * time.Second
+
+// Provider reads the on-call assignments of a referenced service from an on-call provider
+type Provider interface {
+	FetchOnCall(ctx context.Context, reference types.ServiceReference) ([]types.OnCallAssignment, error)
+}
+
+// CreateProvidersFromEnv creates a client for every provider with credentials in the environment
+// PAGERDUTY_API_TOKEN and OPSGENIE_API_KEY enable the providers; PAGERDUTY_API_URL and
+// OPSGENIE_API_URL override their base URLs
+func CreateProvidersFromEnv() map[string]Provider {
+	httpClient := &http.Client{Timeout: providerTimeout}
+	providers := make(map[string]Provider)
+
+	if token := os.Getenv("PAGERDUTY_API_TOKEN"); token != "" {
+		providers[types.ProviderPagerDuty] = NewPagerDutyClient(os.Getenv("PAGERDUTY_API_URL"), token, httpClient)
+	}
+	if apiKey := os.Getenv("OPSGENIE_API_KEY"); apiKey != "" {
+		providers[types.ProviderOpsgenie] = NewOpsgenieClient(os.Getenv("OPSGENIE_API_URL"), apiKey, httpClient)
+	}
+
+	return providers
+}
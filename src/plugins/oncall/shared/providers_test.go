@@ -0,0 +1,174 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bacon/src/plugins/oncall/types"
+)
+
+// newProviderServer serves canned JSON responses by request path and checks the auth header
+func newProviderServer(t *testing.T, authorization string, responses map[string]func(r *http.Request) interface{}) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != authorization {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"unauthorized"}`))
+			return
+		}
+
+		respond, found := responses[r.URL.Path]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"not found"}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(respond(r))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPagerDutyClient_FetchOnCall(t *testing.T) {
+	var offsets []string
+	server := newProviderServer(t, "Token token=pd-token", map[string]func(r *http.Request) interface{}{
+		"/services/PCHK": func(r *http.Request) interface{} {
+			return map[string]interface{}{"service": map[string]interface{}{
+				"id":                "PCHK",
+				"escalation_policy": map[string]interface{}{"id": "PEP1", "summary": "Checkout Escalation"},
+			}}
+		},
+		"/oncalls": func(r *http.Request) interface{} {
+			assert.Equal(t, "PEP1", r.URL.Query().Get("escalation_policy_ids[]"))
+			offsets = append(offsets, r.URL.Query().Get("offset"))
+			if r.URL.Query().Get("offset") == "0" {
+				return map[string]interface{}{
+					"oncalls": []interface{}{map[string]interface{}{
+						"user":             map[string]interface{}{"name": "Alice", "email": "alice@example.com"},
+						"schedule":         map[string]interface{}{"id": "PS1", "summary": "Checkout Primary"},
+						"escalation_level": 1,
+						"start":            "2024-05-01T00:00:00Z",
+						"end":              "2024-05-08T00:00:00Z",
+					}},
+					"more": true,
+				}
+			}
+			return map[string]interface{}{
+				"oncalls": []interface{}{map[string]interface{}{
+					"user":             map[string]interface{}{"name": "Bob", "email": "bob@example.com"},
+					"escalation_level": 2,
+				}},
+				"more": false,
+			}
+		},
+	})
+
+	client := NewPagerDutyClient(server.URL, "pd-token", server.Client())
+	assignments, err := client.FetchOnCall(context.Background(), types.ServiceReference{Service: "checkout", Provider: types.ProviderPagerDuty, ServiceID: "PCHK"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0", "100"}, offsets)
+	assert.Equal(t, []types.OnCallAssignment{
+		{Provider: types.ProviderPagerDuty, Service: "checkout", EscalationPolicy: "Checkout Escalation", Schedule: "Checkout Primary", Level: 1, Email: "alice@example.com", Name: "Alice", Start: "2024-05-01T00:00:00Z", End: "2024-05-08T00:00:00Z"},
+		{Provider: types.ProviderPagerDuty, Service: "checkout", EscalationPolicy: "Checkout Escalation", Level: 2, Email: "bob@example.com", Name: "Bob"},
+	}, assignments)
+}
+
+func TestPagerDutyClient_FetchOnCall_Errors(t *testing.T) {
+	server := newProviderServer(t, "Token token=pd-token", map[string]func(r *http.Request) interface{}{
+		"/services/PNOPOLICY": func(r *http.Request) interface{} {
+			return map[string]interface{}{"service": map[string]interface{}{"id": "PNOPOLICY"}}
+		},
+	})
+
+	t.Run("rejected token", func(t *testing.T) {
+		client := NewPagerDutyClient(server.URL, "wrong", server.Client())
+		_, err := client.FetchOnCall(context.Background(), types.ServiceReference{ServiceID: "PCHK"})
+
+		var apiErr *APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+		assert.Equal(t, types.ProviderPagerDuty, apiErr.Provider)
+	})
+
+	t.Run("service without escalation policy", func(t *testing.T) {
+		client := NewPagerDutyClient(server.URL, "pd-token", server.Client())
+		_, err := client.FetchOnCall(context.Background(), types.ServiceReference{ServiceID: "PNOPOLICY"})
+
+		assert.ErrorContains(t, err, "no escalation policy")
+	})
+}
+
+func TestOpsgenieClient_FetchOnCall(t *testing.T) {
+	server := newProviderServer(t, "GenieKey og-key", map[string]func(r *http.Request) interface{}{
+		"/v1/services/og-1": func(r *http.Request) interface{} {
+			return map[string]interface{}{"data": map[string]interface{}{"id": "og-1", "teamId": "team-1"}}
+		},
+		"/v2/escalations": func(r *http.Request) interface{} {
+			return map[string]interface{}{"data": []interface{}{
+				map[string]interface{}{
+					"name":      "Checkout Escalation",
+					"ownerTeam": map[string]interface{}{"id": "team-1"},
+					"rules": []interface{}{
+						map[string]interface{}{"recipient": map[string]interface{}{"type": "schedule", "id": "sched-1", "name": "checkout_schedule"}},
+						map[string]interface{}{"recipient": map[string]interface{}{"type": "user", "username": "lead@example.com"}},
+						map[string]interface{}{"recipient": map[string]interface{}{"type": "team", "id": "team-2"}},
+					},
+				},
+				map[string]interface{}{
+					"name":      "Other Escalation",
+					"ownerTeam": map[string]interface{}{"id": "team-2"},
+					"rules": []interface{}{
+						map[string]interface{}{"recipient": map[string]interface{}{"type": "user", "username": "other@example.com"}},
+					},
+				},
+			}}
+		},
+		"/v2/schedules/sched-1/on-calls": func(r *http.Request) interface{} {
+			assert.Equal(t, "true", r.URL.Query().Get("flat"))
+			return map[string]interface{}{"data": map[string]interface{}{
+				"_parent":          map[string]interface{}{"id": "sched-1", "name": "Checkout Schedule"},
+				"onCallRecipients": []string{"alice@example.com", "bob@example.com"},
+			}}
+		},
+	})
+
+	client := NewOpsgenieClient(server.URL, "og-key", server.Client())
+	assignments, err := client.FetchOnCall(context.Background(), types.ServiceReference{Service: "checkout", Provider: types.ProviderOpsgenie, ServiceID: "og-1", Region: "EU"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []types.OnCallAssignment{
+		{Provider: types.ProviderOpsgenie, Service: "checkout", EscalationPolicy: "Checkout Escalation", Schedule: "Checkout Schedule", Level: 1, Email: "alice@example.com"},
+		{Provider: types.ProviderOpsgenie, Service: "checkout", EscalationPolicy: "Checkout Escalation", Schedule: "Checkout Schedule", Level: 1, Email: "bob@example.com"},
+		{Provider: types.ProviderOpsgenie, Service: "checkout", EscalationPolicy: "Checkout Escalation", Level: 2, Email: "lead@example.com"},
+	}, assignments)
+}
+
+func TestOpsgenieClient_RegionBaseURL(t *testing.T) {
+	client := NewOpsgenieClient("", "og-key", nil)
+
+	assert.Equal(t, OpsgenieAPIURL, client.api.baseURL)
+	assert.Equal(t, OpsgenieEUAPIURL, client.euAPI.baseURL)
+}
+
+func TestCreateProvidersFromEnv(t *testing.T) {
+	t.Setenv("PAGERDUTY_API_TOKEN", "")
+	t.Setenv("OPSGENIE_API_KEY", "")
+	assert.Empty(t, CreateProvidersFromEnv())
+
+	t.Setenv("PAGERDUTY_API_TOKEN", "pd-token")
+	providers := CreateProvidersFromEnv()
+	assert.Len(t, providers, 1)
+	assert.IsType(t, &PagerDutyClient{}, providers[types.ProviderPagerDuty])
+
+	t.Setenv("OPSGENIE_API_KEY", "og-key")
+	assert.Len(t, CreateProvidersFromEnv(), 2)
+}
@@ -0,0 +1,98 @@
+// Package shared provides PagerDuty and Opsgenie clients and pure functional utilities for on-call ingestion.
+package shared
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+
+	ddShared "bacon/src/plugins/datadog/shared"
+	ddTypes "bacon/src/plugins/datadog/types"
+	"bacon/src/plugins/oncall/types"
+)
+
+// integrationProviders maps Datadog integration keys onto on-call providers
+var integrationProviders = map[string]string{
+	ddShared.IntegrationPagerDuty: types.ProviderPagerDuty,
+	ddShared.IntegrationOpsgenie:  types.ProviderOpsgenie,
+}
+
+// serviceIDPathMarkers are the path segments that precede the service ID in provider service URLs
+var serviceIDPathMarkers = map[string][]string{
+	types.ProviderPagerDuty: {"service-directory", "services"},
+	types.ProviderOpsgenie:  {"service", "services"},
+}
+
+// ExtractServiceReferences collects the on-call provider services referenced by Datadog service integrations
+// Pure function; references without a parsable service ID are dropped and the result is sorted by service
+func ExtractServiceReferences(services []ddTypes.DatadogService) []types.ServiceReference {
+	references := lo.FlatMap(services, func(service ddTypes.DatadogService, _ int) []types.ServiceReference {
+		return lo.FilterMap(lo.Keys(service.Integrations), func(key string, _ int) (types.ServiceReference, bool) {
+			provider, known := integrationProviders[key]
+			if !known {
+				return types.ServiceReference{}, false
+			}
+			return createServiceReference(service.Name, provider, service.Integrations[key])
+		})
+	})
+
+	sort.Slice(references, func(i, j int) bool {
+		if references[i].Service != references[j].Service {
+			return references[i].Service < references[j].Service
+		}
+		return references[i].Provider < references[j].Provider
+	})
+	return references
+}
+
+// FilterServiceReferences keeps the references matching the requested services and providers
+// Pure function; an empty selection keeps everything
+func FilterServiceReferences(references []types.ServiceReference, services, providers []string) []types.ServiceReference {
+	return lo.Filter(references, func(reference types.ServiceReference, _ int) bool {
+		return (len(services) == 0 || lo.Contains(services, reference.Service)) &&
+			(len(providers) == 0 || lo.Contains(providers, reference.Provider))
+	})
+}
+
+// createServiceReference builds a reference from an integration value written by the service catalog transform
+func createServiceReference(service, provider string, integration interface{}) (types.ServiceReference, bool) {
+	fields, ok := integration.(map[string]interface{})
+	if !ok {
+		return types.ServiceReference{}, false
+	}
+
+	serviceURL, _ := fields["service_url"].(string)
+	region, _ := fields["region"].(string)
+	serviceID := ParseServiceID(provider, serviceURL)
+	if service == "" || serviceID == "" {
+		return types.ServiceReference{}, false
+	}
+
+	return types.ServiceReference{
+		Service:    service,
+		Provider:   provider,
+		ServiceURL: serviceURL,
+		ServiceID:  serviceID,
+		Region:     region,
+	}, true
+}
+
+// ParseServiceID extracts the provider service ID from a service URL
+// PagerDuty URLs look like https://acme.pagerduty.com/service-directory/PABC123 and Opsgenie URLs
+// like https://acme.app.opsgenie.com/service/<uuid>; an empty result means the URL is not recognized
+func ParseServiceID(provider, serviceURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(serviceURL))
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+
+	segments := lo.Compact(strings.Split(parsed.Path, "/"))
+	for i, segment := range segments[:max(len(segments)-1, 0)] {
+		if lo.Contains(serviceIDPathMarkers[provider], segment) {
+			return segments[i+1]
+		}
+	}
+	return ""
+}
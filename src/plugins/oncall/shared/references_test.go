@@ -0,0 +1,79 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ddTypes "bacon/src/plugins/datadog/types"
+	"bacon/src/plugins/oncall/types"
+)
+
+func TestParseServiceID(t *testing.T) {
+	testCases := []struct {
+		name       string
+		provider   string
+		serviceURL string
+		expected   string
+	}{
+		{"pagerduty service directory", types.ProviderPagerDuty, "https://acme.pagerduty.com/service-directory/PABC123", "PABC123"},
+		{"pagerduty services path", types.ProviderPagerDuty, "https://acme.pagerduty.com/services/PABC123/", "PABC123"},
+		{"opsgenie service", types.ProviderOpsgenie, "https://acme.app.opsgenie.com/service/4f2c-11/status", "4f2c-11"},
+		{"opsgenie services", types.ProviderOpsgenie, "https://acme.app.eu.opsgenie.com/services/4f2c-11", "4f2c-11"},
+		{"marker without id", types.ProviderPagerDuty, "https://acme.pagerduty.com/service-directory", ""},
+		{"no host", types.ProviderPagerDuty, "service-directory/PABC123", ""},
+		{"unknown provider", "victorops", "https://acme.pagerduty.com/services/PABC123", ""},
+		{"empty", types.ProviderOpsgenie, "", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ParseServiceID(tc.provider, tc.serviceURL))
+		})
+	}
+}
+
+func TestExtractServiceReferences(t *testing.T) {
+	services := []ddTypes.DatadogService{
+		{
+			Name: "payments",
+			Integrations: map[string]interface{}{
+				"pagerduty": map[string]interface{}{"service_url": "https://acme.pagerduty.com/service-directory/PPAY"},
+				"slack":     map[string]interface{}{"channel": "#payments"},
+			},
+		},
+		{
+			Name: "checkout",
+			Integrations: map[string]interface{}{
+				"opsgenie":  map[string]interface{}{"service_url": "https://acme.app.eu.opsgenie.com/service/og-1", "region": "EU"},
+				"pagerduty": map[string]interface{}{"service_url": "https://acme.pagerduty.com/service-directory/PCHK"},
+			},
+		},
+		{
+			Name:         "search",
+			Integrations: map[string]interface{}{"pagerduty": map[string]interface{}{"service_url": "not a url"}},
+		},
+		{Name: "ledger"},
+	}
+
+	references := ExtractServiceReferences(services)
+
+	assert.Equal(t, []types.ServiceReference{
+		{Service: "checkout", Provider: types.ProviderOpsgenie, ServiceURL: "https://acme.app.eu.opsgenie.com/service/og-1", ServiceID: "og-1", Region: "EU"},
+		{Service: "checkout", Provider: types.ProviderPagerDuty, ServiceURL: "https://acme.pagerduty.com/service-directory/PCHK", ServiceID: "PCHK"},
+		{Service: "payments", Provider: types.ProviderPagerDuty, ServiceURL: "https://acme.pagerduty.com/service-directory/PPAY", ServiceID: "PPAY"},
+	}, references)
+}
+
+func TestFilterServiceReferences(t *testing.T) {
+	references := []types.ServiceReference{
+		{Service: "checkout", Provider: types.ProviderOpsgenie},
+		{Service: "checkout", Provider: types.ProviderPagerDuty},
+		{Service: "payments", Provider: types.ProviderPagerDuty},
+	}
+
+	assert.Len(t, FilterServiceReferences(references, nil, nil), 3)
+	assert.Equal(t, references[:2], FilterServiceReferences(references, []string{"checkout"}, nil))
+	assert.Equal(t, []types.ServiceReference{references[1], references[2]}, FilterServiceReferences(references, nil, []string{types.ProviderPagerDuty}))
+	assert.Equal(t, []types.ServiceReference{references[0]}, FilterServiceReferences(references, []string{"checkout"}, []string{types.ProviderOpsgenie}))
+}
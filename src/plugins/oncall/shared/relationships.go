@@ -0,0 +1,81 @@
+package shared
+
+import (
+	"sort"
+	"time"
+
+	"github.com/samber/lo"
+
+	"bacon/src/plugins/oncall/types"
+	"bacon/src/shared/scraperoutput"
+)
+
+// RelationshipTypeOnCallFor links a person to a service they are paged for
+const RelationshipTypeOnCallFor = "on_call_for"
+
+// SourceOnCallSchedules is the scraper output source of on-call relationships
+const SourceOnCallSchedules = "oncall-schedules"
+
+// OnCallConfidence is the base confidence of on-call relationships; a current page route is strong
+// evidence of operational responsibility but not of ownership
+const OnCallConfidence = 0.85
+
+// levelConfidence lowers the edge confidence of people paged only after earlier levels time out
+var levelConfidence = map[int]float64{
+	1: 0.9,
+	2: 0.75,
+}
+
+// deepLevelConfidence applies to level three and beyond
+const deepLevelConfidence = 0.6
+
+// FilterAssignmentsByLevel keeps assignments at or above the given escalation level
+// Pure function; a maxLevel of zero keeps every level
+func FilterAssignmentsByLevel(assignments []types.OnCallAssignment, maxLevel int) []types.OnCallAssignment {
+	return lo.Filter(assignments, func(assignment types.OnCallAssignment, _ int) bool {
+		return maxLevel <= 0 || assignment.Level <= maxLevel
+	})
+}
+
+// CreateOnCallRelationships derives on_call_for edges from people to services
+// Pure function; a person reachable at several levels keeps the edge of their earliest level
+func CreateOnCallRelationships(assignments []types.OnCallAssignment) []types.OnCallRelationship {
+	reachable := lo.Filter(assignments, func(assignment types.OnCallAssignment, _ int) bool {
+		return assignment.Email != "" && assignment.Service != ""
+	})
+
+	earliest := lo.Reduce(reachable, func(acc map[[2]string]int, assignment types.OnCallAssignment, _ int) map[[2]string]int {
+		key := [2]string{assignment.Email, assignment.Service}
+		if level, seen := acc[key]; !seen || assignment.Level < level {
+			acc[key] = assignment.Level
+		}
+		return acc
+	}, make(map[[2]string]int))
+
+	relationships := lo.MapToSlice(earliest, func(key [2]string, level int) types.OnCallRelationship {
+		return types.OnCallRelationship{
+			From:       key[0],
+			To:         key[1],
+			Type:       RelationshipTypeOnCallFor,
+			Confidence: lo.ValueOr(levelConfidence, level, deepLevelConfidence),
+		}
+	})
+
+	sort.Slice(relationships, func(i, j int) bool {
+		if relationships[i].To != relationships[j].To {
+			return relationships[i].To < relationships[j].To
+		}
+		return relationships[i].From < relationships[j].From
+	})
+	return relationships
+}
+
+// CreateOnCallOutput wraps on-call relationships in the ScraperOutput format consumed by the processor
+// Pure function; every edge carries its escalation level confidence
+func CreateOnCallOutput(relationships []types.OnCallRelationship, timestamp time.Time) types.ScraperOutput {
+	edges := lo.Map(relationships, func(rel types.OnCallRelationship, _ int) scraperoutput.Edge {
+		return scraperoutput.Edge(rel)
+	})
+
+	return scraperoutput.New(SourceOnCallSchedules, OnCallConfidence, timestamp, scraperoutput.Relationships(edges))
+}
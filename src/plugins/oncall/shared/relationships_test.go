@@ -0,0 +1,50 @@
+package shared
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"bacon/src/plugins/oncall/types"
+	"bacon/src/shared/scraperoutput"
+)
+
+func TestFilterAssignmentsByLevel(t *testing.T) {
+	assignments := []types.OnCallAssignment{{Level: 1}, {Level: 2}, {Level: 3}}
+
+	assert.Len(t, FilterAssignmentsByLevel(assignments, 0), 3)
+	assert.Equal(t, assignments[:2], FilterAssignmentsByLevel(assignments, 2))
+}
+
+func TestCreateOnCallRelationships(t *testing.T) {
+	assignments := []types.OnCallAssignment{
+		{Service: "payments", Email: "bob@example.com", Level: 2},
+		{Service: "payments", Email: "bob@example.com", Level: 1},
+		{Service: "checkout", Email: "carol@example.com", Level: 3},
+		{Service: "checkout", Email: "alice@example.com", Level: 2},
+		{Service: "checkout", Email: "", Level: 1},
+	}
+
+	assert.Equal(t, []types.OnCallRelationship{
+		{From: "alice@example.com", To: "checkout", Type: RelationshipTypeOnCallFor, Confidence: 0.75},
+		{From: "carol@example.com", To: "checkout", Type: RelationshipTypeOnCallFor, Confidence: 0.6},
+		{From: "bob@example.com", To: "payments", Type: RelationshipTypeOnCallFor, Confidence: 0.9},
+	}, CreateOnCallRelationships(assignments))
+}
+
+func TestCreateOnCallOutput(t *testing.T) {
+	timestamp := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	relationships := []types.OnCallRelationship{
+		{From: "alice@example.com", To: "checkout", Type: RelationshipTypeOnCallFor, Confidence: 0.9},
+	}
+
+	output := CreateOnCallOutput(relationships, timestamp)
+
+	assert.Equal(t, SourceOnCallSchedules, output.Source)
+	assert.Equal(t, OnCallConfidence, output.Confidence)
+	assert.Equal(t, "2024-05-01T09:30:00Z", output.ProducedAt)
+	assert.Equal(t, scraperoutput.Relationships([]scraperoutput.Edge{
+		{From: "alice@example.com", To: "checkout", Type: "on_call_for", Confidence: 0.9},
+	}), output.Payload)
+}
@@ -0,0 +1,61 @@
+// Package types provides immutable data structures for PagerDuty and Opsgenie on-call ingestion.
+package types
+
+import "bacon/src/shared/scraperoutput"
+
+// Providers of on-call escalation data
+const (
+	ProviderPagerDuty = "pagerduty"
+	ProviderOpsgenie  = "opsgenie"
+)
+
+// ServiceReference points from a Datadog service to the service that pages for it in an on-call provider
+type ServiceReference struct {
+	Service    string `json:"service"`  // Datadog service name, the graph name of the service
+	Provider   string `json:"provider"` // "pagerduty" or "opsgenie"
+	ServiceURL string `json:"service_url"`
+	ServiceID  string `json:"service_id"`       // provider service ID parsed from ServiceURL
+	Region     string `json:"region,omitempty"` // Opsgenie region, e.g. "EU"
+}
+
+// OnCallAssignment represents a person reachable through a service's escalation policy
+type OnCallAssignment struct {
+	Provider         string `json:"provider"`
+	Service          string `json:"service"`
+	EscalationPolicy string `json:"escalation_policy"`
+	Schedule         string `json:"schedule,omitempty"` // empty when the person is a direct escalation target
+	Level            int    `json:"level"`              // escalation level, 1 is paged first
+	Email            string `json:"email"`
+	Name             string `json:"name,omitempty"`
+	Start            string `json:"start,omitempty"` // on-call shift bounds when known
+	End              string `json:"end,omitempty"`
+}
+
+// ScraperEvent represents the input event for the on-call scraper Lambda function
+type ScraperEvent struct {
+	Services  []string `json:"services,omitempty"`  // restrict ingestion to these Datadog services
+	Providers []string `json:"providers,omitempty"` // restrict ingestion to these providers
+	MaxLevel  int      `json:"max_level,omitempty"` // deepest escalation level to follow; 0 follows every level
+}
+
+// ScraperResponse represents the output response from the on-call scraper Lambda function
+type ScraperResponse struct {
+	Status      string                 `json:"status"`
+	Message     string                 `json:"message"`
+	Count       int                    `json:"count"`
+	Timestamp   string                 `json:"timestamp"`
+	ExecutionID string                 `json:"execution_id"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Outputs     []ScraperOutput        `json:"scraper_outputs,omitempty"`
+}
+
+// ScraperOutput represents relationship data handed to the relationship-finding processor
+type ScraperOutput = scraperoutput.Envelope
+
+// OnCallRelationship represents an on_call_for edge from a person to a service
+type OnCallRelationship struct {
+	From       string  `json:"from"`
+	To         string  `json:"to"`
+	Type       string  `json:"type"`
+	Confidence float64 `json:"confidence,omitempty"` // overrides the output confidence when set
+}
@@ -0,0 +1,34 @@
+// Package extractor registers the OpenShift source with the relationship processor
+package extractor
+
+import (
+	"fmt"
+	"strings"
+
+	"bacon/src/shared/relationship-finding/extractors"
+	"bacon/src/shared/scraperoutput"
+)
+
+const SourceOpenShiftMetadata = "openshift-metadata"
+
+func init() {
+	// Ownership annotated on cluster resources is very high confidence
+	extractors.MustRegister(extractors.Registration{Source: SourceOpenShiftMetadata, Extractor: extractors.ExtractorFunc(ExtractResources), Weight: 0.9, Priority: 2})
+}
+
+// ExtractResources maps every owned Kubernetes resource to an owns edge on Kind/Name
+func ExtractResources(output scraperoutput.Envelope) []extractors.Relationship {
+	var relationships []extractors.Relationship
+
+	for _, resource := range output.Payload.Resources {
+		if resource.Owner == "" {
+			continue
+		}
+		resourceName := fmt.Sprintf("%s/%s", resource.Kind, resource.Name)
+		rel := extractors.NewRelationship(output, resource.Owner, resourceName, "owns")
+		rel.ResourceType = strings.ToLower(resource.Kind)
+		relationships = append(relationships, rel)
+	}
+
+	return relationships
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-xray-sdk-go/v2/xray"
+
+	"bacon/src/shared/scraperoutput"
 )
 
 type Event struct {
@@ -55,10 +57,34 @@ func HandleRequest(ctx context.Context, event Event) (string, error) {
 		return "", fmt.Errorf("failed to scrape OpenShift metadata: %w", err)
 	}
 
-	result, _ := json.Marshal(ownershipData)
+	result, _ := json.Marshal(createScraperOutput(ownershipData))
 	return string(result), nil
 }
 
+// createScraperOutput wraps scraped resources in the envelope consumed by the relationship processor
+func createScraperOutput(ownershipData *OwnershipData) scraperoutput.Envelope {
+	resources := make([]scraperoutput.Resource, 0, len(ownershipData.Resources))
+	for _, resource := range ownershipData.Resources {
+		resources = append(resources, scraperoutput.Resource{
+			Kind:      resource.Kind,
+			Name:      resource.Name,
+			Namespace: resource.Namespace,
+			Owner:     resource.Owner,
+		})
+	}
+
+	producedAt, err := time.Parse(time.RFC3339, ownershipData.Timestamp)
+	if err != nil {
+		producedAt = time.Now()
+	}
+	envelope := scraperoutput.New(ownershipData.Source, ownershipData.Confidence, producedAt, scraperoutput.Resources(resources))
+
+	// A scrape lists every resource of its namespace, so resources it no longer lists are retracted
+	scope := ownershipData.Cluster + "/" + ownershipData.Namespace
+	envelope.Snapshot = &scraperoutput.Snapshot{ID: scope + "@" + envelope.ProducedAt, Scope: scope}
+	return envelope
+}
+
 func scrapeOpenShiftMetadata(ctx context.Context, cluster, namespace string) (*OwnershipData, error) {
 	_, seg := xray.BeginSubsegment(ctx, "scrape-k8s-resources")
 	defer seg.Close(nil)
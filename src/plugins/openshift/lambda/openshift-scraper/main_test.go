@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -9,6 +10,7 @@ import (
 	"pgregory.net/rapid"
 
 	common "bacon/src/shared"
+	"bacon/src/shared/scraperoutput"
 )
 
 func TestMain(m *testing.M) {
@@ -224,6 +226,38 @@ func TestOwnershipDataCreation(t *testing.T) {
 	}
 }
 
+// Test OwnershipData is converted to the typed scraper output envelope
+func TestCreateScraperOutput(t *testing.T) {
+	ownershipData := &OwnershipData{
+		Cluster:   "production",
+		Namespace: "payments",
+		Resources: []KubernetesResource{
+			{Kind: "Deployment", Name: "api-service", Namespace: "payments", Owner: "backend-team", Labels: map[string]string{"app": "api"}},
+		},
+		Timestamp:  "2024-01-15T10:00:00Z",
+		Source:     "openshift-metadata",
+		Confidence: 0.9,
+	}
+
+	output := createScraperOutput(ownershipData)
+
+	if output.SchemaVersion != scraperoutput.SchemaVersion || output.Source != "openshift-metadata" || output.Confidence != 0.9 {
+		t.Errorf("Unexpected envelope %+v", output)
+	}
+	if output.ProducedAt != "2024-01-15T10:00:00Z" {
+		t.Errorf("Expected produced_at from the scrape timestamp, got %s", output.ProducedAt)
+	}
+	expected := scraperoutput.Resources([]scraperoutput.Resource{
+		{Kind: "Deployment", Name: "api-service", Namespace: "payments", Owner: "backend-team"},
+	})
+	if !reflect.DeepEqual(output.Payload, expected) {
+		t.Errorf("Expected payload %+v, got %+v", expected, output.Payload)
+	}
+	if !reflect.DeepEqual(output.Snapshot, &scraperoutput.Snapshot{ID: "production/payments@2024-01-15T10:00:00Z", Scope: "production/payments"}) {
+		t.Errorf("Expected a snapshot of the namespace, got %+v", output.Snapshot)
+	}
+}
+
 // Defensive programming tests
 func TestDefensiveProgramming(t *testing.T) {
 	t.Run("extractOwnershipInfo with nil resource", func(t *testing.T) {
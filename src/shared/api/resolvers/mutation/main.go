@@ -3,13 +3,17 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-xray-sdk-go/v2/xray"
+
+	"bacon/src/shared/graphstore"
+	"bacon/src/shared/relationship-finding/extractors"
 )
 
 type AppSyncEvent struct {
@@ -36,8 +40,12 @@ type Relationship struct {
 	LastValidated   string  `json:"lastValidated"`
 	CreatedAt       string  `json:"createdAt"`
 	UpdatedAt       string  `json:"updatedAt"`
+	Metadata        string  `json:"metadata,omitempty"`
 }
 
+// confidenceExplanationProperty holds the JSON explanation the relationship processor stores with each edge
+const confidenceExplanationProperty = "confidence_explanation"
+
 type CreateRelationshipInput struct {
 	FromUserID   string                 `json:"fromUserId"`
 	ToResourceID string                 `json:"toResourceId"`
@@ -54,30 +62,29 @@ func HandleRequest(ctx context.Context, event AppSyncEvent) (interface{}, error)
 	log.Printf("Handling AppSync mutation: %s", event.Info.FieldName)
 	_ = seg.AddAnnotation("field_name", event.Info.FieldName)
 
-	cfg, err := config.LoadDefaultConfig(ctx)
+	store, err := graphstore.Default(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+		return nil, fmt.Errorf("failed to configure graph store: %w", err)
 	}
-	_ = cfg // Use for Neptune client in production
 
 	switch event.Info.FieldName {
 	case "createRelationship":
-		return handleCreateRelationship(ctx, event.Arguments)
+		return handleCreateRelationship(ctx, store, event.Arguments)
 	case "updateRelationshipConfidence":
-		return handleUpdateRelationshipConfidence(ctx, event.Arguments)
+		return handleUpdateRelationshipConfidence(ctx, store, event.Arguments)
 	case "resolveConflict":
-		return handleResolveConflict(ctx, event.Arguments)
+		return handleResolveConflict(ctx, store, event.Arguments)
 	case "approveRelationships":
-		return handleApproveRelationships(ctx, event.Arguments)
+		return handleApproveRelationships(ctx, store, event.Arguments)
 	case "rejectRelationships":
-		return handleRejectRelationships(ctx, event.Arguments)
+		return handleRejectRelationships(ctx, store, event.Arguments)
 	default:
 		return nil, fmt.Errorf("unknown field: %s", event.Info.FieldName)
 	}
 }
 
-func handleCreateRelationship(ctx context.Context, args map[string]interface{}) (*Relationship, error) {
-	_, seg := xray.BeginSubsegment(ctx, "create-relationship")
+func handleCreateRelationship(ctx context.Context, store graphstore.GraphStore, args map[string]interface{}) (*Relationship, error) {
+	ctx, seg := xray.BeginSubsegment(ctx, "create-relationship")
 	defer seg.Close(nil)
 
 	inputRaw := args["input"].(map[string]interface{})
@@ -101,30 +108,50 @@ func handleCreateRelationship(ctx context.Context, args map[string]interface{})
 	_ = seg.AddAnnotation("to_resource_id", toResourceID)
 	_ = seg.AddAnnotation("relationship_type", relType)
 
-	// Mock relationship creation - in production would create in Neptune
+	if fromUserID == "" || toResourceID == "" {
+		return nil, fmt.Errorf("fromUserId and toResourceId are required")
+	}
+
+	// Both endpoints are created if missing; existing ones keep their name
+	err := store.UpsertVertices(ctx, []graphstore.Vertex{
+		{ID: fromUserID, Label: graphstore.LabelUser},
+		{ID: toResourceID, Label: graphstore.LabelResource},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create relationship endpoints: %w", err)
+	}
+
+	// Edge labels are stored the way the relationship processor writes them
+	label := strings.ToLower(relType)
 	now := time.Now().Format(time.RFC3339)
-	relationship := &Relationship{
-		ID:              fmt.Sprintf("rel-%d", time.Now().Unix()),
-		From:            fromUserID,
-		To:              toResourceID,
-		Type:            relType,
-		Confidence:      confidence,
-		ConfidenceLevel: calculateConfidenceLevel(confidence),
-		Source:          source,
-		HasConflict:     false,
-		LastValidated:   now,
-		CreatedAt:       now,
-		UpdatedAt:       now,
+	edge := graphstore.Edge{
+		ID:         graphstore.EdgeID(fromUserID, label, toResourceID, source),
+		Label:      label,
+		From:       fromUserID,
+		To:         toResourceID,
+		Confidence: confidence,
+		Source:     source,
+		Timestamp:  now,
+		Properties: map[string]string{"created_at": now},
+	}
+	if existing, found, err := store.GetEdge(ctx, edge.ID); err == nil && found && existing.Properties["created_at"] != "" {
+		edge.Properties["created_at"] = existing.Properties["created_at"]
+	}
+	explainManually(&edge)
+
+	if err := store.UpsertEdges(ctx, []graphstore.Edge{edge}); err != nil {
+		return nil, fmt.Errorf("failed to create relationship: %w", err)
 	}
 
 	log.Printf("Created relationship: %s -> %s (%s) with confidence %f", 
 		fromUserID, toResourceID, relType, confidence)
 
-	return relationship, nil
+	relationship := toRelationship(edge)
+	return &relationship, nil
 }
 
-func handleUpdateRelationshipConfidence(ctx context.Context, args map[string]interface{}) (*Relationship, error) {
-	_, seg := xray.BeginSubsegment(ctx, "update-relationship-confidence")
+func handleUpdateRelationshipConfidence(ctx context.Context, store graphstore.GraphStore, args map[string]interface{}) (*Relationship, error) {
+	ctx, seg := xray.BeginSubsegment(ctx, "update-relationship-confidence")
 	defer seg.Close(nil)
 
 	relationshipID := args["id"].(string)
@@ -133,29 +160,26 @@ func handleUpdateRelationshipConfidence(ctx context.Context, args map[string]int
 	_ = seg.AddAnnotation("relationship_id", relationshipID)
 	_ = seg.AddAnnotation("new_confidence", newConfidence)
 
-	// Mock confidence update - in production would update Neptune
-	now := time.Now().Format(time.RFC3339)
-	relationship := &Relationship{
-		ID:              relationshipID,
-		From:            "updated-user",
-		To:              "updated-resource",
-		Type:            "OWNS",
-		Confidence:      newConfidence,
-		ConfidenceLevel: calculateConfidenceLevel(newConfidence),
-		Source:          "manual-update",
-		HasConflict:     false,
-		LastValidated:   now,
-		CreatedAt:       "2024-01-01T00:00:00Z",
-		UpdatedAt:       now,
+	edge, err := loadRelationship(ctx, store, relationshipID)
+	if err != nil {
+		return nil, err
+	}
+
+	edge.Confidence = newConfidence
+	edge.Timestamp = time.Now().Format(time.RFC3339)
+	explainManually(&edge)
+	if err := store.UpsertEdges(ctx, []graphstore.Edge{edge}); err != nil {
+		return nil, fmt.Errorf("failed to update relationship %s: %w", relationshipID, err)
 	}
 
 	log.Printf("Updated relationship %s confidence to %f", relationshipID, newConfidence)
 
-	return relationship, nil
+	relationship := toRelationship(edge)
+	return &relationship, nil
 }
 
-func handleResolveConflict(ctx context.Context, args map[string]interface{}) (*Relationship, error) {
-	_, seg := xray.BeginSubsegment(ctx, "resolve-conflict")
+func handleResolveConflict(ctx context.Context, store graphstore.GraphStore, args map[string]interface{}) (*Relationship, error) {
+	ctx, seg := xray.BeginSubsegment(ctx, "resolve-conflict")
 	defer seg.Close(nil)
 
 	conflictID := args["id"].(string)
@@ -164,29 +188,62 @@ func handleResolveConflict(ctx context.Context, args map[string]interface{}) (*R
 	_ = seg.AddAnnotation("conflict_id", conflictID)
 	_ = seg.AddAnnotation("winner_id", winnerID)
 
-	// Mock conflict resolution - in production would update Neptune
-	now := time.Now().Format(time.RFC3339)
-	relationship := &Relationship{
-		ID:              winnerID,
-		From:            "resolved-user",
-		To:              "disputed-resource",
-		Type:            "OWNS",
-		Confidence:      0.95, // High confidence after manual resolution
-		ConfidenceLevel: "VERY_HIGH",
-		Source:          "manual-resolution",
-		HasConflict:     false, // Conflict resolved
-		LastValidated:   now,
-		CreatedAt:       "2024-01-01T00:00:00Z",
-		UpdatedAt:       now,
+	winner, err := loadRelationship(ctx, store, winnerID)
+	if err != nil {
+		return nil, err
+	}
+	recordReviews(ctx, store, []graphstore.Edge{winner}, graphstore.ReviewApproved)
+
+	winner.Confidence = 0.95 // High confidence after manual resolution
+	winner.Source = "manual-resolution"
+	winner.HasConflict = false // Conflict resolved
+	winner.Timestamp = time.Now().Format(time.RFC3339)
+	explainManually(&winner)
+	if err := store.UpsertEdges(ctx, []graphstore.Edge{winner}); err != nil {
+		return nil, fmt.Errorf("failed to resolve conflict %s: %w", conflictID, err)
+	}
+
+	// The id names either the conflict's review record, whose other candidates all lose, or the losing claim
+	record, isRecord := loadConflict(ctx, store, conflictID)
+	losers := []string{conflictID}
+	if isRecord {
+		losers = record.Candidates
+	} else {
+		record, _ = loadConflict(ctx, store, winner.Properties[graphstore.ConflictProperty])
+	}
+
+	// The losing claims are removed
+	for _, loserID := range losers {
+		if loserID == "" || loserID == winnerID {
+			continue
+		}
+		if loser, found, err := store.GetEdge(ctx, loserID); err == nil && found {
+			recordReviews(ctx, store, []graphstore.Edge{loser}, graphstore.ReviewRejected)
+		}
+		if err := store.DeleteEdges(ctx, graphstore.EdgeFilter{ID: loserID}); err != nil {
+			return nil, fmt.Errorf("failed to remove conflicting relationship %s: %w", loserID, err)
+		}
+	}
+
+	// The review record closes with the reviewer's verdict
+	if record.ID != "" && record.Open() {
+		record.Status = graphstore.ConflictClosed
+		record.Resolution = graphstore.ConflictResolvedManually
+		record.Winner = winnerID
+		record.ClosedAt = winner.Timestamp
+		if err := store.UpsertVertices(ctx, []graphstore.Vertex{record.Vertex()}); err != nil {
+			return nil, fmt.Errorf("failed to close conflict %s: %w", record.ID, err)
+		}
 	}
 
 	log.Printf("Resolved conflict %s, winner: %s", conflictID, winnerID)
 
-	return relationship, nil
+	relationship := toRelationship(winner)
+	return &relationship, nil
 }
 
-func handleApproveRelationships(ctx context.Context, args map[string]interface{}) ([]Relationship, error) {
-	_, seg := xray.BeginSubsegment(ctx, "approve-relationships")
+func handleApproveRelationships(ctx context.Context, store graphstore.GraphStore, args map[string]interface{}) ([]Relationship, error) {
+	ctx, seg := xray.BeginSubsegment(ctx, "approve-relationships")
 	defer seg.Close(nil)
 
 	idsRaw := args["ids"].([]interface{})
@@ -197,34 +254,39 @@ func handleApproveRelationships(ctx context.Context, args map[string]interface{}
 
 	_ = seg.AddAnnotation("relationship_count", len(ids))
 
-	// Mock bulk approval - in production would update Neptune
-	var relationships []Relationship
+	relationships := make([]Relationship, 0, len(ids))
+	edges := make([]graphstore.Edge, 0, len(ids))
 	now := time.Now().Format(time.RFC3339)
 
+	reviewed := make([]graphstore.Edge, 0, len(ids))
 	for _, id := range ids {
-		rel := Relationship{
-			ID:              id,
-			From:            "approved-user",
-			To:              "approved-resource",
-			Type:            "OWNS",
-			Confidence:      0.90, // High confidence after approval
-			ConfidenceLevel: "VERY_HIGH",
-			Source:          "manual-approval",
-			HasConflict:     false,
-			LastValidated:   now,
-			CreatedAt:       "2024-01-01T00:00:00Z",
-			UpdatedAt:       now,
+		edge, err := loadRelationship(ctx, store, id)
+		if err != nil {
+			return nil, err
 		}
-		relationships = append(relationships, rel)
+		reviewed = append(reviewed, edge)
+
+		edge.Confidence = 0.90 // High confidence after approval
+		edge.Source = "manual-approval"
+		edge.HasConflict = false
+		edge.Timestamp = now
+		explainManually(&edge)
+		edges = append(edges, edge)
+		relationships = append(relationships, toRelationship(edge))
 	}
 
+	if err := store.UpsertEdges(ctx, edges); err != nil {
+		return nil, fmt.Errorf("failed to approve relationships: %w", err)
+	}
+	recordReviews(ctx, store, reviewed, graphstore.ReviewApproved)
+
 	log.Printf("Approved %d relationships", len(relationships))
 
 	return relationships, nil
 }
 
-func handleRejectRelationships(ctx context.Context, args map[string]interface{}) ([]Relationship, error) {
-	_, seg := xray.BeginSubsegment(ctx, "reject-relationships")
+func handleRejectRelationships(ctx context.Context, store graphstore.GraphStore, args map[string]interface{}) ([]Relationship, error) {
+	ctx, seg := xray.BeginSubsegment(ctx, "reject-relationships")
 	defer seg.Close(nil)
 
 	idsRaw := args["ids"].([]interface{})
@@ -235,13 +297,165 @@ func handleRejectRelationships(ctx context.Context, args map[string]interface{})
 
 	_ = seg.AddAnnotation("relationship_count", len(ids))
 
-	// Mock bulk rejection - in production would delete from Neptune
+	filters := make([]graphstore.EdgeFilter, 0, len(ids))
+	reviewed := make([]graphstore.Edge, 0, len(ids))
+	for _, id := range ids {
+		if id == "" {
+			return nil, fmt.Errorf("relationship id is required")
+		}
+		filters = append(filters, graphstore.EdgeFilter{ID: id})
+		if edge, found, err := store.GetEdge(ctx, id); err == nil && found {
+			reviewed = append(reviewed, edge)
+		}
+	}
+	recordReviews(ctx, store, reviewed, graphstore.ReviewRejected)
+	if len(filters) > 0 {
+		if err := store.DeleteEdges(ctx, filters...); err != nil {
+			return nil, fmt.Errorf("failed to reject relationships: %w", err)
+		}
+	}
+
 	log.Printf("Rejected %d relationships: %v", len(ids), ids)
 
 	// Return empty array as relationships were deleted
 	return []Relationship{}, nil
 }
 
+// recordReviews keeps the verdict on each edge with the processor's explanation of its confidence, so scoring
+// strategies can be calibrated against reviewers; a failure to record does not fail the review itself
+func recordReviews(ctx context.Context, store graphstore.GraphStore, edges []graphstore.Edge, outcome string) {
+	if len(edges) == 0 {
+		return
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	reviews := make([]graphstore.Vertex, 0, len(edges))
+	for _, edge := range edges {
+		// Confidences set by an earlier review carry no evidence of their own
+		explanation := ""
+		if !strings.HasPrefix(edge.Source, "manual") {
+			explanation = edge.Properties[confidenceExplanationProperty]
+		}
+		reviews = append(reviews, graphstore.ReviewVertex(edge.ID, outcome, explanation, now))
+	}
+
+	if err := store.UpsertVertices(ctx, reviews); err != nil {
+		log.Printf("Failed to record %d %s reviews: %v", len(reviews), outcome, err)
+	}
+}
+
+// loadConflict fetches the review record of a conflict, reporting whether the id names one
+func loadConflict(ctx context.Context, store graphstore.GraphStore, id string) (graphstore.Conflict, bool) {
+	if id == "" {
+		return graphstore.Conflict{}, false
+	}
+	vertex, found, err := store.GetVertex(ctx, id)
+	if err != nil || !found || vertex.Label != graphstore.LabelConflict {
+		return graphstore.Conflict{}, false
+	}
+	record, err := graphstore.ConflictFromVertex(vertex)
+	if err != nil {
+		log.Printf("Ignoring unreadable conflict record: %v", err)
+		return graphstore.Conflict{}, false
+	}
+	return record, true
+}
+
+// loadRelationship fetches the edge of a relationship, failing when it does not exist
+func loadRelationship(ctx context.Context, store graphstore.GraphStore, id string) (graphstore.Edge, error) {
+	if id == "" {
+		return graphstore.Edge{}, fmt.Errorf("relationship id is required")
+	}
+
+	edge, found, err := store.GetEdge(ctx, id)
+	if err != nil {
+		return graphstore.Edge{}, fmt.Errorf("failed to get relationship %s: %w", id, err)
+	}
+	if !found {
+		return graphstore.Edge{}, fmt.Errorf("relationship %s not found", id)
+	}
+	return edge, nil
+}
+
+func toRelationship(edge graphstore.Edge) Relationship {
+	level := calculateConfidenceLevel(edge.Confidence)
+	if edge.HasConflict {
+		level = "DISPUTED"
+	}
+
+	createdAt := edge.Properties["created_at"]
+	if createdAt == "" {
+		createdAt = edge.Timestamp
+	}
+
+	return Relationship{
+		ID:              edge.ID,
+		From:            edge.From,
+		To:              edge.To,
+		Type:            strings.ToUpper(edge.Label),
+		Confidence:      edge.Confidence,
+		ConfidenceLevel: level,
+		Source:          edge.Source,
+		HasConflict:     edge.HasConflict,
+		LastValidated:   edge.Timestamp,
+		CreatedAt:       createdAt,
+		UpdatedAt:       edge.Timestamp,
+		Metadata:        relationshipMetadata(edge),
+	}
+}
+
+// explainManually replaces the processor's explanation of an edge's confidence once a person has set it
+func explainManually(edge *graphstore.Edge) {
+	contribution := extractors.Contribution{
+		Source:              edge.Source,
+		Timestamp:           edge.Timestamp,
+		BaseConfidence:      edge.Confidence,
+		SourceWeight:        1.0,
+		FreshnessMultiplier: 1.0,
+		Confidence:          edge.Confidence,
+	}
+	explanation, err := json.Marshal(extractors.Explanation{
+		Confidence:          edge.Confidence,
+		BaseConfidence:      edge.Confidence,
+		SourceWeight:        1.0,
+		FreshnessMultiplier: 1.0,
+		Sources:             []extractors.Contribution{contribution},
+	})
+	if err != nil {
+		return
+	}
+
+	if edge.Properties == nil {
+		edge.Properties = make(map[string]string)
+	}
+	edge.Properties[confidenceExplanationProperty] = string(explanation)
+}
+
+// relationshipMetadata encodes the properties of an edge, such as its confidence explanation, as the AWSJSON
+// metadata of a relationship; edges without properties have none
+func relationshipMetadata(edge graphstore.Edge) string {
+	metadata := make(map[string]interface{}, len(edge.Properties))
+	for key, value := range edge.Properties {
+		switch {
+		case key == "created_at":
+			continue
+		case key == confidenceExplanationProperty && json.Valid([]byte(value)):
+			metadata[key] = json.RawMessage(value)
+		default:
+			metadata[key] = value
+		}
+	}
+	if len(metadata) == 0 {
+		return ""
+	}
+
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
 func calculateConfidenceLevel(confidence float64) string {
 	switch {
 	case confidence >= 0.9:
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"strings"
@@ -8,13 +9,50 @@ import (
 	"time"
 
 	common "bacon/src/shared"
+	"bacon/src/shared/graphstore"
 )
 
+// testStore is the in-memory graph HandleRequest falls back to without a Neptune endpoint
+var testStore = graphstore.Local
+
 func TestMain(m *testing.M) {
 	// Setup test environment
+	if err := seedRelationships(append(generateIDList(1000), "rel-123", "rel-456", "conflict-123"), true); err != nil {
+		panic(err)
+	}
 	m.Run()
 }
 
+// seedRelationships stores, or resets, an ownership edge under each of the given IDs
+func seedRelationships(ids []interface{}, conflict bool) error {
+	ctx := context.Background()
+	err := testStore.UpsertVertices(ctx, []graphstore.Vertex{
+		{ID: graphstore.VertexID("seed-team"), Label: graphstore.LabelUser, Name: "seed-team"},
+		{ID: graphstore.VertexID("seed-service"), Label: graphstore.LabelResource, Name: "seed-service"},
+	})
+	if err != nil {
+		return err
+	}
+
+	edges := make([]graphstore.Edge, 0, len(ids))
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		edges = append(edges, graphstore.Edge{
+			ID:          id.(string),
+			Label:       "owns",
+			From:        graphstore.VertexID("seed-team"),
+			To:          graphstore.VertexID("seed-service"),
+			Confidence:  0.6,
+			Source:      "github-codeowners",
+			HasConflict: conflict,
+			Timestamp:   "2024-01-01T00:00:00Z",
+		})
+	}
+	return testStore.UpsertEdges(ctx, edges)
+}
+
 // Test HandleRequest function with comprehensive scenarios
 func TestHandleRequest(t *testing.T) {
 	ctx, cleanup := common.TestContext("mutation-resolver-test")
@@ -233,7 +271,7 @@ func TestHandleCreateRelationship(t *testing.T) {
 					"type":         "OWNS",
 				},
 			},
-			expectError: false,
+			expectError: true,
 		},
 		{
 			name: "empty resource ID",
@@ -244,7 +282,7 @@ func TestHandleCreateRelationship(t *testing.T) {
 					"type":         "OWNS",
 				},
 			},
-			expectError: false,
+			expectError: true,
 		},
 		{
 			name: "unicode characters in IDs",
@@ -272,7 +310,7 @@ func TestHandleCreateRelationship(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result, err := handleCreateRelationship(ctx, tc.args)
+			result, err := handleCreateRelationship(ctx, testStore, tc.args)
 
 			if tc.expectError && err == nil {
 				t.Error("Expected error but got none")
@@ -371,7 +409,7 @@ func TestHandleUpdateRelationshipConfidence(t *testing.T) {
 				"id":         "",
 				"confidence": 0.8,
 			},
-			expectError: false,
+			expectError: true,
 		},
 		{
 			name: "very long relationship ID",
@@ -393,7 +431,11 @@ func TestHandleUpdateRelationshipConfidence(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result, err := handleUpdateRelationshipConfidence(ctx, tc.args)
+			if err := seedRelationships([]interface{}{tc.args["id"]}, false); err != nil {
+				t.Fatalf("Failed to seed relationship: %v", err)
+			}
+
+			result, err := handleUpdateRelationshipConfidence(ctx, testStore, tc.args)
 
 			if tc.expectError && err == nil {
 				t.Error("Expected error but got none")
@@ -432,27 +474,39 @@ func TestHandleResolveConflict(t *testing.T) {
 	defer cleanup()
 
 	testCases := []struct {
-		name       string
-		conflictID string
-		winnerID   string
+		name        string
+		conflictID  string
+		winnerID    string
+		expectError bool
 	}{
-		{"normal conflict resolution", "conflict-123", "rel-456"},
-		{"empty conflict ID", "", "rel-456"},
-		{"empty winner ID", "conflict-123", ""},
-		{"both empty", "", ""},
-		{"unicode IDs", "冲突-123", "获胜者-456"},
-		{"special characters", "conflict@#$%", "winner_123"},
-		{"very long IDs", strings.Repeat("c", 500), strings.Repeat("w", 500)},
+		{"normal conflict resolution", "conflict-123", "rel-456", false},
+		{"empty conflict ID", "", "rel-456", false},
+		{"empty winner ID", "conflict-123", "", true},
+		{"both empty", "", "", true},
+		{"unicode IDs", "冲突-123", "获胜者-456", false},
+		{"special characters", "conflict@#$%", "winner_123", false},
+		{"very long IDs", strings.Repeat("c", 500), strings.Repeat("w", 500), false},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
+			if err := seedRelationships([]interface{}{tc.conflictID, tc.winnerID}, true); err != nil {
+				t.Fatalf("Failed to seed relationships: %v", err)
+			}
+
 			args := map[string]interface{}{
 				"id":       tc.conflictID,
 				"winnerId": tc.winnerID,
 			}
 
-			result, err := handleResolveConflict(ctx, args)
+			result, err := handleResolveConflict(ctx, testStore, args)
+
+			if tc.expectError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
 
 			if err != nil {
 				t.Errorf("Unexpected error: %v", err)
@@ -462,6 +516,13 @@ func TestHandleResolveConflict(t *testing.T) {
 				t.Error("Expected non-nil result")
 			}
 
+			// The losing claim is gone
+			if tc.conflictID != "" {
+				if _, found, _ := testStore.GetEdge(ctx, tc.conflictID); found {
+					t.Errorf("Expected conflicting relationship %s to be removed", tc.conflictID)
+				}
+			}
+
 			if result != nil {
 				// Validate resolved conflict
 				if result.ID != tc.winnerID {
@@ -517,7 +578,7 @@ func TestHandleApproveRelationships(t *testing.T) {
 		{
 			name:        "empty string IDs",
 			ids:         []interface{}{"", "rel-2", ""},
-			expectError: false,
+			expectError: true,
 		},
 		{
 			name:        "unicode IDs",
@@ -538,11 +599,15 @@ func TestHandleApproveRelationships(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
+			if err := seedRelationships(tc.ids, true); err != nil {
+				t.Fatalf("Failed to seed relationships: %v", err)
+			}
+
 			args := map[string]interface{}{
 				"ids": tc.ids,
 			}
 
-			relationships, err := handleApproveRelationships(ctx, args)
+			relationships, err := handleApproveRelationships(ctx, testStore, args)
 
 			if tc.expectError && err == nil {
 				t.Error("Expected error but got none")
@@ -607,7 +672,7 @@ func TestHandleRejectRelationships(t *testing.T) {
 				"ids": tc.ids,
 			}
 
-			relationships, err := handleRejectRelationships(ctx, args)
+			relationships, err := handleRejectRelationships(ctx, testStore, args)
 
 			if err != nil {
 				t.Errorf("Unexpected error: %v", err)
@@ -625,6 +690,114 @@ func TestHandleRejectRelationships(t *testing.T) {
 	}
 }
 
+// Test mutations are written to the graph
+func TestMutationsPersist(t *testing.T) {
+	ctx, cleanup := common.TestContext("mutations-persist-test")
+	defer cleanup()
+
+	store := graphstore.NewMemoryStore()
+	create := func(from, source string) *Relationship {
+		result, err := handleCreateRelationship(ctx, store, map[string]interface{}{
+			"input": map[string]interface{}{"fromUserId": from, "toResourceId": "payments", "type": "OWNS", "source": source},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return result
+	}
+
+	first := create("team-a", "manual")
+	second := create("team-b", "github-codeowners")
+
+	edge, found, _ := store.GetEdge(ctx, first.ID)
+	if !found || edge.Label != "owns" || edge.From != "team-a" || edge.To != "payments" {
+		t.Fatalf("Expected the created relationship to be stored, got %+v (found %v)", edge, found)
+	}
+	if resource, _, _ := store.GetVertex(ctx, "payments"); resource.Label != graphstore.LabelResource {
+		t.Errorf("Expected the resource vertex to be created, got %+v", resource)
+	}
+
+	if _, err := handleUpdateRelationshipConfidence(ctx, store, map[string]interface{}{"id": first.ID, "confidence": 0.7}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if edge, _, _ := store.GetEdge(ctx, first.ID); edge.Confidence != 0.7 {
+		t.Errorf("Expected the confidence update to be stored, got %f", edge.Confidence)
+	}
+	if updated, _ := loadRelationship(ctx, store, first.ID); !strings.Contains(toRelationship(updated).Metadata, `"confidence_explanation":{"confidence":0.7,`) {
+		t.Errorf("Expected the manual confidence to replace the explanation, got %s", toRelationship(updated).Metadata)
+	}
+
+	if _, err := handleUpdateRelationshipConfidence(ctx, store, map[string]interface{}{"id": "missing", "confidence": 0.7}); err == nil {
+		t.Error("Expected an error updating a missing relationship")
+	}
+
+	if _, err := handleResolveConflict(ctx, store, map[string]interface{}{"id": second.ID, "winnerId": first.ID}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	edges, _ := store.FindEdges(ctx, graphstore.EdgeFilter{To: "payments"})
+	if len(edges) != 1 || edges[0].ID != first.ID || edges[0].Source != "manual-resolution" {
+		t.Errorf("Expected only the winner to remain, got %+v", edges)
+	}
+
+	if _, err := handleRejectRelationships(ctx, store, map[string]interface{}{"ids": []interface{}{first.ID}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, found, _ := store.GetEdge(ctx, first.ID); found {
+		t.Error("Expected the rejected relationship to be deleted")
+	}
+
+	// Every verdict is kept for calibrating the confidence scoring
+	loser, _, _ := store.GetVertex(ctx, graphstore.ReviewVertex(second.ID, "", "", "").ID)
+	if loser.Properties[graphstore.ReviewOutcomeProperty] != graphstore.ReviewRejected {
+		t.Errorf("Expected the losing claim to be recorded as rejected, got %+v", loser)
+	}
+	if loser.Properties[graphstore.ReviewExplanationProperty] == "" {
+		t.Errorf("Expected the losing claim's explanation to be kept, got %+v", loser)
+	}
+	winner, _, _ := store.GetVertex(ctx, graphstore.ReviewVertex(first.ID, "", "", "").ID)
+	if winner.Properties[graphstore.ReviewOutcomeProperty] != graphstore.ReviewRejected {
+		t.Errorf("Expected the latest verdict on the winner to be kept, got %+v", winner)
+	}
+}
+
+// Test resolving a conflict by its review record removes every other candidate and closes the record
+func TestResolveConflictClosesRecord(t *testing.T) {
+	ctx, cleanup := common.TestContext("resolve-conflict-record-test")
+	defer cleanup()
+
+	store := graphstore.NewMemoryStore()
+	var candidates []string
+	for _, owner := range []string{"team-a", "team-b", "team-c"} {
+		result, err := handleCreateRelationship(ctx, store, map[string]interface{}{
+			"input": map[string]interface{}{"fromUserId": owner, "toResourceId": "payments", "type": "OWNS", "source": "aws-tags"},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		candidates = append(candidates, result.ID)
+	}
+	record := graphstore.Conflict{
+		ID: graphstore.ConflictID("owns", "payments"), Resource: "payments", RelationshipType: "owns",
+		Candidates: candidates, Status: graphstore.ConflictOpen, FirstSeen: "2024-05-01T09:00:00Z",
+	}
+	if err := store.UpsertVertices(ctx, []graphstore.Vertex{record.Vertex()}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := handleResolveConflict(ctx, store, map[string]interface{}{"id": record.ID, "winnerId": candidates[1]}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	edges, _ := store.FindEdges(ctx, graphstore.EdgeFilter{To: "payments"})
+	if len(edges) != 1 || edges[0].ID != candidates[1] {
+		t.Errorf("Expected only the winner to remain, got %+v", edges)
+	}
+	closed, ok := loadConflict(ctx, store, record.ID)
+	if !ok || closed.Open() || closed.Resolution != graphstore.ConflictResolvedManually || closed.Winner != candidates[1] || closed.ClosedAt == "" {
+		t.Errorf("Expected the record closed with the reviewer's verdict, got %+v", closed)
+	}
+}
+
 // Test calculateConfidenceLevel function with comprehensive boundary conditions
 func TestCalculateConfidenceLevel(t *testing.T) {
 	testCases := []struct {
@@ -690,7 +863,7 @@ func TestPropertyBasedCreateRelationship(t *testing.T) {
 			},
 		}
 
-		result, err := handleCreateRelationship(ctx, args)
+		result, err := handleCreateRelationship(ctx, testStore, args)
 
 		if err != nil {
 			t.Errorf("Property violated: random valid input caused error: %v", err)
@@ -766,12 +939,16 @@ func TestPropertyBasedBulkOperations(t *testing.T) {
 			ids[j] = fmt.Sprintf("rel-%d-%d", i, j)
 		}
 
+		if err := seedRelationships(ids, true); err != nil {
+			t.Fatalf("Failed to seed relationships: %v", err)
+		}
+
 		// Test approval
 		args := map[string]interface{}{
 			"ids": ids,
 		}
 
-		relationships, err := handleApproveRelationships(ctx, args)
+		relationships, err := handleApproveRelationships(ctx, testStore, args)
 
 		if err != nil {
 			t.Errorf("Property violated: bulk approval failed: %v", err)
@@ -796,7 +973,7 @@ func TestPropertyBasedBulkOperations(t *testing.T) {
 		}
 
 		// Test rejection
-		rejectedRels, err := handleRejectRelationships(ctx, args)
+		rejectedRels, err := handleRejectRelationships(ctx, testStore, args)
 		if err != nil {
 			t.Errorf("Property violated: bulk rejection failed: %v", err)
 		}
@@ -846,7 +1023,7 @@ func TestDefensiveProgramming(t *testing.T) {
 			}
 		}()
 
-		_, err := handleCreateRelationship(ctx, map[string]interface{}{})
+		_, err := handleCreateRelationship(ctx, testStore, map[string]interface{}{})
 		if err != nil {
 			t.Logf("Expected behavior with missing input: %v", err)
 		}
@@ -864,7 +1041,7 @@ func TestDefensiveProgramming(t *testing.T) {
 			"confidence": "not-a-number", // Wrong type
 		}
 
-		_, err := handleUpdateRelationshipConfidence(ctx, args)
+		_, err := handleUpdateRelationshipConfidence(ctx, testStore, args)
 		if err != nil {
 			t.Logf("Expected behavior with wrong types: %v", err)
 		}
@@ -881,7 +1058,7 @@ func TestDefensiveProgramming(t *testing.T) {
 			"ids": "not-an-array",
 		}
 
-		_, err := handleApproveRelationships(ctx, args)
+		_, err := handleApproveRelationships(ctx, testStore, args)
 		if err != nil {
 			t.Logf("Expected behavior with non-array IDs: %v", err)
 		}
@@ -929,7 +1106,7 @@ func TestMutationTargets(t *testing.T) {
 				},
 			}
 
-			result, err := handleCreateRelationship(ctx, args)
+			result, err := handleCreateRelationship(ctx, testStore, args)
 			if err != nil {
 				t.Errorf("Error creating relationship: %v", err)
 				continue
@@ -962,8 +1139,8 @@ func TestMutationTargets(t *testing.T) {
 			},
 		}
 
-		result1, _ := handleCreateRelationship(ctx, args1)
-		result2, _ := handleCreateRelationship(ctx, args2)
+		result1, _ := handleCreateRelationship(ctx, testStore, args1)
+		result2, _ := handleCreateRelationship(ctx, testStore, args2)
 
 		// Both should use default confidence of 0.5
 		if result1 != nil && result2 != nil {
@@ -1020,13 +1197,17 @@ func BenchmarkBulkApproval(b *testing.B) {
 		ids[i] = fmt.Sprintf("rel-%d", i)
 	}
 
+	if err := seedRelationships(ids, true); err != nil {
+		b.Fatalf("Failed to seed relationships: %v", err)
+	}
+
 	args := map[string]interface{}{
 		"ids": ids,
 	}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = handleApproveRelationships(ctx, args)
+		_, _ = handleApproveRelationships(ctx, testStore, args)
 	}
 }
 
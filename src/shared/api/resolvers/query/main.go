@@ -3,13 +3,17 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-xray-sdk-go/v2/xray"
+
+	"bacon/src/shared/graphstore"
 )
 
 type AppSyncEvent struct {
@@ -35,19 +39,42 @@ type Resource struct {
 }
 
 type Relationship struct {
-	ID              string  `json:"id"`
-	From            string  `json:"from"`
-	To              string  `json:"to"`
-	Type            string  `json:"type"`
-	Confidence      float64 `json:"confidence"`
-	ConfidenceLevel string  `json:"confidenceLevel"`
-	Source          string  `json:"source"`
-	HasConflict     bool    `json:"hasConflict"`
-	LastValidated   string  `json:"lastValidated"`
-	CreatedAt       string  `json:"createdAt"`
-	UpdatedAt       string  `json:"updatedAt"`
+	ID              string         `json:"id"`
+	From            string         `json:"from"`
+	To              string         `json:"to"`
+	Type            string         `json:"type"`
+	Confidence      float64        `json:"confidence"`
+	ConfidenceLevel string         `json:"confidenceLevel"`
+	Source          string         `json:"source"`
+	HasConflict     bool           `json:"hasConflict"`
+	LastValidated   string         `json:"lastValidated"`
+	CreatedAt       string         `json:"createdAt"`
+	UpdatedAt       string         `json:"updatedAt"`
+	Metadata        string         `json:"metadata,omitempty"`
+	ConflictsWith   []Relationship `json:"conflictsWith,omitempty"`
+}
+
+// Conflict is the review record of a conflict between the claims of several sources on one resource
+type Conflict struct {
+	ID               string         `json:"id"`
+	Resource         string         `json:"resource"`
+	RelationshipType string         `json:"relationshipType"`
+	Candidates       []Relationship `json:"candidates"`
+	Evidence         string         `json:"evidence,omitempty"`
+	Assignee         string         `json:"assignee,omitempty"`
+	Status           string         `json:"status"`
+	Resolution       string         `json:"resolution,omitempty"`
+	FirstSeen        string         `json:"firstSeen"`
+	LastSeen         string         `json:"lastSeen"`
+	SLADueAt         string         `json:"slaDueAt"`
+	ClosedAt         string         `json:"closedAt,omitempty"`
+	AgeHours         float64        `json:"ageHours"`
+	SLABreached      bool           `json:"slaBreached"`
 }
 
+// confidenceExplanationProperty holds the JSON explanation the relationship processor stores with each edge
+const confidenceExplanationProperty = "confidence_explanation"
+
 type OwnershipStats struct {
 	TotalResources      int                   `json:"totalResources"`
 	OwnedResources      int                   `json:"ownedResources"`
@@ -92,231 +119,604 @@ func HandleRequest(ctx context.Context, event AppSyncEvent) (interface{}, error)
 	log.Printf("Handling AppSync query: %s", event.Info.FieldName)
 	_ = seg.AddAnnotation("field_name", event.Info.FieldName)
 
-	cfg, err := config.LoadDefaultConfig(ctx)
+	store, err := graphstore.Default(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+		return nil, fmt.Errorf("failed to configure graph store: %w", err)
 	}
-	_ = cfg // Use for Neptune client in production
 
 	switch event.Info.FieldName {
 	case "getResource":
-		return handleGetResource(ctx, event.Arguments)
+		return handleGetResource(ctx, store, event.Arguments)
 	case "getResourcesByConfidence":
-		return handleGetResourcesByConfidence(ctx, event.Arguments)
+		return handleGetResourcesByConfidence(ctx, store, event.Arguments)
 	case "getConflictedRelationships":
-		return handleGetConflictedRelationships(ctx, event.Arguments)
+		return handleGetConflictedRelationships(ctx, store, event.Arguments)
 	case "getRelationshipsBySource":
-		return handleGetRelationshipsBySource(ctx, event.Arguments)
+		return handleGetRelationshipsBySource(ctx, store, event.Arguments)
 	case "searchResources":
-		return handleSearchResources(ctx, event.Arguments)
+		return handleSearchResources(ctx, store, event.Arguments)
 	case "searchResourcesByOwner":
-		return handleSearchResourcesByOwner(ctx, event.Arguments)
+		return handleSearchResourcesByOwner(ctx, store, event.Arguments)
 	case "getOwnershipCoverage":
-		return handleGetOwnershipCoverage(ctx, event.Arguments)
+		return handleGetOwnershipCoverage(ctx, store, event.Arguments)
 	case "getConfidenceDistribution":
-		return handleGetConfidenceDistribution(ctx, event.Arguments)
+		return handleGetConfidenceDistribution(ctx, store, event.Arguments)
+	case "getConflicts":
+		return handleGetConflicts(ctx, store, event.Arguments)
+	case "getConflict":
+		return handleGetConflict(ctx, store, event.Arguments)
 	default:
 		return nil, fmt.Errorf("unknown field: %s", event.Info.FieldName)
 	}
 }
 
-func handleGetResource(ctx context.Context, args map[string]interface{}) (*Resource, error) {
-	_, seg := xray.BeginSubsegment(ctx, "get-resource")
+func handleGetResource(ctx context.Context, store graphstore.GraphStore, args map[string]interface{}) (*Resource, error) {
+	ctx, seg := xray.BeginSubsegment(ctx, "get-resource")
 	defer seg.Close(nil)
 
 	resourceID := args["id"].(string)
 	_ = seg.AddAnnotation("resource_id", resourceID)
 
-	// Mock resource for POC - in production would query Neptune
-	resource := &Resource{
-		ID:          resourceID,
-		Name:        "example-api-service",
-		Type:        "KUBERNETES_SERVICE",
-		Description: "Main API service for the application",
-		Relationships: []Relationship{
-			{
-				ID:              "rel-1",
-				From:            "backend-team",
-				To:              resourceID,
-				Type:            "OWNS",
-				Confidence:      0.85,
-				ConfidenceLevel: "HIGH",
-				Source:          "openshift-metadata",
-				HasConflict:     false,
-				LastValidated:   "2024-01-15T10:00:00Z",
-				CreatedAt:       "2024-01-01T00:00:00Z",
-				UpdatedAt:       "2024-01-15T10:00:00Z",
-			},
-		},
-		CreatedAt: "2024-01-01T00:00:00Z",
-		UpdatedAt: "2024-01-15T10:00:00Z",
-	}
-
-	return resource, nil
-}
-
-func handleGetResourcesByConfidence(ctx context.Context, args map[string]interface{}) ([]Resource, error) {
-	_, seg := xray.BeginSubsegment(ctx, "get-resources-by-confidence")
+	if resourceID == "" {
+		return nil, fmt.Errorf("resource id is required")
+	}
+
+	vertex, found, err := store.GetVertex(ctx, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource %s: %w", resourceID, err)
+	}
+	if !found {
+		return nil, nil
+	}
+
+	edges, err := store.FindEdges(ctx, graphstore.EdgeFilter{To: resourceID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relationships of %s: %w", resourceID, err)
+	}
+
+	resource := toResource(vertex, edges)
+	if err := linkConflicts(ctx, store, resource.Relationships, edges); err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+func handleGetResourcesByConfidence(ctx context.Context, store graphstore.GraphStore, args map[string]interface{}) ([]Resource, error) {
+	ctx, seg := xray.BeginSubsegment(ctx, "get-resources-by-confidence")
 	defer seg.Close(nil)
 
 	minConfidence := args["minConfidence"].(float64)
 	_ = seg.AddAnnotation("min_confidence", minConfidence)
 
-	// Mock filtered resources
-	resources := []Resource{
-		{
-			ID:          "res-1",
-			Name:        "high-confidence-service",
-			Type:        "KUBERNETES_SERVICE",
-			Description: "Service with high confidence ownership",
-			Relationships: []Relationship{
-				{
-					ID:              "rel-1",
-					Confidence:      0.92,
-					ConfidenceLevel: "VERY_HIGH",
-					Source:          "aws-tags",
-					HasConflict:     false,
-				},
-			},
-		},
+	edges, err := store.FindEdges(ctx, graphstore.EdgeFilter{MinConfidence: minConfidence})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relationships: %w", err)
 	}
 
-	return resources, nil
+	return resourcesOf(ctx, store, edges)
 }
 
-func handleGetConflictedRelationships(ctx context.Context, _ map[string]interface{}) ([]Relationship, error) {
-	_, seg := xray.BeginSubsegment(ctx, "get-conflicted-relationships")
+func handleGetConflictedRelationships(ctx context.Context, store graphstore.GraphStore, _ map[string]interface{}) ([]Relationship, error) {
+	ctx, seg := xray.BeginSubsegment(ctx, "get-conflicted-relationships")
 	defer seg.Close(nil)
 
-	// Mock conflicted relationships
-	relationships := []Relationship{
-		{
-			ID:              "rel-conflict-1",
-			From:            "team-a",
-			To:              "disputed-service",
-			Type:            "OWNS",
-			Confidence:      0.75,
-			ConfidenceLevel: "DISPUTED",
-			Source:          "github-codeowners",
-			HasConflict:     true,
-			LastValidated:   "2024-01-15T10:00:00Z",
-		},
+	edges, err := store.FindEdges(ctx, graphstore.EdgeFilter{ConflictOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conflicted relationships: %w", err)
 	}
 
-	return relationships, nil
+	return relationshipsOf(ctx, store, edges)
 }
 
-func handleGetRelationshipsBySource(ctx context.Context, args map[string]interface{}) ([]Relationship, error) {
-	_, seg := xray.BeginSubsegment(ctx, "get-relationships-by-source")
+func handleGetRelationshipsBySource(ctx context.Context, store graphstore.GraphStore, args map[string]interface{}) ([]Relationship, error) {
+	ctx, seg := xray.BeginSubsegment(ctx, "get-relationships-by-source")
 	defer seg.Close(nil)
 
 	source := args["source"].(string)
 	_ = seg.AddAnnotation("source", source)
 
-	// Mock source-filtered relationships
-	relationships := []Relationship{
-		{
-			ID:              "rel-1",
-			From:            "platform-team",
-			To:              "infrastructure-service",
-			Type:            "OWNS",
-			Confidence:      0.88,
-			ConfidenceLevel: "HIGH",
-			Source:          source,
-			HasConflict:     false,
-		},
+	// An empty source would match every edge rather than none
+	if source == "" {
+		return []Relationship{}, nil
 	}
 
-	return relationships, nil
+	edges, err := store.FindEdges(ctx, graphstore.EdgeFilter{Source: source})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relationships from %s: %w", source, err)
+	}
+
+	return relationshipsOf(ctx, store, edges)
 }
 
-func handleSearchResources(ctx context.Context, args map[string]interface{}) ([]Resource, error) {
-	_, seg := xray.BeginSubsegment(ctx, "search-resources")
+func handleSearchResources(ctx context.Context, store graphstore.GraphStore, args map[string]interface{}) ([]Resource, error) {
+	ctx, seg := xray.BeginSubsegment(ctx, "search-resources")
 	defer seg.Close(nil)
 
 	searchText := args["text"].(string)
 	_ = seg.AddAnnotation("search_text", searchText)
 
-	// Mock search results
-	resources := []Resource{
-		{
-			ID:          "search-result-1",
-			Name:        fmt.Sprintf("service-matching-%s", strings.ToLower(searchText)),
-			Type:        "APPLICATION",
-			Description: fmt.Sprintf("Service that matches search term: %s", searchText),
-		},
+	vertices, err := store.FindVertices(ctx, graphstore.VertexFilter{Label: graphstore.LabelResource, NameContains: searchText})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search resources: %w", err)
+	}
+
+	resources := make([]Resource, 0, len(vertices))
+	for _, vertex := range vertices {
+		edges, err := store.FindEdges(ctx, graphstore.EdgeFilter{To: vertex.ID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get relationships of %s: %w", vertex.ID, err)
+		}
+		resource := toResource(vertex, edges)
+		if err := linkConflicts(ctx, store, resource.Relationships, edges); err != nil {
+			return nil, err
+		}
+		resources = append(resources, resource)
 	}
 
 	return resources, nil
 }
 
-func handleSearchResourcesByOwner(ctx context.Context, args map[string]interface{}) ([]Resource, error) {
-	_, seg := xray.BeginSubsegment(ctx, "search-resources-by-owner")
+func handleSearchResourcesByOwner(ctx context.Context, store graphstore.GraphStore, args map[string]interface{}) ([]Resource, error) {
+	ctx, seg := xray.BeginSubsegment(ctx, "search-resources-by-owner")
 	defer seg.Close(nil)
 
 	owner := args["owner"].(string)
 	_ = seg.AddAnnotation("owner", owner)
 
-	// Mock owner-based search
-	resources := []Resource{
-		{
-			ID:          "owner-resource-1",
-			Name:        fmt.Sprintf("%s-owned-service", owner),
-			Type:        "KUBERNETES_SERVICE",
-			Description: fmt.Sprintf("Service owned by %s", owner),
-		},
+	if owner == "" {
+		return []Resource{}, nil
 	}
 
-	return resources, nil
+	edges, err := store.FindEdges(ctx, graphstore.EdgeFilter{From: graphstore.VertexID(owner), Label: ownsLabel})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resources owned by %s: %w", owner, err)
+	}
+
+	return resourcesOf(ctx, store, edges)
 }
 
-func handleGetOwnershipCoverage(ctx context.Context, _ map[string]interface{}) (*OwnershipStats, error) {
-	_, seg := xray.BeginSubsegment(ctx, "get-ownership-coverage")
+func handleGetOwnershipCoverage(ctx context.Context, store graphstore.GraphStore, _ map[string]interface{}) (*OwnershipStats, error) {
+	ctx, seg := xray.BeginSubsegment(ctx, "get-ownership-coverage")
 	defer seg.Close(nil)
 
-	// Mock ownership statistics
+	vertices, err := store.FindVertices(ctx, graphstore.VertexFilter{Label: graphstore.LabelResource})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resources: %w", err)
+	}
+	ownerships, err := store.FindEdges(ctx, graphstore.EdgeFilter{Label: ownsLabel})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ownerships: %w", err)
+	}
+
+	owned := make(map[string]bool)
+	for _, edge := range ownerships {
+		owned[edge.To] = true
+	}
+
 	stats := &OwnershipStats{
-		TotalResources:     150,
-		OwnedResources:     127,
-		UnownedResources:   23,
-		CoveragePercentage: 84.7,
-		ByResourceType: []ResourceTypeStats{
-			{Type: "KUBERNETES_SERVICE", Total: 45, Owned: 42, Coverage: 93.3},
-			{Type: "AWS_RESOURCE", Total: 65, Owned: 58, Coverage: 89.2},
-			{Type: "GITHUB_REPOSITORY", Total: 40, Owned: 27, Coverage: 67.5},
-		},
-		ByTeam: []TeamStats{
-			{Team: "backend-team", ResourceCount: 35, AverageConfidence: 0.87},
-			{Team: "platform-team", ResourceCount: 42, AverageConfidence: 0.91},
-			{Team: "frontend-team", ResourceCount: 28, AverageConfidence: 0.79},
-		},
+		TotalResources: len(vertices),
+		ByResourceType: []ResourceTypeStats{},
+		ByTeam:         []TeamStats{},
 	}
 
+	byType := make(map[string]*ResourceTypeStats)
+	for _, vertex := range vertices {
+		edges, err := store.FindEdges(ctx, graphstore.EdgeFilter{To: vertex.ID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get relationships of %s: %w", vertex.ID, err)
+		}
+
+		resourceType := resourceTypeOf(vertex, edges)
+		if byType[resourceType] == nil {
+			byType[resourceType] = &ResourceTypeStats{Type: resourceType}
+		}
+		byType[resourceType].Total++
+		if owned[vertex.ID] {
+			byType[resourceType].Owned++
+			stats.OwnedResources++
+		}
+	}
+	stats.UnownedResources = stats.TotalResources - stats.OwnedResources
+	stats.CoveragePercentage = percentage(stats.OwnedResources, stats.TotalResources)
+
+	for _, typeStats := range byType {
+		typeStats.Coverage = percentage(typeStats.Owned, typeStats.Total)
+		stats.ByResourceType = append(stats.ByResourceType, *typeStats)
+	}
+	sort.Slice(stats.ByResourceType, func(i, j int) bool { return stats.ByResourceType[i].Type < stats.ByResourceType[j].Type })
+
+	byTeam := make(map[string][]graphstore.Edge)
+	for _, edge := range ownerships {
+		byTeam[edge.From] = append(byTeam[edge.From], edge)
+	}
+	for team, edges := range byTeam {
+		resources := make(map[string]bool)
+		for _, edge := range edges {
+			resources[edge.To] = true
+		}
+		stats.ByTeam = append(stats.ByTeam, TeamStats{
+			Team:              vertexName(ctx, store, team),
+			ResourceCount:     len(resources),
+			AverageConfidence: averageConfidence(edges),
+		})
+	}
+	sort.Slice(stats.ByTeam, func(i, j int) bool { return stats.ByTeam[i].Team < stats.ByTeam[j].Team })
+
 	return stats, nil
 }
 
-func handleGetConfidenceDistribution(ctx context.Context, _ map[string]interface{}) (*ConfidenceStats, error) {
-	_, seg := xray.BeginSubsegment(ctx, "get-confidence-distribution")
+func handleGetConfidenceDistribution(ctx context.Context, store graphstore.GraphStore, _ map[string]interface{}) (*ConfidenceStats, error) {
+	ctx, seg := xray.BeginSubsegment(ctx, "get-confidence-distribution")
 	defer seg.Close(nil)
 
-	// Mock confidence statistics
+	edges, err := store.FindEdges(ctx, graphstore.EdgeFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relationships: %w", err)
+	}
+
 	stats := &ConfidenceStats{
-		High:              85,
-		Medium:            42,
-		Low:               18,
-		VeryLow:           5,
-		AverageConfidence: 0.82,
-		DistributionBySource: []SourceConfidenceStats{
-			{Source: "aws-tags", Count: 45, AverageConfidence: 0.91},
-			{Source: "openshift-metadata", Count: 38, AverageConfidence: 0.89},
-			{Source: "github-codeowners", Count: 52, AverageConfidence: 0.78},
-			{Source: "github-activity", Count: 15, AverageConfidence: 0.64},
-		},
+		AverageConfidence:    averageConfidence(edges),
+		DistributionBySource: []SourceConfidenceStats{},
+	}
+
+	bySource := make(map[string][]graphstore.Edge)
+	for _, edge := range edges {
+		switch {
+		case edge.Confidence >= 0.8:
+			stats.High++
+		case edge.Confidence >= 0.6:
+			stats.Medium++
+		case edge.Confidence >= 0.4:
+			stats.Low++
+		default:
+			stats.VeryLow++
+		}
+		bySource[edge.Source] = append(bySource[edge.Source], edge)
+	}
+
+	for source, sourceEdges := range bySource {
+		stats.DistributionBySource = append(stats.DistributionBySource, SourceConfidenceStats{
+			Source:            source,
+			Count:             len(sourceEdges),
+			AverageConfidence: averageConfidence(sourceEdges),
+		})
 	}
+	sort.Slice(stats.DistributionBySource, func(i, j int) bool {
+		return stats.DistributionBySource[i].Source < stats.DistributionBySource[j].Source
+	})
 
 	return stats, nil
 }
 
+// handleGetConflicts lists the conflict review records, oldest first, optionally only those with the given
+// status or those past their SLA
+func handleGetConflicts(ctx context.Context, store graphstore.GraphStore, args map[string]interface{}) ([]Conflict, error) {
+	ctx, seg := xray.BeginSubsegment(ctx, "get-conflicts")
+	defer seg.Close(nil)
+
+	status, _ := args["status"].(string)
+	breachedOnly, _ := args["slaBreached"].(bool)
+	_ = seg.AddAnnotation("status", status)
+
+	vertices, err := store.FindVertices(ctx, graphstore.VertexFilter{Label: graphstore.LabelConflict})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conflicts: %w", err)
+	}
+
+	now := time.Now()
+	var records []graphstore.Conflict
+	for _, vertex := range vertices {
+		record, err := graphstore.ConflictFromVertex(vertex)
+		if err != nil {
+			log.Printf("Skipping unreadable conflict record: %v", err)
+			continue
+		}
+		if status != "" && !strings.EqualFold(record.Status, status) {
+			continue
+		}
+		if breachedOnly && !record.SLABreached(now) {
+			continue
+		}
+		records = append(records, record)
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].FirstSeen < records[j].FirstSeen })
+
+	conflicts := make([]Conflict, 0, len(records))
+	for _, record := range records {
+		conflict, err := toConflict(ctx, store, record, now)
+		if err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts, nil
+}
+
+func handleGetConflict(ctx context.Context, store graphstore.GraphStore, args map[string]interface{}) (*Conflict, error) {
+	ctx, seg := xray.BeginSubsegment(ctx, "get-conflict")
+	defer seg.Close(nil)
+
+	conflictID, _ := args["id"].(string)
+	_ = seg.AddAnnotation("conflict_id", conflictID)
+
+	if conflictID == "" {
+		return nil, fmt.Errorf("conflict id is required")
+	}
+
+	vertex, found, err := store.GetVertex(ctx, conflictID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conflict %s: %w", conflictID, err)
+	}
+	if !found || vertex.Label != graphstore.LabelConflict {
+		return nil, nil
+	}
+
+	record, err := graphstore.ConflictFromVertex(vertex)
+	if err != nil {
+		return nil, err
+	}
+	conflict, err := toConflict(ctx, store, record, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &conflict, nil
+}
+
+// toConflict converts a conflict record, with the candidates still in the graph, its age and SLA state at now
+func toConflict(ctx context.Context, store graphstore.GraphStore, record graphstore.Conflict, now time.Time) (Conflict, error) {
+	var edges []graphstore.Edge
+	for _, id := range record.Candidates {
+		edge, found, err := store.GetEdge(ctx, id)
+		if err != nil {
+			return Conflict{}, fmt.Errorf("failed to get candidate %s of conflict %s: %w", id, record.ID, err)
+		}
+		if found {
+			edges = append(edges, edge)
+		}
+	}
+	candidates, err := relationshipsOf(ctx, store, edges)
+	if err != nil {
+		return Conflict{}, err
+	}
+
+	evidence, err := json.Marshal(record.Evidence)
+	if err != nil {
+		return Conflict{}, fmt.Errorf("failed to encode evidence of conflict %s: %w", record.ID, err)
+	}
+
+	return Conflict{
+		ID:               record.ID,
+		Resource:         record.Resource,
+		RelationshipType: strings.ToUpper(record.RelationshipType),
+		Candidates:       candidates,
+		Evidence:         string(evidence),
+		Assignee:         record.Assignee,
+		Status:           strings.ToUpper(record.Status),
+		Resolution:       record.Resolution,
+		FirstSeen:        record.FirstSeen,
+		LastSeen:         record.LastSeen,
+		SLADueAt:         record.SLADueAt,
+		ClosedAt:         record.ClosedAt,
+		AgeHours:         record.Age(now).Hours(),
+		SLABreached:      record.SLABreached(now),
+	}, nil
+}
+
+// ownsLabel is the edge label of ownership relationships, as written by the relationship processor
+const ownsLabel = "owns"
+
+// resourcesOf groups edges by the resource they point to, in resource ID order
+func resourcesOf(ctx context.Context, store graphstore.GraphStore, edges []graphstore.Edge) ([]Resource, error) {
+	var ids []string
+	byResource := make(map[string][]graphstore.Edge)
+	for _, edge := range edges {
+		if _, seen := byResource[edge.To]; !seen {
+			ids = append(ids, edge.To)
+		}
+		byResource[edge.To] = append(byResource[edge.To], edge)
+	}
+	sort.Strings(ids)
+
+	resources := make([]Resource, 0, len(ids))
+	for _, id := range ids {
+		vertex, found, err := store.GetVertex(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get resource %s: %w", id, err)
+		}
+		if !found {
+			vertex = graphstore.Vertex{ID: id}
+		}
+		resource := toResource(vertex, byResource[id])
+		if err := linkConflicts(ctx, store, resource.Relationships, byResource[id]); err != nil {
+			return nil, err
+		}
+		resources = append(resources, resource)
+	}
+	return resources, nil
+}
+
+func toResource(vertex graphstore.Vertex, edges []graphstore.Edge) Resource {
+	name := vertex.Name
+	if name == "" {
+		name = vertex.ID
+	}
+	return Resource{
+		ID:            vertex.ID,
+		Name:          name,
+		Type:          resourceTypeOf(vertex, edges),
+		Description:   vertex.Properties["description"],
+		Relationships: toRelationships(edges),
+		CreatedAt:     vertex.Properties["created_at"],
+		UpdatedAt:     vertex.Properties["updated_at"],
+	}
+}
+
+func toRelationships(edges []graphstore.Edge) []Relationship {
+	relationships := make([]Relationship, 0, len(edges))
+	for _, edge := range edges {
+		createdAt := edge.Properties["created_at"]
+		if createdAt == "" {
+			createdAt = edge.Timestamp
+		}
+		relationships = append(relationships, Relationship{
+			ID:              edge.ID,
+			From:            edge.From,
+			To:              edge.To,
+			Type:            strings.ToUpper(edge.Label),
+			Confidence:      edge.Confidence,
+			ConfidenceLevel: confidenceLevel(edge),
+			Source:          edge.Source,
+			HasConflict:     edge.HasConflict,
+			LastValidated:   edge.Timestamp,
+			CreatedAt:       createdAt,
+			UpdatedAt:       edge.Timestamp,
+			Metadata:        relationshipMetadata(edge),
+		})
+	}
+	return relationships
+}
+
+// relationshipsOf converts edges to relationships linked to the relationships they conflict with
+func relationshipsOf(ctx context.Context, store graphstore.GraphStore, edges []graphstore.Edge) ([]Relationship, error) {
+	relationships := toRelationships(edges)
+	if err := linkConflicts(ctx, store, relationships, edges); err != nil {
+		return nil, err
+	}
+	return relationships, nil
+}
+
+// linkConflicts fills in the conflictsWith of the relationships converted from edges, in the same order
+// Counterparts are taken from edges where present and read from the store otherwise; ones since removed, e.g.
+// rejected in review, are left out
+func linkConflicts(ctx context.Context, store graphstore.GraphStore, relationships []Relationship, edges []graphstore.Edge) error {
+	byID := make(map[string]graphstore.Edge, len(edges))
+	for _, edge := range edges {
+		byID[edge.ID] = edge
+	}
+
+	for i, edge := range edges {
+		// The conflict properties are left behind once a conflict is resolved
+		if !isContested(edge) || edge.Properties[graphstore.ConflictsWithProperty] == "" {
+			continue
+		}
+		var ids []string
+		if err := json.Unmarshal([]byte(edge.Properties[graphstore.ConflictsWithProperty]), &ids); err != nil {
+			log.Printf("Ignoring malformed conflicts of %s: %v", edge.ID, err)
+			continue
+		}
+
+		var counterparts []graphstore.Edge
+		for _, id := range ids {
+			counterpart, found := byID[id]
+			if !found {
+				var err error
+				counterpart, found, err = store.GetEdge(ctx, id)
+				if err != nil {
+					return fmt.Errorf("failed to get relationship %s conflicting with %s: %w", id, edge.ID, err)
+				}
+			}
+			if found {
+				counterparts = append(counterparts, counterpart)
+			}
+		}
+		relationships[i].ConflictsWith = toRelationships(counterparts)
+	}
+	return nil
+}
+
+// isContested reports whether an edge is conflicted or superseded, i.e. whether its conflict properties are current
+func isContested(edge graphstore.Edge) bool {
+	return edge.HasConflict || edge.Properties[graphstore.SupersededProperty] == "true"
+}
+
+// relationshipMetadata encodes the properties of an edge, such as its confidence explanation, as the AWSJSON
+// metadata of a relationship; edges without properties have none
+func relationshipMetadata(edge graphstore.Edge) string {
+	metadata := make(map[string]interface{}, len(edge.Properties))
+	for key, value := range edge.Properties {
+		switch {
+		case key == "created_at", key == graphstore.ConflictsWithProperty:
+			continue
+		case key == graphstore.SupersededProperty && !isContested(edge):
+			continue
+		case key == confidenceExplanationProperty && json.Valid([]byte(value)):
+			metadata[key] = json.RawMessage(value)
+		default:
+			metadata[key] = value
+		}
+	}
+	if len(metadata) == 0 {
+		return ""
+	}
+
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+func confidenceLevel(edge graphstore.Edge) string {
+	switch {
+	case edge.HasConflict:
+		return "DISPUTED"
+	case edge.Confidence >= 0.9:
+		return "VERY_HIGH"
+	case edge.Confidence >= 0.8:
+		return "HIGH"
+	case edge.Confidence >= 0.6:
+		return "MEDIUM"
+	case edge.Confidence >= 0.4:
+		return "LOW"
+	default:
+		return "VERY_LOW"
+	}
+}
+
+// resourceTypeOf prefers the type recorded on the resource and otherwise infers it from the sources describing it
+func resourceTypeOf(vertex graphstore.Vertex, edges []graphstore.Edge) string {
+	if resourceType := vertex.Properties["type"]; resourceType != "" {
+		return resourceType
+	}
+	for _, edge := range edges {
+		switch {
+		case strings.HasPrefix(edge.Source, "openshift-"):
+			return "KUBERNETES_SERVICE"
+		case strings.HasPrefix(edge.Source, "aws-"):
+			return "AWS_RESOURCE"
+		case strings.HasPrefix(edge.Source, "github-"):
+			return "GITHUB_REPOSITORY"
+		}
+	}
+	return "APPLICATION"
+}
+
+func vertexName(ctx context.Context, store graphstore.GraphStore, id string) string {
+	vertex, found, err := store.GetVertex(ctx, id)
+	if err != nil || !found || vertex.Name == "" {
+		return id
+	}
+	return vertex.Name
+}
+
+func averageConfidence(edges []graphstore.Edge) float64 {
+	if len(edges) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, edge := range edges {
+		total += edge.Confidence
+	}
+	return total / float64(len(edges))
+}
+
+func percentage(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
+
 func main() {
 	lambda.Start(HandleRequest)
-}
\ No newline at end of file
+}
@@ -1,19 +1,89 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"strings"
 	"testing"
+	"time"
 
 	common "bacon/src/shared"
+	"bacon/src/shared/graphstore"
 )
 
+// testStore is the in-memory graph HandleRequest falls back to without a Neptune endpoint
+var testStore = graphstore.Local
+
 func TestMain(m *testing.M) {
 	// Setup test environment
+	if err := seedTestGraph(context.Background(), testStore); err != nil {
+		panic(err)
+	}
 	m.Run()
 }
 
+// seedTestGraph writes a small ownership graph, with one disputed resource, as the relationship processor would
+func seedTestGraph(ctx context.Context, store graphstore.GraphStore) error {
+	err := store.UpsertVertices(ctx, []graphstore.Vertex{
+		{ID: graphstore.VertexID("backend-team"), Label: graphstore.LabelUser, Name: "backend-team"},
+		{ID: graphstore.VertexID("platform-team"), Label: graphstore.LabelUser, Name: "platform-team"},
+		{ID: graphstore.VertexID("team-a"), Label: graphstore.LabelUser, Name: "team-a"},
+		{ID: graphstore.VertexID("team-b"), Label: graphstore.LabelUser, Name: "team-b"},
+		{ID: graphstore.VertexID("test-owner"), Label: graphstore.LabelUser, Name: "test-owner"},
+		{
+			ID: graphstore.VertexID("example-api-service"), Label: graphstore.LabelResource, Name: "example-api-service",
+			Properties: map[string]string{"type": "KUBERNETES_SERVICE", "description": "Main API service for the application"},
+		},
+		{ID: graphstore.VertexID("infrastructure-service"), Label: graphstore.LabelResource, Name: "infrastructure-service"},
+		{ID: graphstore.VertexID("disputed-service"), Label: graphstore.LabelResource, Name: "disputed-service"},
+		{ID: graphstore.VertexID("test-search-service"), Label: graphstore.LabelResource, Name: "test-search-service"},
+		{ID: graphstore.VertexID("unowned-service"), Label: graphstore.LabelResource, Name: "unowned-service"},
+	})
+	if err != nil {
+		return err
+	}
+
+	edge := func(from, to, source string, confidence float64, conflict bool) graphstore.Edge {
+		return graphstore.Edge{
+			ID:          graphstore.EdgeID(from, "owns", to, source),
+			Label:       "owns",
+			From:        graphstore.VertexID(from),
+			To:          graphstore.VertexID(to),
+			Confidence:  confidence,
+			Source:      source,
+			HasConflict: conflict,
+			Timestamp:   "2024-01-15T10:00:00Z",
+		}
+	}
+	return store.UpsertEdges(ctx, []graphstore.Edge{
+		edge("backend-team", "example-api-service", "openshift-metadata", 0.85, false),
+		edge("platform-team", "infrastructure-service", "aws-tags", 0.92, false),
+		edge("team-a", "disputed-service", "github-codeowners", 0.75, true),
+		edge("team-b", "disputed-service", "datadog-service-catalog", 0.7, true),
+		edge("test-owner", "test-search-service", "github-codeowners", 0.88, false),
+	})
+}
+
+// seedResource adds a resource owned by backend-team under the given ID
+func seedResource(t *testing.T, id string) {
+	ctx := context.Background()
+	if err := testStore.UpsertVertices(ctx, []graphstore.Vertex{{ID: id, Label: graphstore.LabelResource, Name: id}}); err != nil {
+		t.Fatalf("Failed to seed resource: %v", err)
+	}
+	err := testStore.UpsertEdges(ctx, []graphstore.Edge{{
+		ID:         graphstore.EdgeID("backend-team", "owns", id, "aws-tags"),
+		Label:      "owns",
+		From:       graphstore.VertexID("backend-team"),
+		To:         id,
+		Confidence: 0.9,
+		Source:     "aws-tags",
+	}})
+	if err != nil {
+		t.Fatalf("Failed to seed ownership: %v", err)
+	}
+}
+
 // Test HandleRequest function with comprehensive scenarios
 func TestHandleRequest(t *testing.T) {
 	ctx, cleanup := common.TestContext("query-resolver-test")
@@ -33,7 +103,7 @@ func TestHandleRequest(t *testing.T) {
 					ParentTypeName: "Query",
 				},
 				Arguments: map[string]interface{}{
-					"id": "test-resource-123",
+					"id": graphstore.VertexID("example-api-service"),
 				},
 			},
 			expectError:    false,
@@ -190,7 +260,7 @@ func TestHandleGetResource(t *testing.T) {
 			args: map[string]interface{}{
 				"id": "",
 			},
-			expectError: false, // Function doesn't validate, just uses the value
+			expectError: true,
 		},
 		{
 			name: "very long resource ID",
@@ -217,7 +287,11 @@ func TestHandleGetResource(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			resource, err := handleGetResource(ctx, tc.args)
+			if id := tc.args["id"].(string); id != "" {
+				seedResource(t, id)
+			}
+
+			resource, err := handleGetResource(ctx, testStore, tc.args)
 
 			if tc.expectError && err == nil {
 				t.Error("Expected error but got none")
@@ -313,7 +387,7 @@ func TestHandleGetResourcesByConfidence(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			resources, err := handleGetResourcesByConfidence(ctx, tc.args)
+			resources, err := handleGetResourcesByConfidence(ctx, testStore, tc.args)
 
 			if tc.expectError && err == nil {
 				t.Error("Expected error but got none")
@@ -353,7 +427,7 @@ func TestHandleGetConflictedRelationships(t *testing.T) {
 	defer cleanup()
 
 	// Test with empty arguments
-	relationships, err := handleGetConflictedRelationships(ctx, map[string]interface{}{})
+	relationships, err := handleGetConflictedRelationships(ctx, testStore, map[string]interface{}{})
 
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
@@ -399,7 +473,7 @@ func TestHandleGetRelationshipsBySource(t *testing.T) {
 				"source": tc.source,
 			}
 
-			relationships, err := handleGetRelationshipsBySource(ctx, args)
+			relationships, err := handleGetRelationshipsBySource(ctx, testStore, args)
 
 			if err != nil {
 				t.Errorf("Unexpected error: %v", err)
@@ -448,7 +522,7 @@ func TestHandleSearchResources(t *testing.T) {
 				"text": tc.searchText,
 			}
 
-			resources, err := handleSearchResources(ctx, args)
+			resources, err := handleSearchResources(ctx, testStore, args)
 
 			if err != nil {
 				t.Errorf("Unexpected error: %v", err)
@@ -459,15 +533,8 @@ func TestHandleSearchResources(t *testing.T) {
 			}
 			for _, resource := range resources {
 				// Validate that search text is reflected in the result
-				if tc.searchText != "" {
-					expectedName := fmt.Sprintf("service-matching-%s", strings.ToLower(tc.searchText))
-					if resource.Name != expectedName {
-						t.Errorf("Expected name to contain search text, got %s", resource.Name)
-					}
-					expectedDesc := fmt.Sprintf("Service that matches search term: %s", tc.searchText)
-					if resource.Description != expectedDesc {
-						t.Errorf("Expected description to contain search text, got %s", resource.Description)
-					}
+				if !strings.Contains(resource.Name, tc.searchText) {
+					t.Errorf("Expected name to contain search text, got %s", resource.Name)
 				}
 			}
 		})
@@ -498,7 +565,7 @@ func TestHandleSearchResourcesByOwner(t *testing.T) {
 				"owner": tc.owner,
 			}
 
-			resources, err := handleSearchResourcesByOwner(ctx, args)
+			resources, err := handleSearchResourcesByOwner(ctx, testStore, args)
 
 			if err != nil {
 				t.Errorf("Unexpected error: %v", err)
@@ -509,13 +576,10 @@ func TestHandleSearchResourcesByOwner(t *testing.T) {
 			}
 			for _, resource := range resources {
 				// Validate that owner is reflected in the result
-				expectedName := fmt.Sprintf("%s-owned-service", tc.owner)
-				if resource.Name != expectedName {
-					t.Errorf("Expected name %s, got %s", expectedName, resource.Name)
-				}
-				expectedDesc := fmt.Sprintf("Service owned by %s", tc.owner)
-				if resource.Description != expectedDesc {
-					t.Errorf("Expected description %s, got %s", expectedDesc, resource.Description)
+				for _, rel := range resource.Relationships {
+					if rel.From != graphstore.VertexID(tc.owner) || rel.Type != "OWNS" {
+						t.Errorf("Expected only %s ownerships, got %+v", tc.owner, rel)
+					}
 				}
 			}
 		})
@@ -527,7 +591,7 @@ func TestHandleGetOwnershipCoverage(t *testing.T) {
 	ctx, cleanup := common.TestContext("get-ownership-coverage-test")
 	defer cleanup()
 
-	stats, err := handleGetOwnershipCoverage(ctx, map[string]interface{}{})
+	stats, err := handleGetOwnershipCoverage(ctx, testStore, map[string]interface{}{})
 
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
@@ -593,7 +657,7 @@ func TestHandleGetConfidenceDistribution(t *testing.T) {
 	ctx, cleanup := common.TestContext("get-confidence-distribution-test")
 	defer cleanup()
 
-	stats, err := handleGetConfidenceDistribution(ctx, map[string]interface{}{})
+	stats, err := handleGetConfidenceDistribution(ctx, testStore, map[string]interface{}{})
 
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
@@ -638,6 +702,208 @@ func TestHandleGetConfidenceDistribution(t *testing.T) {
 	}
 }
 
+// Test the resolvers answer from the graph rather than fixed data
+func TestQueriesReflectGraph(t *testing.T) {
+	ctx, cleanup := common.TestContext("queries-reflect-graph-test")
+	defer cleanup()
+
+	store := graphstore.NewMemoryStore()
+	if err := seedTestGraph(ctx, store); err != nil {
+		t.Fatalf("Failed to seed graph: %v", err)
+	}
+
+	resource, err := handleGetResource(ctx, store, map[string]interface{}{"id": graphstore.VertexID("example-api-service")})
+	if err != nil || resource == nil {
+		t.Fatalf("Expected the resource, got %v (err %v)", resource, err)
+	}
+	if resource.Name != "example-api-service" || resource.Type != "KUBERNETES_SERVICE" || len(resource.Relationships) != 1 {
+		t.Errorf("Unexpected resource %+v", resource)
+	}
+	if rel := resource.Relationships[0]; rel.From != graphstore.VertexID("backend-team") || rel.Type != "OWNS" || rel.ConfidenceLevel != "HIGH" {
+		t.Errorf("Unexpected relationship %+v", rel)
+	}
+
+	missing, err := handleGetResource(ctx, store, map[string]interface{}{"id": "entity:missing"})
+	if err != nil || missing != nil {
+		t.Errorf("Expected no resource for an unknown ID, got %v (err %v)", missing, err)
+	}
+
+	conflicted, _ := handleGetConflictedRelationships(ctx, store, map[string]interface{}{})
+	if len(conflicted) != 2 || conflicted[0].To != graphstore.VertexID("disputed-service") {
+		t.Errorf("Expected both claims on disputed-service, got %+v", conflicted)
+	}
+
+	confident, _ := handleGetResourcesByConfidence(ctx, store, map[string]interface{}{"minConfidence": 0.86})
+	if len(confident) != 2 {
+		t.Errorf("Expected 2 resources with confident owners, got %+v", confident)
+	}
+
+	owned, _ := handleSearchResourcesByOwner(ctx, store, map[string]interface{}{"owner": "test-owner"})
+	if len(owned) != 1 || owned[0].Name != "test-search-service" {
+		t.Errorf("Expected test-owner's resource, got %+v", owned)
+	}
+
+	found, _ := handleSearchResources(ctx, store, map[string]interface{}{"text": "service"})
+	if len(found) != 5 {
+		t.Errorf("Expected every resource to match, got %d", len(found))
+	}
+
+	coverage, _ := handleGetOwnershipCoverage(ctx, store, map[string]interface{}{})
+	if coverage.TotalResources != 5 || coverage.OwnedResources != 4 || coverage.CoveragePercentage != 80 {
+		t.Errorf("Unexpected coverage %+v", coverage)
+	}
+	if len(coverage.ByTeam) != 5 || coverage.ByTeam[0].Team != "backend-team" {
+		t.Errorf("Unexpected team stats %+v", coverage.ByTeam)
+	}
+
+	distribution, _ := handleGetConfidenceDistribution(ctx, store, map[string]interface{}{})
+	if distribution.High != 3 || distribution.Medium != 2 || len(distribution.DistributionBySource) != 4 {
+		t.Errorf("Unexpected distribution %+v", distribution)
+	}
+}
+
+// Test relationships expose the stored confidence explanation as metadata
+func TestRelationshipMetadata(t *testing.T) {
+	explanation := `{"confidence":0.62,"base_confidence":0.8,"source_weight":0.8,"freshness_multiplier":0.97,"agreement_bonus":0,"sources":[]}`
+	relationships := toRelationships([]graphstore.Edge{
+		{ID: "e1", Properties: map[string]string{confidenceExplanationProperty: explanation, "from_rule": "github_team", "created_at": "2024-05-01T09:30:00Z"}},
+		{ID: "e2"},
+	})
+
+	if !strings.Contains(relationships[0].Metadata, `"confidence_explanation":{"confidence":0.62,`) {
+		t.Errorf("Expected the explanation to be nested as JSON, got %s", relationships[0].Metadata)
+	}
+	if !strings.Contains(relationships[0].Metadata, `"from_rule":"github_team"`) || strings.Contains(relationships[0].Metadata, "created_at") {
+		t.Errorf("Expected the other properties without created_at, got %s", relationships[0].Metadata)
+	}
+	if relationships[1].Metadata != "" {
+		t.Errorf("Expected no metadata without properties, got %s", relationships[1].Metadata)
+	}
+}
+
+// Test conflicted relationships link to the relationships they conflict with
+func TestRelationshipConflictsWith(t *testing.T) {
+	ctx, cleanup := common.TestContext("relationship-conflicts-with-test")
+	defer cleanup()
+
+	store := graphstore.NewMemoryStore()
+	if err := store.UpsertVertices(ctx, []graphstore.Vertex{
+		{ID: "entity:team-a", Label: graphstore.LabelUser}, {ID: "entity:team-b", Label: graphstore.LabelUser},
+		{ID: "entity:repo", Label: graphstore.LabelResource},
+	}); err != nil {
+		t.Fatalf("Failed to seed graph: %v", err)
+	}
+	err := store.UpsertEdges(ctx, []graphstore.Edge{
+		{ID: "winner", Label: "owns", From: "entity:team-a", To: "entity:repo", Source: "github-codeowners", HasConflict: true,
+			Properties: map[string]string{graphstore.ConflictsWithProperty: `["loser","rejected"]`, graphstore.SupersededProperty: "false"}},
+		{ID: "loser", Label: "owns", From: "entity:team-b", To: "entity:repo", Source: "aws-tags", HasConflict: true,
+			Properties: map[string]string{graphstore.ConflictsWithProperty: `["winner"]`, graphstore.SupersededProperty: "true"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to seed graph: %v", err)
+	}
+
+	// The counterpart is read from the store when the query did not return it
+	bySource, err := handleGetRelationshipsBySource(ctx, store, map[string]interface{}{"source": "github-codeowners"})
+	if err != nil || len(bySource) != 1 {
+		t.Fatalf("Expected the CODEOWNERS relationship, got %+v (err %v)", bySource, err)
+	}
+	if conflicts := bySource[0].ConflictsWith; len(conflicts) != 1 || conflicts[0].ID != "loser" || conflicts[0].Source != "aws-tags" {
+		t.Errorf("Expected the winner to link to the loser only, got %+v", conflicts)
+	}
+
+	resource, err := handleGetResource(ctx, store, map[string]interface{}{"id": "entity:repo"})
+	if err != nil || resource == nil || len(resource.Relationships) != 2 {
+		t.Fatalf("Expected both claims on the resource, got %+v (err %v)", resource, err)
+	}
+	loser := resource.Relationships[0]
+	if loser.ID != "loser" || len(loser.ConflictsWith) != 1 || loser.ConflictsWith[0].ID != "winner" {
+		t.Errorf("Expected the loser to link to the winner, got %+v", loser)
+	}
+	if !strings.Contains(loser.Metadata, `"superseded":"true"`) || strings.Contains(loser.Metadata, graphstore.ConflictsWithProperty) {
+		t.Errorf("Expected the superseded flag in the metadata without the raw links, got %s", loser.Metadata)
+	}
+
+	// A claim overruled below the conflict threshold is superseded without being conflicted
+	overruled, err := relationshipsOf(ctx, store, []graphstore.Edge{
+		{ID: "weak", Properties: map[string]string{graphstore.ConflictsWithProperty: `["winner"]`, graphstore.SupersededProperty: "true"}},
+	})
+	if err != nil || len(overruled[0].ConflictsWith) != 1 || overruled[0].ConflictsWith[0].ID != "winner" ||
+		!strings.Contains(overruled[0].Metadata, `"superseded":"true"`) {
+		t.Errorf("Expected the superseded relationship to link to the winner, got %+v (err %v)", overruled[0], err)
+	}
+
+	// Once resolved, the links left on the edge are no longer current
+	resolved, err := relationshipsOf(ctx, store, []graphstore.Edge{
+		{ID: "winner", Properties: map[string]string{graphstore.ConflictsWithProperty: `["loser"]`, graphstore.SupersededProperty: "false"}},
+	})
+	if err != nil || resolved[0].ConflictsWith != nil || resolved[0].Metadata != "" {
+		t.Errorf("Expected no conflicts on a resolved relationship, got %+v (err %v)", resolved[0], err)
+	}
+}
+
+// Test the conflict review queue lists records oldest first with their age and SLA state
+func TestHandleGetConflicts(t *testing.T) {
+	ctx, cleanup := common.TestContext("get-conflicts-test")
+	defer cleanup()
+
+	store := graphstore.NewMemoryStore()
+	if err := seedTestGraph(ctx, store); err != nil {
+		t.Fatalf("Failed to seed graph: %v", err)
+	}
+	edges, _ := store.FindEdges(ctx, graphstore.EdgeFilter{})
+	now := time.Now().UTC()
+	breached := graphstore.Conflict{
+		ID: "conflict:breached", Resource: edges[0].To, RelationshipType: "owns", Candidates: []string{edges[0].ID, "edge:deleted"},
+		Evidence: []graphstore.ConflictEvidence{{Relationship: edges[0].ID, Owner: "backend-team", Source: "aws-tags", Confidence: 0.9}},
+		Assignee: "backend-team", Status: graphstore.ConflictOpen,
+		FirstSeen: now.Add(-96 * time.Hour).Format(time.RFC3339), SLADueAt: now.Add(-24 * time.Hour).Format(time.RFC3339),
+	}
+	recent := graphstore.Conflict{
+		ID: "conflict:recent", Resource: edges[1].To, RelationshipType: "owns", Status: graphstore.ConflictOpen,
+		FirstSeen: now.Add(-time.Hour).Format(time.RFC3339), SLADueAt: now.Add(71 * time.Hour).Format(time.RFC3339),
+	}
+	closed := graphstore.Conflict{
+		ID: "conflict:closed", Resource: edges[2].To, RelationshipType: "manages", Status: graphstore.ConflictClosed,
+		Resolution: graphstore.ConflictResolvedByAgreement, FirstSeen: now.Add(-200 * time.Hour).Format(time.RFC3339),
+		SLADueAt: now.Add(-128 * time.Hour).Format(time.RFC3339), ClosedAt: now.Add(-190 * time.Hour).Format(time.RFC3339),
+	}
+	if err := store.UpsertVertices(ctx, []graphstore.Vertex{recent.Vertex(), breached.Vertex(), closed.Vertex()}); err != nil {
+		t.Fatalf("Failed to seed conflicts: %v", err)
+	}
+
+	all, err := handleGetConflicts(ctx, store, map[string]interface{}{})
+	if err != nil || len(all) != 3 || all[0].ID != "conflict:closed" || all[1].ID != "conflict:breached" {
+		t.Fatalf("Expected every conflict oldest first, got %+v (err %v)", all, err)
+	}
+	if all[0].Status != "CLOSED" || all[0].SLABreached || int(all[0].AgeHours) != 10 {
+		t.Errorf("Expected a closed conflict to stop ageing, got %+v", all[0])
+	}
+
+	open, _ := handleGetConflicts(ctx, store, map[string]interface{}{"status": "OPEN", "slaBreached": true})
+	if len(open) != 1 || open[0].ID != "conflict:breached" {
+		t.Fatalf("Expected only the breached open conflict, got %+v", open)
+	}
+	conflict := open[0]
+	if !conflict.SLABreached || int(conflict.AgeHours) != 96 || conflict.RelationshipType != "OWNS" || conflict.Assignee != "backend-team" {
+		t.Errorf("Unexpected conflict %+v", conflict)
+	}
+	if len(conflict.Candidates) != 1 || conflict.Candidates[0].ID != edges[0].ID {
+		t.Errorf("Expected the candidates still in the graph, got %+v", conflict.Candidates)
+	}
+	if !strings.Contains(conflict.Evidence, `"source":"aws-tags"`) {
+		t.Errorf("Expected the evidence as JSON, got %s", conflict.Evidence)
+	}
+
+	single, err := handleGetConflict(ctx, store, map[string]interface{}{"id": "conflict:recent"})
+	if err != nil || single == nil || single.SLABreached || single.Status != "OPEN" {
+		t.Errorf("Expected the recent conflict within its SLA, got %+v (err %v)", single, err)
+	}
+	if missing, err := handleGetConflict(ctx, store, map[string]interface{}{"id": edges[0].To}); err != nil || missing != nil {
+		t.Errorf("Expected no conflict for a resource ID, got %+v (err %v)", missing, err)
+	}
+}
+
 // Property-based tests using rapid testing approach
 func TestPropertyBasedAppSyncEventHandling(t *testing.T) {
 	ctx, cleanup := common.TestContext("property-based-appsync-test")
@@ -718,7 +984,7 @@ func TestPropertyBasedConfidenceValues(t *testing.T) {
 			"minConfidence": minConfidence,
 		}
 
-		resources, err := handleGetResourcesByConfidence(ctx, args)
+		resources, err := handleGetResourcesByConfidence(ctx, testStore, args)
 
 		if err != nil {
 			t.Errorf("Unexpected error for confidence %f: %v", minConfidence, err)
@@ -771,7 +1037,7 @@ func TestDefensiveProgramming(t *testing.T) {
 		}()
 
 		// Missing "id" argument should be handled gracefully
-		_, err := handleGetResource(ctx, map[string]interface{}{})
+		_, err := handleGetResource(ctx, testStore, map[string]interface{}{})
 		if err != nil {
 			t.Logf("Expected behavior with missing arguments: %v", err)
 		}
@@ -789,7 +1055,7 @@ func TestDefensiveProgramming(t *testing.T) {
 			"minConfidence": "not-a-number",
 		}
 
-		_, err := handleGetResourcesByConfidence(ctx, args)
+		_, err := handleGetResourcesByConfidence(ctx, testStore, args)
 		if err != nil {
 			t.Logf("Expected behavior with wrong argument type: %v", err)
 		}
@@ -808,7 +1074,7 @@ func TestMutationTargets(t *testing.T) {
 			"id": "",
 		}
 
-		result, err := handleGetResource(ctx, args)
+		result, err := handleGetResource(ctx, testStore, args)
 
 		// Should handle empty ID gracefully
 		if err != nil {
@@ -824,7 +1090,7 @@ func TestMutationTargets(t *testing.T) {
 			"minConfidence": 0.0,
 		}
 
-		result, err := handleGetResourcesByConfidence(ctx, args)
+		result, err := handleGetResourcesByConfidence(ctx, testStore, args)
 
 		if err != nil {
 			t.Errorf("Zero confidence should not cause error: %v", err)
@@ -839,7 +1105,7 @@ func TestMutationTargets(t *testing.T) {
 			"text": "",
 		}
 
-		result, err := handleSearchResources(ctx, args)
+		result, err := handleSearchResources(ctx, testStore, args)
 
 		if err != nil {
 			t.Errorf("Empty search text should not cause error: %v", err)
@@ -876,7 +1142,7 @@ func BenchmarkGetOwnershipCoverage(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = handleGetOwnershipCoverage(ctx, map[string]interface{}{})
+		_, _ = handleGetOwnershipCoverage(ctx, testStore, map[string]interface{}{})
 	}
 }
 
@@ -884,8 +1150,9 @@ func BenchmarkGetOwnershipCoverage(b *testing.B) {
 func generateRandomArgsForField(fieldName string) map[string]interface{} {
 	switch fieldName {
 	case "getResource":
+		resources := []string{"example-api-service", "infrastructure-service", "disputed-service", "test-search-service"}
 		return map[string]interface{}{
-			"id": generateRandomString(20),
+			"id": graphstore.VertexID(resources[rand.Intn(len(resources))]),
 		}
 	case "getResourcesByConfidence":
 		return map[string]interface{}{
@@ -0,0 +1,41 @@
+package graphstore
+
+import "encoding/json"
+
+// LabelArchive marks the audit record the relationship processor keeps of an edge it removed once no evidence
+// was left for it
+const LabelArchive = "Archive"
+
+// Why an edge was archived, and the properties an archive record holds it in
+const (
+	ArchiveRetracted = "retracted" // the source stopped making the claim
+	ArchiveDeleted   = "deleted"   // an endpoint was deleted at the source
+	ArchiveMerged    = "merged"    // the relationship is now stored under the claim of a stronger source
+	ArchiveResolved  = "resolved"  // an endpoint now resolves to another identity
+
+	ArchiveRelationshipProperty = "relationship_id"
+	ArchiveReasonProperty       = "reason"
+	ArchiveSnapshotProperty     = "snapshot_id" // the snapshot that no longer contained the claim, if any
+	ArchiveEdgeProperty         = "edge"        // JSON of the edge as it was last stored
+	ArchivedAtProperty          = "archived_at"
+)
+
+// ArchiveID derives the ID of the archive record of an edge
+func ArchiveID(edgeID string) string {
+	return "archive:" + edgeID
+}
+
+// ArchiveVertex is the audit record of an archived edge; archiving the same edge again updates the same record
+func ArchiveVertex(edge Edge, reason, snapshotID, archivedAt string) Vertex {
+	stored, _ := json.Marshal(edge)
+	properties := map[string]string{
+		ArchiveRelationshipProperty: edge.ID,
+		ArchiveReasonProperty:       reason,
+		ArchiveEdgeProperty:         string(stored),
+		ArchivedAtProperty:          archivedAt,
+	}
+	if snapshotID != "" {
+		properties[ArchiveSnapshotProperty] = snapshotID
+	}
+	return Vertex{ID: ArchiveID(edge.ID), Label: LabelArchive, Name: edge.ID, Properties: properties}
+}
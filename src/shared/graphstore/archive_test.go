@@ -0,0 +1,27 @@
+package graphstore
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveVertex(t *testing.T) {
+	edge := Edge{ID: EdgeID("team-a", "owns", "payments", "aws-tags"), Label: "owns", From: VertexID("team-a"), To: VertexID("payments"), Source: "aws-tags", Confidence: 0.8}
+
+	vertex := ArchiveVertex(edge, ArchiveRetracted, "snapshot-2", "2024-05-01T09:00:00Z")
+	assert.Equal(t, ArchiveID(edge.ID), vertex.ID)
+	assert.Equal(t, LabelArchive, vertex.Label)
+	assert.Equal(t, ArchiveRetracted, vertex.Properties[ArchiveReasonProperty])
+	assert.Equal(t, "snapshot-2", vertex.Properties[ArchiveSnapshotProperty])
+
+	var archived Edge
+	require.NoError(t, json.Unmarshal([]byte(vertex.Properties[ArchiveEdgeProperty]), &archived))
+	assert.Equal(t, edge, archived)
+
+	// Without a snapshot the property is left out
+	_, found := ArchiveVertex(edge, ArchiveDeleted, "", "2024-05-01T09:00:00Z").Properties[ArchiveSnapshotProperty]
+	assert.False(t, found)
+}
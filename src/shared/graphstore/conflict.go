@@ -0,0 +1,153 @@
+package graphstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// LabelConflict marks the review record the relationship processor opens for each conflict it detects
+const LabelConflict = "Conflict"
+
+// Conflict statuses and how a conflict was closed
+const (
+	ConflictOpen   = "open"
+	ConflictClosed = "closed"
+
+	ConflictResolvedByAgreement = "agreement" // the sources came to agree
+	ConflictResolvedManually    = "manual"    // a reviewer picked the winner
+)
+
+// ConflictProperty holds on a conflicted edge the ID of the conflict record it is a candidate of
+const ConflictProperty = "conflict_id"
+
+// Properties a conflict record is held in
+const (
+	conflictResourceProperty   = "resource"
+	conflictTypeProperty       = "relationship_type"
+	conflictCandidatesProperty = "candidates"
+	conflictEvidenceProperty   = "evidence"
+	conflictAssigneeProperty   = "assignee"
+	conflictStatusProperty     = "status"
+	conflictResolutionProperty = "resolution"
+	conflictWinnerProperty     = "winner"
+	conflictFirstSeenProperty  = "first_seen"
+	conflictLastSeenProperty   = "last_seen"
+	conflictSLADueAtProperty   = "sla_due_at"
+	conflictClosedAtProperty   = "closed_at"
+)
+
+// Conflict is the review record of the competing claims of several sources on one resource
+// It stays open until the sources come to agree or a reviewer resolves it. Times are RFC 3339.
+type Conflict struct {
+	ID               string
+	Resource         string // vertex ID of the contested resource
+	RelationshipType string
+	Candidates       []string // edge IDs of the competing claims, winners first
+	Evidence         []ConflictEvidence
+	Assignee         string
+	Status           string
+	Resolution       string
+	Winner           string // edge ID of the claim a reviewer picked
+	FirstSeen        string
+	LastSeen         string
+	SLADueAt         string
+	ClosedAt         string
+}
+
+// ConflictEvidence is the claim of one candidate as the processor last saw it
+type ConflictEvidence struct {
+	Relationship string  `json:"relationship"`
+	Owner        string  `json:"owner"`
+	Source       string  `json:"source"`
+	Confidence   float64 `json:"confidence"`
+	Timestamp    string  `json:"timestamp,omitempty"`
+	Superseded   bool    `json:"superseded,omitempty"`
+}
+
+// ConflictID derives the stable ID of the conflict record over the claims of one relationship type on a target,
+// so a conflict detected again updates its open record
+func ConflictID(relationshipType, target string) string {
+	sum := sha256.Sum256([]byte(relationshipType + "\x00" + target))
+	return "conflict:" + hex.EncodeToString(sum[:16])
+}
+
+// Vertex returns the vertex a conflict record is stored as
+func (c Conflict) Vertex() Vertex {
+	candidates, _ := json.Marshal(c.Candidates)
+	evidence, _ := json.Marshal(c.Evidence)
+	return Vertex{ID: c.ID, Label: LabelConflict, Name: c.ID, Properties: map[string]string{
+		conflictResourceProperty:   c.Resource,
+		conflictTypeProperty:       c.RelationshipType,
+		conflictCandidatesProperty: string(candidates),
+		conflictEvidenceProperty:   string(evidence),
+		conflictAssigneeProperty:   c.Assignee,
+		conflictStatusProperty:     c.Status,
+		conflictResolutionProperty: c.Resolution,
+		conflictWinnerProperty:     c.Winner,
+		conflictFirstSeenProperty:  c.FirstSeen,
+		conflictLastSeenProperty:   c.LastSeen,
+		conflictSLADueAtProperty:   c.SLADueAt,
+		conflictClosedAtProperty:   c.ClosedAt,
+	}}
+}
+
+// ConflictFromVertex reads a conflict record back from its vertex
+func ConflictFromVertex(vertex Vertex) (Conflict, error) {
+	if vertex.Label != LabelConflict {
+		return Conflict{}, fmt.Errorf("vertex %s is a %s, not a conflict", vertex.ID, vertex.Label)
+	}
+
+	properties := vertex.Properties
+	conflict := Conflict{
+		ID:               vertex.ID,
+		Resource:         properties[conflictResourceProperty],
+		RelationshipType: properties[conflictTypeProperty],
+		Assignee:         properties[conflictAssigneeProperty],
+		Status:           properties[conflictStatusProperty],
+		Resolution:       properties[conflictResolutionProperty],
+		Winner:           properties[conflictWinnerProperty],
+		FirstSeen:        properties[conflictFirstSeenProperty],
+		LastSeen:         properties[conflictLastSeenProperty],
+		SLADueAt:         properties[conflictSLADueAtProperty],
+		ClosedAt:         properties[conflictClosedAtProperty],
+	}
+	if err := json.Unmarshal([]byte(properties[conflictCandidatesProperty]), &conflict.Candidates); err != nil {
+		return Conflict{}, fmt.Errorf("conflict %s has malformed candidates: %w", vertex.ID, err)
+	}
+	if err := json.Unmarshal([]byte(properties[conflictEvidenceProperty]), &conflict.Evidence); err != nil {
+		return Conflict{}, fmt.Errorf("conflict %s has malformed evidence: %w", vertex.ID, err)
+	}
+	return conflict, nil
+}
+
+// Open reports whether the conflict still awaits resolution
+func (c Conflict) Open() bool {
+	return c.Status == ConflictOpen
+}
+
+// Age is how long the conflict has been, or was, open at now
+func (c Conflict) Age(now time.Time) time.Duration {
+	firstSeen, err := time.Parse(time.RFC3339, c.FirstSeen)
+	if err != nil {
+		return 0
+	}
+	if closedAt, err := time.Parse(time.RFC3339, c.ClosedAt); err == nil && !c.Open() {
+		now = closedAt
+	}
+	return now.Sub(firstSeen)
+}
+
+// SLABreached reports whether the conflict was still open when its SLA ran out
+func (c Conflict) SLABreached(now time.Time) bool {
+	dueAt, err := time.Parse(time.RFC3339, c.SLADueAt)
+	if err != nil {
+		return false
+	}
+	if closedAt, err := time.Parse(time.RFC3339, c.ClosedAt); err == nil && !c.Open() {
+		now = closedAt
+	}
+	return now.After(dueAt)
+}
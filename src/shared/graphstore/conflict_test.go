@@ -0,0 +1,59 @@
+package graphstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConflict_RoundTrip(t *testing.T) {
+	conflict := Conflict{
+		ID:               ConflictID("owns", "payments"),
+		Resource:         VertexID("payments"),
+		RelationshipType: "owns",
+		Candidates:       []string{"edge:a", "edge:b"},
+		Evidence: []ConflictEvidence{
+			{Relationship: "edge:a", Owner: "team-a", Source: "github-codeowners", Confidence: 0.8},
+			{Relationship: "edge:b", Owner: "team-b", Source: "aws-tags", Confidence: 0.9, Superseded: true},
+		},
+		Assignee:  "team-a",
+		Status:    ConflictOpen,
+		FirstSeen: "2024-05-01T09:00:00Z",
+		LastSeen:  "2024-05-02T09:00:00Z",
+		SLADueAt:  "2024-05-04T09:00:00Z",
+	}
+
+	vertex := conflict.Vertex()
+	assert.Equal(t, LabelConflict, vertex.Label)
+
+	read, err := ConflictFromVertex(vertex)
+	require.NoError(t, err)
+	assert.Equal(t, conflict, read)
+
+	_, err = ConflictFromVertex(Vertex{ID: "entity:payments", Label: LabelResource})
+	assert.Error(t, err)
+}
+
+func TestConflictID(t *testing.T) {
+	id := ConflictID("owns", "payments")
+
+	assert.Equal(t, id, ConflictID("owns", "payments"))
+	assert.NotEqual(t, id, ConflictID("manages", "payments"))
+}
+
+func TestConflict_AgeAndSLA(t *testing.T) {
+	now := time.Date(2024, 5, 5, 9, 0, 0, 0, time.UTC)
+	conflict := Conflict{Status: ConflictOpen, FirstSeen: "2024-05-01T09:00:00Z", SLADueAt: "2024-05-04T09:00:00Z"}
+
+	assert.Equal(t, 96*time.Hour, conflict.Age(now))
+	assert.True(t, conflict.SLABreached(now))
+	assert.False(t, conflict.SLABreached(now.Add(-48*time.Hour)))
+
+	// A closed conflict stops ageing when it is closed
+	conflict.Status = ConflictClosed
+	conflict.ClosedAt = "2024-05-02T09:00:00Z"
+	assert.Equal(t, 24*time.Hour, conflict.Age(now))
+	assert.False(t, conflict.SLABreached(now))
+}
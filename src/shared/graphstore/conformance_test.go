@@ -0,0 +1,165 @@
+package graphstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testGraphStore checks the behaviour every GraphStore implementation shares; newStore returns an empty store
+func testGraphStore(t *testing.T, newStore func(t *testing.T) GraphStore) {
+	ctx := context.Background()
+
+	seed := func(t *testing.T) GraphStore {
+		store := newStore(t)
+		require.NoError(t, store.UpsertVertices(ctx, []Vertex{
+			{ID: VertexID("team-a"), Label: LabelUser, Name: "team-a"},
+			{ID: VertexID("team-b"), Label: LabelUser, Name: "team-b"},
+			{ID: VertexID("payments"), Label: LabelResource, Name: "payments", Properties: map[string]string{"type": "APPLICATION"}},
+			{ID: VertexID("checkout"), Label: LabelResource, Name: "checkout"},
+		}))
+		require.NoError(t, store.UpsertEdges(ctx, []Edge{
+			{ID: "edge-1", Label: "owns", From: VertexID("team-a"), To: VertexID("payments"), Confidence: 0.9, Source: "aws-tags", Timestamp: "2024-05-01T09:30:00Z"},
+			{ID: "edge-2", Label: "owns", From: VertexID("team-b"), To: VertexID("payments"), Confidence: 0.6, Source: "datadog-service-catalog", HasConflict: true},
+			{ID: "edge-3", Label: "depends_on", From: VertexID("checkout"), To: VertexID("payments"), Confidence: 0.7, Source: "datadog-apm"},
+			{ID: "edge-4", Label: "owns", From: VertexID("team-a"), To: VertexID("checkout"), Confidence: 0.8, Source: "github-codeowners"},
+		}))
+		return store
+	}
+
+	edgeIDs := func(edges []Edge) []string {
+		ids := make([]string, 0, len(edges))
+		for _, edge := range edges {
+			ids = append(ids, edge.ID)
+		}
+		return ids
+	}
+
+	t.Run("get by ID", func(t *testing.T) {
+		store := seed(t)
+
+		vertex, found, err := store.GetVertex(ctx, VertexID("payments"))
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, Vertex{ID: VertexID("payments"), Label: LabelResource, Name: "payments", Properties: map[string]string{"type": "APPLICATION"}}, vertex)
+
+		edge, found, err := store.GetEdge(ctx, "edge-1")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, Edge{ID: "edge-1", Label: "owns", From: VertexID("team-a"), To: VertexID("payments"), Confidence: 0.9, Source: "aws-tags", Timestamp: "2024-05-01T09:30:00Z"}, edge)
+
+		_, found, err = store.GetVertex(ctx, VertexID("missing"))
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		_, found, err = store.GetEdge(ctx, "missing")
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("upserts are idempotent", func(t *testing.T) {
+		store := seed(t)
+
+		require.NoError(t, store.UpsertVertices(ctx, []Vertex{{ID: VertexID("payments"), Label: LabelUser}}))
+		require.NoError(t, store.UpsertEdges(ctx, []Edge{
+			{ID: "edge-1", Label: "owns", From: VertexID("team-a"), To: VertexID("payments"), Confidence: 0.95, Source: "aws-tags"},
+		}))
+
+		// Labels are fixed at creation and an upsert without a name keeps the existing one
+		vertex, _, err := store.GetVertex(ctx, VertexID("payments"))
+		require.NoError(t, err)
+		assert.Equal(t, LabelResource, vertex.Label)
+		assert.Equal(t, "payments", vertex.Name)
+		assert.Equal(t, "APPLICATION", vertex.Properties["type"])
+
+		edges, err := store.FindEdges(ctx, EdgeFilter{From: VertexID("team-a"), To: VertexID("payments")})
+		require.NoError(t, err)
+		require.Len(t, edges, 1)
+		assert.Equal(t, 0.95, edges[0].Confidence)
+	})
+
+	t.Run("edges need existing vertices", func(t *testing.T) {
+		store := seed(t)
+
+		err := store.UpsertEdges(ctx, []Edge{{ID: "edge-5", Label: "owns", From: VertexID("team-a"), To: VertexID("missing"), Source: "aws-tags"}})
+		assert.Error(t, err)
+	})
+
+	t.Run("neighbors", func(t *testing.T) {
+		store := seed(t)
+
+		out, err := store.Neighbors(ctx, VertexID("team-a"), Out)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"checkout", "payments"}, []string{out[0].Name, out[1].Name})
+
+		in, err := store.Neighbors(ctx, VertexID("payments"), In)
+		require.NoError(t, err)
+		assert.Len(t, in, 3)
+
+		both, err := store.Neighbors(ctx, VertexID("checkout"), Both)
+		require.NoError(t, err)
+		assert.Len(t, both, 2)
+	})
+
+	t.Run("find vertices", func(t *testing.T) {
+		store := seed(t)
+
+		resources, err := store.FindVertices(ctx, VertexFilter{Label: LabelResource})
+		require.NoError(t, err)
+		assert.Len(t, resources, 2)
+
+		matching, err := store.FindVertices(ctx, VertexFilter{NameContains: "team"})
+		require.NoError(t, err)
+		assert.Len(t, matching, 2)
+	})
+
+	t.Run("find edges", func(t *testing.T) {
+		store := seed(t)
+
+		testCases := []struct {
+			name     string
+			filter   EdgeFilter
+			expected []string
+		}{
+			{"all", EdgeFilter{}, []string{"edge-1", "edge-2", "edge-3", "edge-4"}},
+			{"by ID", EdgeFilter{ID: "edge-3"}, []string{"edge-3"}},
+			{"by label and target", EdgeFilter{Label: "owns", To: VertexID("payments")}, []string{"edge-1", "edge-2"}},
+			{"by either endpoint", EdgeFilter{Vertex: VertexID("checkout")}, []string{"edge-3", "edge-4"}},
+			{"by source", EdgeFilter{Source: "aws-tags"}, []string{"edge-1"}},
+			{"by source prefix", EdgeFilter{SourcePrefix: "datadog-"}, []string{"edge-2", "edge-3"}},
+			{"by confidence", EdgeFilter{MinConfidence: 0.8}, []string{"edge-1", "edge-4"}},
+			{"by conflict", EdgeFilter{ConflictOnly: true}, []string{"edge-2"}},
+			{"combined", EdgeFilter{From: VertexID("team-a"), MinConfidence: 0.85}, []string{"edge-1"}},
+			{"no match", EdgeFilter{Source: "unknown"}, []string{}},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				edges, err := store.FindEdges(ctx, tc.filter)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, edgeIDs(edges))
+			})
+		}
+	})
+
+	t.Run("delete edges", func(t *testing.T) {
+		store := seed(t)
+
+		require.NoError(t, store.DeleteEdges(ctx,
+			EdgeFilter{Vertex: VertexID("team-b"), SourcePrefix: "datadog-"},
+			EdgeFilter{From: VertexID("checkout"), Label: "depends_on", To: VertexID("payments")},
+			EdgeFilter{ID: "missing"},
+		))
+
+		edges, err := store.FindEdges(ctx, EdgeFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"edge-1", "edge-4"}, edgeIDs(edges))
+
+		assert.Error(t, store.DeleteEdges(ctx, EdgeFilter{}))
+		edges, err = store.FindEdges(ctx, EdgeFilter{})
+		require.NoError(t, err)
+		assert.Len(t, edges, 2)
+	})
+}
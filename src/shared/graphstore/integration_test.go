@@ -0,0 +1,29 @@
+package graphstore
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"bacon/src/shared/gremlin"
+)
+
+// TestNeptuneStore_GremlinServer runs the shared store behaviour against a local Gremlin Server with TinkerGraph, e.g.
+// docker run -p 8182:8182 tinkerpop/gremlin-server, then GREMLIN_SERVER_URL=ws://localhost:8182/gremlin go test
+func TestNeptuneStore_GremlinServer(t *testing.T) {
+	endpoint := os.Getenv("GREMLIN_SERVER_URL")
+	if endpoint == "" {
+		t.Skip("Skipping Gremlin Server integration test - set GREMLIN_SERVER_URL to run it")
+	}
+
+	client := gremlin.NewClient(gremlin.Config{Endpoint: endpoint})
+	defer client.Close()
+
+	testGraphStore(t, func(t *testing.T) GraphStore {
+		_, err := client.Submit(context.Background(), "g.V().drop().iterate()", nil)
+		require.NoError(t, err)
+		return NewNeptuneStore(client)
+	})
+}
@@ -0,0 +1,215 @@
+package graphstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MemoryStore is a fully functional GraphStore held in process memory
+type MemoryStore struct {
+	mu       sync.RWMutex
+	vertices map[string]Vertex
+	edges    map[string]Edge
+}
+
+// NewMemoryStore creates an empty store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		vertices: make(map[string]Vertex),
+		edges:    make(map[string]Edge),
+	}
+}
+
+func (s *MemoryStore) UpsertVertices(_ context.Context, vertices []Vertex) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, vertex := range vertices {
+		if vertex.ID == "" {
+			return fmt.Errorf("vertex has no ID")
+		}
+
+		existing, found := s.vertices[vertex.ID]
+		if !found {
+			existing = Vertex{ID: vertex.ID, Label: vertex.Label}
+		}
+		if vertex.Name != "" {
+			existing.Name = vertex.Name
+		}
+		existing.Properties = mergeProperties(existing.Properties, vertex.Properties)
+		s.vertices[vertex.ID] = existing
+	}
+	return nil
+}
+
+func (s *MemoryStore) UpsertEdges(_ context.Context, edges []Edge) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, edge := range edges {
+		if edge.ID == "" {
+			return fmt.Errorf("edge has no ID")
+		}
+
+		existing, found := s.edges[edge.ID]
+		if !found {
+			if _, ok := s.vertices[edge.From]; !ok {
+				return fmt.Errorf("edge %s: vertex %s does not exist", edge.ID, edge.From)
+			}
+			if _, ok := s.vertices[edge.To]; !ok {
+				return fmt.Errorf("edge %s: vertex %s does not exist", edge.ID, edge.To)
+			}
+			existing = Edge{ID: edge.ID, Label: edge.Label, From: edge.From, To: edge.To}
+		}
+		existing.Confidence = edge.Confidence
+		existing.Source = edge.Source
+		existing.HasConflict = edge.HasConflict
+		existing.Timestamp = edge.Timestamp
+		existing.Properties = mergeProperties(existing.Properties, edge.Properties)
+		s.edges[edge.ID] = existing
+	}
+	return nil
+}
+
+func (s *MemoryStore) GetVertex(_ context.Context, id string) (Vertex, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	vertex, found := s.vertices[id]
+	return copyVertex(vertex), found, nil
+}
+
+func (s *MemoryStore) GetEdge(_ context.Context, id string) (Edge, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	edge, found := s.edges[id]
+	return copyEdge(edge), found, nil
+}
+
+func (s *MemoryStore) Neighbors(_ context.Context, id string, direction Direction) ([]Vertex, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	neighbors := make(map[string]bool)
+	for _, edge := range s.edges {
+		if edge.From == id && direction != In {
+			neighbors[edge.To] = true
+		}
+		if edge.To == id && direction != Out {
+			neighbors[edge.From] = true
+		}
+	}
+
+	vertices := make([]Vertex, 0, len(neighbors))
+	for neighbor := range neighbors {
+		vertices = append(vertices, copyVertex(s.vertices[neighbor]))
+	}
+	sortVertices(vertices)
+	return vertices, nil
+}
+
+func (s *MemoryStore) FindVertices(_ context.Context, filter VertexFilter) ([]Vertex, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	vertices := make([]Vertex, 0)
+	for _, vertex := range s.vertices {
+		if filter.Label != "" && vertex.Label != filter.Label {
+			continue
+		}
+		if filter.NameContains != "" && !strings.Contains(vertex.Name, filter.NameContains) {
+			continue
+		}
+		vertices = append(vertices, copyVertex(vertex))
+	}
+	sortVertices(vertices)
+	return vertices, nil
+}
+
+func (s *MemoryStore) FindEdges(_ context.Context, filter EdgeFilter) ([]Edge, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	edges := make([]Edge, 0)
+	for _, edge := range s.edges {
+		if filter.matches(edge) {
+			edges = append(edges, copyEdge(edge))
+		}
+	}
+	sortEdges(edges)
+	return edges, nil
+}
+
+func (s *MemoryStore) DeleteEdges(_ context.Context, filters ...EdgeFilter) error {
+	for _, filter := range filters {
+		if filter.isEmpty() {
+			return fmt.Errorf("refusing to delete edges with an empty filter")
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, edge := range s.edges {
+		for _, filter := range filters {
+			if filter.matches(edge) {
+				delete(s.edges, id)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (f EdgeFilter) matches(edge Edge) bool {
+	switch {
+	case f.ID != "" && edge.ID != f.ID,
+		f.Label != "" && edge.Label != f.Label,
+		f.From != "" && edge.From != f.From,
+		f.To != "" && edge.To != f.To,
+		f.Vertex != "" && edge.From != f.Vertex && edge.To != f.Vertex,
+		f.Source != "" && edge.Source != f.Source,
+		f.SourcePrefix != "" && !strings.HasPrefix(edge.Source, f.SourcePrefix),
+		edge.Confidence < f.MinConfidence,
+		f.ConflictOnly && !edge.HasConflict:
+		return false
+	}
+	return true
+}
+
+func mergeProperties(existing, updates map[string]string) map[string]string {
+	if len(updates) == 0 {
+		return existing
+	}
+	merged := make(map[string]string, len(existing)+len(updates))
+	for key, value := range existing {
+		merged[key] = value
+	}
+	for key, value := range updates {
+		merged[key] = value
+	}
+	return merged
+}
+
+// copyVertex detaches the returned property map from the stored one
+func copyVertex(vertex Vertex) Vertex {
+	vertex.Properties = mergeProperties(nil, vertex.Properties)
+	return vertex
+}
+
+func copyEdge(edge Edge) Edge {
+	edge.Properties = mergeProperties(nil, edge.Properties)
+	return edge
+}
+
+func sortVertices(vertices []Vertex) {
+	sort.Slice(vertices, func(i, j int) bool { return vertices[i].ID < vertices[j].ID })
+}
+
+func sortEdges(edges []Edge) {
+	sort.Slice(edges, func(i, j int) bool { return edges[i].ID < edges[j].ID })
+}
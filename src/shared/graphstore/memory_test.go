@@ -0,0 +1,36 @@
+package graphstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	testGraphStore(t, func(*testing.T) GraphStore { return NewMemoryStore() })
+}
+
+func TestMemoryStore_ReturnsCopies(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	require.NoError(t, store.UpsertVertices(ctx, []Vertex{{ID: "v1", Label: LabelResource, Properties: map[string]string{"type": "DATABASE"}}}))
+
+	vertex, _, err := store.GetVertex(ctx, "v1")
+	require.NoError(t, err)
+	vertex.Properties["type"] = "APPLICATION"
+
+	stored, _, err := store.GetVertex(ctx, "v1")
+	require.NoError(t, err)
+	assert.Equal(t, "DATABASE", stored.Properties["type"])
+}
+
+func TestEdgeID(t *testing.T) {
+	id := EdgeID("team-a", "owns", "repo", "aws-tags")
+
+	assert.Equal(t, id, EdgeID("team-a", "owns", "repo", "aws-tags"))
+	assert.NotEqual(t, id, EdgeID("team-a", "owns", "repo", "github-codeowners"))
+	// Field boundaries are part of the key
+	assert.NotEqual(t, EdgeID("a", "owns", "bc", "s"), EdgeID("ab", "owns", "c", "s"))
+}
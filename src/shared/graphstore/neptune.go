@@ -0,0 +1,287 @@
+package graphstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"bacon/src/shared/gremlin"
+)
+
+// DefaultBatchSize is the number of upserts or deletions sent per multi-statement script
+const DefaultBatchSize = 25
+
+// Edge properties with a dedicated Edge field; any other property lands in Edge.Properties
+const (
+	propertyName        = "name"
+	propertyConfidence  = "confidence"
+	propertySource      = "source"
+	propertyHasConflict = "has_conflict"
+	propertyTimestamp   = "timestamp"
+)
+
+const (
+	vertexProjection = ".project('id', 'label', 'properties').by(T.id).by(T.label).by(__.valueMap())"
+	edgeProjection   = ".project('id', 'label', 'from', 'to', 'properties').by(T.id).by(T.label)" +
+		".by(__.outV().id()).by(__.inV().id()).by(__.valueMap())"
+)
+
+// NeptuneStore is the GraphStore backed by Neptune, or any Gremlin Server, through parameterized scripts
+type NeptuneStore struct {
+	submitter gremlin.Submitter
+	batchSize int
+}
+
+// NewNeptuneStore creates a store submitting through the given client
+func NewNeptuneStore(submitter gremlin.Submitter) *NeptuneStore {
+	return &NeptuneStore{submitter: submitter, batchSize: DefaultBatchSize}
+}
+
+func (s *NeptuneStore) UpsertVertices(ctx context.Context, vertices []Vertex) error {
+	return s.submitInBatches(ctx, len(vertices), func(batch *gremlin.Batch, i int) error {
+		vertex := vertices[i]
+		if vertex.ID == "" {
+			return fmt.Errorf("vertex has no ID")
+		}
+
+		properties := make(map[string]interface{}, len(vertex.Properties)+1)
+		for key, value := range vertex.Properties {
+			properties[key] = value
+		}
+		if vertex.Name != "" {
+			properties[propertyName] = vertex.Name
+		}
+		batch.UpsertVertex(vertex.ID, vertex.Label, properties)
+		return nil
+	})
+}
+
+func (s *NeptuneStore) UpsertEdges(ctx context.Context, edges []Edge) error {
+	return s.submitInBatches(ctx, len(edges), func(batch *gremlin.Batch, i int) error {
+		edge := edges[i]
+		if edge.ID == "" {
+			return fmt.Errorf("edge has no ID")
+		}
+
+		properties := make(map[string]interface{}, len(edge.Properties)+4)
+		for key, value := range edge.Properties {
+			properties[key] = value
+		}
+		properties[propertyConfidence] = edge.Confidence
+		properties[propertySource] = edge.Source
+		properties[propertyHasConflict] = edge.HasConflict
+		properties[propertyTimestamp] = edge.Timestamp
+		batch.UpsertEdge(edge.ID, edge.Label, edge.From, edge.To, properties)
+		return nil
+	})
+}
+
+func (s *NeptuneStore) GetVertex(ctx context.Context, id string) (Vertex, bool, error) {
+	vertices, err := s.queryVertices(ctx, "g.V(p0)"+vertexProjection, map[string]interface{}{"p0": id})
+	if err != nil || len(vertices) == 0 {
+		return Vertex{}, false, err
+	}
+	return vertices[0], true, nil
+}
+
+func (s *NeptuneStore) GetEdge(ctx context.Context, id string) (Edge, bool, error) {
+	edges, err := s.FindEdges(ctx, EdgeFilter{ID: id})
+	if err != nil || len(edges) == 0 {
+		return Edge{}, false, err
+	}
+	return edges[0], true, nil
+}
+
+func (s *NeptuneStore) Neighbors(ctx context.Context, id string, direction Direction) ([]Vertex, error) {
+	step := map[Direction]string{Out: "out()", In: "in()", Both: "both()"}[direction]
+	return s.queryVertices(ctx, "g.V(p0)."+step+".dedup()"+vertexProjection, map[string]interface{}{"p0": id})
+}
+
+func (s *NeptuneStore) FindVertices(ctx context.Context, filter VertexFilter) ([]Vertex, error) {
+	batch := gremlin.NewBatch()
+	traversal := "g.V()"
+	if filter.Label != "" {
+		traversal += ".hasLabel(" + batch.Bind(filter.Label) + ")"
+	}
+	if filter.NameContains != "" {
+		traversal += ".has('name', TextP.containing(" + batch.Bind(filter.NameContains) + "))"
+	}
+	return s.queryVertices(ctx, traversal+vertexProjection, batch.Bindings())
+}
+
+func (s *NeptuneStore) FindEdges(ctx context.Context, filter EdgeFilter) ([]Edge, error) {
+	batch := gremlin.NewBatch()
+	results, err := s.submitter.Submit(ctx, edgeTraversal(batch, filter)+edgeProjection, batch.Bindings())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query edges: %w", err)
+	}
+
+	edges := make([]Edge, 0, len(results))
+	for _, result := range results {
+		edges = append(edges, decodeEdge(result))
+	}
+	sortEdges(edges)
+	return edges, nil
+}
+
+func (s *NeptuneStore) DeleteEdges(ctx context.Context, filters ...EdgeFilter) error {
+	return s.submitInBatches(ctx, len(filters), func(batch *gremlin.Batch, i int) error {
+		if filters[i].isEmpty() {
+			return fmt.Errorf("refusing to delete edges with an empty filter")
+		}
+		batch.Add(edgeTraversal(batch, filters[i]) + ".drop()")
+		return nil
+	})
+}
+
+// submitInBatches adds count statements through add and submits them batchSize at a time
+func (s *NeptuneStore) submitInBatches(ctx context.Context, count int, add func(batch *gremlin.Batch, i int) error) error {
+	batch := gremlin.NewBatch()
+	for i := 0; i < count; i++ {
+		if err := add(batch, i); err != nil {
+			return err
+		}
+		if batch.Len() == s.batchSize {
+			if err := batch.Submit(ctx, s.submitter); err != nil {
+				return err
+			}
+			batch = gremlin.NewBatch()
+		}
+	}
+	return batch.Submit(ctx, s.submitter)
+}
+
+func (s *NeptuneStore) queryVertices(ctx context.Context, script string, bindings map[string]interface{}) ([]Vertex, error) {
+	results, err := s.submitter.Submit(ctx, script, bindings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query vertices: %w", err)
+	}
+
+	vertices := make([]Vertex, 0, len(results))
+	for _, result := range results {
+		vertices = append(vertices, decodeVertex(result))
+	}
+	sortVertices(vertices)
+	return vertices, nil
+}
+
+// edgeTraversal starts from the most selective endpoint of the filter and narrows down with has steps
+func edgeTraversal(batch *gremlin.Batch, filter EdgeFilter) string {
+	var traversal strings.Builder
+
+	switch {
+	case filter.ID != "":
+		traversal.WriteString("g.E(" + batch.Bind(filter.ID) + ")")
+	case filter.From != "":
+		traversal.WriteString("g.V(" + batch.Bind(filter.From) + ").outE()")
+		filter.From = ""
+	case filter.To != "":
+		traversal.WriteString("g.V(" + batch.Bind(filter.To) + ").inE()")
+		filter.To = ""
+	case filter.Vertex != "":
+		traversal.WriteString("g.V(" + batch.Bind(filter.Vertex) + ").bothE().dedup()")
+		filter.Vertex = ""
+	default:
+		traversal.WriteString("g.E()")
+	}
+
+	if filter.Label != "" {
+		traversal.WriteString(".hasLabel(" + batch.Bind(filter.Label) + ")")
+	}
+	if filter.From != "" {
+		traversal.WriteString(".where(__.outV().hasId(" + batch.Bind(filter.From) + "))")
+	}
+	if filter.To != "" {
+		traversal.WriteString(".where(__.inV().hasId(" + batch.Bind(filter.To) + "))")
+	}
+	if filter.Vertex != "" {
+		traversal.WriteString(".where(__.bothV().hasId(" + batch.Bind(filter.Vertex) + "))")
+	}
+	if filter.Source != "" {
+		traversal.WriteString(".has('source', " + batch.Bind(filter.Source) + ")")
+	}
+	if filter.SourcePrefix != "" {
+		traversal.WriteString(".has('source', TextP.startingWith(" + batch.Bind(filter.SourcePrefix) + "))")
+	}
+	if filter.MinConfidence != 0 {
+		traversal.WriteString(".has('confidence', P.gte(" + batch.Bind(filter.MinConfidence) + "))")
+	}
+	if filter.ConflictOnly {
+		traversal.WriteString(".has('has_conflict', true)")
+	}
+
+	return traversal.String()
+}
+
+func decodeVertex(result interface{}) Vertex {
+	projection, _ := result.(map[string]interface{})
+	vertex := Vertex{
+		ID:    stringValue(projection["id"]),
+		Label: stringValue(projection["label"]),
+	}
+
+	// Vertex property values come back as lists, one entry per cardinality slot
+	properties, _ := projection["properties"].(map[string]interface{})
+	for key, values := range properties {
+		value := stringValue(firstValue(values))
+		if key == propertyName {
+			vertex.Name = value
+			continue
+		}
+		if vertex.Properties == nil {
+			vertex.Properties = make(map[string]string)
+		}
+		vertex.Properties[key] = value
+	}
+	return vertex
+}
+
+func decodeEdge(result interface{}) Edge {
+	projection, _ := result.(map[string]interface{})
+	edge := Edge{
+		ID:    stringValue(projection["id"]),
+		Label: stringValue(projection["label"]),
+		From:  stringValue(projection["from"]),
+		To:    stringValue(projection["to"]),
+	}
+
+	properties, _ := projection["properties"].(map[string]interface{})
+	for key, value := range properties {
+		switch key {
+		case propertyConfidence:
+			edge.Confidence, _ = value.(float64)
+		case propertySource:
+			edge.Source = stringValue(value)
+		case propertyHasConflict:
+			edge.HasConflict, _ = value.(bool)
+		case propertyTimestamp:
+			edge.Timestamp = stringValue(value)
+		default:
+			if edge.Properties == nil {
+				edge.Properties = make(map[string]string)
+			}
+			edge.Properties[key] = stringValue(value)
+		}
+	}
+	return edge
+}
+
+func firstValue(value interface{}) interface{} {
+	if values, ok := value.([]interface{}); ok {
+		if len(values) == 0 {
+			return nil
+		}
+		return values[0]
+	}
+	return value
+}
+
+func stringValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
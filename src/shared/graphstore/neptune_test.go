@@ -0,0 +1,131 @@
+package graphstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedSubmitter records submitted scripts and answers them with canned results
+type scriptedSubmitter struct {
+	scripts  []string
+	bindings []map[string]interface{}
+	results  []interface{}
+	err      error
+}
+
+func (s *scriptedSubmitter) Submit(_ context.Context, script string, bindings map[string]interface{}) ([]interface{}, error) {
+	s.scripts = append(s.scripts, script)
+	s.bindings = append(s.bindings, bindings)
+	return s.results, s.err
+}
+
+func TestNeptuneStore_UpsertsAreParameterizedAndBatched(t *testing.T) {
+	submitter := &scriptedSubmitter{}
+	store := NewNeptuneStore(submitter)
+
+	vertices := make([]Vertex, DefaultBatchSize+1)
+	for i := range vertices {
+		vertices[i] = Vertex{ID: VertexID("o'brien"), Label: LabelUser, Name: "o'brien"}
+	}
+	require.NoError(t, store.UpsertVertices(context.Background(), vertices))
+
+	require.Len(t, submitter.scripts, 2)
+	assert.Len(t, strings.Split(submitter.scripts[0], ";\n"), DefaultBatchSize)
+	assert.NotContains(t, submitter.scripts[0], "o'brien")
+	assert.Equal(t, "g.V(p0).fold().coalesce(unfold(), addV(p1).property(T.id, p0)).property(single, p2, p3).iterate()", submitter.scripts[1])
+	assert.Equal(t, map[string]interface{}{"p0": "entity:o'brien", "p1": LabelUser, "p2": "name", "p3": "o'brien"}, submitter.bindings[1])
+}
+
+func TestNeptuneStore_UpsertEdges(t *testing.T) {
+	submitter := &scriptedSubmitter{}
+	store := NewNeptuneStore(submitter)
+
+	require.NoError(t, store.UpsertEdges(context.Background(), []Edge{
+		{ID: "edge-1", Label: "owns", From: "v1", To: "v2", Confidence: 0.9, Source: "aws-tags", Timestamp: "2024-05-01T09:30:00Z"},
+	}))
+
+	require.Len(t, submitter.scripts, 1)
+	assert.True(t, strings.HasPrefix(submitter.scripts[0], "g.E(p0).fold().coalesce(unfold(), addE(p1).from(__.V(p2)).to(__.V(p3))"))
+	values := make(map[interface{}]bool)
+	for _, value := range submitter.bindings[0] {
+		values[value] = true
+	}
+	for _, expected := range []interface{}{"edge-1", "owns", "v1", "v2", 0.9, "aws-tags", false, "2024-05-01T09:30:00Z"} {
+		assert.True(t, values[expected], "expected %v to be bound", expected)
+	}
+
+	assert.Error(t, store.UpsertEdges(context.Background(), []Edge{{Label: "owns"}}))
+}
+
+func TestNeptuneStore_EdgeTraversals(t *testing.T) {
+	testCases := []struct {
+		name     string
+		filter   EdgeFilter
+		expected string
+	}{
+		{"all", EdgeFilter{}, "g.E()"},
+		{"by ID", EdgeFilter{ID: "edge-1"}, "g.E(p0)"},
+		{"from source vertex", EdgeFilter{From: "v1", Label: "owns", To: "v2"}, "g.V(p0).outE().hasLabel(p1).where(__.inV().hasId(p2))"},
+		{"either endpoint", EdgeFilter{Vertex: "v1", SourcePrefix: "datadog-"}, "g.V(p0).bothE().dedup().has('source', TextP.startingWith(p1))"},
+		{"scored", EdgeFilter{Source: "aws-tags", MinConfidence: 0.8, ConflictOnly: true}, "g.E().has('source', p0).has('confidence', P.gte(p1)).has('has_conflict', true)"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			submitter := &scriptedSubmitter{}
+			_, err := NewNeptuneStore(submitter).FindEdges(context.Background(), tc.filter)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected+edgeProjection, submitter.scripts[0])
+		})
+	}
+}
+
+func TestNeptuneStore_DecodesResults(t *testing.T) {
+	submitter := &scriptedSubmitter{results: []interface{}{
+		map[string]interface{}{
+			"id": "entity:payments", "label": LabelResource,
+			"properties": map[string]interface{}{"name": []interface{}{"payments"}, "type": []interface{}{"APPLICATION"}},
+		},
+	}}
+	store := NewNeptuneStore(submitter)
+
+	vertex, found, err := store.GetVertex(context.Background(), "entity:payments")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, Vertex{ID: "entity:payments", Label: LabelResource, Name: "payments", Properties: map[string]string{"type": "APPLICATION"}}, vertex)
+
+	submitter.results = []interface{}{
+		map[string]interface{}{
+			"id": "edge-1", "label": "owns", "from": "entity:team-a", "to": "entity:payments",
+			"properties": map[string]interface{}{"confidence": 0.9, "source": "aws-tags", "has_conflict": true, "timestamp": "2024-05-01T09:30:00Z", "reviewer": "ana"},
+		},
+	}
+	edge, found, err := store.GetEdge(context.Background(), "edge-1")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, Edge{
+		ID: "edge-1", Label: "owns", From: "entity:team-a", To: "entity:payments",
+		Confidence: 0.9, Source: "aws-tags", HasConflict: true, Timestamp: "2024-05-01T09:30:00Z",
+		Properties: map[string]string{"reviewer": "ana"},
+	}, edge)
+
+	submitter.results = nil
+	_, found, err = store.GetEdge(context.Background(), "missing")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestNeptuneStore_Errors(t *testing.T) {
+	store := NewNeptuneStore(&scriptedSubmitter{err: errors.New("connection refused")})
+
+	_, err := store.FindVertices(context.Background(), VertexFilter{})
+	assert.ErrorContains(t, err, "connection refused")
+
+	assert.ErrorContains(t, store.DeleteEdges(context.Background(), EdgeFilter{}), "empty filter")
+}
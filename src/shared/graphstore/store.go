@@ -0,0 +1,166 @@
+// Package graphstore is the ownership graph shared by the relationship processor and the AppSync resolvers.
+// Neptune backs it in production; the in-memory implementation lets the ingest, resolve and query path run
+// and be tested locally.
+package graphstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+
+	"bacon/src/shared/gremlin"
+)
+
+// Vertex labels created by the relationship processor
+const (
+	LabelUser     = "User"
+	LabelResource = "Resource"
+)
+
+// LabelReview marks the record the mutation API keeps of a manual review, for calibrating confidence scoring
+const LabelReview = "Review"
+
+// Review outcomes and the properties a review record holds them in
+const (
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+
+	ReviewOutcomeProperty      = "outcome"
+	ReviewRelationshipProperty = "relationship_id"
+	ReviewExplanationProperty  = "confidence_explanation" // the processor's explanation of the reviewed confidence
+	ReviewedAtProperty         = "reviewed_at"
+)
+
+// Properties the relationship processor records the conflicts of an edge in. Superseded is written on every
+// edge; the IDs an edge conflicts with are only current while its HasConflict is set or it is superseded
+const (
+	ConflictsWithProperty = "conflicts_with" // JSON array of the IDs of the edges an edge conflicts with or was overruled by
+	SupersededProperty    = "superseded"     // "true" on the edges that lost to a claim of a higher priority source
+)
+
+// Vertex is an entity of the graph: a user or team, or a resource they own
+type Vertex struct {
+	ID         string            `json:"id"`
+	Label      string            `json:"label"`
+	Name       string            `json:"name"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// Edge is a relationship between two vertices as asserted by one source
+type Edge struct {
+	ID          string            `json:"id"`
+	Label       string            `json:"label"`
+	From        string            `json:"from"`
+	To          string            `json:"to"`
+	Confidence  float64           `json:"confidence"`
+	Source      string            `json:"source"`
+	HasConflict bool              `json:"has_conflict"`
+	Timestamp   string            `json:"timestamp"`
+	Properties  map[string]string `json:"properties,omitempty"`
+}
+
+// Direction selects which edges of a vertex lead to its neighbors
+type Direction int
+
+const (
+	Out Direction = iota
+	In
+	Both
+)
+
+// VertexFilter selects vertices; zero fields match everything
+type VertexFilter struct {
+	Label        string
+	NameContains string // case-sensitive substring of the name
+}
+
+// EdgeFilter selects edges; zero fields match everything
+type EdgeFilter struct {
+	ID            string
+	Label         string
+	From          string
+	To            string
+	Vertex        string // either endpoint
+	Source        string
+	SourcePrefix  string
+	MinConfidence float64
+	ConflictOnly  bool
+}
+
+// GraphStore reads and writes the ownership graph
+// Upserts create missing elements and overwrite the given fields of existing ones: a vertex keeps its label,
+// and its name when the upsert carries none. Edges can only be upserted between existing vertices.
+// Results are ordered by ID.
+type GraphStore interface {
+	UpsertVertices(ctx context.Context, vertices []Vertex) error
+	UpsertEdges(ctx context.Context, edges []Edge) error
+	GetVertex(ctx context.Context, id string) (Vertex, bool, error)
+	GetEdge(ctx context.Context, id string) (Edge, bool, error)
+	Neighbors(ctx context.Context, id string, direction Direction) ([]Vertex, error)
+	FindVertices(ctx context.Context, filter VertexFilter) ([]Vertex, error)
+	FindEdges(ctx context.Context, filter EdgeFilter) ([]Edge, error)
+	// DeleteEdges removes the edges matching any of the filters; an empty filter is rejected rather than deleting everything
+	DeleteEdges(ctx context.Context, filters ...EdgeFilter) error
+}
+
+// VertexID derives the stable ID of the vertex representing a named entity
+func VertexID(name string) string {
+	return "entity:" + name
+}
+
+// ReviewVertex is the record of a reviewer approving or rejecting a relationship, with the explanation of the
+// confidence the processor had given it; reviewing a relationship again updates the same record
+func ReviewVertex(relationshipID, outcome, explanation, reviewedAt string) Vertex {
+	properties := map[string]string{
+		ReviewOutcomeProperty:      outcome,
+		ReviewRelationshipProperty: relationshipID,
+		ReviewedAtProperty:         reviewedAt,
+	}
+	if explanation != "" {
+		properties[ReviewExplanationProperty] = explanation
+	}
+	return Vertex{ID: "review:" + relationshipID, Label: LabelReview, Name: relationshipID, Properties: properties}
+}
+
+// EdgeID derives the stable ID of an edge from its endpoints, label and the source asserting it
+// Each source keeps its own edge so confidence scoring and conflict resolution can still tell them apart
+func EdgeID(from, label, to, source string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{from, label, to, source}, "\x00")))
+	return "edge:" + hex.EncodeToString(sum[:16])
+}
+
+// Local is the process-wide in-memory store used when no Neptune endpoint is configured
+var Local = NewMemoryStore()
+
+var (
+	defaultMu    sync.Mutex
+	defaultStore GraphStore
+)
+
+// Default returns the process-wide store: Neptune when NEPTUNE_ENDPOINT is set, Local otherwise
+// The store is created once and reused across warm Lambda invocations
+func Default(ctx context.Context) (GraphStore, error) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	if defaultStore != nil {
+		return defaultStore, nil
+	}
+
+	config, configured, err := gremlin.NeptuneConfigFromEnv(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !configured {
+		defaultStore = Local
+	} else {
+		defaultStore = NewNeptuneStore(gremlin.NewClient(config))
+	}
+	return defaultStore, nil
+}
+
+func (f EdgeFilter) isEmpty() bool {
+	return f == EdgeFilter{}
+}
@@ -0,0 +1,62 @@
+package gremlin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Submitter evaluates parameterized scripts; Client is the production implementation
+type Submitter interface {
+	Submit(ctx context.Context, script string, bindings map[string]interface{}) ([]interface{}, error)
+}
+
+// Batch accumulates statements into one multi-statement script with uniquely named bindings
+// Each statement runs to completion with iterate(), so a batch costs a single round trip
+type Batch struct {
+	statements []string
+	bindings   map[string]interface{}
+}
+
+// NewBatch creates an empty batch
+func NewBatch() *Batch {
+	return &Batch{bindings: make(map[string]interface{})}
+}
+
+// Bind registers a value and returns the binding name to reference it by in a statement
+func (b *Batch) Bind(value interface{}) string {
+	name := fmt.Sprintf("p%d", len(b.bindings))
+	b.bindings[name] = value
+	return name
+}
+
+// Add appends a traversal statement; it is iterated so intermediate results are not sent back
+func (b *Batch) Add(statement string) {
+	b.statements = append(b.statements, statement+".iterate()")
+}
+
+// Len returns the number of statements in the batch
+func (b *Batch) Len() int {
+	return len(b.statements)
+}
+
+// Script returns the statements joined into a single script
+func (b *Batch) Script() string {
+	return strings.Join(b.statements, ";\n")
+}
+
+// Bindings returns the values bound by the statements
+func (b *Batch) Bindings() map[string]interface{} {
+	return b.bindings
+}
+
+// Submit sends the batch; an empty batch is a no-op
+func (b *Batch) Submit(ctx context.Context, submitter Submitter) error {
+	if b.Len() == 0 {
+		return nil
+	}
+	if _, err := submitter.Submit(ctx, b.Script(), b.Bindings()); err != nil {
+		return fmt.Errorf("failed to submit batch of %d statements: %w", b.Len(), err)
+	}
+	return nil
+}
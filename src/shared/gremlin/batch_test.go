@@ -0,0 +1,24 @@
+package gremlin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatch_UpsertVertexAndEdge(t *testing.T) {
+	batch := NewBatch()
+	batch.UpsertVertex("entity:team-a", "User", map[string]interface{}{"name": "team-a"})
+	batch.UpsertEdge("edge:1", "owns", "entity:team-a", "entity:repo", map[string]interface{}{"source": "aws-tags", "confidence": 0.9})
+
+	assert.Equal(t, 2, batch.Len())
+	assert.Equal(t,
+		"g.V(p0).fold().coalesce(unfold(), addV(p1).property(T.id, p0)).property(single, p2, p3).iterate();\n"+
+			"g.E(p4).fold().coalesce(unfold(), addE(p5).from(__.V(p6)).to(__.V(p7)).property(T.id, p4)).property(p8, p9).property(p10, p11).iterate()",
+		batch.Script())
+	assert.Equal(t, map[string]interface{}{
+		"p0": "entity:team-a", "p1": "User", "p2": "name", "p3": "team-a",
+		"p4": "edge:1", "p5": "owns", "p6": "entity:team-a", "p7": "entity:repo",
+		"p8": "confidence", "p9": 0.9, "p10": "source", "p11": "aws-tags",
+	}, batch.Bindings())
+}
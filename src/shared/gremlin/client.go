@@ -0,0 +1,158 @@
+package gremlin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/google/uuid"
+	"golang.org/x/net/websocket"
+)
+
+// DefaultTimeout bounds a request when neither the context nor the config sets a deadline
+const DefaultTimeout = 30 * time.Second
+
+// Config describes how to reach a Gremlin Server or Neptune cluster
+type Config struct {
+	Endpoint    string                  // e.g. wss://cluster.region.neptune.amazonaws.com:8182/gremlin or ws://localhost:8182/gremlin
+	Region      string                  // region of the Neptune cluster, used for SigV4 signing
+	Credentials aws.CredentialsProvider // nil disables SigV4 signing, e.g. for a local Gremlin Server
+	Timeout     time.Duration
+}
+
+// Client submits scripts over a single WebSocket connection, dialled lazily and redialled after failures
+// Requests are serialized; the Lambda handlers using it process one batch at a time anyway
+type Client struct {
+	config Config
+	mu     sync.Mutex
+	conn   *websocket.Conn
+}
+
+// NewClient creates a client; no connection is made until the first request
+func NewClient(config Config) *Client {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	return &Client{config: config}
+}
+
+// Submit evaluates a script with its bindings and returns the results with GraphSON types stripped
+// Values must be passed as bindings rather than formatted into the script
+func (c *Client) Submit(ctx context.Context, script string, bindings map[string]interface{}) ([]interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	conn, err := c.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := c.roundTrip(ctx, conn, script, bindings)
+	var serverErr *ServerError
+	if err != nil && !errors.As(err, &serverErr) {
+		// The connection state is unknown after a transport error; dial afresh next time
+		c.closeConn()
+	}
+	return results, err
+}
+
+// Close closes the underlying connection, if any
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.closeConn()
+}
+
+func (c *Client) closeConn() error {
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+func (c *Client) connect(ctx context.Context) (*websocket.Conn, error) {
+	if c.conn != nil {
+		return c.conn, nil
+	}
+
+	location, err := url.Parse(c.config.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gremlin endpoint %q: %w", c.config.Endpoint, err)
+	}
+
+	wsConfig, err := websocket.NewConfig(location.String(), "http://localhost/")
+	if err != nil {
+		return nil, fmt.Errorf("invalid gremlin endpoint %q: %w", c.config.Endpoint, err)
+	}
+
+	if c.config.Credentials != nil {
+		headers, err := signHandshake(ctx, location, c.config.Region, c.config.Credentials, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		wsConfig.Header = headers
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	conn, err := wsConfig.DialContext(dialCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to gremlin endpoint %s: %w", location.Host, err)
+	}
+
+	c.conn = conn
+	return conn, nil
+}
+
+func (c *Client) roundTrip(ctx context.Context, conn *websocket.Conn, script string, bindings map[string]interface{}) ([]interface{}, error) {
+	deadline, hasDeadline := ctx.Deadline()
+	if !hasDeadline {
+		deadline = time.Now().Add(c.config.Timeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, fmt.Errorf("failed to set gremlin deadline: %w", err)
+	}
+
+	requestID := uuid.NewString()
+	frame, err := encodeRequest(requestID, script, bindings)
+	if err != nil {
+		return nil, err
+	}
+	if err := websocket.Message.Send(conn, frame); err != nil {
+		return nil, fmt.Errorf("failed to send gremlin request: %w", err)
+	}
+
+	// Large results are streamed as partial content messages followed by a final status
+	var results []interface{}
+	for {
+		var message []byte
+		if err := websocket.Message.Receive(conn, &message); err != nil {
+			return nil, fmt.Errorf("failed to read gremlin response: %w", err)
+		}
+
+		resp, data, err := decodeResponse(message)
+		if err != nil {
+			return nil, err
+		}
+		if resp.RequestID != requestID {
+			return nil, fmt.Errorf("gremlin response for unexpected request %s", resp.RequestID)
+		}
+
+		switch resp.Status.Code {
+		case StatusPartialContent:
+			results = append(results, data...)
+		case StatusSuccess, StatusNoContent:
+			return append(results, data...), nil
+		default:
+			return nil, &ServerError{Code: resp.Status.Code, Message: resp.Status.Message}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package gremlin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+// fakeServer answers each request with the status codes queued for it, echoing its bindings as results
+type fakeServer struct {
+	statuses []int
+	requests []requestArgs
+	headers  []string
+}
+
+func (f *fakeServer) handle(conn *websocket.Conn) {
+	f.headers = append(f.headers, conn.Request().Header.Get("Authorization"))
+	for {
+		var frame []byte
+		if err := websocket.Message.Receive(conn, &frame); err != nil {
+			return
+		}
+		var req struct {
+			RequestID typedValue  `json:"requestId"`
+			Args      requestArgs `json:"args"`
+		}
+		if err := json.Unmarshal(frame[1+int(frame[0]):], &req); err != nil {
+			return
+		}
+		f.requests = append(f.requests, req.Args)
+
+		for _, status := range f.statuses {
+			message := fmt.Sprintf(`{"requestId": %q, "status": {"code": %d, "message": "status %d"}, "result": {"data": {"@type": "g:List", "@value": [%q]}}}`,
+				req.RequestID.Value, status, status, req.Args.Gremlin)
+			if err := websocket.Message.Send(conn, message); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func startFakeServer(t *testing.T, statuses ...int) (*fakeServer, string) {
+	fake := &fakeServer{statuses: statuses}
+	server := httptest.NewServer(websocket.Handler(fake.handle))
+	t.Cleanup(server.Close)
+	return fake, "ws" + strings.TrimPrefix(server.URL, "http") + "/gremlin"
+}
+
+func TestClient_Submit(t *testing.T) {
+	fake, endpoint := startFakeServer(t, StatusPartialContent, StatusSuccess)
+	client := NewClient(Config{Endpoint: endpoint, Timeout: 5 * time.Second})
+	defer client.Close()
+
+	results, err := client.Submit(context.Background(), "g.V(p0)", map[string]interface{}{"p0": "entity:payments"})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"g.V(p0)", "g.V(p0)"}, results)
+
+	// The connection is reused for later requests
+	_, err = client.Submit(context.Background(), "g.V(p0).count()", map[string]interface{}{"p0": "entity:payments"})
+	require.NoError(t, err)
+
+	require.Len(t, fake.requests, 2)
+	assert.Len(t, fake.headers, 1)
+	assert.Equal(t, map[string]interface{}{"p0": "entity:payments"}, fake.requests[0].Bindings)
+	assert.Equal(t, "gremlin-groovy", fake.requests[0].Language)
+}
+
+func TestClient_ServerError(t *testing.T) {
+	_, endpoint := startFakeServer(t, 597)
+	client := NewClient(Config{Endpoint: endpoint})
+	defer client.Close()
+
+	_, err := client.Submit(context.Background(), "g.V(p0).bogus()", map[string]interface{}{"p0": "x"})
+
+	var serverErr *ServerError
+	require.ErrorAs(t, err, &serverErr)
+	assert.Equal(t, 597, serverErr.Code)
+}
+
+func TestClient_ConnectionFailure(t *testing.T) {
+	client := NewClient(Config{Endpoint: "ws://127.0.0.1:1/gremlin", Timeout: time.Second})
+
+	_, err := client.Submit(context.Background(), "g.V()", nil)
+
+	assert.ErrorContains(t, err, "failed to connect")
+}
+
+func TestClient_SignsHandshake(t *testing.T) {
+	fake, endpoint := startFakeServer(t, StatusNoContent)
+	client := NewClient(Config{
+		Endpoint:    endpoint,
+		Region:      "eu-west-1",
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) { return testCredentials, nil }),
+	})
+	defer client.Close()
+
+	_, err := client.Submit(context.Background(), "g.V().limit(1)", nil)
+	require.NoError(t, err)
+
+	require.Len(t, fake.headers, 1)
+	assert.Contains(t, fake.headers[0], "/eu-west-1/neptune-db/aws4_request")
+}
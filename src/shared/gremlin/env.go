@@ -0,0 +1,44 @@
+package gremlin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	common "bacon/src/shared"
+)
+
+const defaultNeptunePort = "8182"
+
+// NeptuneConfigFromEnv builds a config from NEPTUNE_ENDPOINT, NEPTUNE_PORT and NEPTUNE_IAM_AUTH
+// NEPTUNE_ENDPOINT is either a cluster host, reached over wss with IAM authentication, or a full
+// ws(s):// URL such as ws://localhost:8182/gremlin for a local Gremlin Server
+// The second return value is false when no endpoint is configured
+func NeptuneConfigFromEnv(ctx context.Context) (Config, bool, error) {
+	endpoint := os.Getenv("NEPTUNE_ENDPOINT")
+	if endpoint == "" {
+		return Config{}, false, nil
+	}
+
+	if !strings.Contains(endpoint, "://") {
+		port := os.Getenv("NEPTUNE_PORT")
+		if port == "" {
+			port = defaultNeptunePort
+		}
+		endpoint = fmt.Sprintf("wss://%s:%s/gremlin", endpoint, port)
+	}
+
+	config := Config{Endpoint: endpoint}
+	if os.Getenv("NEPTUNE_IAM_AUTH") == "false" {
+		return config, true, nil
+	}
+
+	awsConfig, err := common.LoadAWSConfig(ctx)
+	if err != nil {
+		return Config{}, true, err
+	}
+	config.Region = awsConfig.Region
+	config.Credentials = awsConfig.Credentials
+	return config, true, nil
+}
@@ -0,0 +1,47 @@
+package gremlin
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGremlinServer runs against a local Gremlin Server with TinkerGraph, e.g.
+// docker run -p 8182:8182 tinkerpop/gremlin-server, then GREMLIN_SERVER_URL=ws://localhost:8182/gremlin go test
+func TestGremlinServer(t *testing.T) {
+	endpoint := os.Getenv("GREMLIN_SERVER_URL")
+	if endpoint == "" {
+		t.Skip("Skipping Gremlin Server integration test - set GREMLIN_SERVER_URL to run it")
+	}
+
+	ctx := context.Background()
+	client := NewClient(Config{Endpoint: endpoint})
+	defer client.Close()
+
+	_, err := client.Submit(ctx, "g.V().hasLabel(p0).drop().iterate()", map[string]interface{}{"p0": "IntegrationTest"})
+	require.NoError(t, err)
+
+	upsert := func() {
+		batch := NewBatch()
+		batch.UpsertVertex("entity:o'brien", "IntegrationTest", map[string]interface{}{"name": "o'brien"})
+		batch.UpsertVertex("entity:repo", "IntegrationTest", map[string]interface{}{"name": "repo"})
+		batch.UpsertEdge("edge:o'brien-owns-repo", "owns", "entity:o'brien", "entity:repo",
+			map[string]interface{}{"source": "aws-tags", "confidence": 0.9})
+		require.NoError(t, batch.Submit(ctx, client))
+	}
+
+	// Upserting twice leaves a single edge
+	upsert()
+	upsert()
+
+	results, err := client.Submit(ctx, "g.V(p0).outE(p1).count()", map[string]interface{}{"p0": "entity:o'brien", "p1": "owns"})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{float64(1)}, results)
+
+	results, err = client.Submit(ctx, "g.V(p0).values(p1)", map[string]interface{}{"p0": "entity:o'brien", "p1": "name"})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"o'brien"}, results)
+}
@@ -0,0 +1,173 @@
+// Package gremlin is a minimal Gremlin Server client speaking the WebSocket protocol with GraphSON 3.0
+// It submits parameterized scripts, so user-supplied values travel as bindings and never as script text,
+// and signs the handshake with SigV4 when talking to Amazon Neptune.
+package gremlin
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const mimeType = "application/vnd.gremlin-v3.0+json"
+
+// Response status codes of the Gremlin Server protocol
+const (
+	StatusSuccess        = 200
+	StatusNoContent      = 204
+	StatusPartialContent = 206
+)
+
+type request struct {
+	RequestID typedValue  `json:"requestId"`
+	Op        string      `json:"op"`
+	Processor string      `json:"processor"`
+	Args      requestArgs `json:"args"`
+}
+
+type requestArgs struct {
+	Gremlin  string                 `json:"gremlin"`
+	Bindings map[string]interface{} `json:"bindings,omitempty"`
+	Language string                 `json:"language"`
+}
+
+type response struct {
+	RequestID string         `json:"requestId"`
+	Status    responseStatus `json:"status"`
+	Result    responseResult `json:"result"`
+}
+
+type responseStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type responseResult struct {
+	Data json.RawMessage `json:"data"`
+}
+
+// typedValue is a GraphSON 3.0 typed value
+type typedValue struct {
+	Type  string      `json:"@type"`
+	Value interface{} `json:"@value"`
+}
+
+// ServerError is a non-success status returned by the server
+type ServerError struct {
+	Code    int
+	Message string
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("gremlin server returned %d: %s", e.Code, e.Message)
+}
+
+// encodeRequest frames an eval request as the server expects it: the length-prefixed mime type followed by the JSON body
+func encodeRequest(requestID, script string, bindings map[string]interface{}) ([]byte, error) {
+	typedBindings := make(map[string]interface{}, len(bindings))
+	for name, value := range bindings {
+		typedBindings[name] = toGraphSON(value)
+	}
+
+	body, err := json.Marshal(request{
+		RequestID: typedValue{Type: "g:UUID", Value: requestID},
+		Op:        "eval",
+		Args: requestArgs{
+			Gremlin:  script,
+			Bindings: typedBindings,
+			Language: "gremlin-groovy",
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode gremlin request: %w", err)
+	}
+
+	frame := make([]byte, 0, 1+len(mimeType)+len(body))
+	frame = append(frame, byte(len(mimeType)))
+	frame = append(frame, mimeType...)
+	return append(frame, body...), nil
+}
+
+// toGraphSON types numeric binding values; strings, booleans and nil are untyped in GraphSON
+func toGraphSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return typedValue{Type: "g:Int64", Value: v}
+	case int32:
+		return typedValue{Type: "g:Int32", Value: v}
+	case int64:
+		return typedValue{Type: "g:Int64", Value: v}
+	case float32:
+		return typedValue{Type: "g:Float", Value: v}
+	case float64:
+		return typedValue{Type: "g:Double", Value: v}
+	case []string:
+		values := make([]interface{}, 0, len(v))
+		for _, s := range v {
+			values = append(values, s)
+		}
+		return typedValue{Type: "g:List", Value: values}
+	}
+	return value
+}
+
+// decodeResponse parses a response message and unwraps its GraphSON result data into plain Go values
+func decodeResponse(message []byte) (response, []interface{}, error) {
+	var resp response
+	if err := json.Unmarshal(message, &resp); err != nil {
+		return response{}, nil, fmt.Errorf("failed to decode gremlin response: %w", err)
+	}
+	if len(resp.Result.Data) == 0 || string(resp.Result.Data) == "null" {
+		return resp, nil, nil
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(resp.Result.Data, &data); err != nil {
+		return response{}, nil, fmt.Errorf("failed to decode gremlin result: %w", err)
+	}
+
+	switch results := fromGraphSON(data).(type) {
+	case []interface{}:
+		return resp, results, nil
+	case nil:
+		return resp, nil, nil
+	default:
+		return resp, []interface{}{results}, nil
+	}
+}
+
+// fromGraphSON strips GraphSON 3.0 type wrappers, turning g:Map into maps keyed by the string form of their keys
+func fromGraphSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		values := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			values = append(values, fromGraphSON(item))
+		}
+		return values
+	case map[string]interface{}:
+		typeName, typed := v["@type"].(string)
+		if !typed {
+			values := make(map[string]interface{}, len(v))
+			for key, item := range v {
+				values[key] = fromGraphSON(item)
+			}
+			return values
+		}
+		return fromTypedGraphSON(typeName, v["@value"])
+	}
+	return value
+}
+
+func fromTypedGraphSON(typeName string, value interface{}) interface{} {
+	if typeName != "g:Map" {
+		// Lists, sets, numbers, tokens and elements all unwrap to their value
+		return fromGraphSON(value)
+	}
+
+	entries, _ := value.([]interface{})
+	values := make(map[string]interface{}, len(entries)/2)
+	for i := 0; i+1 < len(entries); i += 2 {
+		values[fmt.Sprint(fromGraphSON(entries[i]))] = fromGraphSON(entries[i+1])
+	}
+	return values
+}
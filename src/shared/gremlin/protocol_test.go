@@ -0,0 +1,70 @@
+package gremlin
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeRequest(t *testing.T) {
+	frame, err := encodeRequest("3f1e0c4e-6c1d-4b8e-9a57-2f1d8e3c0b11", "g.V(p0).values(p1)", map[string]interface{}{
+		"p0": "entity:o'brien",
+		"p1": "name",
+		"p2": 0.8,
+		"p3": true,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, byte(len(mimeType)), frame[0])
+	assert.Equal(t, mimeType, string(frame[1:1+len(mimeType)]))
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(frame[1+len(mimeType):], &body))
+	assert.Equal(t, map[string]interface{}{"@type": "g:UUID", "@value": "3f1e0c4e-6c1d-4b8e-9a57-2f1d8e3c0b11"}, body["requestId"])
+	assert.Equal(t, "eval", body["op"])
+
+	args := body["args"].(map[string]interface{})
+	assert.Equal(t, "g.V(p0).values(p1)", args["gremlin"])
+	assert.Equal(t, map[string]interface{}{
+		"p0": "entity:o'brien",
+		"p1": "name",
+		"p2": map[string]interface{}{"@type": "g:Double", "@value": 0.8},
+		"p3": true,
+	}, args["bindings"])
+}
+
+func TestDecodeResponse(t *testing.T) {
+	t.Run("graphson results are untyped", func(t *testing.T) {
+		resp, data, err := decodeResponse([]byte(`{
+			"requestId": "req-1",
+			"status": {"code": 200, "message": "", "attributes": {"@type": "g:Map", "@value": []}},
+			"result": {"data": {"@type": "g:List", "@value": [
+				{"@type": "g:Map", "@value": ["name", "payments", "confidence", {"@type": "g:Double", "@value": 0.8}, {"@type": "g:T", "@value": "id"}, "entity:payments"]},
+				{"@type": "g:Int64", "@value": 3}
+			]}, "meta": {"@type": "g:Map", "@value": []}}
+		}`))
+		require.NoError(t, err)
+
+		assert.Equal(t, "req-1", resp.RequestID)
+		assert.Equal(t, StatusSuccess, resp.Status.Code)
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"name": "payments", "confidence": 0.8, "id": "entity:payments"},
+			float64(3),
+		}, data)
+	})
+
+	t.Run("no content", func(t *testing.T) {
+		resp, data, err := decodeResponse([]byte(`{"requestId": "req-2", "status": {"code": 204}, "result": {"data": null}}`))
+		require.NoError(t, err)
+
+		assert.Equal(t, StatusNoContent, resp.Status.Code)
+		assert.Empty(t, data)
+	})
+
+	t.Run("malformed message", func(t *testing.T) {
+		_, _, err := decodeResponse([]byte(`not json`))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,60 @@
+package gremlin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+const neptuneService = "neptune-db"
+
+// emptyPayloadHash is the SHA-256 of the empty handshake body
+var emptyPayloadHash = func() string {
+	sum := sha256.Sum256(nil)
+	return hex.EncodeToString(sum[:])
+}()
+
+// signHandshake signs the WebSocket upgrade request for Neptune IAM authentication
+// Neptune verifies the signature of an HTTPS GET on the same host and path, so that request is signed and its headers reused
+func signHandshake(ctx context.Context, location *url.URL, region string, credentials aws.CredentialsProvider, signingTime time.Time) (http.Header, error) {
+	if region == "" {
+		return nil, fmt.Errorf("region is required to sign gremlin requests")
+	}
+
+	signed := *location
+	switch location.Scheme {
+	case "wss":
+		signed.Scheme = "https"
+	case "ws":
+		signed.Scheme = "http"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, signed.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build gremlin signing request: %w", err)
+	}
+
+	creds, err := credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+
+	if err := v4.NewSigner().SignHTTP(ctx, creds, req, emptyPayloadHash, neptuneService, region, signingTime); err != nil {
+		return nil, fmt.Errorf("failed to sign gremlin request: %w", err)
+	}
+
+	headers := http.Header{}
+	for _, name := range []string{"Authorization", "X-Amz-Date", "X-Amz-Security-Token"} {
+		if value := req.Header.Get(name); value != "" {
+			headers.Set(name, value)
+		}
+	}
+	return headers, nil
+}
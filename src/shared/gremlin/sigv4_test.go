@@ -0,0 +1,36 @@
+package gremlin
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testCredentials = aws.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret", SessionToken: "session-token"}
+
+func TestSignHandshake(t *testing.T) {
+	location, err := url.Parse("wss://cluster.eu-west-1.neptune.amazonaws.com:8182/gremlin")
+	require.NoError(t, err)
+	credentials := aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) { return testCredentials, nil })
+
+	headers, err := signHandshake(context.Background(), location, "eu-west-1", credentials, time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	assert.Contains(t, headers.Get("Authorization"), "Credential=AKIDEXAMPLE/20240501/eu-west-1/neptune-db/aws4_request")
+	assert.Contains(t, headers.Get("Authorization"), "SignedHeaders=host;x-amz-date;x-amz-security-token")
+	assert.Equal(t, "20240501T093000Z", headers.Get("X-Amz-Date"))
+	assert.Equal(t, "session-token", headers.Get("X-Amz-Security-Token"))
+}
+
+func TestSignHandshake_RequiresRegion(t *testing.T) {
+	location, err := url.Parse("wss://cluster:8182/gremlin")
+	require.NoError(t, err)
+
+	_, err = signHandshake(context.Background(), location, "", nil, time.Now())
+	assert.ErrorContains(t, err, "region")
+}
@@ -0,0 +1,40 @@
+package gremlin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UpsertVertex adds a statement creating the vertex if it does not exist and then setting its properties
+func (b *Batch) UpsertVertex(id, label string, properties map[string]interface{}) {
+	idBinding := b.Bind(id)
+	statement := fmt.Sprintf("g.V(%s).fold().coalesce(unfold(), addV(%s).property(T.id, %s))", idBinding, b.Bind(label), idBinding)
+	// Neptune defaults vertex properties to set cardinality; single makes repeated upserts overwrite
+	b.Add(statement + b.properties("single, ", properties))
+}
+
+// UpsertEdge adds a statement creating the edge between two existing vertices if it does not exist and then setting its properties
+func (b *Batch) UpsertEdge(id, label, fromID, toID string, properties map[string]interface{}) {
+	idBinding := b.Bind(id)
+	statement := fmt.Sprintf(
+		"g.E(%s).fold().coalesce(unfold(), addE(%s).from(__.V(%s)).to(__.V(%s)).property(T.id, %s))",
+		idBinding, b.Bind(label), b.Bind(fromID), b.Bind(toID), idBinding,
+	)
+	b.Add(statement + b.properties("", properties))
+}
+
+// properties renders property steps in key order so identical upserts produce identical scripts
+func (b *Batch) properties(cardinality string, properties map[string]interface{}) string {
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var steps strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&steps, ".property(%s%s, %s)", cardinality, b.Bind(key), b.Bind(properties[key]))
+	}
+	return steps.String()
+}
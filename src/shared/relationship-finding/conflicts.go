@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-xray-sdk-go/v2/xray"
+
+	"bacon/src/shared/graphstore"
+)
+
+// defaultConflictReviewSLA is how long a conflict may stay open before it breaches its SLA, unless
+// CONFLICT_REVIEW_SLA sets another duration
+const defaultConflictReviewSLA = 72 * time.Hour
+
+func conflictReviewSLA() time.Duration {
+	if value := os.Getenv("CONFLICT_REVIEW_SLA"); value != "" {
+		sla, err := time.ParseDuration(value)
+		if err == nil && sla > 0 {
+			return sla
+		}
+		log.Printf("Ignoring invalid CONFLICT_REVIEW_SLA %q", value)
+	}
+	return defaultConflictReviewSLA
+}
+
+// syncConflicts keeps a review record for every conflict: it opens one for each new conflict in relationships,
+// refreshes the candidates and evidence of those already open, and closes those whose sources now agree
+// Targets this run has no claims on are left alone, since a conflict only closes on evidence that it is over.
+// A conflict a reviewer resolved stays closed until a claim it did not weigh turns up.
+func syncConflicts(ctx context.Context, store graphstore.GraphStore, relationships []Relationship, detector *ConflictDetector, now time.Time) (opened, closed int, err error) {
+	ctx, seg := xray.BeginSubsegment(ctx, "sync-conflicts")
+	defer seg.Close(nil)
+
+	var ids []string
+	groups := make(map[string][]Relationship)
+	for _, rel := range relationships {
+		if !detector.rule(rel.Type).Exclusive {
+			continue
+		}
+		id := graphstore.ConflictID(rel.Type, detector.conflictTarget(rel))
+		if _, seen := groups[id]; !seen {
+			ids = append(ids, id)
+		}
+		groups[id] = append(groups[id], rel)
+	}
+	sort.Strings(ids)
+
+	timestamp := now.UTC().Format(time.RFC3339)
+	var records []graphstore.Vertex
+	for _, id := range ids {
+		record, found, err := loadConflict(ctx, store, id)
+		if err != nil {
+			_ = seg.AddError(err)
+			return opened, closed, err
+		}
+
+		group := groups[id]
+		if !group[0].HasConflict {
+			if found && record.Open() {
+				record.Status = graphstore.ConflictClosed
+				record.Resolution = graphstore.ConflictResolvedByAgreement
+				record.ClosedAt = timestamp
+				record.LastSeen = timestamp
+				records = append(records, record.Vertex())
+				closed++
+			}
+			continue
+		}
+
+		candidates, evidence := conflictEvidence(group)
+		switch {
+		case found && record.Open():
+			record.Candidates = candidates
+			record.Evidence = evidence
+			record.LastSeen = timestamp
+		case found && record.Resolution == graphstore.ConflictResolvedManually && isSubset(candidates, record.Candidates):
+			continue
+		default:
+			// The owner the sources most trust is best placed to confirm or hand over the resource
+			record = graphstore.Conflict{
+				ID:               id,
+				Resource:         graphstore.VertexID(group[0].To),
+				RelationshipType: group[0].Type,
+				Candidates:       candidates,
+				Evidence:         evidence,
+				Assignee:         evidence[0].Owner,
+				Status:           graphstore.ConflictOpen,
+				FirstSeen:        timestamp,
+				LastSeen:         timestamp,
+				SLADueAt:         now.Add(conflictReviewSLA()).UTC().Format(time.RFC3339),
+			}
+			log.Printf("Opened conflict %s over %s of %s, assigned to %s", id, record.RelationshipType, group[0].To, record.Assignee)
+			opened++
+		}
+		records = append(records, record.Vertex())
+	}
+
+	if len(records) > 0 {
+		if err := store.UpsertVertices(ctx, records); err != nil {
+			_ = seg.AddError(err)
+			return 0, 0, err
+		}
+	}
+
+	_ = seg.AddAnnotation("conflicts_opened", opened)
+	_ = seg.AddAnnotation("conflicts_closed", closed)
+	return opened, closed, nil
+}
+
+// loadConflict reads the conflict record with the given ID; a record that cannot be read is reported as missing
+// so a fresh one replaces it
+func loadConflict(ctx context.Context, store graphstore.GraphStore, id string) (graphstore.Conflict, bool, error) {
+	vertex, found, err := store.GetVertex(ctx, id)
+	if err != nil || !found {
+		return graphstore.Conflict{}, false, err
+	}
+	record, err := graphstore.ConflictFromVertex(vertex)
+	if err != nil {
+		log.Printf("Replacing unreadable conflict record: %v", err)
+		return graphstore.Conflict{}, false, nil
+	}
+	return record, true, nil
+}
+
+// conflictEvidence lists the candidates of a conflicted group and their claims, winners first
+func conflictEvidence(group []Relationship) ([]string, []graphstore.ConflictEvidence) {
+	ordered := make([]Relationship, len(group))
+	copy(ordered, group)
+	sort.SliceStable(ordered, func(i, j int) bool { return !ordered[i].Superseded && ordered[j].Superseded })
+
+	candidates := make([]string, 0, len(ordered))
+	evidence := make([]graphstore.ConflictEvidence, 0, len(ordered))
+	for _, rel := range ordered {
+		id := relationshipID(rel)
+		candidates = append(candidates, id)
+		evidence = append(evidence, graphstore.ConflictEvidence{
+			Relationship: id,
+			Owner:        rel.From,
+			Source:       rel.Source,
+			Confidence:   rel.Confidence,
+			Timestamp:    rel.Timestamp,
+			Superseded:   rel.Superseded,
+		})
+	}
+	return candidates, evidence
+}
+
+func isSubset(items, set []string) bool {
+	members := make(map[string]bool, len(set))
+	for _, item := range set {
+		members[item] = true
+	}
+	for _, item := range items {
+		if !members[item] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	common "bacon/src/shared"
+	"bacon/src/shared/graphstore"
+)
+
+// processConflicts detects the conflicts among claims and syncs their review records at now
+func processConflicts(ctx context.Context, t *testing.T, store graphstore.GraphStore, claims []Relationship, now time.Time) (int, int) {
+	t.Helper()
+	detector := initConflictDetector(loadScoringConfig())
+	resolved, _ := detectAndResolveConflicts(ctx, claims, detector)
+	if err := storeRelationships(ctx, store, resolved); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	opened, closed, err := syncConflicts(ctx, store, resolved, detector, now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return opened, closed
+}
+
+func conflictRecord(ctx context.Context, t *testing.T, store graphstore.GraphStore, id string) graphstore.Conflict {
+	t.Helper()
+	record, found, err := loadConflict(ctx, store, id)
+	if err != nil || !found {
+		t.Fatalf("Expected conflict record %s, got found %v (err %v)", id, found, err)
+	}
+	return record
+}
+
+// Test a conflict opens a record, which is refreshed while it lasts and closes once the sources agree
+func TestSyncConflicts_Lifecycle(t *testing.T) {
+	ctx, cleanup := common.TestContext("sync-conflicts-lifecycle-test")
+	defer cleanup()
+
+	store := graphstore.NewMemoryStore()
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	winning := Relationship{From: "team-a", To: "payments", Type: "owns", Source: "aws-tags", Confidence: 0.8}
+	losing := Relationship{From: "team-b", To: "payments", Type: "owns", Source: "github-codeowners", Confidence: 0.7}
+	id := graphstore.ConflictID("owns", "payments")
+
+	opened, closed := processConflicts(ctx, t, store, []Relationship{winning, losing}, start)
+	if opened != 1 || closed != 0 {
+		t.Fatalf("Expected one conflict opened, got %d opened and %d closed", opened, closed)
+	}
+	record := conflictRecord(ctx, t, store, id)
+	if !record.Open() || record.Resource != graphstore.VertexID("payments") || record.Assignee != "team-a" {
+		t.Errorf("Expected an open record assigned to the winning owner, got %+v", record)
+	}
+	if len(record.Candidates) != 2 || record.Candidates[0] != relationshipID(winning) || !record.Evidence[1].Superseded {
+		t.Errorf("Expected both candidates, winner first, got %+v", record)
+	}
+	if record.FirstSeen != "2024-05-01T09:00:00Z" || record.SLADueAt != "2024-05-04T09:00:00Z" {
+		t.Errorf("Expected the default 72h SLA from first seen, got %+v", record)
+	}
+	edge, _, _ := store.GetEdge(ctx, relationshipID(losing))
+	if edge.Properties[graphstore.ConflictProperty] != id {
+		t.Errorf("Expected the candidate edges to name their conflict, got %v", edge.Properties)
+	}
+
+	// Seen again, the record keeps its age
+	opened, _ = processConflicts(ctx, t, store, []Relationship{winning, losing}, start.Add(24*time.Hour))
+	record = conflictRecord(ctx, t, store, id)
+	if opened != 0 || record.FirstSeen != "2024-05-01T09:00:00Z" || record.LastSeen != "2024-05-02T09:00:00Z" {
+		t.Errorf("Expected the open record to be refreshed, got %d opened and %+v", opened, record)
+	}
+
+	// A run without claims on the resource says nothing about the conflict
+	if _, closed := processConflicts(ctx, t, store, []Relationship{{From: "team-c", To: "orders", Type: "owns", Source: "aws-tags"}}, start.Add(48*time.Hour)); closed != 0 {
+		t.Errorf("Expected no conflict closed without evidence, got %d", closed)
+	}
+
+	// Once the sources agree the conflict closes
+	losing.From = "team-a"
+	_, closed = processConflicts(ctx, t, store, []Relationship{winning, losing}, start.Add(72*time.Hour))
+	record = conflictRecord(ctx, t, store, id)
+	if closed != 1 || record.Open() || record.Resolution != graphstore.ConflictResolvedByAgreement || record.ClosedAt != "2024-05-04T09:00:00Z" {
+		t.Errorf("Expected the conflict closed by agreement, got %d closed and %+v", closed, record)
+	}
+}
+
+// Test a conflict a reviewer resolved stays closed until a new claim turns up
+func TestSyncConflicts_ManualResolution(t *testing.T) {
+	ctx, cleanup := common.TestContext("sync-conflicts-manual-test")
+	defer cleanup()
+	t.Setenv("CONFLICT_REVIEW_SLA", "24h")
+
+	store := graphstore.NewMemoryStore()
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	claims := []Relationship{
+		{From: "team-a", To: "payments", Type: "owns", Source: "aws-tags", Confidence: 0.8},
+		{From: "team-b", To: "payments", Type: "owns", Source: "github-codeowners", Confidence: 0.7},
+	}
+	id := graphstore.ConflictID("owns", "payments")
+
+	processConflicts(ctx, t, store, claims, now)
+	record := conflictRecord(ctx, t, store, id)
+	if record.SLADueAt != "2024-05-02T09:00:00Z" {
+		t.Errorf("Expected the configured SLA, got %s", record.SLADueAt)
+	}
+
+	record.Status = graphstore.ConflictClosed
+	record.Resolution = graphstore.ConflictResolvedManually
+	if err := store.UpsertVertices(ctx, []graphstore.Vertex{record.Vertex()}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if opened, _ := processConflicts(ctx, t, store, claims, now.Add(time.Hour)); opened != 0 || conflictRecord(ctx, t, store, id).Open() {
+		t.Errorf("Expected the reviewer's verdict to stand against the same claims")
+	}
+
+	claims = append(claims, Relationship{From: "team-c", To: "payments", Type: "owns", Source: "openshift-metadata", Confidence: 0.6})
+	if opened, _ := processConflicts(ctx, t, store, claims, now.Add(2*time.Hour)); opened != 1 {
+		t.Errorf("Expected a new claim to reopen the conflict, got %d opened", opened)
+	}
+	if record := conflictRecord(ctx, t, store, id); !record.Open() || record.FirstSeen != "2024-05-01T11:00:00Z" {
+		t.Errorf("Expected a fresh open record, got %+v", record)
+	}
+}
+
+// Test shared relationship types never open conflicts
+func TestSyncConflicts_SharedTypes(t *testing.T) {
+	ctx, cleanup := common.TestContext("sync-conflicts-shared-test")
+	defer cleanup()
+
+	store := graphstore.NewMemoryStore()
+	opened, _ := processConflicts(ctx, t, store, []Relationship{
+		{From: "team-a", To: "payments", Type: "maintains", Source: "aws-tags"},
+		{From: "team-b", To: "payments", Type: "maintains", Source: "github-codeowners"},
+	}, time.Now())
+
+	records, _ := store.FindVertices(ctx, graphstore.VertexFilter{Label: graphstore.LabelConflict})
+	if opened != 0 || len(records) != 0 {
+		t.Errorf("Expected no conflict records, got %+v", records)
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-xray-sdk-go/v2/xray"
+
+	"bacon/src/shared/relationship-finding/evidence"
+	"bacon/src/shared/relationship-finding/identity"
+	"bacon/src/shared/scraperoutput"
+)
+
+// newEvidenceStore returns the DynamoDB evidence table when EVIDENCE_TABLE is set and an empty in-memory store otherwise
+var newEvidenceStore = evidence.Default
+
+// withdrawal is the evidence of a claim its source's latest snapshot no longer contains
+type withdrawal struct {
+	Evidence evidence.Evidence
+	Snapshot string // ID of the snapshot that left the claim out
+}
+
+// updateEvidence records claims as the latest evidence of their sources and forgets withdrawn claims and the
+// Datadog evidence of deleted entities and retracted relationships. It returns every claim on the resources this
+// run touched, those just received first, so the resources can be rescored without the other sources being sent again.
+// Stored claims are resolved again with the resolver of this run; those now naming another identity are refiled
+// and returned as they were stored, so the edges left under their old names can be archived.
+func updateEvidence(ctx context.Context, store evidence.Store, claims []Relationship, deletedEntities []string, retracted []Relationship, withdrawn []withdrawal, detector *ConflictDetector, resolver *identity.Resolver) ([]Relationship, []string, []Relationship, error) {
+	ctx, seg := xray.BeginSubsegment(ctx, "update-evidence")
+	defer seg.Close(nil)
+
+	var targets []string
+	affected := make(map[string]bool)
+	addTarget := func(target string) {
+		if !affected[target] {
+			affected[target] = true
+			targets = append(targets, target)
+		}
+	}
+
+	received := make([]evidence.Evidence, 0, len(claims))
+	for _, rel := range claims {
+		target := detector.conflictTarget(rel)
+		addTarget(target)
+		received = append(received, evidence.Evidence{Target: target, Claim: rel})
+	}
+
+	removed, err := staleEvidence(ctx, store, deletedEntities, retracted, detector)
+	if err != nil {
+		_ = seg.AddError(err)
+		return nil, nil, nil, err
+	}
+	for _, w := range withdrawn {
+		removed = append(removed, w.Evidence)
+	}
+	for _, item := range removed {
+		addTarget(item.Target)
+	}
+
+	if err := store.Delete(ctx, removed); err != nil {
+		_ = seg.AddError(err)
+		return nil, nil, nil, err
+	}
+	if err := store.Put(ctx, received); err != nil {
+		_ = seg.AddError(err)
+		return nil, nil, nil, err
+	}
+
+	stored, err := store.ForTargets(ctx, targets)
+	if err != nil {
+		_ = seg.AddError(err)
+		return nil, nil, nil, err
+	}
+
+	// Claims of this run are scored as received; the store only adds what other sources last said
+	seen := make(map[string]bool, len(received)+len(stored))
+	for _, item := range received {
+		seen[item.Target+"\x00"+item.Key()] = true
+	}
+	relationships := append([]Relationship(nil), claims...)
+	var renamed []Relationship
+	var moved, refiled []evidence.Evidence
+	for _, item := range stored {
+		if seen[item.Target+"\x00"+item.Key()] {
+			continue
+		}
+		current := evidence.Evidence{Claim: reresolveRelationship(item.Claim, resolver)}
+		current.Target = detector.conflictTarget(current.Claim)
+		changed := current.Claim.From != item.Claim.From || current.Claim.To != item.Claim.To
+		if changed {
+			renamed = append(renamed, item.Claim)
+			moved = append(moved, item)
+		}
+		// A claim now naming what another one does is the same claim
+		if seen[current.Target+"\x00"+current.Key()] {
+			continue
+		}
+		seen[current.Target+"\x00"+current.Key()] = true
+		if changed {
+			refiled = append(refiled, current)
+		}
+		relationships = append(relationships, current.Claim)
+	}
+
+	if err := store.Delete(ctx, moved); err != nil {
+		_ = seg.AddError(err)
+		return nil, nil, nil, err
+	}
+	if err := store.Put(ctx, refiled); err != nil {
+		_ = seg.AddError(err)
+		return nil, nil, nil, err
+	}
+
+	_ = seg.AddAnnotation("affected_resources", len(targets))
+	_ = seg.AddAnnotation("evidence_removed", len(removed))
+	_ = seg.AddAnnotation("evidence_refiled", len(moved))
+	return relationships, targets, renamed, nil
+}
+
+// staleEvidence finds the Datadog evidence involving deleted entities or naming retracted relationships
+func staleEvidence(ctx context.Context, store evidence.Store, deletedEntities []string, retracted []Relationship, detector *ConflictDetector) ([]evidence.Evidence, error) {
+	var stale []evidence.Evidence
+	for _, entity := range deletedEntities {
+		involving, err := store.Involving(ctx, entity)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range involving {
+			if isDatadogSource(item.Claim.Source) {
+				stale = append(stale, item)
+			}
+		}
+	}
+
+	for _, rel := range retracted {
+		filed, err := store.ForTargets(ctx, []string{detector.conflictTarget(rel)})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range filed {
+			claim := item.Claim
+			if isDatadogSource(claim.Source) && claim.From == rel.From && claim.To == rel.To && claim.Type == rel.Type {
+				stale = append(stale, item)
+			}
+		}
+	}
+
+	return stale, nil
+}
+
+// withdrawnEvidence finds, for every snapshot among the outputs, the stored claims its source made within the
+// snapshot's scope that no output of this run makes any more
+// Claims recorded from outputs produced after the snapshot are kept, so a late snapshot cannot retract what its
+// source has said since.
+func withdrawnEvidence(ctx context.Context, store evidence.Store, outputs []scraperoutput.Envelope, claims []Relationship) ([]withdrawal, error) {
+	made := make(map[string]bool, len(claims))
+	for _, rel := range claims {
+		made[evidence.Evidence{Claim: rel}.Key()] = true
+	}
+
+	var withdrawn []withdrawal
+	seen := make(map[string]bool)
+	for _, output := range outputs {
+		if output.Snapshot == nil {
+			continue
+		}
+		producedAt, err := time.Parse(time.RFC3339, output.ProducedAt)
+		if err != nil {
+			producedAt = time.Now()
+		}
+
+		scoped, err := store.InScope(ctx, output.Source, output.Snapshot.Scope)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range scoped {
+			if made[item.Key()] || seen[item.Target+"\x00"+item.Key()] {
+				continue
+			}
+			if recordedAt, err := time.Parse(time.RFC3339, item.Claim.Timestamp); err == nil && recordedAt.After(producedAt) {
+				continue
+			}
+			seen[item.Target+"\x00"+item.Key()] = true
+			withdrawn = append(withdrawn, withdrawal{Evidence: item, Snapshot: output.Snapshot.ID})
+		}
+	}
+
+	return withdrawn, nil
+}
@@ -0,0 +1,149 @@
+package evidence
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bacon/src/shared/relationship-finding/extractors"
+	"bacon/src/shared/relationship-finding/identity"
+)
+
+func claim(source, from, to, relType string, confidence float64) Evidence {
+	return Evidence{Target: to, Claim: extractors.Relationship{From: from, To: to, Type: relType, Source: source, Confidence: confidence}}
+}
+
+// testStore runs the behaviour every Store implementation must share
+func testStore(t *testing.T, newStore func(*testing.T) Store) {
+	ctx := context.Background()
+
+	t.Run("put replaces the latest claim of a source", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.Put(ctx, []Evidence{
+			claim("aws-tags", "team-a", "payments", "owns", 0.6),
+			claim("github-codeowners", "team-b", "payments", "owns", 0.7),
+		}))
+		require.NoError(t, store.Put(ctx, []Evidence{claim("aws-tags", "team-a", "payments", "owns", 0.9)}))
+
+		stored, err := store.ForTargets(ctx, []string{"payments"})
+		require.NoError(t, err)
+		require.Len(t, stored, 2)
+		assert.Equal(t, "aws-tags", stored[0].Claim.Source)
+		assert.Equal(t, 0.9, stored[0].Claim.Confidence)
+		assert.Equal(t, "github-codeowners", stored[1].Claim.Source)
+	})
+
+	t.Run("for targets returns only the named targets", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.Put(ctx, []Evidence{
+			claim("aws-tags", "team-a", "payments", "owns", 0.6),
+			claim("aws-tags", "team-a", "orders", "owns", 0.6),
+			claim("aws-tags", "team-a", "billing", "owns", 0.6),
+		}))
+
+		stored, err := store.ForTargets(ctx, []string{"payments", "billing", "payments", "unknown"})
+		require.NoError(t, err)
+		require.Len(t, stored, 2)
+		assert.Equal(t, "billing", stored[0].Target)
+		assert.Equal(t, "payments", stored[1].Target)
+	})
+
+	t.Run("delete removes claims by key", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.Put(ctx, []Evidence{
+			claim("aws-tags", "team-a", "payments", "owns", 0.6),
+			claim("github-codeowners", "team-b", "payments", "owns", 0.7),
+		}))
+
+		// Only the key matters, and missing claims are ignored
+		require.NoError(t, store.Delete(ctx, []Evidence{
+			claim("aws-tags", "team-a", "payments", "owns", 0),
+			claim("aws-tags", "team-z", "payments", "owns", 0),
+		}))
+
+		stored, err := store.ForTargets(ctx, []string{"payments"})
+		require.NoError(t, err)
+		require.Len(t, stored, 1)
+		assert.Equal(t, "github-codeowners", stored[0].Claim.Source)
+	})
+
+	t.Run("involving finds claims made by or filed under an entity", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.Put(ctx, []Evidence{
+			claim("datadog-teams", "alice", "team-a", "member_of", 1),
+			claim("datadog-services", "team-a", "payments", "owns", 1),
+			claim("datadog-services", "team-b", "orders", "owns", 1),
+		}))
+
+		involving, err := store.Involving(ctx, "team-a")
+		require.NoError(t, err)
+		require.Len(t, involving, 2)
+		assert.Equal(t, "payments", involving[0].Target)
+		assert.Equal(t, "team-a", involving[1].Target)
+		assert.Equal(t, "alice", involving[1].Claim.From)
+	})
+
+	t.Run("in scope finds the claims a source made within a scope", func(t *testing.T) {
+		store := newStore(t)
+		scoped := func(item Evidence, scope string) Evidence {
+			item.Claim.Scope = scope
+			return item
+		}
+		require.NoError(t, store.Put(ctx, []Evidence{
+			scoped(claim("github-codeowners", "team-a", "src", "owns", 0.8), "acme/payments"),
+			scoped(claim("github-codeowners", "team-b", "docs", "owns", 0.8), "acme/payments"),
+			scoped(claim("github-codeowners", "team-a", "lib", "owns", 0.8), "acme/orders"),
+			scoped(claim("aws-tags", "team-a", "src", "owns", 0.8), "acme/payments"),
+			claim("github-codeowners", "team-c", "src", "owns", 0.8),
+		}))
+
+		scope, err := store.InScope(ctx, "github-codeowners", "acme/payments")
+		require.NoError(t, err)
+		require.Len(t, scope, 2)
+		assert.Equal(t, "docs", scope[0].Target)
+		assert.Equal(t, "src", scope[1].Target)
+
+		unscoped, err := store.InScope(ctx, "github-codeowners", "")
+		require.NoError(t, err)
+		require.Len(t, unscoped, 1)
+		assert.Equal(t, "team-c", unscoped[0].Claim.From)
+	})
+
+	t.Run("aliases replace those of the same name", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.Put(ctx, []Evidence{claim("aws-tags", "team-a", "payments", "owns", 0.6)}))
+		require.NoError(t, store.PutAliases(ctx, []identity.Alias{
+			{Name: "platform", Canonical: "team:infra", Source: identity.SourceInferred},
+			{Name: "backend", Canonical: "team:backend", Source: identity.SourceInferred},
+		}))
+		require.NoError(t, store.PutAliases(ctx, []identity.Alias{{Name: "platform", Canonical: "team:platform", Source: "datadog-teams"}}))
+
+		aliases, err := store.Aliases(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []identity.Alias{
+			{Name: "backend", Canonical: "team:backend", Source: identity.SourceInferred},
+			{Name: "platform", Canonical: "team:platform", Source: "datadog-teams"},
+		}, aliases)
+
+		// Aliases are not claims
+		stored, err := store.Involving(ctx, "team-a")
+		require.NoError(t, err)
+		assert.Len(t, stored, 1)
+	})
+
+	t.Run("claims keep their fields", func(t *testing.T) {
+		store := newStore(t)
+		stored := Evidence{Target: "src", Claim: extractors.Relationship{
+			From: "team-a", To: "/src/**", Type: "owns", Source: "github-codeowners", Confidence: 0.8,
+			Timestamp: "2024-05-01T09:30:00Z", ResourceType: extractors.ResourceTypeRepository, Scope: "acme/payments",
+		}}
+		require.NoError(t, store.Put(ctx, []Evidence{stored}))
+
+		loaded, err := store.ForTargets(ctx, []string{"src"})
+		require.NoError(t, err)
+		require.Len(t, loaded, 1)
+		assert.Equal(t, stored, loaded[0])
+	})
+}
@@ -0,0 +1,282 @@
+package evidence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"bacon/src/shared/relationship-finding/identity"
+)
+
+// Table layout: claims are partitioned by target and sorted by key, the from-index finds the claims an
+// entity made and the scope-index those a source made within a snapshot scope
+const (
+	targetAttribute    = "target"
+	keyAttribute       = "claim_key"
+	fromAttribute      = "from"
+	scopeAttribute     = "source_scope"
+	claimAttribute     = "claim"
+	updatedAtAttribute = "updated_at"
+	fromIndex          = "from-index"
+	scopeIndex         = "scope-index"
+
+	// Identity aliases share the table under their own target, one item per name; they carry neither a from nor
+	// a scope, so the indexes never return them
+	aliasTarget          = "#identity-aliases"
+	canonicalAttribute   = "canonical"
+	aliasSourceAttribute = "alias_source"
+
+	dynamoDBBatchLimit = 25 // BatchWriteItem hard limit
+	maxWriteAttempts   = 5
+	retryBaseDelay     = 100 * time.Millisecond
+)
+
+// DynamoDBAPI is the subset of the DynamoDB client used by the evidence store
+type DynamoDBAPI interface {
+	BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+}
+
+// DynamoDBStore keeps evidence in a DynamoDB table, so it outlives the invocation that recorded it
+type DynamoDBStore struct {
+	client DynamoDBAPI
+	table  string
+	now    func() time.Time
+}
+
+// NewDynamoDBStore creates a store over the given table
+func NewDynamoDBStore(client DynamoDBAPI, table string) *DynamoDBStore {
+	return &DynamoDBStore{client: client, table: table, now: time.Now}
+}
+
+func (s *DynamoDBStore) Put(ctx context.Context, evidence []Evidence) error {
+	updatedAt := s.now().UTC().Format(time.RFC3339)
+	requests := make([]types.WriteRequest, 0, len(evidence))
+	for _, item := range dedupe(evidence) {
+		claim, err := json.Marshal(item.Claim)
+		if err != nil {
+			return fmt.Errorf("failed to encode evidence: %w", err)
+		}
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: map[string]types.AttributeValue{
+			targetAttribute:    &types.AttributeValueMemberS{Value: item.Target},
+			keyAttribute:       &types.AttributeValueMemberS{Value: item.Key()},
+			fromAttribute:      &types.AttributeValueMemberS{Value: item.Claim.From},
+			scopeAttribute:     &types.AttributeValueMemberS{Value: sourceScope(item.Claim.Source, item.Claim.Scope)},
+			claimAttribute:     &types.AttributeValueMemberS{Value: string(claim)},
+			updatedAtAttribute: &types.AttributeValueMemberS{Value: updatedAt},
+		}}})
+	}
+	return s.write(ctx, requests)
+}
+
+func (s *DynamoDBStore) Delete(ctx context.Context, evidence []Evidence) error {
+	requests := make([]types.WriteRequest, 0, len(evidence))
+	for _, item := range dedupe(evidence) {
+		requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: map[string]types.AttributeValue{
+			targetAttribute: &types.AttributeValueMemberS{Value: item.Target},
+			keyAttribute:    &types.AttributeValueMemberS{Value: item.Key()},
+		}}})
+	}
+	return s.write(ctx, requests)
+}
+
+func (s *DynamoDBStore) ForTargets(ctx context.Context, targets []string) ([]Evidence, error) {
+	seen := make(map[string]bool, len(targets))
+	var evidence []Evidence
+	for _, target := range targets {
+		if seen[target] {
+			continue
+		}
+		seen[target] = true
+		items, err := s.query(ctx, "", targetAttribute, target)
+		if err != nil {
+			return nil, err
+		}
+		evidence = append(evidence, items...)
+	}
+	sortEvidence(evidence)
+	return evidence, nil
+}
+
+func (s *DynamoDBStore) Involving(ctx context.Context, entity string) ([]Evidence, error) {
+	made, err := s.query(ctx, fromIndex, fromAttribute, entity)
+	if err != nil {
+		return nil, err
+	}
+	filed, err := s.query(ctx, "", targetAttribute, entity)
+	if err != nil {
+		return nil, err
+	}
+
+	// A claim an entity made on itself is found both ways
+	evidence := dedupe(append(made, filed...))
+	sortEvidence(evidence)
+	return evidence, nil
+}
+
+func (s *DynamoDBStore) InScope(ctx context.Context, source, scope string) ([]Evidence, error) {
+	evidence, err := s.query(ctx, scopeIndex, scopeAttribute, sourceScope(source, scope))
+	if err != nil {
+		return nil, err
+	}
+	sortEvidence(evidence)
+	return evidence, nil
+}
+
+func (s *DynamoDBStore) PutAliases(ctx context.Context, aliases []identity.Alias) error {
+	updatedAt := s.now().UTC().Format(time.RFC3339)
+	requests := make([]types.WriteRequest, 0, len(aliases))
+	seen := make(map[string]bool, len(aliases))
+	for _, alias := range aliases {
+		if seen[alias.Name] {
+			continue
+		}
+		seen[alias.Name] = true
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: map[string]types.AttributeValue{
+			targetAttribute:      &types.AttributeValueMemberS{Value: aliasTarget},
+			keyAttribute:         &types.AttributeValueMemberS{Value: alias.Name},
+			canonicalAttribute:   &types.AttributeValueMemberS{Value: alias.Canonical},
+			aliasSourceAttribute: &types.AttributeValueMemberS{Value: alias.Source},
+			updatedAtAttribute:   &types.AttributeValueMemberS{Value: updatedAt},
+		}}})
+	}
+	return s.write(ctx, requests)
+}
+
+func (s *DynamoDBStore) Aliases(ctx context.Context) ([]identity.Alias, error) {
+	items, err := s.queryItems(ctx, "", targetAttribute, aliasTarget)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := make([]identity.Alias, 0, len(items))
+	for _, item := range items {
+		name, _ := item[keyAttribute].(*types.AttributeValueMemberS)
+		canonical, _ := item[canonicalAttribute].(*types.AttributeValueMemberS)
+		if name == nil || canonical == nil {
+			return nil, fmt.Errorf("alias item is missing its %s or %s", keyAttribute, canonicalAttribute)
+		}
+		alias := identity.Alias{Name: name.Value, Canonical: canonical.Value}
+		if source, ok := item[aliasSourceAttribute].(*types.AttributeValueMemberS); ok {
+			alias.Source = source.Value
+		}
+		aliases = append(aliases, alias)
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Name < aliases[j].Name })
+	return aliases, nil
+}
+
+// sourceScope is the scope-index key of the claims a source made within a scope
+func sourceScope(source, scope string) string {
+	return source + "\x00" + scope
+}
+
+// query reads every claim whose attribute equals value, from the table or one of its indexes
+func (s *DynamoDBStore) query(ctx context.Context, index, attribute, value string) ([]Evidence, error) {
+	items, err := s.queryItems(ctx, index, attribute, value)
+	if err != nil {
+		return nil, err
+	}
+
+	evidence := make([]Evidence, 0, len(items))
+	for _, item := range items {
+		decoded, err := decodeEvidence(item)
+		if err != nil {
+			return nil, err
+		}
+		evidence = append(evidence, decoded)
+	}
+	return evidence, nil
+}
+
+// queryItems reads every item whose attribute equals value, following the pages of the query
+func (s *DynamoDBStore) queryItems(ctx context.Context, index, attribute, value string) ([]map[string]types.AttributeValue, error) {
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(s.table),
+		KeyConditionExpression:    aws.String("#key = :value"),
+		ExpressionAttributeNames:  map[string]string{"#key": attribute},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":value": &types.AttributeValueMemberS{Value: value}},
+	}
+	if index != "" {
+		input.IndexName = aws.String(index)
+	}
+
+	var items []map[string]types.AttributeValue
+	for {
+		output, err := s.client.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query evidence of %s: %w", value, err)
+		}
+		items = append(items, output.Items...)
+		if len(output.LastEvaluatedKey) == 0 {
+			return items, nil
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+}
+
+// write sends requests in batches, retrying unprocessed requests with exponential backoff
+func (s *DynamoDBStore) write(ctx context.Context, requests []types.WriteRequest) error {
+	for start := 0; start < len(requests); start += dynamoDBBatchLimit {
+		end := min(start+dynamoDBBatchLimit, len(requests))
+		pending := requests[start:end]
+
+		for attempt := 0; len(pending) > 0; attempt++ {
+			if attempt == maxWriteAttempts {
+				return fmt.Errorf("failed to write evidence: %d requests left unprocessed", len(pending))
+			}
+			if attempt > 0 {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(retryBaseDelay << (attempt - 1)):
+				}
+			}
+
+			output, err := s.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+				RequestItems: map[string][]types.WriteRequest{s.table: pending},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to write evidence: %w", err)
+			}
+			pending = output.UnprocessedItems[s.table]
+		}
+	}
+	return nil
+}
+
+func decodeEvidence(item map[string]types.AttributeValue) (Evidence, error) {
+	target, _ := item[targetAttribute].(*types.AttributeValueMemberS)
+	claim, _ := item[claimAttribute].(*types.AttributeValueMemberS)
+	if target == nil || claim == nil {
+		return Evidence{}, fmt.Errorf("evidence item is missing its %s or %s", targetAttribute, claimAttribute)
+	}
+
+	evidence := Evidence{Target: target.Value}
+	if err := json.Unmarshal([]byte(claim.Value), &evidence.Claim); err != nil {
+		return Evidence{}, fmt.Errorf("malformed evidence on %s: %w", target.Value, err)
+	}
+	return evidence, nil
+}
+
+// dedupe keeps the last evidence under each target and key, as a batch may not name an item twice
+func dedupe(evidence []Evidence) []Evidence {
+	index := make(map[string]int, len(evidence))
+	var unique []Evidence
+	for _, item := range evidence {
+		id := item.Target + "\x00" + item.Key()
+		if i, found := index[id]; found {
+			unique[i] = item
+			continue
+		}
+		index[id] = len(unique)
+		unique = append(unique, item)
+	}
+	return unique
+}
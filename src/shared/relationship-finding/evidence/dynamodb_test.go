@@ -0,0 +1,158 @@
+package evidence
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDynamoDB is an in-memory evidence table that serves one item per query page and, when throttled, leaves
+// the last request of every batch unprocessed on its first try
+type fakeDynamoDB struct {
+	mu        sync.Mutex
+	items     map[string]map[string]types.AttributeValue // by target and claim key
+	throttled bool
+	batches   int
+	writeErr  error
+}
+
+func newFakeDynamoDB() *fakeDynamoDB {
+	return &fakeDynamoDB{items: make(map[string]map[string]types.AttributeValue)}
+}
+
+func stringAttribute(item map[string]types.AttributeValue, name string) string {
+	value, _ := item[name].(*types.AttributeValueMemberS)
+	if value == nil {
+		return ""
+	}
+	return value.Value
+}
+
+func itemID(item map[string]types.AttributeValue) string {
+	return stringAttribute(item, targetAttribute) + "\x00" + stringAttribute(item, keyAttribute)
+}
+
+func (f *fakeDynamoDB) BatchWriteItem(_ context.Context, params *dynamodb.BatchWriteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.writeErr != nil {
+		return nil, f.writeErr
+	}
+	f.batches++
+	for table, requests := range params.RequestItems {
+		if len(requests) > dynamoDBBatchLimit {
+			return nil, errors.New("too many items in batch")
+		}
+		processed := requests
+		var unprocessed []types.WriteRequest
+		if f.throttled && f.batches%2 == 1 {
+			processed, unprocessed = requests[:len(requests)-1], requests[len(requests)-1:]
+		}
+		for _, request := range processed {
+			if request.PutRequest != nil {
+				f.items[itemID(request.PutRequest.Item)] = request.PutRequest.Item
+			}
+			if request.DeleteRequest != nil {
+				delete(f.items, itemID(request.DeleteRequest.Key))
+			}
+		}
+		if len(unprocessed) > 0 {
+			return &dynamodb.BatchWriteItemOutput{UnprocessedItems: map[string][]types.WriteRequest{table: unprocessed}}, nil
+		}
+	}
+	return &dynamodb.BatchWriteItemOutput{}, nil
+}
+
+func (f *fakeDynamoDB) Query(_ context.Context, params *dynamodb.QueryInput, _ ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	attribute := params.ExpressionAttributeNames["#key"]
+	indexKeys := map[string]string{"": targetAttribute, fromIndex: fromAttribute, scopeIndex: scopeAttribute}
+	if indexKeys[aws.ToString(params.IndexName)] != attribute {
+		return nil, errors.New("key condition does not match the index")
+	}
+	value := params.ExpressionAttributeValues[":value"].(*types.AttributeValueMemberS).Value
+
+	var ids []string
+	for id, item := range f.items {
+		if stringAttribute(item, attribute) == value {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	start := 0
+	if params.ExclusiveStartKey != nil {
+		start = sort.SearchStrings(ids, itemID(params.ExclusiveStartKey)) + 1
+	}
+	if start >= len(ids) {
+		return &dynamodb.QueryOutput{}, nil
+	}
+	item := f.items[ids[start]]
+	output := &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{item}}
+	if start+1 < len(ids) {
+		output.LastEvaluatedKey = map[string]types.AttributeValue{
+			targetAttribute: item[targetAttribute],
+			keyAttribute:    item[keyAttribute],
+		}
+	}
+	return output, nil
+}
+
+func TestDynamoDBStore(t *testing.T) {
+	testStore(t, func(*testing.T) Store { return NewDynamoDBStore(newFakeDynamoDB(), "evidence") })
+}
+
+// Test writes are split into batches the API accepts and unprocessed requests are retried
+func TestDynamoDBStore_Batches(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeDynamoDB()
+	client.throttled = true
+	store := NewDynamoDBStore(client, "evidence")
+
+	var claims []Evidence
+	for i := 0; i < 30; i++ {
+		claims = append(claims, claim("aws-tags", "team-a", "payments", string(rune('a'+i)), 0.6))
+	}
+	require.NoError(t, store.Put(ctx, claims))
+
+	stored, err := store.ForTargets(ctx, []string{"payments"})
+	require.NoError(t, err)
+	assert.Len(t, stored, 30)
+	assert.Equal(t, 4, client.batches, "each of the two batches should be retried once")
+}
+
+// Test a batch naming the same claim twice is sent once, keeping the last claim
+func TestDynamoDBStore_DuplicateClaims(t *testing.T) {
+	ctx := context.Background()
+	store := NewDynamoDBStore(newFakeDynamoDB(), "evidence")
+
+	require.NoError(t, store.Put(ctx, []Evidence{
+		claim("aws-tags", "team-a", "payments", "owns", 0.6),
+		claim("aws-tags", "team-a", "payments", "owns", 0.9),
+	}))
+
+	stored, err := store.ForTargets(ctx, []string{"payments"})
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, 0.9, stored[0].Claim.Confidence)
+}
+
+func TestDynamoDBStore_WriteError(t *testing.T) {
+	client := newFakeDynamoDB()
+	client.writeErr = errors.New("throttled")
+	store := NewDynamoDBStore(client, "evidence")
+
+	err := store.Put(context.Background(), []Evidence{claim("aws-tags", "team-a", "payments", "owns", 0.6)})
+	assert.ErrorContains(t, err, "throttled")
+}
@@ -0,0 +1,77 @@
+// Package evidence keeps the latest claim of every source on every relationship, so the relationship processor
+// can rescore the resources one scraper output touches without every other source being sent again. It also keeps
+// the identity aliases the claims were resolved with, so a source publishing on its own names owners alike.
+package evidence
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	common "bacon/src/shared"
+	"bacon/src/shared/relationship-finding/extractors"
+	"bacon/src/shared/relationship-finding/identity"
+)
+
+// Evidence is the latest claim of one source on a relationship, filed under the target it competes for
+// The target is the claim's resource as conflict detection compares it, so every claim that can be scored or
+// conflict with another one is filed under the same target.
+type Evidence struct {
+	Target string                  `json:"target"`
+	Claim  extractors.Relationship `json:"claim"`
+}
+
+// Key identifies the claim within its target: one per source, endpoints and relationship type
+func (e Evidence) Key() string {
+	return strings.Join([]string{e.Claim.Source, e.Claim.From, e.Claim.To, e.Claim.Type}, "\x00")
+}
+
+// Store keeps the latest evidence of every source
+type Store interface {
+	// Put records each claim as the latest of its source, replacing the claim under the same key
+	Put(ctx context.Context, evidence []Evidence) error
+	// Delete removes the claims under the keys of the given evidence; missing claims are ignored
+	Delete(ctx context.Context, evidence []Evidence) error
+	// ForTargets returns the claims filed under any of the targets, ordered by target and key
+	ForTargets(ctx context.Context, targets []string) ([]Evidence, error)
+	// Involving returns the claims made by an entity or filed under it as their target
+	Involving(ctx context.Context, entity string) ([]Evidence, error)
+	// InScope returns the claims a source made within a snapshot scope, ordered by target and key
+	InScope(ctx context.Context, source, scope string) ([]Evidence, error)
+	// PutAliases records the identity aliases claims were resolved with, replacing those of the same name, so
+	// the claims of later runs resolve the same way
+	PutAliases(ctx context.Context, aliases []identity.Alias) error
+	// Aliases returns every recorded identity alias, ordered by name
+	Aliases(ctx context.Context) ([]identity.Alias, error)
+}
+
+var (
+	defaultMu    sync.Mutex
+	defaultStore Store
+)
+
+// Default returns the DynamoDB store of the EVIDENCE_TABLE table, created once and reused across warm Lambda
+// invocations. Without a table every call returns an empty in-memory store, so each invocation is scored on its
+// own evidence alone.
+func Default(ctx context.Context) (Store, error) {
+	table := os.Getenv("EVIDENCE_TABLE")
+	if table == "" {
+		return NewMemoryStore(), nil
+	}
+
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	if defaultStore != nil {
+		return defaultStore, nil
+	}
+
+	cfg, err := common.LoadAWSConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure evidence store: %w", err)
+	}
+	defaultStore = NewDynamoDBStore(common.CreateDynamoClient(cfg), table)
+	return defaultStore, nil
+}
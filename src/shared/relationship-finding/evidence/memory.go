@@ -0,0 +1,129 @@
+package evidence
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"bacon/src/shared/relationship-finding/identity"
+)
+
+// MemoryStore is a Store held in process memory
+type MemoryStore struct {
+	mu       sync.RWMutex
+	evidence map[string]map[string]Evidence // by target, then key
+	aliases  map[string]identity.Alias      // by name
+}
+
+// NewMemoryStore creates an empty store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{evidence: make(map[string]map[string]Evidence), aliases: make(map[string]identity.Alias)}
+}
+
+func (s *MemoryStore) Put(_ context.Context, evidence []Evidence) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, item := range evidence {
+		if s.evidence[item.Target] == nil {
+			s.evidence[item.Target] = make(map[string]Evidence)
+		}
+		s.evidence[item.Target][item.Key()] = item
+	}
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, evidence []Evidence) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, item := range evidence {
+		delete(s.evidence[item.Target], item.Key())
+		if len(s.evidence[item.Target]) == 0 {
+			delete(s.evidence, item.Target)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) ForTargets(_ context.Context, targets []string) ([]Evidence, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]bool, len(targets))
+	var evidence []Evidence
+	for _, target := range targets {
+		if seen[target] {
+			continue
+		}
+		seen[target] = true
+		for _, item := range s.evidence[target] {
+			evidence = append(evidence, item)
+		}
+	}
+	sortEvidence(evidence)
+	return evidence, nil
+}
+
+func (s *MemoryStore) Involving(_ context.Context, entity string) ([]Evidence, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var evidence []Evidence
+	for target, claims := range s.evidence {
+		for _, item := range claims {
+			if target == entity || item.Claim.From == entity {
+				evidence = append(evidence, item)
+			}
+		}
+	}
+	sortEvidence(evidence)
+	return evidence, nil
+}
+
+func (s *MemoryStore) InScope(_ context.Context, source, scope string) ([]Evidence, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var evidence []Evidence
+	for _, claims := range s.evidence {
+		for _, item := range claims {
+			if item.Claim.Source == source && item.Claim.Scope == scope {
+				evidence = append(evidence, item)
+			}
+		}
+	}
+	sortEvidence(evidence)
+	return evidence, nil
+}
+
+func (s *MemoryStore) PutAliases(_ context.Context, aliases []identity.Alias) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, alias := range aliases {
+		s.aliases[alias.Name] = alias
+	}
+	return nil
+}
+
+func (s *MemoryStore) Aliases(_ context.Context) ([]identity.Alias, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	aliases := make([]identity.Alias, 0, len(s.aliases))
+	for _, alias := range s.aliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Name < aliases[j].Name })
+	return aliases, nil
+}
+
+func sortEvidence(evidence []Evidence) {
+	sort.Slice(evidence, func(i, j int) bool {
+		if evidence[i].Target != evidence[j].Target {
+			return evidence[i].Target < evidence[j].Target
+		}
+		return evidence[i].Key() < evidence[j].Key()
+	})
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	common "bacon/src/shared"
+	"bacon/src/shared/graphstore"
+	"bacon/src/shared/relationship-finding/evidence"
+	"bacon/src/shared/relationship-finding/identity"
+	"bacon/src/shared/scraperoutput"
+)
+
+// useEvidenceStore points the handler at the given evidence store for the duration of a test
+func useEvidenceStore(t *testing.T, store evidence.Store) {
+	previous := newEvidenceStore
+	newEvidenceStore = func(context.Context) (evidence.Store, error) { return store, nil }
+	t.Cleanup(func() { newEvidenceStore = previous })
+}
+
+func datadogOwnershipChange(changeType, service, team string) ScraperOutput {
+	change := map[string]interface{}{"type": changeType, "entity_kind": "service", "entity_name": service}
+	if changeType == "owning_team_added" {
+		change["after"] = team
+	} else {
+		change["before"] = team
+	}
+	return ScraperOutput{
+		Source:     "datadog-changes",
+		Confidence: 0.9,
+		Timestamp:  time.Now().Format(time.RFC3339),
+		Data:       map[string]interface{}{"changes": []interface{}{change}},
+	}
+}
+
+// Test the claims received are scored with what the other sources last said about the same resources
+func TestUpdateEvidence_MergesStoredClaims(t *testing.T) {
+	ctx, cleanup := common.TestContext("update-evidence-merge-test")
+	defer cleanup()
+
+	store := evidence.NewMemoryStore()
+	detector := initConflictDetector(loadScoringConfig())
+	if _, _, _, err := updateEvidence(ctx, store, []Relationship{
+		{From: "team-a", To: "payments", Type: "owns", Source: "aws-tags", Confidence: 0.6},
+		{From: "team-a", To: "orders", Type: "owns", Source: "aws-tags", Confidence: 0.6},
+	}, nil, nil, nil, detector, identity.NewResolver()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	relationships, affected, _, err := updateEvidence(ctx, store, []Relationship{
+		{From: "team-b", To: "payments", Type: "owns", Source: "github-codeowners", Confidence: 0.7},
+		{From: "team-a", To: "payments", Type: "owns", Source: "aws-tags", Confidence: 0.9},
+	}, nil, nil, nil, detector, identity.NewResolver())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(affected) != 1 || affected[0] != "payments" {
+		t.Errorf("Expected only payments to be affected, got %v", affected)
+	}
+	if len(relationships) != 2 || relationships[0].Source != "github-codeowners" || relationships[1].Confidence != 0.9 {
+		t.Errorf("Expected the received claims to replace the stored ones, got %+v", relationships)
+	}
+}
+
+// Test deleted entities and retracted relationships take their Datadog evidence with them
+func TestUpdateEvidence_RemovesStaleEvidence(t *testing.T) {
+	ctx, cleanup := common.TestContext("update-evidence-stale-test")
+	defer cleanup()
+
+	store := evidence.NewMemoryStore()
+	detector := initConflictDetector(loadScoringConfig())
+	if _, _, _, err := updateEvidence(ctx, store, []Relationship{
+		{From: "payments", To: "checkout", Type: "owns", Source: "datadog-service-catalog"},
+		{From: "payments", To: "checkout", Type: "owns", Source: "github-codeowners"},
+		{From: "bo@example.com", To: "platform", Type: "member_of", Source: "datadog-teams"},
+		{From: "ana@example.com", To: "platform", Type: "member_of", Source: "datadog-teams"},
+	}, nil, nil, nil, detector, identity.NewResolver()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	relationships, affected, _, err := updateEvidence(ctx, store, nil, []string{"payments"},
+		[]Relationship{{From: "bo@example.com", To: "platform", Type: "member_of"}}, nil, detector, identity.NewResolver())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(affected) != 2 {
+		t.Errorf("Expected the resources losing evidence to be affected, got %v", affected)
+	}
+	if len(relationships) != 2 || relationships[0].Source != "github-codeowners" || relationships[1].From != "ana@example.com" {
+		t.Errorf("Expected only the remaining evidence, got %+v", relationships)
+	}
+}
+
+// Test scrapers publishing on their own still meet in conflict detection, and a retraction settles the conflict
+func TestHandleProcessorRequest_Incremental(t *testing.T) {
+	ctx, cleanup := common.TestContext("handler-incremental-test")
+	defer cleanup()
+
+	store := graphstore.NewMemoryStore()
+	useGraphStore(t, store)
+	useEvidenceStore(t, evidence.NewMemoryStore())
+
+	openshift := ScraperOutput{
+		Source:     "openshift-metadata",
+		Confidence: 0.9,
+		Timestamp:  time.Now().Format(time.RFC3339),
+		Data: map[string]interface{}{
+			"resources": []interface{}{
+				map[string]interface{}{"kind": "Deployment", "name": "checkout", "owner": "team-backend"},
+			},
+		},
+	}
+
+	response, err := handleProcessorRequest(ctx, ProcessorEvent{ScraperOutputs: []ScraperOutput{openshift}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.RelationshipCount != 1 || response.ConflictsOpened != 0 {
+		t.Fatalf("Expected one uncontested relationship, got %+v", response)
+	}
+
+	response, err = handleProcessorRequest(ctx, ProcessorEvent{ScraperOutputs: []ScraperOutput{
+		datadogOwnershipChange("owning_team_added", "Deployment/checkout", "platform"),
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.RelationshipCount != 2 || response.ConflictCount != 1 || response.ConflictsOpened != 1 || response.AffectedResources != 1 {
+		t.Fatalf("Expected the new claim to conflict with the stored one, got %+v", response)
+	}
+	record := conflictRecord(ctx, t, store, graphstore.ConflictID("owns", "Deployment/checkout"))
+	if len(record.Candidates) != 2 {
+		t.Errorf("Expected both sources among the candidates, got %v", record.Candidates)
+	}
+
+	response, err = handleProcessorRequest(ctx, ProcessorEvent{ScraperOutputs: []ScraperOutput{
+		datadogOwnershipChange("owning_team_removed", "Deployment/checkout", "platform"),
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.RelationshipCount != 1 || response.ConflictCount != 0 || response.ConflictsClosed != 1 {
+		t.Errorf("Expected the retraction to settle the conflict, got %+v", response)
+	}
+	edges, _ := store.FindEdges(ctx, graphstore.EdgeFilter{SourcePrefix: datadogSourcePrefix})
+	if len(edges) != 0 {
+		t.Errorf("Expected the retracted edge to be removed, got %+v", edges)
+	}
+}
+
+// Test a relationship stored under one source moves to the edge of a stronger source publishing it later
+func TestHandleProcessorRequest_MergedAcrossRuns(t *testing.T) {
+	ctx, cleanup := common.TestContext("handler-merged-across-runs-test")
+	defer cleanup()
+
+	store := graphstore.NewMemoryStore()
+	useGraphStore(t, store)
+	useEvidenceStore(t, evidence.NewMemoryStore())
+
+	openshift := ScraperOutput{
+		Source:     "openshift-metadata",
+		Confidence: 0.9,
+		Timestamp:  time.Now().Format(time.RFC3339),
+		Data: map[string]interface{}{
+			"resources": []interface{}{
+				map[string]interface{}{"kind": "Deployment", "name": "checkout", "owner": "team-backend"},
+			},
+		},
+	}
+	// The Datadog claim is stored first and outweighed by the OpenShift one in the next run
+	for _, output := range []ScraperOutput{datadogOwnershipChange("owning_team_added", "Deployment/checkout", "team-backend"), openshift} {
+		if _, err := handleProcessorRequest(ctx, ProcessorEvent{ScraperOutputs: []ScraperOutput{output}}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	edges, _ := store.FindEdges(ctx, graphstore.EdgeFilter{Label: "owns"})
+	if len(edges) != 1 || edges[0].Source != "openshift-metadata" {
+		t.Fatalf("Expected the sources to be merged into one edge, got %+v", edges)
+	}
+
+	records, _ := store.FindVertices(ctx, graphstore.VertexFilter{Label: graphstore.LabelArchive})
+	if len(records) != 1 || records[0].Properties[graphstore.ArchiveReasonProperty] != graphstore.ArchiveMerged {
+		t.Errorf("Expected the edge of the weaker source to be archived as merged, got %+v", records)
+	}
+}
+
+func codeownersSnapshot(id, repository string, producedAt time.Time, entries ...scraperoutput.CodeownersEntry) ScraperOutput {
+	payload := scraperoutput.Codeowners(entries)
+	return ScraperOutput{
+		SchemaVersion: scraperoutput.SchemaVersion,
+		Source:        "github-codeowners",
+		Payload:       &payload,
+		Confidence:    0.8,
+		ProducedAt:    producedAt.UTC().Format(time.RFC3339),
+		Snapshot:      &scraperoutput.Snapshot{ID: id, Scope: repository},
+	}
+}
+
+// Test a claim missing from its source's next snapshot is retracted and its edge archived
+func TestHandleProcessorRequest_SnapshotRetraction(t *testing.T) {
+	ctx, cleanup := common.TestContext("handler-snapshot-retraction-test")
+	defer cleanup()
+
+	store := graphstore.NewMemoryStore()
+	useGraphStore(t, store)
+	useEvidenceStore(t, evidence.NewMemoryStore())
+
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	_, err := handleProcessorRequest(ctx, ProcessorEvent{ScraperOutputs: []ScraperOutput{
+		codeownersSnapshot("run-1", "acme/payments", start,
+			scraperoutput.CodeownersEntry{Path: "/src/", Owners: []string{"@team-a"}},
+			scraperoutput.CodeownersEntry{Path: "/docs/", Owners: []string{"@team-b"}},
+		),
+		codeownersSnapshot("run-1", "acme/orders", start,
+			scraperoutput.CodeownersEntry{Path: "/lib/", Owners: []string{"@team-c"}},
+		),
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The next snapshot of one repository no longer lists /docs/
+	response, err := handleProcessorRequest(ctx, ProcessorEvent{ScraperOutputs: []ScraperOutput{
+		codeownersSnapshot("run-2", "acme/payments", start.Add(time.Hour),
+			scraperoutput.CodeownersEntry{Path: "/src/", Owners: []string{"@team-a"}},
+		),
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.RelationshipsArchived != 1 || response.RelationshipCount != 1 {
+		t.Fatalf("Expected the withdrawn claim to be archived, got %+v", response)
+	}
+
+	docs := graphstore.EdgeID("team-b", "owns", "/docs/", "github-codeowners")
+	if _, found, _ := store.GetEdge(ctx, docs); found {
+		t.Errorf("Expected the withdrawn edge to leave the graph")
+	}
+	record, found, _ := store.GetVertex(ctx, graphstore.ArchiveID(docs))
+	if !found || record.Properties[graphstore.ArchiveReasonProperty] != graphstore.ArchiveRetracted || record.Properties[graphstore.ArchiveSnapshotProperty] != "run-2" {
+		t.Errorf("Expected an audit record naming the snapshot, got %+v (found %v)", record, found)
+	}
+	if _, found, _ := store.GetEdge(ctx, graphstore.EdgeID("team-c", "owns", "/lib/", "github-codeowners")); !found {
+		t.Errorf("Expected the claims of another repository to be left alone")
+	}
+
+	// A snapshot older than the evidence cannot retract it
+	response, err = handleProcessorRequest(ctx, ProcessorEvent{ScraperOutputs: []ScraperOutput{
+		codeownersSnapshot("run-0", "acme/payments", start.Add(-time.Hour)),
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.RelationshipsArchived != 0 {
+		t.Errorf("Expected a stale snapshot to retract nothing, got %+v", response)
+	}
+	if _, found, _ := store.GetEdge(ctx, graphstore.EdgeID("team-a", "owns", "/src/", "github-codeowners")); !found {
+		t.Errorf("Expected the newer claim to remain")
+	}
+}
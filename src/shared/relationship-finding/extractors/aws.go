@@ -0,0 +1,41 @@
+package extractors
+
+import (
+	"strings"
+
+	"bacon/src/shared/scraperoutput"
+)
+
+// SourceAWSTags is the source of AWS resources owned through their Owner tag
+// It has no plugin of its own, so the registration ships with the registry
+const SourceAWSTags = "aws-tags"
+
+func init() {
+	MustRegister(Registration{Source: SourceAWSTags, Extractor: ExtractorFunc(extractAWSRelationships), Weight: 0.9, Priority: 1})
+}
+
+func extractAWSRelationships(output scraperoutput.Envelope) []Relationship {
+	var relationships []Relationship
+
+	// AWS resources are keyed by ARN and owned through their Owner tag
+	for _, resource := range output.Payload.Resources {
+		owner := resource.Tags["Owner"]
+		if resource.ARN == "" || owner == "" {
+			continue
+		}
+		rel := NewRelationship(output, owner, resource.ARN, "owns")
+		rel.ResourceType = ARNResourceType(resource.ARN)
+		relationships = append(relationships, rel)
+	}
+
+	return relationships
+}
+
+// ARNResourceType returns the service of an ARN, e.g. rds for arn:aws:rds:eu-west-1:123456789012:db:orders
+func ARNResourceType(arn string) string {
+	parts := strings.SplitN(arn, ":", 4)
+	if len(parts) < 4 || parts[0] != "arn" {
+		return ""
+	}
+	return parts[2]
+}
@@ -0,0 +1,74 @@
+package extractors
+
+import "bacon/src/shared/scraperoutput"
+
+// NewRelationship creates an edge stamped with the source, confidence and production time of its output
+func NewRelationship(output scraperoutput.Envelope, from, to, relType string) Relationship {
+	return Relationship{
+		From:       from,
+		To:         to,
+		Type:       relType,
+		Confidence: output.Confidence,
+		Source:     output.Source,
+		Timestamp:  output.ProducedAt,
+	}
+}
+
+// Edges extracts the pre-typed edges of a relationships payload
+// Edges may carry their own signal confidence (e.g. paging route vs chat handle), which overrides the output's
+var Edges = ExtractorFunc(func(output scraperoutput.Envelope) []Relationship {
+	var relationships []Relationship
+
+	for _, edge := range output.Payload.Relationships {
+		rel := NewRelationship(output, edge.From, edge.To, edge.Type)
+		if edge.Confidence > 0 {
+			rel.Confidence = edge.Confidence
+		}
+		relationships = append(relationships, rel)
+	}
+
+	return relationships
+})
+
+// ChangeEdges extracts the edges added by the membership and ownership changes of a changes payload
+var ChangeEdges = ExtractorFunc(func(output scraperoutput.Envelope) []Relationship {
+	var relationships []Relationship
+
+	for _, change := range ChangeEvents(output) {
+		rel, ok := ChangeToRelationship(change, change.After)
+		if !ok {
+			continue
+		}
+		rel.Confidence = output.Confidence
+		rel.Source = output.Source
+		rel.Timestamp = output.ProducedAt
+		relationships = append(relationships, rel)
+	}
+
+	return relationships
+})
+
+// ChangeToRelationship maps a membership or ownership change to the edge it adds or removes
+// The counterpart is the "after" value for additions and the "before" value for removals
+func ChangeToRelationship(change scraperoutput.ChangeEvent, counterpart string) (Relationship, bool) {
+	entity := change.EntityName
+	if entity == "" || counterpart == "" {
+		return Relationship{}, false
+	}
+
+	switch change.Type {
+	case "member_joined", "member_left":
+		return Relationship{From: counterpart, To: entity, Type: "member_of"}, true
+	case "owning_team_added", "owning_team_removed":
+		return Relationship{From: counterpart, To: entity, Type: "owns"}, true
+	}
+	return Relationship{}, false
+}
+
+// ChangeEvents returns the change events of an output, if it carries a changes payload
+func ChangeEvents(output scraperoutput.Envelope) []scraperoutput.ChangeEvent {
+	if output.Payload.Changes == nil {
+		return nil
+	}
+	return output.Payload.Changes.Events
+}
@@ -0,0 +1,25 @@
+package extractors
+
+// Explanation breaks the confidence of a relationship down into the factors it was scored from
+// The top-level factors are those of the source whose claim was kept; Sources lists every source that
+// made the same claim, the kept one included, so reviewers can see what the agreement bonus rests on
+// AgreementBonus is whatever the scoring strategy added to the kept claim for the agreement of the others
+type Explanation struct {
+	Confidence          float64        `json:"confidence"`
+	Strategy            string         `json:"strategy,omitempty"`
+	BaseConfidence      float64        `json:"base_confidence"`
+	SourceWeight        float64        `json:"source_weight"`
+	FreshnessMultiplier float64        `json:"freshness_multiplier"`
+	AgreementBonus      float64        `json:"agreement_bonus"`
+	Sources             []Contribution `json:"sources"`
+}
+
+// Contribution is the score one source's claim earned on its own
+type Contribution struct {
+	Source              string  `json:"source"`
+	Timestamp           string  `json:"timestamp"`
+	BaseConfidence      float64 `json:"base_confidence"`
+	SourceWeight        float64 `json:"source_weight"`
+	FreshnessMultiplier float64 `json:"freshness_multiplier"`
+	Confidence          float64 `json:"confidence"`
+}
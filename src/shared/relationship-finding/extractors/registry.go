@@ -0,0 +1,170 @@
+// Package extractors holds the registry through which plugins teach the relationship processor their sources.
+// Each plugin registers a source name, the extractor that turns its envelope into relationships, and the
+// default weight and conflict priority of the source.
+package extractors
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"bacon/src/shared/relationship-finding/identity"
+	"bacon/src/shared/scraperoutput"
+)
+
+// Relationship is an ownership or dependency edge extracted from a scraper output
+type Relationship struct {
+	From        string  `json:"from"`
+	To          string  `json:"to"`
+	Type        string  `json:"type"`
+	Confidence  float64 `json:"confidence"`
+	Source      string  `json:"source"`
+	HasConflict bool    `json:"has_conflict"`
+	Timestamp   string  `json:"timestamp"`
+
+	// ResourceType classifies To for per-type scoring overrides, e.g. rds for an RDS ARN, deployment for an
+	// OpenShift Deployment or repository for a CODEOWNERS path; empty when the extractor cannot tell
+	ResourceType string `json:"resource_type,omitempty"`
+
+	// Scope of the snapshot the claim came from, empty for outputs without one; the next snapshot of the source
+	// over the same scope retracts the claim unless it still contains it
+	Scope string `json:"scope,omitempty"`
+
+	// Provenance of the canonical identities From and To were resolved to, if any
+	FromIdentity *identity.Match `json:"from_identity,omitempty"`
+	ToIdentity   *identity.Match `json:"to_identity,omitempty"`
+
+	// Explanation of Confidence and the version of the scoring config behind it, set once the relationship is scored
+	Explanation          *Explanation `json:"explanation,omitempty"`
+	ScoringConfigVersion string       `json:"scoring_config_version,omitempty"`
+
+	// IDs of the relationships this one conflicts with and of the conflict's review record, and whether it
+	// lost the conflict; superseded relationships are kept so reviewers can see and overturn the resolution
+	ConflictsWith []string `json:"conflicts_with,omitempty"`
+	ConflictID    string   `json:"conflict_id,omitempty"`
+	Superseded    bool     `json:"superseded,omitempty"`
+}
+
+// ResourceTypeRepository is the resource type of CODEOWNERS paths, which live in repositories
+const ResourceTypeRepository = "repository"
+
+// Extractor turns the envelope of a registered source into relationships
+type Extractor interface {
+	Extract(output scraperoutput.Envelope) []Relationship
+}
+
+// ExtractorFunc adapts a function to the Extractor interface
+type ExtractorFunc func(output scraperoutput.Envelope) []Relationship
+
+// Extract calls f(output)
+func (f ExtractorFunc) Extract(output scraperoutput.Envelope) []Relationship {
+	return f(output)
+}
+
+// Registration binds a source to its extractor and default scoring
+type Registration struct {
+	Source    string
+	Extractor Extractor // nil for sources that only carry deletions or retractions
+	Weight    float64   // default confidence weight of the source
+	Priority  int       // default conflict priority; lower wins
+}
+
+// Extract runs the registered extractor; sources without one yield no relationships
+func (r Registration) Extract(output scraperoutput.Envelope) []Relationship {
+	if r.Extractor == nil {
+		return nil
+	}
+	return r.Extractor.Extract(output)
+}
+
+// Registry maps source names to their registrations
+type Registry struct {
+	mu            sync.RWMutex
+	registrations map[string]Registration
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{registrations: make(map[string]Registration)}
+}
+
+// Default is the registry plugins register into from their init functions
+var Default = NewRegistry()
+
+// Register adds a source; registering a source twice is an error
+func (r *Registry) Register(registration Registration) error {
+	if registration.Source == "" {
+		return fmt.Errorf("extractor registration has no source")
+	}
+	if registration.Weight < 0 || registration.Weight > 1 {
+		return fmt.Errorf("source %s: weight %v is outside [0, 1]", registration.Source, registration.Weight)
+	}
+	if registration.Priority <= 0 {
+		return fmt.Errorf("source %s: priority must be positive, got %d", registration.Source, registration.Priority)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.registrations[registration.Source]; exists {
+		return fmt.Errorf("source %s is already registered", registration.Source)
+	}
+	r.registrations[registration.Source] = registration
+	return nil
+}
+
+// MustRegister adds sources to the Default registry and panics on invalid or duplicate registrations
+// Intended for plugin init functions, where a bad registration is a programming error
+func MustRegister(registrations ...Registration) {
+	for _, registration := range registrations {
+		if err := Default.Register(registration); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Lookup returns the registration of a source
+func (r *Registry) Lookup(source string) (Registration, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	registration, found := r.registrations[source]
+	return registration, found
+}
+
+// Sources returns the registered source names in sorted order
+func (r *Registry) Sources() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sources := make([]string, 0, len(r.registrations))
+	for source := range r.registrations {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+// Weights returns the default weight of every registered source
+func (r *Registry) Weights() map[string]float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	weights := make(map[string]float64, len(r.registrations))
+	for source, registration := range r.registrations {
+		weights[source] = registration.Weight
+	}
+	return weights
+}
+
+// Priorities returns the default conflict priority of every registered source
+func (r *Registry) Priorities() map[string]int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	priorities := make(map[string]int, len(r.registrations))
+	for source, registration := range r.registrations {
+		priorities[source] = registration.Priority
+	}
+	return priorities
+}
@@ -0,0 +1,105 @@
+package extractors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bacon/src/shared/scraperoutput"
+)
+
+func TestRegistry_Register(t *testing.T) {
+	registry := NewRegistry()
+
+	require.NoError(t, registry.Register(Registration{Source: "jira-components", Extractor: Edges, Weight: 0.6, Priority: 3}))
+	require.NoError(t, registry.Register(Registration{Source: "backstage-catalog", Weight: 0.7, Priority: 2}))
+
+	assert.Equal(t, []string{"backstage-catalog", "jira-components"}, registry.Sources())
+	assert.Equal(t, map[string]float64{"backstage-catalog": 0.7, "jira-components": 0.6}, registry.Weights())
+	assert.Equal(t, map[string]int{"backstage-catalog": 2, "jira-components": 3}, registry.Priorities())
+
+	registration, found := registry.Lookup("jira-components")
+	assert.True(t, found)
+	assert.Equal(t, 0.6, registration.Weight)
+
+	_, found = registry.Lookup("unknown")
+	assert.False(t, found)
+}
+
+func TestRegistry_RegisterRejectsInvalid(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(Registration{Source: "jira-components", Weight: 0.6, Priority: 3}))
+
+	testCases := []struct {
+		name         string
+		registration Registration
+		message      string
+	}{
+		{"duplicate source", Registration{Source: "jira-components", Weight: 0.5, Priority: 1}, "already registered"},
+		{"missing source", Registration{Weight: 0.5, Priority: 1}, "no source"},
+		{"weight out of range", Registration{Source: "backstage-catalog", Weight: 1.5, Priority: 1}, "outside [0, 1]"},
+		{"non-positive priority", Registration{Source: "backstage-catalog", Weight: 0.5}, "priority must be positive"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := registry.Register(tc.registration)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.message)
+		})
+	}
+	assert.Equal(t, []string{"jira-components"}, registry.Sources())
+}
+
+func TestRegistration_Extract(t *testing.T) {
+	output := scraperoutput.New("datadog-apm", 0.7, time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC), scraperoutput.Relationships([]scraperoutput.Edge{
+		{From: "checkout", To: "billing", Type: "depends_on"},
+		{From: "pagerduty:checkout", To: "checkout", Type: "monitors", Confidence: 0.85},
+	}))
+
+	assert.Equal(t, []Relationship{
+		{From: "checkout", To: "billing", Type: "depends_on", Confidence: 0.7, Source: "datadog-apm", Timestamp: "2024-05-01T09:30:00Z"},
+		{From: "pagerduty:checkout", To: "checkout", Type: "monitors", Confidence: 0.85, Source: "datadog-apm", Timestamp: "2024-05-01T09:30:00Z"},
+	}, Registration{Source: "datadog-apm", Extractor: Edges}.Extract(output))
+
+	assert.Nil(t, Registration{Source: "datadog-deletions"}.Extract(output))
+}
+
+func TestChangeEdges(t *testing.T) {
+	output := scraperoutput.New("datadog-changes", 0.8, time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC), scraperoutput.Changes(scraperoutput.ChangeSet{
+		Events: []scraperoutput.ChangeEvent{
+			{Type: "member_joined", EntityName: "payments", After: "ana@example.com"},
+			{Type: "owning_team_removed", EntityName: "checkout", Before: "payments"},
+			{Type: "entity_removed", EntityName: "billing"},
+		},
+	}))
+
+	assert.Equal(t, []Relationship{
+		{From: "ana@example.com", To: "payments", Type: "member_of", Confidence: 0.8, Source: "datadog-changes", Timestamp: "2024-05-01T09:30:00Z"},
+	}, ChangeEdges.Extract(output))
+
+	retracted, ok := ChangeToRelationship(ChangeEvents(output)[1], "payments")
+	assert.True(t, ok)
+	assert.Equal(t, Relationship{From: "payments", To: "checkout", Type: "owns"}, retracted)
+}
+
+func TestARNResourceType(t *testing.T) {
+	assert.Equal(t, "rds", ARNResourceType("arn:aws:rds:eu-west-1:123456789012:db:orders"))
+	assert.Equal(t, "s3", ARNResourceType("arn:aws:s3:::my-bucket"))
+	assert.Equal(t, "", ARNResourceType("Deployment/web-app"))
+}
+
+func TestExtractAWSRelationships_ResourceType(t *testing.T) {
+	output := scraperoutput.New(SourceAWSTags, 0.9, time.Now(), scraperoutput.Payload{
+		Kind:      scraperoutput.KindResources,
+		Resources: []scraperoutput.Resource{{ARN: "arn:aws:rds:eu-west-1:123456789012:db:orders", Tags: map[string]string{"Owner": "team-data"}}},
+	})
+
+	relationships := extractAWSRelationships(output)
+
+	require.Len(t, relationships, 1)
+	assert.Equal(t, "rds", relationships[0].ResourceType)
+}
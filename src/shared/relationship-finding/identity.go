@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/aws/aws-xray-sdk-go/v2/xray"
+
+	"bacon/src/shared/relationship-finding/evidence"
+	"bacon/src/shared/relationship-finding/identity"
+	"bacon/src/shared/scraperoutput"
+)
+
+// aliasTableSource names the alias table configured through IDENTITY_ALIAS_TABLE in match provenance
+const aliasTableSource = "alias-table"
+
+// newIdentityResolver builds a resolver from the configured alias table, the identity directories among outputs
+// and the aliases earlier runs resolved with, in that order of precedence
+// Conflicting aliases are logged and the first mapping kept, so one bad entry does not fail the batch
+func newIdentityResolver(outputs []scraperoutput.Envelope, known []identity.Alias) *identity.Resolver {
+	resolver := identity.NewResolver()
+
+	if table := os.Getenv("IDENTITY_ALIAS_TABLE"); table != "" {
+		if err := resolver.LoadAliasTable([]byte(table), aliasTableSource); err != nil {
+			log.Printf("Identity alias table: %v", err)
+		}
+	}
+	if err := resolver.LoadIdentities(outputs); err != nil {
+		log.Printf("Identity directories: %v", err)
+	}
+	if err := resolver.LoadAliases(known); err != nil {
+		log.Printf("Stored identity aliases: %v", err)
+	}
+
+	return resolver
+}
+
+// rememberAliases records the aliases a run resolved with that earlier runs did not, so sources publishing on
+// their own resolve alike; the alias table is configuration and read afresh by every run
+func rememberAliases(ctx context.Context, store evidence.Store, resolver *identity.Resolver, known []identity.Alias) error {
+	stored := make(map[identity.Alias]bool, len(known))
+	for _, alias := range known {
+		stored[alias] = true
+	}
+
+	var learned []identity.Alias
+	for _, alias := range resolver.Aliases() {
+		if alias.Source != aliasTableSource && !stored[alias] {
+			learned = append(learned, alias)
+		}
+	}
+	return store.PutAliases(ctx, learned)
+}
+
+// identityEndpoints reports which endpoints of a relationship type name a team or person
+func identityEndpoints(relType string) (from, to bool) {
+	switch relType {
+	case "owns", "on_call_for", "maintains", "manages":
+		return true, false
+	case "member_of":
+		return true, true
+	}
+	return false, false
+}
+
+// resolveIdentities rewrites the team and person endpoints of relationships to canonical identities, keeping the
+// provenance of every match on the relationship, and returns the names no rule resolved
+func resolveIdentities(ctx context.Context, relationships []Relationship, resolver *identity.Resolver) ([]Relationship, []string) {
+	_, seg := xray.BeginSubsegment(ctx, "resolve-identities")
+	defer seg.Close(nil)
+
+	// Names that spell out a team teach the resolver its bare handle and mailbox first
+	var names []string
+	for _, rel := range relationships {
+		from, to := identityEndpoints(rel.Type)
+		if from {
+			names = append(names, rel.From)
+		}
+		if to {
+			names = append(names, rel.To)
+		}
+	}
+	resolver.Learn(names)
+
+	var unresolved []string
+	seen := make(map[string]bool)
+	resolved := make([]Relationship, 0, len(relationships))
+	matched := 0
+
+	for _, rel := range relationships {
+		rel = resolveRelationship(rel, resolver)
+		for _, match := range []*identity.Match{rel.FromIdentity, rel.ToIdentity} {
+			if match == nil {
+				continue
+			}
+			if match.Resolved() {
+				matched++
+			} else if !seen[match.Raw] {
+				seen[match.Raw] = true
+				unresolved = append(unresolved, match.Raw)
+			}
+		}
+		resolved = append(resolved, rel)
+	}
+
+	_ = seg.AddAnnotation("identities_resolved", matched)
+	_ = seg.AddAnnotation("identities_unresolved", len(unresolved))
+	return resolved, unresolved
+}
+
+// resolveRelationship resolves the team and person endpoints of one relationship
+func resolveRelationship(rel Relationship, resolver *identity.Resolver) Relationship {
+	from, to := identityEndpoints(rel.Type)
+	if from {
+		canonical, match := resolver.Resolve(rel.From)
+		rel.From, rel.FromIdentity = canonical, &match
+	}
+	if to {
+		canonical, match := resolver.Resolve(rel.To)
+		rel.To, rel.ToIdentity = canonical, &match
+	}
+	return rel
+}
+
+// reresolveRelationship resolves a stored claim again from the names it was received with, so it follows the
+// aliases learned since; claims recorded without provenance are kept as they are
+func reresolveRelationship(rel Relationship, resolver *identity.Resolver) Relationship {
+	if rel.FromIdentity != nil {
+		canonical, match := resolver.Resolve(rel.FromIdentity.Raw)
+		rel.From, rel.FromIdentity = canonical, &match
+	}
+	if rel.ToIdentity != nil {
+		canonical, match := resolver.Resolve(rel.ToIdentity.Raw)
+		rel.To, rel.ToIdentity = canonical, &match
+	}
+	return rel
+}
+
+// resolveDeletedEntities adds the canonical identity of every deleted entity a directory or alias table knows,
+// so edges stored under either name are removed
+func resolveDeletedEntities(entities []string, resolver *identity.Resolver) []string {
+	resolved := append([]string(nil), entities...)
+	for _, entity := range entities {
+		if canonical, match := resolver.Resolve(entity); match.Rule == identity.RuleAlias && canonical != entity {
+			resolved = append(resolved, canonical)
+		}
+	}
+	return resolved
+}
@@ -0,0 +1,278 @@
+// Package identity unifies the names sources give the same team or person.
+// GitHub, Datadog, OpenShift and AWS each spell owners their own way (backend-team, @org/backend, backend,
+// backend@company.com); the resolver maps every spelling onto one canonical Team or Person ID and records
+// which rule, and which alias table or directory, made each match.
+package identity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"bacon/src/shared/scraperoutput"
+)
+
+// Canonical identity kinds
+const (
+	KindTeam   = scraperoutput.IdentityTeam
+	KindPerson = scraperoutput.IdentityPerson
+)
+
+// Rules a name can be resolved by, in the order they are tried
+const (
+	RuleAlias        = "alias"         // listed in an alias table or an identity directory
+	RuleEmailMailbox = "email_mailbox" // the mailbox of an email is a known team, e.g. backend@company.com
+	RuleEmail        = "email"         // any other email is a person
+	RuleGitHubTeam   = "github_team"   // an org/slug GitHub team reference
+	RuleTeamSuffix   = "team_suffix"   // a name spelled with a team suffix, e.g. backend-team
+	RuleUnresolved   = "unresolved"    // kept as given
+)
+
+// SourceInferred is the alias source of teams learned from the names of a batch rather than from a directory
+const SourceInferred = "inferred"
+
+// teamSuffixes are stripped from team names, so backend-team and backend are the same team
+var teamSuffixes = []string{"-team", "_team", " team"}
+
+// Match records how a raw name was resolved
+type Match struct {
+	Raw       string `json:"raw"`
+	Canonical string `json:"canonical"`
+	Rule      string `json:"rule"`
+	Source    string `json:"source,omitempty"` // alias table or directory source that supplied the alias
+}
+
+// Resolved reports whether the name was mapped onto a canonical identity
+func (m Match) Resolved() bool {
+	return m.Rule != RuleUnresolved
+}
+
+// ID returns the canonical ID of a team or person, e.g. team:backend or person:ana@example.com
+// Team keys drop their team suffix; person keys are emails when known and logins otherwise
+func ID(kind, key string) string {
+	key = Normalize(key)
+	if kind == KindTeam {
+		key = trimTeamSuffix(key)
+	}
+	return kind + ":" + key
+}
+
+// Normalize is the spelling-insensitive form of a name: trimmed, lower case and without a leading @
+func Normalize(name string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "@"))
+}
+
+// Alias is a name mapped onto a canonical identity by an alias table, a directory or what a batch implied
+type Alias struct {
+	Name      string `json:"name"`
+	Canonical string `json:"canonical"`
+	Source    string `json:"source"`
+}
+
+type alias struct {
+	canonical string
+	source    string
+}
+
+// Resolver maps names onto canonical identities through alias tables, identity directories and
+// normalization rules
+type Resolver struct {
+	mu      sync.RWMutex
+	aliases map[string]alias
+}
+
+// NewResolver creates a resolver without aliases; it resolves through the normalization rules only
+func NewResolver() *Resolver {
+	return &Resolver{aliases: make(map[string]alias)}
+}
+
+// AddAlias maps a name onto a canonical ID on behalf of source
+// The first mapping of a name wins; a conflicting later one is rejected with an error
+func (r *Resolver) AddAlias(name, canonical, source string) error {
+	if !isCanonical(canonical) {
+		return fmt.Errorf("alias %q: %q is not a canonical team or person ID", name, canonical)
+	}
+	key := Normalize(name)
+	if key == "" {
+		return fmt.Errorf("alias of %s: empty name", canonical)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, found := r.aliases[key]; found {
+		if existing.canonical != canonical {
+			return fmt.Errorf("alias %q of %s conflicts with %s from %s", name, canonical, existing.canonical, existing.source)
+		}
+		return nil
+	}
+	r.aliases[key] = alias{canonical: canonical, source: source}
+	return nil
+}
+
+// AddIdentity maps the key, email and aliases of a directory identity onto its canonical ID
+func (r *Resolver) AddIdentity(source string, identity scraperoutput.Identity) error {
+	canonical := ID(identity.Kind, identity.Key)
+	if identity.Kind == KindPerson && identity.Email != "" {
+		canonical = ID(KindPerson, identity.Email)
+	}
+
+	var errs []error
+	for _, name := range append([]string{identity.Key, identity.Email}, identity.Aliases...) {
+		if name == "" {
+			continue
+		}
+		if err := r.AddAlias(name, canonical, source); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LoadIdentities adds the identities of every identities payload among outputs
+func (r *Resolver) LoadIdentities(outputs []scraperoutput.Envelope) error {
+	var errs []error
+	for _, output := range outputs {
+		if output.Payload.Kind != scraperoutput.KindIdentities {
+			continue
+		}
+		for _, identity := range output.Payload.Identities {
+			if err := r.AddIdentity(output.Source, identity); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LoadAliasTable adds a JSON alias table mapping canonical IDs onto the names they go by, e.g.
+// {"team:backend": ["platform-backend", "be-oncall@company.com"]}
+func (r *Resolver) LoadAliasTable(data []byte, source string) error {
+	var table map[string][]string
+	if err := json.Unmarshal(data, &table); err != nil {
+		return fmt.Errorf("invalid alias table: %w", err)
+	}
+
+	var errs []error
+	for canonical, names := range table {
+		for _, name := range names {
+			if err := r.AddAlias(name, canonical, source); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LoadAliases adds aliases another resolver knew, e.g. those kept between runs
+func (r *Resolver) LoadAliases(aliases []Alias) error {
+	var errs []error
+	for _, known := range aliases {
+		if err := r.AddAlias(known.Name, known.Canonical, known.Source); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Aliases returns every alias of the resolver by normalized name, ordered by name
+func (r *Resolver) Aliases() []Alias {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	aliases := make([]Alias, 0, len(r.aliases))
+	for name, known := range r.aliases {
+		aliases = append(aliases, Alias{Name: name, Canonical: known.canonical, Source: known.source})
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Name < aliases[j].Name })
+	return aliases
+}
+
+// Learn registers the teams that names unambiguously refer to, e.g. backend-team or @org/backend, so the bare
+// handle and the team mailbox of the same team resolve to it as well
+// Names already covered by an alias table or directory are left alone
+func (r *Resolver) Learn(names []string) {
+	for _, name := range names {
+		match := r.resolve(name)
+		if match.Rule != RuleGitHubTeam && match.Rule != RuleTeamSuffix {
+			continue
+		}
+		// Already known or conflicting handles keep their existing mapping
+		_ = r.AddAlias(strings.TrimPrefix(match.Canonical, KindTeam+":"), match.Canonical, SourceInferred)
+	}
+}
+
+// Resolve maps a name onto its canonical identity; names no rule applies to are returned unchanged
+func (r *Resolver) Resolve(name string) (string, Match) {
+	match := r.resolve(name)
+	return match.Canonical, match
+}
+
+func (r *Resolver) resolve(name string) Match {
+	key := Normalize(name)
+	unresolved := Match{Raw: name, Canonical: name, Rule: RuleUnresolved}
+	if key == "" {
+		return unresolved
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if known, found := r.aliases[key]; found {
+		return Match{Raw: name, Canonical: known.canonical, Rule: RuleAlias, Source: known.source}
+	}
+
+	if mailbox, _, isEmail := strings.Cut(key, "@"); isEmail {
+		if team, found := r.knownTeam(mailbox); found {
+			return Match{Raw: name, Canonical: team.canonical, Rule: RuleEmailMailbox, Source: team.source}
+		}
+		return Match{Raw: name, Canonical: ID(KindPerson, key), Rule: RuleEmail}
+	}
+
+	if i := strings.LastIndex(key, "/"); i >= 0 {
+		slug := key[i+1:]
+		if slug == "" {
+			return unresolved
+		}
+		if team, found := r.knownTeam(slug); found {
+			return Match{Raw: name, Canonical: team.canonical, Rule: RuleGitHubTeam, Source: team.source}
+		}
+		return Match{Raw: name, Canonical: ID(KindTeam, slug), Rule: RuleGitHubTeam}
+	}
+
+	if trimmed := trimTeamSuffix(key); trimmed != key {
+		if team, found := r.knownTeam(trimmed); found {
+			return Match{Raw: name, Canonical: team.canonical, Rule: RuleTeamSuffix, Source: team.source}
+		}
+		return Match{Raw: name, Canonical: ID(KindTeam, trimmed), Rule: RuleTeamSuffix}
+	}
+
+	return unresolved
+}
+
+// knownTeam looks a team name up with and without its team suffix; the caller holds the read lock
+func (r *Resolver) knownTeam(name string) (alias, bool) {
+	for _, key := range []string{name, trimTeamSuffix(name)} {
+		if known, found := r.aliases[key]; found && strings.HasPrefix(known.canonical, KindTeam+":") {
+			return known, true
+		}
+	}
+	return alias{}, false
+}
+
+func trimTeamSuffix(name string) string {
+	for _, suffix := range teamSuffixes {
+		if trimmed := strings.TrimSuffix(name, suffix); trimmed != name && trimmed != "" {
+			return trimmed
+		}
+	}
+	return name
+}
+
+func isCanonical(id string) bool {
+	kind, key, found := strings.Cut(id, ":")
+	return found && key != "" && (kind == KindTeam || kind == KindPerson)
+}
@@ -0,0 +1,155 @@
+package identity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bacon/src/shared/scraperoutput"
+)
+
+func TestID(t *testing.T) {
+	assert.Equal(t, "team:backend", ID(KindTeam, "@Backend-Team "))
+	assert.Equal(t, "person:ana@example.com", ID(KindPerson, "Ana@Example.com"))
+}
+
+func TestResolve_NormalizationRules(t *testing.T) {
+	resolver := NewResolver()
+
+	tests := []struct {
+		raw       string
+		canonical string
+		rule      string
+	}{
+		{"backend-team", "team:backend", RuleTeamSuffix},
+		{"Backend_Team", "team:backend", RuleTeamSuffix},
+		{"@org/backend", "team:backend", RuleGitHubTeam},
+		{"org/backend-team", "team:backend", RuleGitHubTeam},
+		{"Ana@Example.com", "person:ana@example.com", RuleEmail},
+		{"backend", "backend", RuleUnresolved},
+		{"org/", "org/", RuleUnresolved},
+		{"", "", RuleUnresolved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			canonical, match := resolver.Resolve(tt.raw)
+			assert.Equal(t, tt.canonical, canonical)
+			assert.Equal(t, Match{Raw: tt.raw, Canonical: tt.canonical, Rule: tt.rule}, match)
+		})
+	}
+}
+
+func TestResolve_UnifiesTeamSpellings(t *testing.T) {
+	resolver := NewResolver()
+	require.NoError(t, resolver.AddIdentity("datadog-teams", scraperoutput.Identity{Kind: KindTeam, Key: "backend", Aliases: []string{"Backend Engineering"}}))
+
+	for _, raw := range []string{"backend", "backend-team", "@org/backend", "backend@company.com", "Backend Engineering"} {
+		canonical, match := resolver.Resolve(raw)
+		assert.Equal(t, "team:backend", canonical, raw)
+		assert.Equal(t, "datadog-teams", match.Source, raw)
+		assert.True(t, match.Resolved(), raw)
+	}
+
+	_, match := resolver.Resolve("backend@company.com")
+	assert.Equal(t, RuleEmailMailbox, match.Rule)
+}
+
+func TestLearn(t *testing.T) {
+	resolver := NewResolver()
+	require.NoError(t, resolver.AddAlias("infra", "team:platform", "alias-table"))
+
+	resolver.Learn([]string{"backend-team", "@org/infra", "checkout", "ana@example.com"})
+
+	canonical, match := resolver.Resolve("backend")
+	assert.Equal(t, "team:backend", canonical)
+	assert.Equal(t, Match{Raw: "backend", Canonical: "team:backend", Rule: RuleAlias, Source: SourceInferred}, match)
+
+	canonical, match = resolver.Resolve("backend@company.com")
+	assert.Equal(t, "team:backend", canonical)
+	assert.Equal(t, RuleEmailMailbox, match.Rule)
+
+	// Directory mappings are not overridden by what a batch implies
+	canonical, _ = resolver.Resolve("infra")
+	assert.Equal(t, "team:platform", canonical)
+
+	canonical, _ = resolver.Resolve("checkout")
+	assert.Equal(t, "checkout", canonical)
+}
+
+func TestAliases_RoundTrip(t *testing.T) {
+	resolver := NewResolver()
+	require.NoError(t, resolver.AddAlias("Platform", "team:infra", "alias-table"))
+	resolver.Learn([]string{"backend-team"})
+
+	aliases := resolver.Aliases()
+	assert.Equal(t, []Alias{
+		{Name: "backend", Canonical: "team:backend", Source: SourceInferred},
+		{Name: "platform", Canonical: "team:infra", Source: "alias-table"},
+	}, aliases)
+
+	// A later resolver knows what an earlier one learned
+	later := NewResolver()
+	require.NoError(t, later.LoadAliases(aliases))
+	_, match := later.Resolve("backend")
+	assert.Equal(t, Match{Raw: "backend", Canonical: "team:backend", Rule: RuleAlias, Source: SourceInferred}, match)
+
+	assert.Error(t, later.LoadAliases([]Alias{{Name: "backend", Canonical: "team:api", Source: SourceInferred}}))
+}
+
+func TestAddIdentity_PersonKeyedByEmail(t *testing.T) {
+	resolver := NewResolver()
+	require.NoError(t, resolver.AddIdentity("github-members", scraperoutput.Identity{Kind: KindPerson, Key: "ana-gh", Email: "ana@example.com"}))
+
+	canonical, match := resolver.Resolve("@ana-gh")
+	assert.Equal(t, "person:ana@example.com", canonical)
+	assert.Equal(t, Match{Raw: "@ana-gh", Canonical: "person:ana@example.com", Rule: RuleAlias, Source: "github-members"}, match)
+}
+
+func TestAddAlias_FirstMappingWins(t *testing.T) {
+	resolver := NewResolver()
+	require.NoError(t, resolver.AddAlias("platform", "team:infra", "aliases"))
+	require.NoError(t, resolver.AddAlias("Platform", "team:infra", "datadog-teams"))
+
+	err := resolver.AddAlias("platform", "team:sre", "github-members")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "team:infra")
+
+	canonical, match := resolver.Resolve("platform")
+	assert.Equal(t, "team:infra", canonical)
+	assert.Equal(t, "aliases", match.Source)
+
+	assert.Error(t, resolver.AddAlias("platform", "infra", "aliases"))
+	assert.Error(t, resolver.AddAlias(" ", "team:infra", "aliases"))
+}
+
+func TestLoadAliasTable(t *testing.T) {
+	resolver := NewResolver()
+	require.NoError(t, resolver.LoadAliasTable([]byte(`{"team:backend": ["platform-backend", "be-oncall@company.com"]}`), "alias-table"))
+
+	canonical, match := resolver.Resolve("be-oncall@company.com")
+	assert.Equal(t, "team:backend", canonical)
+	assert.Equal(t, RuleAlias, match.Rule)
+	assert.Equal(t, "alias-table", match.Source)
+
+	assert.Error(t, resolver.LoadAliasTable([]byte(`not json`), "alias-table"))
+	assert.Error(t, resolver.LoadAliasTable([]byte(`{"backend": ["be"]}`), "alias-table"))
+}
+
+func TestLoadIdentities(t *testing.T) {
+	resolver := NewResolver()
+	outputs := []scraperoutput.Envelope{
+		scraperoutput.New("datadog-users", 1.0, time.Now(), scraperoutput.Identities([]scraperoutput.Identity{
+			{Kind: KindPerson, Key: "ana@example.com", Email: "ana@example.com", Aliases: []string{"ana"}},
+		})),
+		scraperoutput.New("datadog-service-catalog", 0.9, time.Now(), scraperoutput.Relationships(nil)),
+	}
+
+	require.NoError(t, resolver.LoadIdentities(outputs))
+
+	canonical, match := resolver.Resolve("ana")
+	assert.Equal(t, "person:ana@example.com", canonical)
+	assert.Equal(t, "datadog-users", match.Source)
+}
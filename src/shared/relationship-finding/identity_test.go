@@ -0,0 +1,204 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	common "bacon/src/shared"
+	"bacon/src/shared/graphstore"
+	"bacon/src/shared/relationship-finding/evidence"
+	"bacon/src/shared/relationship-finding/identity"
+	"bacon/src/shared/scraperoutput"
+)
+
+// Test the owner spellings of GitHub, OpenShift, Datadog and AWS are scored as one agreeing owner
+func TestResolveIdentities_UnifiesOwnerSpellings(t *testing.T) {
+	ctx, cleanup := common.TestContext("resolve-identities-test")
+	defer cleanup()
+
+	now := time.Now().Format(time.RFC3339)
+	relationships := []Relationship{
+		{From: "@org/backend", To: "checkout", Type: "owns", Source: "github-codeowners", Confidence: 0.8, Timestamp: now},
+		{From: "backend-team", To: "checkout", Type: "owns", Source: "openshift-metadata", Confidence: 0.9, Timestamp: now},
+		{From: "backend", To: "checkout", Type: "owns", Source: "datadog-service-catalog", Confidence: 0.9, Timestamp: now},
+		{From: "backend@company.com", To: "checkout", Type: "owns", Source: "aws-tags", Confidence: 0.9, Timestamp: now},
+		{From: "checkout", To: "billing", Type: "depends_on", Source: "datadog-apm", Confidence: 0.8, Timestamp: now},
+	}
+
+	resolved, unresolved := resolveIdentities(ctx, relationships, identity.NewResolver())
+
+	if len(unresolved) != 0 {
+		t.Errorf("Expected every owner to resolve, got unresolved %v", unresolved)
+	}
+	for _, rel := range resolved[:4] {
+		if rel.From != "team:backend" {
+			t.Errorf("Expected %s owner to resolve to team:backend, got %q", rel.Source, rel.From)
+		}
+		if rel.FromIdentity == nil || rel.FromIdentity.Canonical != rel.From {
+			t.Errorf("Expected %s owner to keep its match provenance, got %+v", rel.Source, rel.FromIdentity)
+		}
+	}
+	if dependency := resolved[4]; dependency.From != "checkout" || dependency.FromIdentity != nil {
+		t.Errorf("Expected dependencies to be left alone, got %+v", dependency)
+	}
+
+	scored := applyConfidenceScoring(ctx, resolved[:4], initConfidenceEngine(loadScoringConfig()))
+	if len(scored) != 1 {
+		t.Fatalf("Expected the four claims to merge into one owner, got %d", len(scored))
+	}
+	if single := calculateSingleSourceConfidence(resolved[1], initConfidenceEngine(loadScoringConfig())); scored[0].Confidence <= single {
+		t.Errorf("Expected the agreement bonus on %f, got %f", single, scored[0].Confidence)
+	}
+
+	resolved, conflictCount := detectAndResolveConflicts(ctx, scored, initConflictDetector(loadScoringConfig()))
+	if conflictCount != 0 {
+		t.Errorf("Expected no ownership conflict between spellings of one team, got %+v", resolved)
+	}
+}
+
+// Test directory identities from Datadog map handles, names and emails onto canonical people and teams
+func TestResolveIdentities_Directories(t *testing.T) {
+	ctx, cleanup := common.TestContext("resolve-identities-directories-test")
+	defer cleanup()
+
+	resolver := newIdentityResolver([]scraperoutput.Envelope{
+		scraperoutput.New("datadog-users", 1.0, time.Now(), scraperoutput.Identities([]scraperoutput.Identity{
+			{Kind: identity.KindPerson, Key: "ana@example.com", Email: "ana@example.com", Aliases: []string{"ana.s"}},
+		})),
+		scraperoutput.New("datadog-teams", 1.0, time.Now(), scraperoutput.Identities([]scraperoutput.Identity{
+			{Kind: identity.KindTeam, Key: "payments", Aliases: []string{"Payments Squad"}},
+		})),
+	}, nil)
+
+	resolved, unresolved := resolveIdentities(ctx, []Relationship{
+		{From: "ana.s", To: "Payments Squad", Type: "member_of", Source: "datadog-changes"},
+		{From: "ghost", To: "checkout", Type: "owns", Source: "aws-tags"},
+	}, resolver)
+
+	membership := resolved[0]
+	if membership.From != "person:ana@example.com" || membership.To != "team:payments" {
+		t.Errorf("Expected membership of person:ana@example.com in team:payments, got %s -> %s", membership.From, membership.To)
+	}
+	if membership.ToIdentity == nil || membership.ToIdentity.Source != "datadog-teams" || membership.ToIdentity.Rule != identity.RuleAlias {
+		t.Errorf("Expected the team match to name its directory, got %+v", membership.ToIdentity)
+	}
+	if len(unresolved) != 1 || unresolved[0] != "ghost" {
+		t.Errorf("Expected ghost to be reported unresolved, got %v", unresolved)
+	}
+}
+
+// datadogOwnership is a Datadog edge output of one team owning a service
+func datadogOwnership(source, team, service string) ScraperOutput {
+	return ScraperOutput{
+		Source:     source,
+		Confidence: 0.9,
+		Timestamp:  time.Now().Format(time.RFC3339),
+		Data: map[string]interface{}{
+			"relationships": []interface{}{map[string]interface{}{"from": team, "to": service, "type": "owns"}},
+		},
+	}
+}
+
+// Test spellings of one team published in separate runs resolve alike, whichever arrives first
+func TestHandleProcessorRequest_IdentitiesAcrossRuns(t *testing.T) {
+	runs := map[string][]ScraperOutput{
+		"suffixed first": {
+			datadogOwnership("datadog-monitors", "backend-team", "checkout"),
+			datadogOwnership("datadog-service-catalog", "backend", "checkout"),
+		},
+		"bare handle first": {
+			datadogOwnership("datadog-service-catalog", "backend", "checkout"),
+			datadogOwnership("datadog-monitors", "backend-team", "checkout"),
+		},
+	}
+
+	for name, outputs := range runs {
+		t.Run(name, func(t *testing.T) {
+			ctx, cleanup := common.TestContext("handler-identities-across-runs-test")
+			defer cleanup()
+
+			store := graphstore.NewMemoryStore()
+			useGraphStore(t, store)
+			useEvidenceStore(t, evidence.NewMemoryStore())
+
+			for _, output := range outputs {
+				response, err := handleProcessorRequest(ctx, ProcessorEvent{ScraperOutputs: []ScraperOutput{output}})
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if response.ConflictCount != 0 || response.ConflictsOpened != 0 {
+					t.Errorf("Expected no conflict between spellings of one team, got %+v", response)
+				}
+			}
+
+			edges, _ := store.FindEdges(ctx, graphstore.EdgeFilter{Label: "owns"})
+			if len(edges) != 1 || edges[0].From != graphstore.VertexID("team:backend") {
+				t.Errorf("Expected one edge from team:backend, got %+v", edges)
+			}
+		})
+	}
+}
+
+// Test the alias table configured for the processor takes part in resolution
+func TestNewIdentityResolver_AliasTable(t *testing.T) {
+	t.Setenv("IDENTITY_ALIAS_TABLE", `{"team:backend": ["platform-be"]}`)
+
+	canonical, match := newIdentityResolver(nil, nil).Resolve("platform-be")
+	if canonical != "team:backend" || match.Source != aliasTableSource {
+		t.Errorf("Expected platform-be to resolve to team:backend through the alias table, got %s %+v", canonical, match)
+	}
+}
+
+// Test deleted entities are removed under their canonical identity as well as the name they were deleted by
+func TestResolveDeletedEntities(t *testing.T) {
+	resolver := identity.NewResolver()
+	if err := resolver.AddAlias("backend", "team:backend", "datadog-teams"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	deleted := resolveDeletedEntities([]string{"backend", "checkout"}, resolver)
+
+	expected := []string{"backend", "checkout", "team:backend"}
+	if len(deleted) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, deleted)
+	}
+	for i := range expected {
+		if deleted[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, deleted)
+		}
+	}
+}
+
+// Test stored edges record the raw owner name and the rule that resolved it
+func TestHandleProcessorRequest_IdentityProvenance(t *testing.T) {
+	ctx, cleanup := common.TestContext("processor-identity-provenance-test")
+	defer cleanup()
+
+	store := graphstore.NewMemoryStore()
+	useGraphStore(t, store)
+
+	output := createGitHubCodeownersOutput()
+	output.Data["entries"] = []interface{}{
+		map[string]interface{}{"path": "/src/main", "owners": []interface{}{"@org/backend"}},
+		map[string]interface{}{"path": "/docs", "owners": []interface{}{"@user1"}},
+	}
+
+	response, err := handleProcessorRequest(ctx, ProcessorEvent{ScraperOutputs: []ScraperOutput{output}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(response.UnresolvedIdentities) != 1 || response.UnresolvedIdentities[0] != "user1" {
+		t.Errorf("Expected user1 to be reported unresolved, got %v", response.UnresolvedIdentities)
+	}
+
+	edges, err := store.FindEdges(ctx, graphstore.EdgeFilter{From: graphstore.VertexID("team:backend")})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(edges) != 1 {
+		t.Fatalf("Expected one edge owned by team:backend, got %+v", edges)
+	}
+	if edges[0].Properties["from_raw"] != "org/backend" || edges[0].Properties["from_rule"] != identity.RuleGitHubTeam {
+		t.Errorf("Expected the raw owner and rule on the edge, got %v", edges[0].Properties)
+	}
+}
@@ -7,51 +7,77 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-xray-sdk-go/v2/xray"
+
+	"bacon/src/shared/graphstore"
+	"bacon/src/shared/relationship-finding/extractors"
+	"bacon/src/shared/relationship-finding/scoring"
+	"bacon/src/shared/scraperoutput"
 )
 
 type ProcessorEvent struct {
 	ScraperOutputs []ScraperOutput `json:"scraper_outputs"`
 }
 
+// ScraperOutput is the wire form of a scraper output; schema v1 outputs carry an untyped Data map and
+// a Timestamp, later versions the typed Payload and ProducedAt of scraperoutput.Envelope
 type ScraperOutput struct {
-	Source     string                 `json:"source"`
-	Data       map[string]interface{} `json:"data"`
-	Confidence float64                `json:"confidence"`
-	Timestamp  string                 `json:"timestamp"`
+	SchemaVersion string                  `json:"schema_version,omitempty"`
+	Source        string                  `json:"source"`
+	Data          map[string]interface{}  `json:"data,omitempty"`
+	Payload       *scraperoutput.Payload  `json:"payload,omitempty"`
+	Confidence    float64                 `json:"confidence"`
+	Timestamp     string                  `json:"timestamp,omitempty"`
+	ProducedAt    string                  `json:"produced_at,omitempty"`
+	Snapshot      *scraperoutput.Snapshot `json:"snapshot,omitempty"` // set when a typed output is a complete snapshot of its source
 }
 
 type ProcessorResponse struct {
-	Status            string `json:"status"`
-	Message           string `json:"message"`
-	ProcessedAt       string `json:"processed_at"`
-	RelationshipCount int    `json:"relationship_count"`
-	ConflictCount     int    `json:"conflict_count"`
+	Status                string                      `json:"status"`
+	Message               string                      `json:"message"`
+	ProcessedAt           string                      `json:"processed_at"`
+	RelationshipCount     int                         `json:"relationship_count"`
+	ConflictCount         int                         `json:"conflict_count"`
+	RecordErrors          []scraperoutput.RecordError `json:"record_errors,omitempty"`
+	UnknownSources        []string                    `json:"unknown_sources,omitempty"`
+	UnresolvedIdentities  []string                    `json:"unresolved_identities,omitempty"`
+	ConflictsOpened       int                         `json:"conflicts_opened"`
+	ConflictsClosed       int                         `json:"conflicts_closed"`
+	AffectedResources     int                         `json:"affected_resources"`
+	RelationshipsArchived int                         `json:"relationships_archived"`
 }
 
-type Relationship struct {
-	From        string  `json:"from"`
-	To          string  `json:"to"`
-	Type        string  `json:"type"`
-	Confidence  float64 `json:"confidence"`
-	Source      string  `json:"source"`
-	HasConflict bool    `json:"has_conflict"`
-	Timestamp   string  `json:"timestamp"`
-}
+type Relationship = extractors.Relationship
+
+// ScoringStrategy combines the claims of several sources on one relationship into its confidence
+type ScoringStrategy = scoring.Strategy
 
 type ConfidenceEngine struct {
 	SourceWeights  map[string]float64
 	AgreementBonus float64
 	FreshnessDecay float64
+	Strategy       ScoringStrategy // nil keeps the strongest claim plus AgreementBonus
+
+	// Per resource type overrides of SourceWeights and FreshnessDecay
+	ResourceTypeWeights map[string]map[string]float64
+	ResourceTypeDecay   map[string]float64
+
+	ConfigVersion string // version of the scoring config, stamped on every scored relationship
 }
 
+// ConflictRule decides when claims of one relationship type on the same target conflict
+type ConflictRule = scoring.ConflictRule
+
 type ConflictDetector struct {
-	ConflictThreshold float64
-	SourcePriority    map[string]int
+	ConflictThreshold    float64 // claims less confident than this are superseded without making a conflict
+	SourcePriority       map[string]int
+	ResourceTypePriority map[string]map[string]int // per resource type overrides of SourcePriority
+	Rules                map[string]ConflictRule   // by relationship type; types without a rule are shared
 }
 
 func main() {
@@ -64,65 +90,280 @@ func handleProcessorRequest(ctx context.Context, event ProcessorEvent) (Processo
 
 	_ = seg.AddAnnotation("scraper_count", len(event.ScraperOutputs))
 
-	// Initialize confidence engine and conflict detector
-	confEngine := initConfidenceEngine()
-	conflictDet := initConflictDetector()
+	now := time.Now()
+
+	// Initialize confidence engine and conflict detector from one read of the scoring config, so the weights and
+	// priorities of a run come from the version its edges are stamped with
+	scoringConfig := loadScoringConfig()
+	confEngine := initConfidenceEngine(scoringConfig)
+	conflictDet := initConflictDetector(scoringConfig)
+
+	// Decode scraper outputs into typed envelopes; malformed records are dropped and reported
+	envelopes, recordErrors := decodeScraperOutputs(event.ScraperOutputs)
+	_ = seg.AddAnnotation("record_errors", len(recordErrors))
+
+	// Outputs of sources no plugin registered are reported instead of vanishing
+	unregisteredSources := unknownSources(envelopes)
+	_ = seg.AddAnnotation("unknown_sources", len(unregisteredSources))
 
 	// Extract relationships from scraper outputs
-	relationships := extractRelationships(ctx, event.ScraperOutputs)
+	relationships := extractRelationships(ctx, envelopes)
+
+	// Only the resources these outputs touch are rescored, on the latest evidence of every source
+	evidenceStore, err := newEvidenceStore(ctx)
+	if err != nil {
+		_ = seg.AddError(err)
+		return createErrorResponse(fmt.Sprintf("failed to configure evidence store: %v", err), 0, 0), err
+	}
+
+	// Every spelling of a team or person is unified onto one canonical identity before scoring, with the aliases
+	// earlier runs resolved with
+	knownAliases, err := evidenceStore.Aliases(ctx)
+	if err != nil {
+		_ = seg.AddError(err)
+		return createErrorResponse(fmt.Sprintf("failed to read identity aliases: %v", err), 0, 0), err
+	}
+	resolver := newIdentityResolver(envelopes, knownAliases)
+	relationships, unresolvedIdentities := resolveIdentities(ctx, relationships, resolver)
+
+	// Entities deleted at their source no longer vouch for any edge
+	deletedEntities := resolveDeletedEntities(extractDeletedEntities(envelopes), resolver)
+	relationships = dropDeletedEntityRelationships(relationships, deletedEntities)
+
+	// Memberships and ownerships removed between Datadog snapshots are retracted
+	retractedRelationships := extractRetractedRelationships(envelopes)
+	for i, rel := range retractedRelationships {
+		retractedRelationships[i] = resolveRelationship(rel, resolver)
+	}
+	relationships = dropRetractedRelationships(relationships, retractedRelationships)
+
+	// Claims a source's latest snapshot no longer contains are retracted
+	withdrawn, err := withdrawnEvidence(ctx, evidenceStore, envelopes, relationships)
+	if err != nil {
+		_ = seg.AddError(err)
+		return createErrorResponse(fmt.Sprintf("failed to read evidence: %v", err), 0, 0), err
+	}
+
+	relationships, affectedResources, renamedRelationships, err := updateEvidence(ctx, evidenceStore, relationships, deletedEntities, retractedRelationships, withdrawn, conflictDet, resolver)
+	if err != nil {
+		_ = seg.AddError(err)
+		return createErrorResponse(fmt.Sprintf("failed to update evidence: %v", err), 0, 0), err
+	}
+	if err := rememberAliases(ctx, evidenceStore, resolver, knownAliases); err != nil {
+		_ = seg.AddError(err)
+		return createErrorResponse(fmt.Sprintf("failed to record identity aliases: %v", err), 0, 0), err
+	}
 
 	// Apply confidence scoring
 	scoredRelationships := applyConfidenceScoring(ctx, relationships, confEngine)
 
 	// Detect conflicts
-	resolvedRelationships := detectAndResolveConflicts(ctx, scoredRelationships, conflictDet)
+	resolvedRelationships, conflictCount := detectAndResolveConflicts(ctx, scoredRelationships, conflictDet)
+
+	// Store in the graph
+	store, err := newGraphStore(ctx)
+	if err != nil {
+		_ = seg.AddError(err)
+		return createErrorResponse(fmt.Sprintf("failed to configure graph store: %v", err), 0, 0), err
+	}
+
+	err = storeRelationships(ctx, store, resolvedRelationships)
+	if err != nil {
+		_ = seg.AddError(err)
+		return createErrorResponse(fmt.Sprintf("failed to store relationships: %v", err), 0, 0), err
+	}
 
-	// Store in Neptune
-	err := storeInNeptune(ctx, resolvedRelationships)
+	// Edges no evidence is left for are archived with an audit record
+	removed, err := removeRelationships(ctx, store, deletedEntities, retractedRelationships, now)
+	if err != nil {
+		_ = seg.AddError(err)
+		return createErrorResponse(fmt.Sprintf("failed to remove stale relationships: %v", err), len(resolvedRelationships), 0), err
+	}
+	withdrawnArchived, err := archiveWithdrawnRelationships(ctx, store, withdrawn, now)
+	if err != nil {
+		_ = seg.AddError(err)
+		return createErrorResponse(fmt.Sprintf("failed to archive withdrawn relationships: %v", err), len(resolvedRelationships), 0), err
+	}
+	// The relationship's edge from an earlier strongest source is superseded by the one just stored
+	mergedArchived, err := archiveMergedRelationships(ctx, store, resolvedRelationships, now)
+	if err != nil {
+		_ = seg.AddError(err)
+		return createErrorResponse(fmt.Sprintf("failed to archive merged relationships: %v", err), len(resolvedRelationships), 0), err
+	}
+	// Claims now resolving to another identity leave their edges under the old names behind
+	renamedArchived, err := archiveRenamedRelationships(ctx, store, renamedRelationships, resolvedRelationships, now)
 	if err != nil {
 		_ = seg.AddError(err)
-		return createErrorResponse(fmt.Sprintf("failed to store in Neptune: %v", err), 0, 0), err
+		return createErrorResponse(fmt.Sprintf("failed to archive renamed relationships: %v", err), len(resolvedRelationships), 0), err
 	}
+	archived := removed + withdrawnArchived + mergedArchived + renamedArchived
 
-	conflictCount := countConflicts(resolvedRelationships)
+	// Every conflict gets a review record, which closes once its sources agree
+	conflictsOpened, conflictsClosed, err := syncConflicts(ctx, store, resolvedRelationships, conflictDet, now)
+	if err != nil {
+		_ = seg.AddError(err)
+		return createErrorResponse(fmt.Sprintf("failed to record conflicts: %v", err), len(resolvedRelationships), 0), err
+	}
 
 	_ = seg.AddMetadata("processing_result", map[string]interface{}{
-		"relationship_count": len(resolvedRelationships),
-		"conflict_count":     conflictCount,
-		"scraper_sources":    getSourceNames(event.ScraperOutputs),
+		"relationship_count":    len(resolvedRelationships),
+		"conflict_count":        conflictCount,
+		"scraper_sources":       getSourceNames(event.ScraperOutputs),
+		"deleted_entities":      len(deletedEntities),
+		"retracted_edges":       len(retractedRelationships),
+		"affected_resources":    len(affectedResources),
+		"withdrawn_claims":      len(withdrawn),
+		"archived_edges":        archived,
+		"record_errors":         recordErrors,
+		"unknown_sources":       unregisteredSources,
+		"unresolved_identities": unresolvedIdentities,
+		"conflicts_opened":      conflictsOpened,
+		"conflicts_closed":      conflictsClosed,
 	})
 
-	return createSuccessResponse(len(resolvedRelationships), conflictCount), nil
+	response := createSuccessResponse(len(resolvedRelationships), conflictCount)
+	response.RecordErrors = recordErrors
+	response.UnknownSources = unregisteredSources
+	response.UnresolvedIdentities = unresolvedIdentities
+	response.ConflictsOpened = conflictsOpened
+	response.ConflictsClosed = conflictsClosed
+	response.AffectedResources = len(affectedResources)
+	response.RelationshipsArchived = archived
+	return response, nil
 }
 
-func initConfidenceEngine() *ConfidenceEngine {
-	return &ConfidenceEngine{
-		SourceWeights: map[string]float64{
-			"openshift-metadata": 0.9,
-			"aws-tags":           0.9,
-			"github-codeowners":  0.8,
-			"github-activity":    0.6,
-			"datadog-metrics":    0.5,
-		},
-		AgreementBonus: 0.1,
-		FreshnessDecay: 0.05,
+func initConfidenceEngine(config scoring.Config) *ConfidenceEngine {
+	engine := &ConfidenceEngine{
+		SourceWeights:       config.Weights(extractors.Default.Weights()),
+		AgreementBonus:      config.AgreementBonus,
+		FreshnessDecay:      config.FreshnessDecay,
+		ResourceTypeWeights: config.ResourceTypeWeights(),
+		ResourceTypeDecay:   config.ResourceTypeDecay(),
+		ConfigVersion:       config.Version,
+	}
+
+	// SCORING_STRATEGY overrides the strategy of the config; unknown names keep the config's
+	name := config.Strategy
+	if override := os.Getenv("SCORING_STRATEGY"); override != "" {
+		name = override
 	}
+	strategy, err := scoring.ByName(name, engine.AgreementBonus)
+	if err != nil {
+		log.Printf("Scoring strategy: %v", err)
+		strategy, _ = scoring.ByName(config.Strategy, engine.AgreementBonus)
+	}
+	engine.Strategy = strategy
+
+	return engine
 }
 
-func initConflictDetector() *ConflictDetector {
+// strategy returns the scoring strategy of the engine, defaulting to the strongest claim plus the agreement bonus
+func (engine *ConfidenceEngine) strategy() ScoringStrategy {
+	if engine.Strategy == nil {
+		return scoring.MaxWithBonus{Bonus: engine.AgreementBonus}
+	}
+	return engine.Strategy
+}
+
+// sourceWeight returns the weight of a relationship's source, as overridden for its resource type
+func (engine *ConfidenceEngine) sourceWeight(rel Relationship) float64 {
+	if weight, found := engine.ResourceTypeWeights[rel.ResourceType][rel.Source]; found {
+		return weight
+	}
+	return engine.SourceWeights[rel.Source]
+}
+
+// freshnessDecay returns the freshness decay of a relationship, as overridden for its resource type
+func (engine *ConfidenceEngine) freshnessDecay(rel Relationship) float64 {
+	if decay, found := engine.ResourceTypeDecay[rel.ResourceType]; found {
+		return decay
+	}
+	return engine.FreshnessDecay
+}
+
+func initConflictDetector(config scoring.Config) *ConflictDetector {
 	return &ConflictDetector{
-		ConflictThreshold: 0.3,
-		SourcePriority: map[string]int{
-			"aws-tags":           1,
-			"openshift-metadata": 2,
-			"github-codeowners":  3,
-			"github-activity":    3,
-			"datadog-metrics":    4,
-		},
+		ConflictThreshold:    config.ConflictThreshold,
+		SourcePriority:       config.Priorities(extractors.Default.Priorities()),
+		ResourceTypePriority: config.ResourceTypePriorities(),
+		Rules:                config.ConflictRules(),
+	}
+}
+
+// rule returns the conflict rule of a relationship type
+func (detector *ConflictDetector) rule(relType string) ConflictRule {
+	return detector.Rules[strings.ToLower(relType)]
+}
+
+// conflictTarget returns the target a relationship's claim competes for
+// CODEOWNERS paths of path-hierarchy types are compared as paths, so /src/, src and src/** compete as one
+func (detector *ConflictDetector) conflictTarget(rel Relationship) string {
+	if rel.ResourceType != extractors.ResourceTypeRepository || !detector.rule(rel.Type).PathHierarchy {
+		return rel.To
+	}
+	path := strings.TrimSuffix(rel.To, "/**")
+	path = strings.Trim(path, "/")
+	if path == "" || path == "*" || path == "**" {
+		return "/"
+	}
+	return path
+}
+
+// sourcePriority returns the conflict priority of a relationship's source, as overridden for its resource type
+func (detector *ConflictDetector) sourcePriority(rel Relationship) int {
+	if priority, found := detector.ResourceTypePriority[rel.ResourceType][rel.Source]; found {
+		return priority
 	}
+	return detector.SourcePriority[rel.Source]
 }
 
-func extractRelationships(ctx context.Context, outputs []ScraperOutput) []Relationship {
+// loadScoringConfig loads the scoring config at every invocation, so a changed document applies without a
+// redeploy; an invalid document is logged and the embedded default used instead
+func loadScoringConfig() scoring.Config {
+	config, err := scoring.LoadConfig()
+	if err != nil {
+		log.Printf("Scoring config: %v; using embedded version %s", err, config.Version)
+	}
+	return config
+}
+
+// decodeScraperOutputs converts wire outputs into validated envelopes
+// Schema v1 outputs are decoded from their data map; invalid records are dropped and returned as errors
+func decodeScraperOutputs(outputs []ScraperOutput) ([]scraperoutput.Envelope, []scraperoutput.RecordError) {
+	envelopes := make([]scraperoutput.Envelope, 0, len(outputs))
+	var recordErrors []scraperoutput.RecordError
+
+	for _, output := range outputs {
+		envelope, errs := decodeScraperOutput(output)
+		for _, err := range errs {
+			log.Printf("Dropped scraper record: %v", err)
+		}
+		envelopes = append(envelopes, envelope)
+		recordErrors = append(recordErrors, errs...)
+	}
+
+	return envelopes, recordErrors
+}
+
+func decodeScraperOutput(output ScraperOutput) (scraperoutput.Envelope, []scraperoutput.RecordError) {
+	if output.SchemaVersion == "" || output.SchemaVersion == scraperoutput.LegacySchemaVersion {
+		return scraperoutput.DecodeLegacy(output.Source, output.Data, output.Confidence, output.Timestamp)
+	}
+
+	envelope := scraperoutput.Envelope{
+		SchemaVersion: output.SchemaVersion,
+		Source:        output.Source,
+		ProducedAt:    output.ProducedAt,
+		Confidence:    output.Confidence,
+		Snapshot:      output.Snapshot,
+	}
+	if output.Payload != nil {
+		envelope.Payload = *output.Payload
+	}
+	return scraperoutput.Validate(envelope)
+}
+
+func extractRelationships(ctx context.Context, outputs []scraperoutput.Envelope) []Relationship {
 	ctx, seg := xray.BeginSubsegment(ctx, "extract-relationships")
 	defer seg.Close(nil)
 	_ = ctx // Context updated for tracing but not used further in this function
@@ -130,100 +371,136 @@ func extractRelationships(ctx context.Context, outputs []ScraperOutput) []Relati
 	var relationships []Relationship
 
 	for _, output := range outputs {
-		switch output.Source {
-		case "github-codeowners":
-			relationships = append(relationships, extractCodeownersRelationships(output)...)
-		case "openshift-metadata":
-			relationships = append(relationships, extractOpenShiftRelationships(output)...)
-		case "aws-tags":
-			relationships = append(relationships, extractAWSRelationships(output)...)
+		registration, found := extractors.Default.Lookup(output.Source)
+		if !found {
+			continue
 		}
+		extracted := registration.Extract(output)
+		if output.Snapshot != nil {
+			for i := range extracted {
+				extracted[i].Scope = output.Snapshot.Scope
+			}
+		}
+		relationships = append(relationships, extracted...)
 	}
 
 	_ = seg.AddAnnotation("relationship_count", len(relationships))
 	return relationships
 }
 
-func extractCodeownersRelationships(output ScraperOutput) []Relationship {
-	var relationships []Relationship
+// unknownSources lists the sources no plugin registered, in order of first appearance
+// Their outputs contribute nothing, so they are surfaced rather than silently ignored
+func unknownSources(outputs []scraperoutput.Envelope) []string {
+	var unknown []string
+	seen := make(map[string]bool)
 
-	// Extract from CODEOWNERS data structure
-	if entries, ok := output.Data["entries"].([]interface{}); ok {
-		for _, entry := range entries {
-			if entryMap, ok := entry.(map[string]interface{}); ok {
-				path := entryMap["path"].(string)
-				if owners, ok := entryMap["owners"].([]interface{}); ok {
-					for _, owner := range owners {
-						rel := Relationship{
-							From:       strings.TrimPrefix(owner.(string), "@"),
-							To:         path,
-							Type:       "owns",
-							Confidence: output.Confidence,
-							Source:     output.Source,
-							Timestamp:  output.Timestamp,
-						}
-						relationships = append(relationships, rel)
-					}
-				}
-			}
+	for _, output := range outputs {
+		if seen[output.Source] {
+			continue
+		}
+		seen[output.Source] = true
+		if _, found := extractors.Default.Lookup(output.Source); !found {
+			log.Printf("No extractor registered for source %q", output.Source)
+			unknown = append(unknown, output.Source)
 		}
 	}
 
-	return relationships
+	return unknown
 }
 
-func extractOpenShiftRelationships(output ScraperOutput) []Relationship {
-	var relationships []Relationship
+func extractDeletedEntities(outputs []scraperoutput.Envelope) []string {
+	var entities []string
+	seen := make(map[string]bool)
+
+	addEntity := func(entity string) {
+		if entity != "" && !seen[entity] {
+			seen[entity] = true
+			entities = append(entities, entity)
+		}
+	}
 
-	if resources, ok := output.Data["resources"].([]interface{}); ok {
-		for _, resource := range resources {
-			if resMap, ok := resource.(map[string]interface{}); ok {
-				resourceName := fmt.Sprintf("%s/%s", resMap["kind"], resMap["name"])
-				if owner, ok := resMap["owner"].(string); ok && owner != "" {
-					rel := Relationship{
-						From:       owner,
-						To:         resourceName,
-						Type:       "owns",
-						Confidence: output.Confidence,
-						Source:     output.Source,
-						Timestamp:  output.Timestamp,
-					}
-					relationships = append(relationships, rel)
+	for _, output := range outputs {
+		switch output.Source {
+		case "datadog-deletions":
+			// Deletion events list entities tombstoned after a complete Datadog scrape
+			for _, deletion := range output.Payload.Deletions {
+				addEntity(deletion.Entity)
+			}
+		case "datadog-changes":
+			// Snapshot diffs report entities missing from the latest snapshot
+			for _, change := range extractors.ChangeEvents(output) {
+				if change.Type == "entity_removed" {
+					addEntity(change.EntityName)
 				}
 			}
 		}
 	}
 
-	return relationships
+	return entities
 }
 
-func extractAWSRelationships(output ScraperOutput) []Relationship {
-	var relationships []Relationship
+func dropDeletedEntityRelationships(relationships []Relationship, deletedEntities []string) []Relationship {
+	if len(deletedEntities) == 0 {
+		return relationships
+	}
 
-	// Mock AWS relationship extraction
-	if resources, ok := output.Data["resources"].([]interface{}); ok {
-		for _, resource := range resources {
-			if resMap, ok := resource.(map[string]interface{}); ok {
-				if resourceArn, ok := resMap["arn"].(string); ok {
-					if tags, ok := resMap["tags"].(map[string]interface{}); ok {
-						if owner, ok := tags["Owner"].(string); ok {
-							rel := Relationship{
-								From:       owner,
-								To:         resourceArn,
-								Type:       "owns",
-								Confidence: output.Confidence,
-								Source:     output.Source,
-								Timestamp:  output.Timestamp,
-							}
-							relationships = append(relationships, rel)
-						}
-					}
-				}
+	deleted := make(map[string]bool, len(deletedEntities))
+	for _, entity := range deletedEntities {
+		deleted[entity] = true
+	}
+
+	// Only Datadog edges are dropped; other sources still vouch for their own edges
+	var kept []Relationship
+	for _, rel := range relationships {
+		if isDatadogSource(rel.Source) && (deleted[rel.From] || deleted[rel.To]) {
+			continue
+		}
+		kept = append(kept, rel)
+	}
+
+	return kept
+}
+
+func extractRetractedRelationships(outputs []scraperoutput.Envelope) []Relationship {
+	var retracted []Relationship
+
+	for _, output := range outputs {
+		if output.Source != "datadog-changes" {
+			continue
+		}
+		for _, change := range extractors.ChangeEvents(output) {
+			if rel, ok := extractors.ChangeToRelationship(change, change.Before); ok {
+				retracted = append(retracted, rel)
 			}
 		}
 	}
 
-	return relationships
+	return retracted
+}
+
+func dropRetractedRelationships(relationships []Relationship, retracted []Relationship) []Relationship {
+	if len(retracted) == 0 {
+		return relationships
+	}
+
+	retractedKeys := make(map[string]bool, len(retracted))
+	for _, rel := range retracted {
+		retractedKeys[fmt.Sprintf("%s->%s:%s", rel.From, rel.To, rel.Type)] = true
+	}
+
+	var kept []Relationship
+	for _, rel := range relationships {
+		if isDatadogSource(rel.Source) && retractedKeys[fmt.Sprintf("%s->%s:%s", rel.From, rel.To, rel.Type)] {
+			continue
+		}
+		kept = append(kept, rel)
+	}
+
+	return kept
+}
+
+func isDatadogSource(source string) bool {
+	return strings.HasPrefix(source, "datadog-")
 }
 
 func applyConfidenceScoring(ctx context.Context, relationships []Relationship, engine *ConfidenceEngine) []Relationship {
@@ -231,10 +508,11 @@ func applyConfidenceScoring(ctx context.Context, relationships []Relationship, e
 	defer seg.Close(nil)
 	_ = ctx // Context updated for tracing but not used further in this function
 
-	// Group relationships by target for multi-source analysis
+	// Group the claims of each relationship for multi-source analysis; a team maintaining a resource
+	// does not agree with a claim that it owns it
 	relationshipGroups := make(map[string][]Relationship)
 	for _, rel := range relationships {
-		key := fmt.Sprintf("%s->%s", rel.From, rel.To)
+		key := fmt.Sprintf("%s-%s->%s", rel.From, rel.Type, rel.To)
 		relationshipGroups[key] = append(relationshipGroups[key], rel)
 	}
 
@@ -243,7 +521,9 @@ func applyConfidenceScoring(ctx context.Context, relationships []Relationship, e
 		if len(group) == 1 {
 			// Single source - apply base confidence with source weight
 			rel := group[0]
-			rel.Confidence = calculateSingleSourceConfidence(rel, engine)
+			contribution := scoreContribution(rel, engine)
+			rel.Confidence = contribution.Confidence
+			rel.Explanation = explain(contribution, contribution.Confidence, []extractors.Contribution{contribution})
 			scoredRelationships = append(scoredRelationships, rel)
 		} else {
 			// Multi-source - apply agreement bonus
@@ -252,47 +532,83 @@ func applyConfidenceScoring(ctx context.Context, relationships []Relationship, e
 		}
 	}
 
+	for i := range scoredRelationships {
+		scoredRelationships[i].ScoringConfigVersion = engine.ConfigVersion
+	}
+	_ = seg.AddAnnotation("scoring_config_version", engine.ConfigVersion)
+
 	_ = seg.AddAnnotation("scored_relationships", len(scoredRelationships))
 	return scoredRelationships
 }
 
 func calculateSingleSourceConfidence(rel Relationship, engine *ConfidenceEngine) float64 {
+	return scoreContribution(rel, engine).Confidence
+}
+
+// scoreContribution scores the claim of one source on its own and records the factors of the score
+func scoreContribution(rel Relationship, engine *ConfidenceEngine) extractors.Contribution {
 	// Defensive programming: handle nil engine
 	if engine == nil {
 		panic("ConfidenceEngine cannot be nil")
 	}
-	
-	sourceWeight := engine.SourceWeights[rel.Source]
+
+	sourceWeight := engine.sourceWeight(rel)
 	if sourceWeight == 0 {
 		sourceWeight = 0.5 // Default for unknown sources
 	}
 
 	// Apply freshness decay
-	freshnessMultiplier := calculateFreshnessMultiplier(rel.Timestamp, engine.FreshnessDecay)
+	freshnessMultiplier := calculateFreshnessMultiplier(rel.Timestamp, engine.freshnessDecay(rel))
 
 	confidence := rel.Confidence * sourceWeight * freshnessMultiplier
-	return math.Min(confidence, 1.0)
+	return extractors.Contribution{
+		Source:              rel.Source,
+		Timestamp:           rel.Timestamp,
+		BaseConfidence:      rel.Confidence,
+		SourceWeight:        sourceWeight,
+		FreshnessMultiplier: freshnessMultiplier,
+		Confidence:          math.Min(confidence, 1.0),
+	}
 }
 
 func calculateMultiSourceConfidence(group []Relationship, engine *ConfidenceEngine) []Relationship {
-	// Find the relationship with highest base confidence
+	// Find the relationship with highest base confidence; the others are kept in its explanation
 	maxConfidence := 0.0
 	bestRel := group[0]
-
-	for _, rel := range group {
-		baseConf := calculateSingleSourceConfidence(rel, engine)
-		if baseConf > maxConfidence {
-			maxConfidence = baseConf
+	contributions := make([]extractors.Contribution, 0, len(group))
+	best := 0
+
+	for i, rel := range group {
+		contribution := scoreContribution(rel, engine)
+		contributions = append(contributions, contribution)
+		if contribution.Confidence > maxConfidence {
+			maxConfidence = contribution.Confidence
 			bestRel = rel
+			best = i
 		}
 	}
 
-	// Apply multi-source agreement bonus
-	bestRel.Confidence = math.Min(maxConfidence+engine.AgreementBonus, 1.0)
+	// Combine the agreeing sources with the engine's strategy
+	strategy := engine.strategy()
+	bestRel.Confidence = strategy.Combine(contributions)
+	bestRel.Explanation = explain(contributions[best], bestRel.Confidence, contributions)
+	bestRel.Explanation.Strategy = strategy.Name()
 
 	return []Relationship{bestRel}
 }
 
+// explain builds the explanation of a score from the kept claim, the combined confidence and every contributing claim
+func explain(kept extractors.Contribution, confidence float64, contributions []extractors.Contribution) *extractors.Explanation {
+	return &extractors.Explanation{
+		Confidence:          confidence,
+		BaseConfidence:      kept.BaseConfidence,
+		SourceWeight:        kept.SourceWeight,
+		FreshnessMultiplier: kept.FreshnessMultiplier,
+		AgreementBonus:      confidence - kept.Confidence,
+		Sources:             contributions,
+	}
+}
+
 func calculateFreshnessMultiplier(timestamp string, decayRate float64) float64 {
 	parsedTime, err := time.Parse(time.RFC3339, timestamp)
 	if err != nil {
@@ -306,51 +622,128 @@ func calculateFreshnessMultiplier(timestamp string, decayRate float64) float64 {
 	return math.Exp(-daysSinceUpdate * decayRate)
 }
 
-func detectAndResolveConflicts(ctx context.Context, relationships []Relationship, detector *ConflictDetector) []Relationship {
+// detectAndResolveConflicts settles the claims on every target and returns them with the number of conflicts found
+func detectAndResolveConflicts(ctx context.Context, relationships []Relationship, detector *ConflictDetector) ([]Relationship, int) {
 	ctx, seg := xray.BeginSubsegment(ctx, "detect-resolve-conflicts")
 	defer seg.Close(nil)
 	_ = ctx // Context updated for tracing but not used further in this function
 
-	// Group relationships by same target resource
-	resourceGroups := make(map[string][]Relationship)
+	// Group claims of the same type on the same target, keyed by the conflict they would make; maintaining a
+	// resource never conflicts with owning it
+	groups := make(map[string][]Relationship)
 	for _, rel := range relationships {
-		resourceGroups[rel.To] = append(resourceGroups[rel.To], rel)
+		conflictID := graphstore.ConflictID(rel.Type, detector.conflictTarget(rel))
+		groups[conflictID] = append(groups[conflictID], rel)
 	}
 
 	var resolvedRelationships []Relationship
 	conflictCount := 0
 
-	for _, group := range resourceGroups {
-		if len(group) == 1 {
-			resolvedRelationships = append(resolvedRelationships, group[0])
+	for conflictID, group := range groups {
+		// Shared types admit any number of owners; the most confident claim stands for each
+		if !detector.rule(group[0].Type).Exclusive {
+			resolvedRelationships = append(resolvedRelationships, strongestClaimPerOwner(group)...)
 			continue
 		}
 
-		// Check for conflicts (different owners for same resource)
-		owners := make(map[string]bool)
-		for _, rel := range group {
-			owners[rel.From] = true
-		}
-
-		if len(owners) > 1 {
-			// Conflict detected - resolve using priority
-			resolved := resolveConflict(group, detector)
-			resolvedRelationships = append(resolvedRelationships, resolved)
+		settled, conflicted := settleConflict(group, conflictID, detector)
+		if conflicted {
 			conflictCount++
-		} else {
-			// Same owner from multiple sources - take highest confidence
-			best := group[0]
-			for _, rel := range group[1:] {
-				if rel.Confidence >= best.Confidence {
-					best = rel
-				}
-			}
-			resolvedRelationships = append(resolvedRelationships, best)
 		}
+		resolvedRelationships = append(resolvedRelationships, settled...)
 	}
 
 	_ = seg.AddAnnotation("conflict_count", conflictCount)
-	return resolvedRelationships
+	return resolvedRelationships, conflictCount
+}
+
+// strongestClaimPerOwner keeps the most confident claim of each owner in a group, in order of first claim
+func strongestClaimPerOwner(group []Relationship) []Relationship {
+	var owners []string
+	strongest := make(map[string]Relationship)
+	for _, rel := range group {
+		best, found := strongest[rel.From]
+		if !found {
+			owners = append(owners, rel.From)
+		}
+		if !found || rel.Confidence >= best.Confidence {
+			strongest[rel.From] = rel
+		}
+	}
+
+	claims := make([]Relationship, 0, len(owners))
+	for _, owner := range owners {
+		claims = append(claims, strongest[owner])
+	}
+	return claims
+}
+
+// settleConflict resolves the claims on a target of an exclusive type
+// The source with the highest priority wins, along with every owner it names: owners listed together, e.g. on
+// one CODEOWNERS line, co-own the target, and other sources naming them only corroborate it. The claims of the
+// remaining owners are kept as superseded, each side linked to the other and to the conflict. There is no conflict
+// when no other owner is claimed, or only below the conflict threshold; such claims are superseded all the same,
+// but not flagged as conflicted.
+func settleConflict(group []Relationship, conflictID string, detector *ConflictDetector) ([]Relationship, bool) {
+	winningSource := resolveConflict(group, detector).Source
+
+	var named, dissenting []Relationship
+	coOwners := make(map[string]bool)
+	for _, rel := range group {
+		if rel.Source == winningSource {
+			named = append(named, rel)
+			coOwners[rel.From] = true
+		}
+	}
+	for _, rel := range group {
+		if !coOwners[rel.From] {
+			dissenting = append(dissenting, rel)
+		}
+	}
+
+	winners := strongestClaimPerOwner(named)
+	if len(dissenting) == 0 {
+		return winners, false
+	}
+	losers := strongestClaimPerOwner(dissenting)
+
+	winnerIDs := relationshipIDs(winners)
+	for i := range losers {
+		losers[i].Superseded = true
+		losers[i].ConflictsWith = winnerIDs
+	}
+
+	// Claims less confident than the threshold are overruled without making a conflict
+	conflicted := false
+	for _, rel := range losers {
+		if rel.Confidence >= detector.ConflictThreshold {
+			conflicted = true
+			break
+		}
+	}
+	if !conflicted {
+		return append(winners, losers...), false
+	}
+
+	loserIDs := relationshipIDs(losers)
+	for i := range winners {
+		winners[i].HasConflict = true
+		winners[i].ConflictsWith = loserIDs
+		winners[i].ConflictID = conflictID
+	}
+	for i := range losers {
+		losers[i].HasConflict = true
+		losers[i].ConflictID = conflictID
+	}
+	return append(winners, losers...), true
+}
+
+func relationshipIDs(relationships []Relationship) []string {
+	ids := make([]string, 0, len(relationships))
+	for _, rel := range relationships {
+		ids = append(ids, relationshipID(rel))
+	}
+	return ids
 }
 
 func resolveConflict(conflicted []Relationship, detector *ConflictDetector) Relationship {
@@ -359,7 +752,7 @@ func resolveConflict(conflicted []Relationship, detector *ConflictDetector) Rela
 	bestRel := conflicted[0]
 
 	for _, rel := range conflicted {
-		priority := detector.SourcePriority[rel.Source]
+		priority := detector.sourcePriority(rel)
 		if priority == 0 {
 			priority = 999 // Unknown sources get lowest priority
 		}
@@ -375,42 +768,6 @@ func resolveConflict(conflicted []Relationship, detector *ConflictDetector) Rela
 	return bestRel
 }
 
-func storeInNeptune(ctx context.Context, relationships []Relationship) error {
-	ctx, seg := xray.BeginSubsegment(ctx, "store-in-neptune")
-	defer seg.Close(nil)
-	_ = ctx // Context updated for tracing but not used further in this function
-
-	// Mock Neptune storage for POC - in production would use Gremlin client
-	// This would create vertices and edges in Neptune graph database
-
-	log.Printf("Storing %d relationships in Neptune", len(relationships))
-
-	for _, rel := range relationships {
-		// Mock Gremlin query construction
-		gremlinQuery := fmt.Sprintf(
-			"g.V().has('name', '%s').fold().coalesce(unfold(), addV('User').property('name', '%s')).as('from')."+
-				"V().has('name', '%s').fold().coalesce(unfold(), addV('Resource').property('name', '%s')).as('to')."+
-				"addE('%s').from('from').to('to')."+
-				"property('confidence', %f)."+
-				"property('source', '%s')."+
-				"property('has_conflict', %t)."+
-				"property('timestamp', '%s')",
-			rel.From, rel.From,
-			rel.To, rel.To,
-			rel.Type,
-			rel.Confidence,
-			rel.Source,
-			rel.HasConflict,
-			rel.Timestamp,
-		)
-
-		log.Printf("Would execute Gremlin query: %s", gremlinQuery)
-	}
-
-	_ = seg.AddAnnotation("relationships_stored", len(relationships))
-	return nil
-}
-
 func countConflicts(relationships []Relationship) int {
 	count := 0
 	for _, rel := range relationships {
@@ -0,0 +1,10 @@
+package main
+
+// Plugins register their sources with the extractor registry from init; linking a plugin in is all the
+// processor needs to recognise its outputs
+import (
+	_ "bacon/src/plugins/datadog/extractor"
+	_ "bacon/src/plugins/github/extractor"
+	_ "bacon/src/plugins/oncall/extractor"
+	_ "bacon/src/plugins/openshift/extractor"
+)
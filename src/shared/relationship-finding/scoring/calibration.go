@@ -0,0 +1,97 @@
+package scoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"bacon/src/shared/graphstore"
+	"bacon/src/shared/relationship-finding/extractors"
+)
+
+// Example is a relationship a reviewer approved or rejected, with the claims the processor had scored it from
+type Example struct {
+	RelationshipID string
+	Contributions  []extractors.Contribution
+	Approved       bool
+}
+
+// Result measures how well the confidences of one strategy predicted the reviewers' verdicts
+// Lower Brier scores and log losses are better; Accuracy counts confidences on the verdict's side of 0.5
+type Result struct {
+	Strategy   string  `json:"strategy"`
+	Examples   int     `json:"examples"`
+	BrierScore float64 `json:"brier_score"`
+	LogLoss    float64 `json:"log_loss"`
+	Accuracy   float64 `json:"accuracy"`
+}
+
+// Calibrate scores every example with every strategy and compares the confidences with the verdicts
+func Calibrate(examples []Example, strategies ...Strategy) []Result {
+	results := make([]Result, 0, len(strategies))
+
+	for _, strategy := range strategies {
+		result := Result{Strategy: strategy.Name()}
+		for _, example := range examples {
+			if len(example.Contributions) == 0 {
+				continue
+			}
+			confidence := strategy.Combine(example.Contributions)
+			outcome := 0.0
+			if example.Approved {
+				outcome = 1.0
+			}
+
+			result.Examples++
+			result.BrierScore += (confidence - outcome) * (confidence - outcome)
+			result.LogLoss -= outcome*math.Log(bound(confidence)) + (1-outcome)*math.Log(1-bound(confidence))
+			if (confidence >= 0.5) == example.Approved {
+				result.Accuracy++
+			}
+		}
+		if result.Examples > 0 {
+			n := float64(result.Examples)
+			result.BrierScore /= n
+			result.LogLoss /= n
+			result.Accuracy /= n
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// LoadExamples reads the reviews recorded by the mutation API
+// Reviews of relationships the processor never explained, such as manually created ones, carry no evidence
+// and are skipped
+func LoadExamples(ctx context.Context, store graphstore.GraphStore) ([]Example, error) {
+	reviews, err := store.FindVertices(ctx, graphstore.VertexFilter{Label: graphstore.LabelReview})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reviews: %w", err)
+	}
+
+	var examples []Example
+	for _, review := range reviews {
+		encoded := review.Properties[graphstore.ReviewExplanationProperty]
+		if encoded == "" {
+			continue
+		}
+
+		var explanation extractors.Explanation
+		if err := json.Unmarshal([]byte(encoded), &explanation); err != nil {
+			return nil, fmt.Errorf("review %s: invalid explanation: %w", review.ID, err)
+		}
+		examples = append(examples, Example{
+			RelationshipID: review.Properties[graphstore.ReviewRelationshipProperty],
+			Contributions:  explanation.Sources,
+			Approved:       review.Properties[graphstore.ReviewOutcomeProperty] == graphstore.ReviewApproved,
+		})
+	}
+
+	return examples, nil
+}
+
+func bound(p float64) float64 {
+	return math.Min(math.Max(p, probabilityBound), 1-probabilityBound)
+}
@@ -0,0 +1,85 @@
+package scoring
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bacon/src/shared/graphstore"
+	"bacon/src/shared/relationship-finding/extractors"
+)
+
+func TestCalibrate(t *testing.T) {
+	examples := []Example{
+		{RelationshipID: "agreed", Approved: true, Contributions: claims(map[string]float64{"aws-tags": 0.35, "github-codeowners": 0.35, "openshift-metadata": 0.35})},
+		{RelationshipID: "lone", Approved: false, Contributions: claims(map[string]float64{"github-codeowners": 0.4})},
+		{RelationshipID: "manual", Approved: true},
+	}
+
+	results := Calibrate(examples, MaxWithBonus{Bonus: 0.1}, NoisyOR{})
+	require.Len(t, results, 2)
+
+	maxBonus, noisyOR := results[0], results[1]
+	assert.Equal(t, MaxWithBonusName, maxBonus.Strategy)
+	assert.Equal(t, 2, maxBonus.Examples, "examples without evidence are skipped")
+
+	// Three weak agreeing sources only clear 0.5 when their agreement compounds
+	assert.Equal(t, 0.5, maxBonus.Accuracy)
+	assert.Equal(t, 1.0, noisyOR.Accuracy)
+	assert.Less(t, noisyOR.BrierScore, maxBonus.BrierScore)
+	assert.Less(t, noisyOR.LogLoss, maxBonus.LogLoss)
+}
+
+func TestLoadExamples(t *testing.T) {
+	ctx := context.Background()
+	store := graphstore.NewMemoryStore()
+
+	explanation, err := json.Marshal(extractors.Explanation{Sources: claims(map[string]float64{"aws-tags": 0.7})})
+	require.NoError(t, err)
+	require.NoError(t, store.UpsertVertices(ctx, []graphstore.Vertex{
+		graphstore.ReviewVertex("edge:1", graphstore.ReviewApproved, string(explanation), "2024-05-01T09:30:00Z"),
+		graphstore.ReviewVertex("edge:2", graphstore.ReviewRejected, string(explanation), "2024-05-01T09:30:00Z"),
+		graphstore.ReviewVertex("edge:3", graphstore.ReviewApproved, "", "2024-05-01T09:30:00Z"),
+		{ID: graphstore.VertexID("payments"), Label: graphstore.LabelResource, Name: "payments"},
+	}))
+
+	examples, err := LoadExamples(ctx, store)
+	require.NoError(t, err)
+
+	byID := make(map[string]Example)
+	for _, example := range examples {
+		byID[example.RelationshipID] = example
+	}
+	require.Len(t, byID, 2)
+	assert.True(t, byID["edge:1"].Approved)
+	assert.False(t, byID["edge:2"].Approved)
+	assert.Equal(t, "aws-tags", byID["edge:1"].Contributions[0].Source)
+
+	require.NoError(t, store.UpsertVertices(ctx, []graphstore.Vertex{graphstore.ReviewVertex("edge:4", graphstore.ReviewApproved, "{", "")}))
+	_, err = LoadExamples(ctx, store)
+	assert.Error(t, err)
+}
+
+// TestCalibrateReviews reports every strategy against the reviews in the configured graph store
+// Run with CALIBRATE_REVIEWS=1 and NEPTUNE_ENDPOINT set to calibrate against production reviews
+func TestCalibrateReviews(t *testing.T) {
+	if os.Getenv("CALIBRATE_REVIEWS") == "" {
+		t.Skip("CALIBRATE_REVIEWS not set")
+	}
+
+	ctx := context.Background()
+	store, err := graphstore.Default(ctx)
+	require.NoError(t, err)
+
+	examples, err := LoadExamples(ctx, store)
+	require.NoError(t, err)
+
+	for _, result := range Calibrate(examples, MaxWithBonus{Bonus: 0.1}, NoisyOR{}, LogOdds{Prior: 0.5}) {
+		t.Logf("%-10s examples=%d brier=%.4f log_loss=%.4f accuracy=%.3f",
+			result.Strategy, result.Examples, result.BrierScore, result.LogLoss, result.Accuracy)
+	}
+}
@@ -0,0 +1,226 @@
+package scoring
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// defaultConfig is the scoring configuration shipped with the processor
+//
+//go:embed config.json
+var defaultConfig []byte
+
+// Config is a versioned scoring configuration document
+// Sources overrides the weights and priorities plugins register their sources with; ResourceTypes overrides
+// them again for relationships on one type of resource, e.g. trusting aws-tags most for rds. RelationshipTypes
+// holds the conflict rule of each relationship type.
+type Config struct {
+	Version           string                        `json:"version"`
+	Strategy          string                        `json:"strategy,omitempty"`
+	AgreementBonus    float64                       `json:"agreement_bonus"`
+	FreshnessDecay    float64                       `json:"freshness_decay"`
+	ConflictThreshold float64                       `json:"conflict_threshold"`
+	Sources           map[string]SourceConfig       `json:"sources,omitempty"`
+	ResourceTypes     map[string]ResourceTypeConfig `json:"resource_types,omitempty"`
+	RelationshipTypes map[string]ConflictRule       `json:"relationship_types,omitempty"`
+}
+
+// SourceConfig overrides the weight and conflict priority of a source; unset fields keep the registered default
+type SourceConfig struct {
+	Weight   *float64 `json:"weight,omitempty"`
+	Priority *int     `json:"priority,omitempty"`
+}
+
+// ResourceTypeConfig overrides the freshness decay and sources of one resource type
+type ResourceTypeConfig struct {
+	FreshnessDecay *float64                `json:"freshness_decay,omitempty"`
+	Sources        map[string]SourceConfig `json:"sources,omitempty"`
+}
+
+// ConflictRule decides when claims of one relationship type on the same target conflict
+// An exclusive type admits one owner per target, so different owners claimed by different sources conflict;
+// a shared type, such as maintains or deploys, admits any number. PathHierarchy compares CODEOWNERS targets
+// as paths: /src/, src and src/** are one target, while a nested path overrides its parent rather than
+// conflicting with it.
+type ConflictRule struct {
+	Exclusive     bool `json:"exclusive"`
+	PathHierarchy bool `json:"path_hierarchy,omitempty"`
+}
+
+// DefaultConfig returns the embedded configuration
+func DefaultConfig() Config {
+	config, err := ParseConfig(defaultConfig)
+	if err != nil {
+		panic(fmt.Sprintf("embedded scoring config: %v", err))
+	}
+	return config
+}
+
+// LoadConfig returns the configuration document named by the environment: SCORING_CONFIG holds the document
+// itself and SCORING_CONFIG_FILE a path to it; without either the embedded default applies
+// An invalid override is returned as an error alongside the default, so a bad push cannot stop processing
+func LoadConfig() (Config, error) {
+	data := []byte(os.Getenv("SCORING_CONFIG"))
+	if path := os.Getenv("SCORING_CONFIG_FILE"); len(data) == 0 && path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return DefaultConfig(), fmt.Errorf("failed to read scoring config: %w", err)
+		}
+	}
+	if len(data) == 0 {
+		return DefaultConfig(), nil
+	}
+
+	config, err := ParseConfig(data)
+	if err != nil {
+		return DefaultConfig(), err
+	}
+	return config, nil
+}
+
+// ParseConfig decodes and validates a configuration document
+func ParseConfig(data []byte) (Config, error) {
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return Config{}, fmt.Errorf("invalid scoring config: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid scoring config %s: %w", config.Version, err)
+	}
+	return config, nil
+}
+
+// Validate reports every value out of range, and a missing version
+func (c Config) Validate() error {
+	var errs []error
+	if c.Version == "" {
+		errs = append(errs, errors.New("version: missing"))
+	}
+	if _, err := ByName(c.Strategy, c.AgreementBonus); err != nil {
+		errs = append(errs, fmt.Errorf("strategy: %w", err))
+	}
+	if c.AgreementBonus < 0 || c.AgreementBonus > 1 {
+		errs = append(errs, fmt.Errorf("agreement_bonus: %v is outside [0, 1]", c.AgreementBonus))
+	}
+	if c.FreshnessDecay < 0 {
+		errs = append(errs, fmt.Errorf("freshness_decay: %v is negative", c.FreshnessDecay))
+	}
+	if c.ConflictThreshold < 0 || c.ConflictThreshold > 1 {
+		errs = append(errs, fmt.Errorf("conflict_threshold: %v is outside [0, 1]", c.ConflictThreshold))
+	}
+	errs = append(errs, validateSources("sources", c.Sources)...)
+	for resourceType, override := range c.ResourceTypes {
+		if override.FreshnessDecay != nil && *override.FreshnessDecay < 0 {
+			errs = append(errs, fmt.Errorf("resource_types.%s.freshness_decay: %v is negative", resourceType, *override.FreshnessDecay))
+		}
+		errs = append(errs, validateSources("resource_types."+resourceType+".sources", override.Sources)...)
+	}
+	for relationshipType, rule := range c.RelationshipTypes {
+		if rule.PathHierarchy && !rule.Exclusive {
+			errs = append(errs, fmt.Errorf("relationship_types.%s.path_hierarchy: shared types never conflict", relationshipType))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validateSources(path string, sources map[string]SourceConfig) []error {
+	var errs []error
+	for source, override := range sources {
+		if override.Weight != nil && (*override.Weight < 0 || *override.Weight > 1) {
+			errs = append(errs, fmt.Errorf("%s.%s.weight: %v is outside [0, 1]", path, source, *override.Weight))
+		}
+		if override.Priority != nil && *override.Priority < 1 {
+			errs = append(errs, fmt.Errorf("%s.%s.priority: %d is below 1", path, source, *override.Priority))
+		}
+	}
+	return errs
+}
+
+// Weights returns the registered source weights with the configured overrides applied
+func (c Config) Weights(registered map[string]float64) map[string]float64 {
+	weights := make(map[string]float64, len(registered))
+	for source, weight := range registered {
+		weights[source] = weight
+	}
+	for source, override := range c.Sources {
+		if override.Weight != nil {
+			weights[source] = *override.Weight
+		}
+	}
+	return weights
+}
+
+// Priorities returns the registered source priorities with the configured overrides applied
+func (c Config) Priorities(registered map[string]int) map[string]int {
+	priorities := make(map[string]int, len(registered))
+	for source, priority := range registered {
+		priorities[source] = priority
+	}
+	for source, override := range c.Sources {
+		if override.Priority != nil {
+			priorities[source] = *override.Priority
+		}
+	}
+	return priorities
+}
+
+// ResourceTypeWeights returns the source weights each resource type overrides
+func (c Config) ResourceTypeWeights() map[string]map[string]float64 {
+	overrides := make(map[string]map[string]float64)
+	for resourceType, override := range c.ResourceTypes {
+		for source, sourceOverride := range override.Sources {
+			if sourceOverride.Weight == nil {
+				continue
+			}
+			if overrides[resourceType] == nil {
+				overrides[resourceType] = make(map[string]float64)
+			}
+			overrides[resourceType][source] = *sourceOverride.Weight
+		}
+	}
+	return overrides
+}
+
+// ResourceTypePriorities returns the source priorities each resource type overrides
+func (c Config) ResourceTypePriorities() map[string]map[string]int {
+	overrides := make(map[string]map[string]int)
+	for resourceType, override := range c.ResourceTypes {
+		for source, sourceOverride := range override.Sources {
+			if sourceOverride.Priority == nil {
+				continue
+			}
+			if overrides[resourceType] == nil {
+				overrides[resourceType] = make(map[string]int)
+			}
+			overrides[resourceType][source] = *sourceOverride.Priority
+		}
+	}
+	return overrides
+}
+
+// ResourceTypeDecay returns the freshness decay each resource type overrides
+func (c Config) ResourceTypeDecay() map[string]float64 {
+	overrides := make(map[string]float64)
+	for resourceType, override := range c.ResourceTypes {
+		if override.FreshnessDecay != nil {
+			overrides[resourceType] = *override.FreshnessDecay
+		}
+	}
+	return overrides
+}
+
+// ConflictRules returns the conflict rule of each relationship type; types the document leaves out keep the
+// rules of the embedded config, and types neither names are shared
+func (c Config) ConflictRules() map[string]ConflictRule {
+	rules := make(map[string]ConflictRule)
+	for relationshipType, rule := range DefaultConfig().RelationshipTypes {
+		rules[relationshipType] = rule
+	}
+	for relationshipType, rule := range c.RelationshipTypes {
+		rules[relationshipType] = rule
+	}
+	return rules
+}
@@ -0,0 +1,116 @@
+package scoring
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultConfig(t *testing.T) {
+	config := DefaultConfig()
+
+	assert.NotEmpty(t, config.Version)
+	assert.NoError(t, config.Validate())
+	assert.Equal(t, 1.0, config.ResourceTypeWeights()["rds"]["aws-tags"])
+	assert.Equal(t, 1, config.ResourceTypePriorities()["repository"]["github-codeowners"])
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("embedded default", func(t *testing.T) {
+		config, err := LoadConfig()
+		require.NoError(t, err)
+		assert.Equal(t, DefaultConfig().Version, config.Version)
+	})
+
+	t.Run("inline document", func(t *testing.T) {
+		t.Setenv("SCORING_CONFIG", `{"version": "tuned-7", "agreement_bonus": 0.2, "freshness_decay": 0.01, "conflict_threshold": 0.3}`)
+
+		config, err := LoadConfig()
+		require.NoError(t, err)
+		assert.Equal(t, "tuned-7", config.Version)
+		assert.Equal(t, 0.2, config.AgreementBonus)
+	})
+
+	t.Run("document file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "scoring.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"version": "file-1", "freshness_decay": 0.02}`), 0o600))
+		t.Setenv("SCORING_CONFIG_FILE", path)
+
+		config, err := LoadConfig()
+		require.NoError(t, err)
+		assert.Equal(t, "file-1", config.Version)
+	})
+
+	t.Run("invalid document keeps the default", func(t *testing.T) {
+		t.Setenv("SCORING_CONFIG", `{"agreement_bonus": 2}`)
+
+		config, err := LoadConfig()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "version: missing")
+		assert.Contains(t, err.Error(), "agreement_bonus")
+		assert.Equal(t, DefaultConfig().Version, config.Version)
+	})
+
+	t.Run("missing file keeps the default", func(t *testing.T) {
+		t.Setenv("SCORING_CONFIG_FILE", filepath.Join(t.TempDir(), "missing.json"))
+
+		config, err := LoadConfig()
+		assert.Error(t, err)
+		assert.Equal(t, DefaultConfig().Version, config.Version)
+	})
+}
+
+func TestConfig_Validate(t *testing.T) {
+	_, err := ParseConfig([]byte(`{
+		"version": "bad",
+		"strategy": "bayes",
+		"freshness_decay": -1,
+		"sources": {"aws-tags": {"weight": 1.5, "priority": 0}},
+		"resource_types": {"rds": {"freshness_decay": -0.1, "sources": {"aws-tags": {"weight": -1}}}},
+		"relationship_types": {"deploys": {"path_hierarchy": true}}
+	}`))
+
+	require.Error(t, err)
+	for _, field := range []string{"strategy", "freshness_decay", "sources.aws-tags.weight", "sources.aws-tags.priority", "resource_types.rds.freshness_decay", "resource_types.rds.sources.aws-tags.weight", "relationship_types.deploys.path_hierarchy"} {
+		assert.Contains(t, err.Error(), field)
+	}
+
+	_, err = ParseConfig([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestConfig_Overrides(t *testing.T) {
+	config, err := ParseConfig([]byte(`{
+		"version": "2",
+		"sources": {"aws-tags": {"weight": 0.6}, "github-codeowners": {"priority": 1}},
+		"resource_types": {"deployment": {"freshness_decay": 0.2, "sources": {"openshift-metadata": {"priority": 1}}}}
+	}`))
+	require.NoError(t, err)
+
+	registeredWeights := map[string]float64{"aws-tags": 0.9, "github-codeowners": 0.8}
+	assert.Equal(t, map[string]float64{"aws-tags": 0.6, "github-codeowners": 0.8}, config.Weights(registeredWeights))
+	assert.Equal(t, 0.9, registeredWeights["aws-tags"], "registered weights are not modified")
+
+	assert.Equal(t, map[string]int{"aws-tags": 1, "github-codeowners": 1}, config.Priorities(map[string]int{"aws-tags": 1, "github-codeowners": 3}))
+	assert.Equal(t, map[string]float64{"deployment": 0.2}, config.ResourceTypeDecay())
+	assert.Equal(t, map[string]map[string]int{"deployment": {"openshift-metadata": 1}}, config.ResourceTypePriorities())
+	assert.Empty(t, config.ResourceTypeWeights())
+}
+
+func TestConfig_ConflictRules(t *testing.T) {
+	rules := DefaultConfig().ConflictRules()
+	assert.Equal(t, ConflictRule{Exclusive: true, PathHierarchy: true}, rules["owns"])
+	assert.False(t, rules["maintains"].Exclusive)
+	assert.False(t, rules["deploys"].Exclusive)
+
+	// A document only overrides the types it names
+	config, err := ParseConfig([]byte(`{"version": "3", "relationship_types": {"owns": {"exclusive": false}, "deploys": {"exclusive": true}}}`))
+	require.NoError(t, err)
+	rules = config.ConflictRules()
+	assert.False(t, rules["owns"].Exclusive)
+	assert.True(t, rules["deploys"].Exclusive)
+	assert.True(t, rules["manages"].Exclusive)
+}
@@ -0,0 +1,120 @@
+// Package scoring combines the claims several sources make about one relationship into a single confidence.
+// Each claim arrives already weighted by its source and decayed by its age; a Strategy decides how much
+// agreement between sources adds on top of the strongest claim.
+package scoring
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"bacon/src/shared/relationship-finding/extractors"
+)
+
+// Strategy names accepted by ByName
+const (
+	MaxWithBonusName = "max_bonus"
+	NoisyORName      = "noisy_or"
+	LogOddsName      = "log_odds"
+)
+
+// probabilityBound keeps log-odds finite for claims scored at exactly 0 or 1
+const probabilityBound = 1e-6
+
+// Strategy combines the scored claims of every source asserting the same relationship
+type Strategy interface {
+	Name() string
+	Combine(contributions []extractors.Contribution) float64
+}
+
+// ByName returns the strategy registered under name; bonus is the agreement bonus of max_bonus
+func ByName(name string, bonus float64) (Strategy, error) {
+	switch name {
+	case MaxWithBonusName, "":
+		return MaxWithBonus{Bonus: bonus}, nil
+	case NoisyORName:
+		return NoisyOR{}, nil
+	case LogOddsName:
+		return LogOdds{Prior: 0.5}, nil
+	}
+	return nil, fmt.Errorf("unknown scoring strategy %q", name)
+}
+
+// MaxWithBonus keeps the strongest claim and adds a flat bonus when any other source agrees
+// Two agreeing sources score the same as ten
+type MaxWithBonus struct {
+	Bonus float64
+}
+
+func (MaxWithBonus) Name() string { return MaxWithBonusName }
+
+func (s MaxWithBonus) Combine(contributions []extractors.Contribution) float64 {
+	best := 0.0
+	for _, contribution := range contributions {
+		best = math.Max(best, contribution.Confidence)
+	}
+	if len(contributions) > 1 {
+		best += s.Bonus
+	}
+	return math.Min(best, 1.0)
+}
+
+// NoisyOR treats every independent source as an independent chance of the relationship being true:
+// the relationship is wrong only if every source is wrong
+// Sources of one family, such as the Datadog catalog and its change feed, share their evidence and
+// count once, with their strongest claim
+type NoisyOR struct{}
+
+func (NoisyOR) Name() string { return NoisyORName }
+
+func (NoisyOR) Combine(contributions []extractors.Contribution) float64 {
+	disbelief := 1.0
+	for _, confidence := range strongestByFamily(contributions) {
+		disbelief *= 1 - math.Min(math.Max(confidence, 0), 1)
+	}
+	return 1 - disbelief
+}
+
+// LogOdds adds up the evidence of independent source families in log-odds space relative to a prior, so
+// claims below the prior count against the relationship rather than merely adding less
+type LogOdds struct {
+	Prior float64
+}
+
+func (LogOdds) Name() string { return LogOddsName }
+
+func (s LogOdds) Combine(contributions []extractors.Contribution) float64 {
+	if s.Prior <= 0 || s.Prior >= 1 {
+		s.Prior = 0.5
+	}
+	prior := logit(s.Prior)
+	evidence := prior
+	for _, confidence := range strongestByFamily(contributions) {
+		evidence += logit(confidence) - prior
+	}
+	return 1 / (1 + math.Exp(-evidence))
+}
+
+// SourceFamily is the system a source reads from, the part of its name before the first dash,
+// e.g. datadog for datadog-apm
+func SourceFamily(source string) string {
+	family, _, _ := strings.Cut(source, "-")
+	return family
+}
+
+// strongestByFamily returns the strongest claim of every source family
+func strongestByFamily(contributions []extractors.Contribution) map[string]float64 {
+	strongest := make(map[string]float64)
+	for _, contribution := range contributions {
+		family := SourceFamily(contribution.Source)
+		if confidence, found := strongest[family]; !found || contribution.Confidence > confidence {
+			strongest[family] = contribution.Confidence
+		}
+	}
+	return strongest
+}
+
+func logit(p float64) float64 {
+	p = math.Min(math.Max(p, probabilityBound), 1-probabilityBound)
+	return math.Log(p / (1 - p))
+}
@@ -0,0 +1,72 @@
+package scoring
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bacon/src/shared/relationship-finding/extractors"
+)
+
+func claims(confidences map[string]float64) []extractors.Contribution {
+	var contributions []extractors.Contribution
+	for source, confidence := range confidences {
+		contributions = append(contributions, extractors.Contribution{Source: source, Confidence: confidence})
+	}
+	return contributions
+}
+
+func TestMaxWithBonus(t *testing.T) {
+	strategy := MaxWithBonus{Bonus: 0.1}
+
+	assert.InDelta(t, 0.6, strategy.Combine(claims(map[string]float64{"aws-tags": 0.6})), 1e-9)
+	assert.InDelta(t, 0.7, strategy.Combine(claims(map[string]float64{"aws-tags": 0.6, "github-codeowners": 0.4})), 1e-9)
+	assert.Equal(t, 1.0, strategy.Combine(claims(map[string]float64{"aws-tags": 0.95, "github-codeowners": 0.9})))
+}
+
+func TestNoisyOR(t *testing.T) {
+	strategy := NoisyOR{}
+
+	assert.InDelta(t, 0.6, strategy.Combine(claims(map[string]float64{"aws-tags": 0.6})), 1e-9)
+	assert.InDelta(t, 0.875, strategy.Combine(claims(map[string]float64{"aws-tags": 0.5, "github-codeowners": 0.5, "openshift-metadata": 0.5})), 1e-9)
+
+	// The Datadog catalog and its change feed are one family and count once
+	assert.InDelta(t, 0.75, strategy.Combine(claims(map[string]float64{
+		"datadog-service-catalog": 0.5,
+		"datadog-changes":         0.4,
+		"aws-tags":                0.5,
+	})), 1e-9)
+}
+
+func TestLogOdds(t *testing.T) {
+	strategy := LogOdds{Prior: 0.5}
+
+	assert.InDelta(t, 0.8, strategy.Combine(claims(map[string]float64{"aws-tags": 0.8})), 1e-9)
+	assert.InDelta(t, 0.5, strategy.Combine(claims(map[string]float64{"aws-tags": 0.8, "github-codeowners": 0.2})), 1e-9)
+	assert.Greater(t, strategy.Combine(claims(map[string]float64{"aws-tags": 0.8, "github-codeowners": 0.8})), 0.9)
+
+	// Claims of 0 and 1 stay finite, and a zero prior falls back to even odds
+	assert.Less(t, strategy.Combine(claims(map[string]float64{"aws-tags": 1})), 1.0)
+	assert.InDelta(t, 0.8, LogOdds{}.Combine(claims(map[string]float64{"aws-tags": 0.8})), 1e-9)
+}
+
+func TestByName(t *testing.T) {
+	for _, name := range []string{MaxWithBonusName, NoisyORName, LogOddsName} {
+		strategy, err := ByName(name, 0.1)
+		require.NoError(t, err)
+		assert.Equal(t, name, strategy.Name())
+	}
+
+	strategy, err := ByName("", 0.2)
+	require.NoError(t, err)
+	assert.Equal(t, MaxWithBonus{Bonus: 0.2}, strategy)
+
+	_, err = ByName("bayes", 0.1)
+	assert.Error(t, err)
+}
+
+func TestSourceFamily(t *testing.T) {
+	assert.Equal(t, "datadog", SourceFamily("datadog-service-catalog"))
+	assert.Equal(t, "manual", SourceFamily("manual"))
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-xray-sdk-go/v2/xray"
+
+	"bacon/src/shared/graphstore"
+	"bacon/src/shared/relationship-finding/identity"
+)
+
+// Only Datadog edges are removed by deletions and retractions; other sources still vouch for their own edges
+const datadogSourcePrefix = "datadog-"
+
+// confidenceExplanationProperty holds the JSON explanation of an edge's confidence, read back by the resolvers
+const confidenceExplanationProperty = "confidence_explanation"
+
+// newGraphStore returns Neptune when NEPTUNE_ENDPOINT is set and the process-local in-memory graph otherwise
+// It is a variable so tests can run the handler against their own store
+var newGraphStore = graphstore.Default
+
+func storeRelationships(ctx context.Context, store graphstore.GraphStore, relationships []Relationship) error {
+	ctx, seg := xray.BeginSubsegment(ctx, "store-relationships")
+	defer seg.Close(nil)
+
+	log.Printf("Storing %d relationships", len(relationships))
+
+	vertices, edges := relationshipElements(relationships)
+	if err := store.UpsertVertices(ctx, vertices); err != nil {
+		_ = seg.AddError(err)
+		return err
+	}
+	if err := store.UpsertEdges(ctx, edges); err != nil {
+		_ = seg.AddError(err)
+		return err
+	}
+
+	_ = seg.AddAnnotation("relationships_stored", len(relationships))
+	_ = seg.AddAnnotation("vertices_upserted", len(vertices))
+	return nil
+}
+
+// relationshipElements maps relationships onto the endpoints they connect, each listed once, and their edges
+// Vertices and edges are keyed by stable IDs, so reprocessing the same evidence updates rather than duplicates
+func relationshipElements(relationships []Relationship) ([]graphstore.Vertex, []graphstore.Edge) {
+	var vertices []graphstore.Vertex
+	seen := make(map[string]bool)
+
+	addVertex := func(name, label string) {
+		id := graphstore.VertexID(name)
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		vertices = append(vertices, graphstore.Vertex{ID: id, Label: label, Name: name})
+	}
+
+	edges := make([]graphstore.Edge, 0, len(relationships))
+	for _, rel := range relationships {
+		addVertex(rel.From, graphstore.LabelUser)
+		addVertex(rel.To, graphstore.LabelResource)
+		edges = append(edges, graphstore.Edge{
+			ID:          relationshipID(rel),
+			Label:       rel.Type,
+			From:        graphstore.VertexID(rel.From),
+			To:          graphstore.VertexID(rel.To),
+			Confidence:  rel.Confidence,
+			Source:      rel.Source,
+			HasConflict: rel.HasConflict,
+			Timestamp:   rel.Timestamp,
+			Properties:  edgeProperties(rel),
+		})
+	}
+
+	return vertices, edges
+}
+
+// relationshipID is the ID of the edge a relationship is stored as
+func relationshipID(rel Relationship) string {
+	return graphstore.EdgeID(rel.From, rel.Type, rel.To, rel.Source)
+}
+
+// edgeProperties records on an edge the raw names its endpoints were resolved from, and how, the
+// explanation of its confidence with the version of the scoring config it was scored under, and whether it was
+// superseded or conflicted
+func edgeProperties(rel Relationship) map[string]string {
+	properties := make(map[string]string)
+	for prefix, match := range map[string]*identity.Match{"from": rel.FromIdentity, "to": rel.ToIdentity} {
+		if match == nil {
+			continue
+		}
+		properties[prefix+"_raw"] = match.Raw
+		properties[prefix+"_rule"] = match.Rule
+		if match.Source != "" {
+			properties[prefix+"_alias_source"] = match.Source
+		}
+	}
+	if rel.Explanation != nil {
+		if explanation, err := json.Marshal(rel.Explanation); err == nil {
+			properties[confidenceExplanationProperty] = string(explanation)
+		}
+	}
+	if rel.ScoringConfigVersion != "" {
+		properties["scoring_config_version"] = rel.ScoringConfigVersion
+	}
+	// Upserts cannot remove the properties of an earlier run, so whether the edge is superseded is written on
+	// every edge, and the edges it was overruled by or conflicts with on every superseded or conflicted one
+	properties[graphstore.SupersededProperty] = strconv.FormatBool(rel.Superseded)
+	if rel.HasConflict || rel.Superseded {
+		if conflictsWith, err := json.Marshal(rel.ConflictsWith); err == nil {
+			properties[graphstore.ConflictsWithProperty] = string(conflictsWith)
+		}
+		if rel.ConflictID != "" {
+			properties[graphstore.ConflictProperty] = rel.ConflictID
+		}
+	}
+	return properties
+}
+
+// removeRelationships archives the Datadog edges of deleted entities and retracted relationships
+func removeRelationships(ctx context.Context, store graphstore.GraphStore, deletedEntities []string, retracted []Relationship, now time.Time) (int, error) {
+	ctx, seg := xray.BeginSubsegment(ctx, "remove-relationships")
+	defer seg.Close(nil)
+
+	var filters []graphstore.EdgeFilter
+	var reasons []string
+	for _, entity := range deletedEntities {
+		filters = append(filters, graphstore.EdgeFilter{
+			Vertex:       graphstore.VertexID(entity),
+			SourcePrefix: datadogSourcePrefix,
+		})
+		reasons = append(reasons, graphstore.ArchiveDeleted)
+	}
+	for _, rel := range retracted {
+		filters = append(filters, graphstore.EdgeFilter{
+			From:         graphstore.VertexID(rel.From),
+			Label:        rel.Type,
+			To:           graphstore.VertexID(rel.To),
+			SourcePrefix: datadogSourcePrefix,
+		})
+		reasons = append(reasons, graphstore.ArchiveRetracted)
+	}
+
+	archivedAt := now.UTC().Format(time.RFC3339)
+	var records []graphstore.Vertex
+	seen := make(map[string]bool)
+	for i, filter := range filters {
+		edges, err := store.FindEdges(ctx, filter)
+		if err != nil {
+			_ = seg.AddError(err)
+			return 0, err
+		}
+		for _, edge := range edges {
+			if !seen[edge.ID] {
+				seen[edge.ID] = true
+				records = append(records, graphstore.ArchiveVertex(edge, reasons[i], "", archivedAt))
+			}
+		}
+	}
+
+	if err := archiveEdges(ctx, store, records, filters); err != nil {
+		_ = seg.AddError(err)
+		return 0, err
+	}
+
+	_ = seg.AddAnnotation("entities_removed", len(deletedEntities))
+	_ = seg.AddAnnotation("edges_retracted", len(retracted))
+	_ = seg.AddAnnotation("edges_archived", len(records))
+	return len(records), nil
+}
+
+// archiveWithdrawnRelationships archives the edges of the claims their sources' snapshots no longer contain,
+// which no evidence is left for
+func archiveWithdrawnRelationships(ctx context.Context, store graphstore.GraphStore, withdrawn []withdrawal, now time.Time) (int, error) {
+	ctx, seg := xray.BeginSubsegment(ctx, "archive-withdrawn-relationships")
+	defer seg.Close(nil)
+
+	archivedAt := now.UTC().Format(time.RFC3339)
+	var records []graphstore.Vertex
+	var filters []graphstore.EdgeFilter
+	for _, w := range withdrawn {
+		edge, found, err := store.GetEdge(ctx, relationshipID(w.Evidence.Claim))
+		if err != nil {
+			_ = seg.AddError(err)
+			return 0, err
+		}
+		if !found {
+			continue
+		}
+		records = append(records, graphstore.ArchiveVertex(edge, graphstore.ArchiveRetracted, w.Snapshot, archivedAt))
+		filters = append(filters, graphstore.EdgeFilter{ID: edge.ID})
+	}
+
+	if err := archiveEdges(ctx, store, records, filters); err != nil {
+		_ = seg.AddError(err)
+		return 0, err
+	}
+
+	_ = seg.AddAnnotation("edges_archived", len(records))
+	return len(records), nil
+}
+
+// archiveMergedRelationships archives the edges other sources stored for the relationships, which the claims of
+// every source are merged into; a relationship is stored under the source of its strongest claim, so the edge of
+// an earlier strongest source would otherwise stay behind once a stronger one makes the same claim
+func archiveMergedRelationships(ctx context.Context, store graphstore.GraphStore, relationships []Relationship, now time.Time) (int, error) {
+	ctx, seg := xray.BeginSubsegment(ctx, "archive-merged-relationships")
+	defer seg.Close(nil)
+
+	archivedAt := now.UTC().Format(time.RFC3339)
+	var records []graphstore.Vertex
+	var filters []graphstore.EdgeFilter
+	for _, rel := range relationships {
+		edges, err := store.FindEdges(ctx, graphstore.EdgeFilter{
+			From:  graphstore.VertexID(rel.From),
+			Label: rel.Type,
+			To:    graphstore.VertexID(rel.To),
+		})
+		if err != nil {
+			_ = seg.AddError(err)
+			return 0, err
+		}
+		for _, edge := range edges {
+			if edge.ID != relationshipID(rel) {
+				records = append(records, graphstore.ArchiveVertex(edge, graphstore.ArchiveMerged, "", archivedAt))
+				filters = append(filters, graphstore.EdgeFilter{ID: edge.ID})
+			}
+		}
+	}
+
+	if err := archiveEdges(ctx, store, records, filters); err != nil {
+		_ = seg.AddError(err)
+		return 0, err
+	}
+
+	_ = seg.AddAnnotation("edges_archived", len(records))
+	return len(records), nil
+}
+
+// archiveRenamedRelationships archives the edges of stored claims whose endpoints now resolve to other identities,
+// unless a current relationship is still stored between the old names
+func archiveRenamedRelationships(ctx context.Context, store graphstore.GraphStore, renamed, current []Relationship, now time.Time) (int, error) {
+	ctx, seg := xray.BeginSubsegment(ctx, "archive-renamed-relationships")
+	defer seg.Close(nil)
+
+	stored := make(map[graphstore.EdgeFilter]bool, len(current))
+	for _, rel := range current {
+		stored[graphstore.EdgeFilter{From: graphstore.VertexID(rel.From), Label: rel.Type, To: graphstore.VertexID(rel.To)}] = true
+	}
+
+	archivedAt := now.UTC().Format(time.RFC3339)
+	var records []graphstore.Vertex
+	var filters []graphstore.EdgeFilter
+	for _, rel := range renamed {
+		filter := graphstore.EdgeFilter{From: graphstore.VertexID(rel.From), Label: rel.Type, To: graphstore.VertexID(rel.To)}
+		if stored[filter] {
+			continue
+		}
+		stored[filter] = true
+
+		edges, err := store.FindEdges(ctx, filter)
+		if err != nil {
+			_ = seg.AddError(err)
+			return 0, err
+		}
+		for _, edge := range edges {
+			records = append(records, graphstore.ArchiveVertex(edge, graphstore.ArchiveResolved, "", archivedAt))
+			filters = append(filters, graphstore.EdgeFilter{ID: edge.ID})
+		}
+	}
+
+	if err := archiveEdges(ctx, store, records, filters); err != nil {
+		_ = seg.AddError(err)
+		return 0, err
+	}
+
+	_ = seg.AddAnnotation("edges_archived", len(records))
+	return len(records), nil
+}
+
+// archiveEdges writes the archive records before deleting the edges, so no edge leaves the graph unrecorded
+func archiveEdges(ctx context.Context, store graphstore.GraphStore, records []graphstore.Vertex, filters []graphstore.EdgeFilter) error {
+	if len(filters) == 0 {
+		return nil
+	}
+	if len(records) > 0 {
+		if err := store.UpsertVertices(ctx, records); err != nil {
+			return err
+		}
+	}
+	return store.DeleteEdges(ctx, filters...)
+}
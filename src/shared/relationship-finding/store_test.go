@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	common "bacon/src/shared"
+	"bacon/src/shared/graphstore"
+	"bacon/src/shared/relationship-finding/extractors"
+)
+
+// failingStore is a graph store whose writes always fail
+type failingStore struct {
+	*graphstore.MemoryStore
+	err error
+}
+
+func (f failingStore) UpsertVertices(context.Context, []graphstore.Vertex) error {
+	return f.err
+}
+
+// useGraphStore points the handler at the given store for the duration of a test
+func useGraphStore(t *testing.T, store graphstore.GraphStore) {
+	previous := newGraphStore
+	newGraphStore = func(context.Context) (graphstore.GraphStore, error) { return store, nil }
+	t.Cleanup(func() { newGraphStore = previous })
+}
+
+// Test relationships are stored with their endpoints, keyed by stable IDs
+func TestStoreRelationships_Elements(t *testing.T) {
+	ctx, cleanup := common.TestContext("store-relationships-elements-test")
+	defer cleanup()
+
+	store := graphstore.NewMemoryStore()
+	relationships := []Relationship{
+		{From: "o'brien", To: "payments", Type: "owns", Source: "github-codeowners", Confidence: 0.8, Timestamp: "2024-05-01T09:30:00Z"},
+		{From: "o'brien", To: "checkout", Type: "owns", Source: "aws-tags", Confidence: 0.9, HasConflict: true},
+	}
+
+	// Storing twice updates rather than duplicates
+	for i := 0; i < 2; i++ {
+		if err := storeRelationships(ctx, store, relationships); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	owner, found, _ := store.GetVertex(ctx, graphstore.VertexID("o'brien"))
+	if !found || owner.Label != graphstore.LabelUser || owner.Name != "o'brien" {
+		t.Errorf("Expected the owner to be stored as a named user, got %+v (found %v)", owner, found)
+	}
+	resource, found, _ := store.GetVertex(ctx, graphstore.VertexID("payments"))
+	if !found || resource.Label != graphstore.LabelResource {
+		t.Errorf("Expected the resource to be stored, got %+v (found %v)", resource, found)
+	}
+
+	edges, _ := store.FindEdges(ctx, graphstore.EdgeFilter{})
+	if len(edges) != 2 {
+		t.Fatalf("Expected 2 edges, got %d", len(edges))
+	}
+
+	edge, found, _ := store.GetEdge(ctx, graphstore.EdgeID("o'brien", "owns", "payments", "github-codeowners"))
+	if !found {
+		t.Fatal("Expected the edge to be keyed by its stable ID")
+	}
+	if edge.Confidence != 0.8 || edge.Source != "github-codeowners" || edge.Timestamp != "2024-05-01T09:30:00Z" {
+		t.Errorf("Unexpected edge %+v", edge)
+	}
+
+	conflicted, _ := store.FindEdges(ctx, graphstore.EdgeFilter{ConflictOnly: true})
+	if len(conflicted) != 1 || conflicted[0].To != graphstore.VertexID("checkout") {
+		t.Errorf("Expected the conflicted edge to be flagged, got %+v", conflicted)
+	}
+}
+
+// Test confidence explanations and the scoring config version are persisted as edge metadata
+func TestStoreRelationships_Explanation(t *testing.T) {
+	ctx, cleanup := common.TestContext("store-relationships-explanation-test")
+	defer cleanup()
+
+	store := graphstore.NewMemoryStore()
+	explanation := &extractors.Explanation{
+		Confidence:     0.62,
+		BaseConfidence: 0.8,
+		SourceWeight:   0.8,
+		Sources:        []extractors.Contribution{{Source: "github-codeowners", Confidence: 0.62}},
+	}
+	rel := Relationship{From: "team-a", To: "repo", Type: "owns", Source: "github-codeowners", Confidence: 0.62, Explanation: explanation, ScoringConfigVersion: "2024-06-01.1"}
+
+	if err := storeRelationships(ctx, store, []Relationship{rel}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	edge, _, _ := store.GetEdge(ctx, graphstore.EdgeID("team-a", "owns", "repo", "github-codeowners"))
+	var stored extractors.Explanation
+	if err := json.Unmarshal([]byte(edge.Properties[confidenceExplanationProperty]), &stored); err != nil {
+		t.Fatalf("Expected a JSON explanation on the edge, got %v: %v", edge.Properties, err)
+	}
+	if !reflect.DeepEqual(&stored, explanation) {
+		t.Errorf("Expected %+v, got %+v", explanation, stored)
+	}
+	if edge.Properties["scoring_config_version"] != "2024-06-01.1" {
+		t.Errorf("Expected the scoring config version on the edge, got %v", edge.Properties)
+	}
+}
+
+// Test conflicted edges record the edges they conflict with and which side lost
+func TestStoreRelationships_Conflicts(t *testing.T) {
+	ctx, cleanup := common.TestContext("store-relationships-conflicts-test")
+	defer cleanup()
+
+	store := graphstore.NewMemoryStore()
+	winner := Relationship{From: "team-a", To: "repo", Type: "owns", Source: "github-codeowners", HasConflict: true}
+	loser := Relationship{From: "team-b", To: "repo", Type: "owns", Source: "aws-tags", HasConflict: true, Superseded: true}
+	winner.ConflictsWith = []string{relationshipID(loser)}
+	loser.ConflictsWith = []string{relationshipID(winner)}
+
+	if err := storeRelationships(ctx, store, []Relationship{winner, loser}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, rel := range []Relationship{winner, loser} {
+		edge, _, _ := store.GetEdge(ctx, relationshipID(rel))
+		var conflictsWith []string
+		if err := json.Unmarshal([]byte(edge.Properties[graphstore.ConflictsWithProperty]), &conflictsWith); err != nil {
+			t.Fatalf("Expected the conflicting edge IDs on the edge, got %v: %v", edge.Properties, err)
+		}
+		if !reflect.DeepEqual(conflictsWith, rel.ConflictsWith) {
+			t.Errorf("Expected %v, got %v", rel.ConflictsWith, conflictsWith)
+		}
+		if edge.Properties[graphstore.SupersededProperty] != strconv.FormatBool(rel.Superseded) {
+			t.Errorf("Expected superseded %t on %s, got %v", rel.Superseded, rel.From, edge.Properties)
+		}
+	}
+}
+
+// Test a claim superseded below the conflict threshold is marked without being flagged, and unmarked once it is not
+func TestStoreRelationships_Superseded(t *testing.T) {
+	ctx, cleanup := common.TestContext("store-relationships-superseded-test")
+	defer cleanup()
+
+	store := graphstore.NewMemoryStore()
+	winner := Relationship{From: "team-a", To: "repo", Type: "owns", Source: "aws-tags"}
+	loser := Relationship{From: "team-b", To: "repo", Type: "owns", Source: "github-codeowners", Superseded: true, ConflictsWith: []string{relationshipID(winner)}}
+
+	if err := storeRelationships(ctx, store, []Relationship{winner, loser}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	edge, _, _ := store.GetEdge(ctx, relationshipID(loser))
+	if edge.HasConflict || edge.Properties[graphstore.SupersededProperty] != "true" ||
+		edge.Properties[graphstore.ConflictsWithProperty] != `["`+relationshipID(winner)+`"]` {
+		t.Errorf("Expected the superseded markers without a conflict, got %+v", edge)
+	}
+	if conflicted, _ := store.FindEdges(ctx, graphstore.EdgeFilter{ConflictOnly: true}); len(conflicted) != 0 {
+		t.Errorf("Expected no conflicted edges, got %+v", conflicted)
+	}
+
+	// Upserts keep earlier properties, so the marker is overwritten rather than left behind
+	loser.Superseded = false
+	if err := storeRelationships(ctx, store, []Relationship{loser}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	edge, _, _ = store.GetEdge(ctx, relationshipID(loser))
+	if edge.Properties[graphstore.SupersededProperty] != "false" {
+		t.Errorf("Expected the edge to no longer be superseded, got %v", edge.Properties)
+	}
+}
+
+func TestStoreRelationships_StoreError(t *testing.T) {
+	ctx, cleanup := common.TestContext("store-relationships-error-test")
+	defer cleanup()
+
+	store := failingStore{MemoryStore: graphstore.NewMemoryStore(), err: errors.New("connection refused")}
+	err := storeRelationships(ctx, store, []Relationship{{From: "team-a", To: "repo", Type: "owns", Source: "aws-tags"}})
+
+	if err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("Expected the store error to be returned, got %v", err)
+	}
+}
+
+// Test deletions and retractions only remove Datadog edges
+func TestRemoveRelationships(t *testing.T) {
+	ctx, cleanup := common.TestContext("remove-relationships-test")
+	defer cleanup()
+
+	store := graphstore.NewMemoryStore()
+	err := storeRelationships(ctx, store, []Relationship{
+		{From: "team-a", To: "payments", Type: "owns", Source: "datadog-service-catalog"},
+		{From: "team-a", To: "payments", Type: "owns", Source: "aws-tags"},
+		{From: "ana@example.com", To: "platform", Type: "member_of", Source: "datadog-teams"},
+		{From: "ana@example.com", To: "platform", Type: "owns", Source: "datadog-teams"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	archived, err := removeRelationships(ctx, store,
+		[]string{"payments"},
+		[]Relationship{{From: "ana@example.com", To: "platform", Type: "member_of"}},
+		time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	edges, _ := store.FindEdges(ctx, graphstore.EdgeFilter{})
+	var remaining []string
+	for _, edge := range edges {
+		remaining = append(remaining, edge.Label+"/"+edge.Source)
+	}
+	if len(remaining) != 2 || !strings.Contains(strings.Join(remaining, ","), "owns/aws-tags") ||
+		!strings.Contains(strings.Join(remaining, ","), "owns/datadog-teams") {
+		t.Errorf("Expected only the AWS edge and the unretracted Datadog edge to remain, got %v", remaining)
+	}
+
+	// Every removed edge leaves an audit record
+	records, _ := store.FindVertices(ctx, graphstore.VertexFilter{Label: graphstore.LabelArchive})
+	if archived != 2 || len(records) != 2 {
+		t.Fatalf("Expected 2 archive records, got %d archived and %+v", archived, records)
+	}
+	record, _, _ := store.GetVertex(ctx, graphstore.ArchiveID(graphstore.EdgeID("team-a", "owns", "payments", "datadog-service-catalog")))
+	if record.Properties[graphstore.ArchiveReasonProperty] != graphstore.ArchiveDeleted || record.Properties[graphstore.ArchivedAtProperty] != "2024-05-01T09:00:00Z" {
+		t.Errorf("Expected the deleted edge to be archived as deleted, got %+v", record)
+	}
+
+	// Nothing to remove is not an error
+	if _, err := removeRelationships(ctx, store, nil, nil, time.Now()); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+// Test the processor output can be queried back from the graph it was written to
+func TestHandleProcessorRequest_WritesGraph(t *testing.T) {
+	ctx, cleanup := common.TestContext("processor-writes-graph-test")
+	defer cleanup()
+
+	store := graphstore.NewMemoryStore()
+	useGraphStore(t, store)
+
+	event := ProcessorEvent{
+		ScraperOutputs: []ScraperOutput{createGitHubCodeownersOutput()},
+	}
+
+	response, err := handleProcessorRequest(ctx, event)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	edges, err := store.FindEdges(ctx, graphstore.EdgeFilter{Source: "github-codeowners"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(edges) == 0 || len(edges) != response.RelationshipCount {
+		t.Fatalf("Expected the %d processed relationships in the graph, got %d", response.RelationshipCount, len(edges))
+	}
+
+	owners, err := store.Neighbors(ctx, edges[0].To, graphstore.In)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(owners) != len(edges) {
+		t.Errorf("Expected every owner to be reachable from the resource, got %+v", owners)
+	}
+}
@@ -0,0 +1,162 @@
+// Package scraperoutput defines the versioned envelope every scraper hands to the relationship processor.
+// The payload is a tagged union selected by Payload.Kind, so the processor reads typed records instead of
+// asserting on untyped maps.
+package scraperoutput
+
+import "time"
+
+// Schema versions of the scraper output contract
+const (
+	// LegacySchemaVersion is the original untyped format: a source, a data map, a confidence and a timestamp
+	LegacySchemaVersion = "1"
+	// SchemaVersion is the typed envelope emitted by current scrapers
+	SchemaVersion = "2"
+)
+
+// Payload kinds; each selects one member of Payload
+const (
+	KindCodeowners    = "codeowners"
+	KindResources     = "resources"
+	KindRelationships = "relationships"
+	KindChanges       = "changes"
+	KindDeletions     = "deletions"
+	KindIdentities    = "identities"
+)
+
+// Identity kinds
+const (
+	IdentityTeam   = "team"
+	IdentityPerson = "person"
+)
+
+// Envelope is the output of one scraper run for one source
+type Envelope struct {
+	SchemaVersion string  `json:"schema_version"`
+	Source        string  `json:"source"`
+	ProducedAt    string  `json:"produced_at"` // RFC3339
+	Confidence    float64 `json:"confidence"`
+	Payload       Payload `json:"payload"`
+
+	// Snapshot is set when the output holds every claim the source still makes within the snapshot's scope,
+	// so claims it no longer contains can be retracted
+	Snapshot *Snapshot `json:"snapshot,omitempty"`
+}
+
+// Snapshot identifies a complete output of a source over one scope, e.g. a repository or a namespace
+// An empty scope covers the claims the source made in outputs without a scope.
+type Snapshot struct {
+	ID    string `json:"id"`
+	Scope string `json:"scope,omitempty"`
+}
+
+// Payload is a tagged union; only the member selected by Kind is read
+type Payload struct {
+	Kind          string            `json:"kind"`
+	Codeowners    []CodeownersEntry `json:"codeowners,omitempty"`
+	Resources     []Resource        `json:"resources,omitempty"`
+	Relationships []Edge            `json:"relationships,omitempty"`
+	Changes       *ChangeSet        `json:"changes,omitempty"`
+	Deletions     []Deletion        `json:"deletions,omitempty"`
+	Identities    []Identity        `json:"identities,omitempty"`
+}
+
+// CodeownersEntry assigns owners to a path in a repository
+type CodeownersEntry struct {
+	Repository string   `json:"repository,omitempty"`
+	Path       string   `json:"path"`
+	Owners     []string `json:"owners"`
+}
+
+// Resource is an infrastructure resource and the owner declared on it
+// Kubernetes resources are named by Kind and Name, cloud resources by ARN
+type Resource struct {
+	Kind      string            `json:"kind,omitempty"`
+	Name      string            `json:"name,omitempty"`
+	Namespace string            `json:"namespace,omitempty"`
+	ARN       string            `json:"arn,omitempty"`
+	Owner     string            `json:"owner,omitempty"`
+	Tags      map[string]string `json:"tags,omitempty"`
+}
+
+// Edge is a pre-typed relationship between two graph entities
+type Edge struct {
+	From       string  `json:"from"`
+	To         string  `json:"to"`
+	Type       string  `json:"type"`
+	Confidence float64 `json:"confidence,omitempty"` // overrides the envelope confidence when set
+}
+
+// ChangeSet is the diff between two snapshots of a source
+type ChangeSet struct {
+	SnapshotID         string        `json:"snapshot_id"`
+	PreviousSnapshotID string        `json:"previous_snapshot_id,omitempty"`
+	Events             []ChangeEvent `json:"events"`
+}
+
+// ChangeEvent is a single entity change between snapshots
+type ChangeEvent struct {
+	Type       string `json:"type"`
+	EntityKind string `json:"entity_kind"`
+	EntityID   string `json:"entity_id,omitempty"`
+	EntityName string `json:"entity_name"`
+	Field      string `json:"field,omitempty"`
+	Before     string `json:"before,omitempty"`
+	After      string `json:"after,omitempty"`
+}
+
+// Deletion is an entity removed at its source
+type Deletion struct {
+	Entity string `json:"entity"` // graph name of the entity
+	ID     string `json:"id,omitempty"`
+	Kind   string `json:"kind,omitempty"`
+}
+
+// Identity is a team or person as a source knows it, with the other names it goes by there
+// Teams are keyed by handle or slug, people by email when the source exposes one and by login otherwise
+type Identity struct {
+	Kind    string   `json:"kind"`
+	Key     string   `json:"key"`
+	Email   string   `json:"email,omitempty"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// New creates an envelope at the current schema version
+func New(source string, confidence float64, producedAt time.Time, payload Payload) Envelope {
+	return Envelope{
+		SchemaVersion: SchemaVersion,
+		Source:        source,
+		ProducedAt:    producedAt.UTC().Format(time.RFC3339),
+		Confidence:    confidence,
+		Payload:       payload,
+	}
+}
+
+// Codeowners creates a codeowners payload
+func Codeowners(entries []CodeownersEntry) Payload {
+	return Payload{Kind: KindCodeowners, Codeowners: entries}
+}
+
+// Resources creates a resources payload
+func Resources(resources []Resource) Payload {
+	return Payload{Kind: KindResources, Resources: resources}
+}
+
+// Relationships creates a relationships payload
+func Relationships(edges []Edge) Payload {
+	return Payload{Kind: KindRelationships, Relationships: edges}
+}
+
+// Changes creates a changes payload
+func Changes(changes ChangeSet) Payload {
+	return Payload{Kind: KindChanges, Changes: &changes}
+}
+
+// Deletions creates a deletions payload
+func Deletions(deletions []Deletion) Payload {
+	return Payload{Kind: KindDeletions, Deletions: deletions}
+}
+
+// Identities creates an identities payload
+func Identities(identities []Identity) Payload {
+	return Payload{Kind: KindIdentities, Identities: identities}
+}
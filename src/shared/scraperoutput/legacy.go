@@ -0,0 +1,219 @@
+package scraperoutput
+
+import "fmt"
+
+// legacyKinds maps the data key of a schema v1 output onto its payload kind, in detection order
+var legacyKinds = []struct {
+	key  string
+	kind string
+}{
+	{"entries", KindCodeowners},
+	{"resources", KindResources},
+	{"relationships", KindRelationships},
+	{"changes", KindChanges},
+	{"deleted", KindDeletions},
+}
+
+// DecodeLegacy converts a schema v1 output into a validated envelope
+// Every value is type-checked, so malformed records become record errors instead of panics
+func DecodeLegacy(source string, data map[string]interface{}, confidence float64, timestamp string) (Envelope, []RecordError) {
+	envelope := Envelope{
+		SchemaVersion: LegacySchemaVersion,
+		Source:        source,
+		ProducedAt:    timestamp,
+		Confidence:    confidence,
+	}
+
+	for _, legacy := range legacyKinds {
+		value, found := data[legacy.key]
+		if !found {
+			continue
+		}
+
+		errs := &recordErrors{source: source, kind: legacy.kind}
+		if records, ok := value.([]interface{}); ok {
+			envelope.Payload = decodeLegacyPayload(legacy.kind, data, records, errs)
+		} else {
+			errs.add(EnvelopeIndex, "data."+legacy.key, fmt.Sprintf("expected an array, got %T", value))
+		}
+
+		validated, validationErrs := Validate(envelope)
+		return validated, append(errs.errs, validationErrs...)
+	}
+
+	return Validate(envelope)
+}
+
+// decodeLegacyPayload decodes and validates the records of one payload kind
+func decodeLegacyPayload(kind string, data map[string]interface{}, records []interface{}, errs *recordErrors) Payload {
+	switch kind {
+	case KindCodeowners:
+		return Codeowners(decodeLegacyRecords(records, errs, decodeLegacyCodeownersEntry, validateCodeownersEntry))
+	case KindResources:
+		return Resources(decodeLegacyRecords(records, errs, decodeLegacyResource, validateResource))
+	case KindRelationships:
+		return Relationships(decodeLegacyRecords(records, errs, decodeLegacyEdge, validateEdge))
+	case KindChanges:
+		fields := legacyFields{record: data, errs: errs, index: EnvelopeIndex}
+		return Changes(ChangeSet{
+			SnapshotID:         fields.string("snapshot_id"),
+			PreviousSnapshotID: fields.string("previous_snapshot_id"),
+			Events:             decodeLegacyRecords(records, errs, decodeLegacyChangeEvent, validateChangeEvent),
+		})
+	}
+	return Deletions(decodeLegacyRecords(records, errs, decodeLegacyDeletion, validateDeletion))
+}
+
+// decodeLegacyRecords decodes and validates each object record
+// Records are validated here rather than by Validate so errors carry their index in the original data
+func decodeLegacyRecords[T any](records []interface{}, errs *recordErrors, decode func(legacyFields) T, validate func(T) (string, string)) []T {
+	var decoded []T
+	for i, value := range records {
+		record, ok := value.(map[string]interface{})
+		if !ok {
+			errs.add(i, "", fmt.Sprintf("expected an object, got %T", value))
+			continue
+		}
+
+		reported := len(errs.errs)
+		result := decode(legacyFields{record: record, errs: errs, index: i})
+		if len(errs.errs) > reported {
+			continue
+		}
+		if field, message := validate(result); message != "" {
+			errs.add(i, field, message)
+			continue
+		}
+		decoded = append(decoded, result)
+	}
+	return decoded
+}
+
+// legacyFields reads typed fields of one record and reports mistyped values
+type legacyFields struct {
+	record map[string]interface{}
+	errs   *recordErrors
+	index  int
+}
+
+// string returns a string field; missing fields are empty
+func (f legacyFields) string(name string) string {
+	value, found := f.record[name]
+	if !found || value == nil {
+		return ""
+	}
+	str, ok := value.(string)
+	if !ok {
+		f.errs.add(f.index, name, fmt.Sprintf("expected a string, got %T", value))
+	}
+	return str
+}
+
+// number returns a numeric field; missing fields are zero
+func (f legacyFields) number(name string) float64 {
+	value, found := f.record[name]
+	if !found || value == nil {
+		return 0
+	}
+	number, ok := value.(float64)
+	if !ok {
+		f.errs.add(f.index, name, fmt.Sprintf("expected a number, got %T", value))
+	}
+	return number
+}
+
+// strings returns an array of strings; missing fields are nil
+func (f legacyFields) strings(name string) []string {
+	value, found := f.record[name]
+	if !found || value == nil {
+		return nil
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		f.errs.add(f.index, name, fmt.Sprintf("expected an array, got %T", value))
+		return nil
+	}
+
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		str, ok := item.(string)
+		if !ok {
+			f.errs.add(f.index, name, fmt.Sprintf("expected strings, got %T", item))
+			return nil
+		}
+		result = append(result, str)
+	}
+	return result
+}
+
+// stringMap returns an object of strings; missing fields are nil
+func (f legacyFields) stringMap(name string) map[string]string {
+	value, found := f.record[name]
+	if !found || value == nil {
+		return nil
+	}
+	items, ok := value.(map[string]interface{})
+	if !ok {
+		f.errs.add(f.index, name, fmt.Sprintf("expected an object, got %T", value))
+		return nil
+	}
+
+	result := make(map[string]string, len(items))
+	for key, item := range items {
+		str, ok := item.(string)
+		if !ok {
+			f.errs.add(f.index, name+"."+key, fmt.Sprintf("expected a string, got %T", item))
+			return nil
+		}
+		result[key] = str
+	}
+	return result
+}
+
+func decodeLegacyCodeownersEntry(f legacyFields) CodeownersEntry {
+	return CodeownersEntry{
+		Repository: f.string("repository"),
+		Path:       f.string("path"),
+		Owners:     f.strings("owners"),
+	}
+}
+
+func decodeLegacyResource(f legacyFields) Resource {
+	return Resource{
+		Kind:      f.string("kind"),
+		Name:      f.string("name"),
+		Namespace: f.string("namespace"),
+		ARN:       f.string("arn"),
+		Owner:     f.string("owner"),
+		Tags:      f.stringMap("tags"),
+	}
+}
+
+func decodeLegacyEdge(f legacyFields) Edge {
+	return Edge{
+		From:       f.string("from"),
+		To:         f.string("to"),
+		Type:       f.string("type"),
+		Confidence: f.number("confidence"),
+	}
+}
+
+func decodeLegacyChangeEvent(f legacyFields) ChangeEvent {
+	return ChangeEvent{
+		Type:       f.string("type"),
+		EntityKind: f.string("entity_kind"),
+		EntityID:   f.string("entity_id"),
+		EntityName: f.string("entity_name"),
+		Field:      f.string("field"),
+		Before:     f.string("before"),
+		After:      f.string("after"),
+	}
+}
+
+func decodeLegacyDeletion(f legacyFields) Deletion {
+	return Deletion{
+		Entity: f.string("entity"),
+		ID:     f.string("id"),
+		Kind:   f.string("kind"),
+	}
+}
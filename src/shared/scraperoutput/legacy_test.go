@@ -0,0 +1,116 @@
+package scraperoutput
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeLegacy_Codeowners(t *testing.T) {
+	envelope, errs := DecodeLegacy("github-codeowners", map[string]interface{}{
+		"entries": []interface{}{
+			map[string]interface{}{"path": "/src/main", "owners": []interface{}{"@user1", "@team/backend"}},
+			map[string]interface{}{"path": 42, "owners": []interface{}{"@user2"}},
+			map[string]interface{}{"path": "/docs", "owners": []interface{}{"@user3", 7}},
+			"not-an-entry",
+			map[string]interface{}{"owners": []interface{}{"@user4"}},
+		},
+	}, 0.8, "2024-05-01T09:30:00Z")
+
+	assert.Equal(t, LegacySchemaVersion, envelope.SchemaVersion)
+	assert.Equal(t, "2024-05-01T09:30:00Z", envelope.ProducedAt)
+	assert.Equal(t, Codeowners([]CodeownersEntry{{Path: "/src/main", Owners: []string{"@user1", "@team/backend"}}}), envelope.Payload)
+
+	require.Len(t, errs, 4)
+	assert.Equal(t, []int{1, 2, 3, 4}, []int{errs[0].Index, errs[1].Index, errs[2].Index, errs[3].Index})
+	assert.Equal(t, "path", errs[0].Field)
+	assert.Equal(t, "expected a string, got int", errs[0].Message)
+	assert.Equal(t, "owners", errs[1].Field)
+	assert.Equal(t, "expected an object, got string", errs[2].Message)
+	assert.Equal(t, "missing", errs[3].Message)
+}
+
+func TestDecodeLegacy_Resources(t *testing.T) {
+	envelope, errs := DecodeLegacy("aws-tags", map[string]interface{}{
+		"resources": []interface{}{
+			map[string]interface{}{"arn": "arn:aws:s3:::my-bucket", "tags": map[string]interface{}{"Owner": "team-data"}},
+			map[string]interface{}{"arn": "arn:aws:s3:::other", "tags": map[string]interface{}{"Cost": 12.5}},
+			map[string]interface{}{"kind": "Deployment", "name": "web-app", "owner": "team-backend"},
+		},
+	}, 0.9, "")
+
+	assert.Equal(t, []Resource{
+		{ARN: "arn:aws:s3:::my-bucket", Tags: map[string]string{"Owner": "team-data"}},
+		{Kind: "Deployment", Name: "web-app", Owner: "team-backend"},
+	}, envelope.Payload.Resources)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "tags.Cost", errs[0].Field)
+}
+
+func TestDecodeLegacy_Relationships(t *testing.T) {
+	envelope, errs := DecodeLegacy("datadog-monitors", map[string]interface{}{
+		"relationships": []interface{}{
+			map[string]interface{}{"from": "pagerduty:checkout", "to": "checkout", "type": "monitors", "confidence": 0.85},
+			map[string]interface{}{"from": "checkout", "to": "billing", "type": "depends_on", "confidence": "high"},
+			map[string]interface{}{"from": "checkout", "to": "billing"},
+		},
+	}, 1.0, "")
+
+	assert.Equal(t, []Edge{{From: "pagerduty:checkout", To: "checkout", Type: "monitors", Confidence: 0.85}}, envelope.Payload.Relationships)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "confidence", errs[0].Field)
+	assert.Equal(t, "type", errs[1].Field)
+}
+
+func TestDecodeLegacy_ChangesAndDeletions(t *testing.T) {
+	changes, errs := DecodeLegacy("datadog-changes", map[string]interface{}{
+		"snapshot_id":          "run-2",
+		"previous_snapshot_id": "run-1",
+		"changes": []interface{}{
+			map[string]interface{}{"type": "member_joined", "entity_kind": "team", "entity_name": "payments", "after": "ana@example.com"},
+		},
+	}, 0.9, "")
+
+	assert.Empty(t, errs)
+	assert.Equal(t, Changes(ChangeSet{
+		SnapshotID:         "run-2",
+		PreviousSnapshotID: "run-1",
+		Events:             []ChangeEvent{{Type: "member_joined", EntityKind: "team", EntityName: "payments", After: "ana@example.com"}},
+	}), changes.Payload)
+
+	deletions, errs := DecodeLegacy("datadog-deletions", map[string]interface{}{
+		"deleted": []interface{}{
+			map[string]interface{}{"entity": "payments", "id": "team-1", "kind": "team"},
+			map[string]interface{}{"id": "team-2", "kind": "team"},
+		},
+	}, 1.0, "")
+
+	assert.Equal(t, []Deletion{{Entity: "payments", ID: "team-1", Kind: "team"}}, deletions.Payload.Deletions)
+	require.Len(t, errs, 1)
+	assert.Equal(t, 1, errs[0].Index)
+}
+
+func TestDecodeLegacy_MalformedData(t *testing.T) {
+	t.Run("records are not an array", func(t *testing.T) {
+		envelope, errs := DecodeLegacy("github-codeowners", map[string]interface{}{"entries": "invalid"}, 0.8, "")
+
+		assert.Equal(t, Payload{}, envelope.Payload)
+		require.Len(t, errs, 1)
+		assert.Equal(t, EnvelopeIndex, errs[0].Index)
+		assert.Equal(t, "data.entries", errs[0].Field)
+	})
+
+	t.Run("no known payload", func(t *testing.T) {
+		envelope, errs := DecodeLegacy("test-source", map[string]interface{}{"test": "data"}, 0.8, "")
+
+		assert.Equal(t, Payload{}, envelope.Payload)
+		assert.Empty(t, errs)
+	})
+
+	t.Run("nil data", func(t *testing.T) {
+		_, errs := DecodeLegacy("test-source", nil, 0.8, "")
+
+		assert.Empty(t, errs)
+	})
+}
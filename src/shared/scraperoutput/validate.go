@@ -0,0 +1,186 @@
+package scraperoutput
+
+import (
+	"fmt"
+	"time"
+)
+
+// EnvelopeIndex is the RecordError index of problems with the envelope rather than a record
+const EnvelopeIndex = -1
+
+// RecordError reports a record that failed to decode or validate and was dropped
+type RecordError struct {
+	Source  string `json:"source"`
+	Kind    string `json:"kind,omitempty"`
+	Index   int    `json:"index"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e RecordError) Error() string {
+	if e.Index == EnvelopeIndex {
+		return fmt.Sprintf("%s: %s: %s", e.Source, e.Field, e.Message)
+	}
+	return fmt.Sprintf("%s: %s[%d].%s: %s", e.Source, e.Kind, e.Index, e.Field, e.Message)
+}
+
+// recordErrors collects the errors of one envelope
+type recordErrors struct {
+	source string
+	kind   string
+	errs   []RecordError
+}
+
+func (c *recordErrors) add(index int, field, message string) {
+	c.errs = append(c.errs, RecordError{Source: c.source, Kind: c.kind, Index: index, Field: field, Message: message})
+}
+
+// Validate checks an envelope and drops the records that fail validation
+// An envelope without a source or with an unknown schema version or payload kind keeps no records;
+// an out-of-range confidence is clamped to [0, 1], and a snapshot only stands when no record was dropped
+func Validate(envelope Envelope) (Envelope, []RecordError) {
+	errs := &recordErrors{source: envelope.Source, kind: envelope.Payload.Kind}
+
+	if envelope.Source == "" {
+		errs.add(EnvelopeIndex, "source", "missing")
+		envelope.Payload = Payload{}
+		envelope.Snapshot = nil
+		return envelope, errs.errs
+	}
+	if envelope.SchemaVersion != SchemaVersion && envelope.SchemaVersion != LegacySchemaVersion {
+		errs.add(EnvelopeIndex, "schema_version", fmt.Sprintf("unsupported version %q", envelope.SchemaVersion))
+		envelope.Payload = Payload{}
+		envelope.Snapshot = nil
+		return envelope, errs.errs
+	}
+	if envelope.Confidence < 0 || envelope.Confidence > 1 {
+		errs.add(EnvelopeIndex, "confidence", fmt.Sprintf("%v is outside [0, 1]", envelope.Confidence))
+		envelope.Confidence = clamp(envelope.Confidence)
+	}
+	if envelope.ProducedAt != "" {
+		if _, err := time.Parse(time.RFC3339, envelope.ProducedAt); err != nil {
+			errs.add(EnvelopeIndex, "produced_at", "not an RFC3339 timestamp")
+		}
+	}
+
+	reported := len(errs.errs)
+	envelope.Payload = validatePayload(envelope.Payload, errs)
+
+	// A snapshot missing records would retract the claims they made, so it only stands when every record is valid
+	if envelope.Snapshot != nil {
+		switch {
+		case envelope.Snapshot.ID == "":
+			errs.add(EnvelopeIndex, "snapshot.id", "missing")
+			envelope.Snapshot = nil
+		case len(errs.errs) > reported:
+			errs.add(EnvelopeIndex, "snapshot", "records were dropped, so the output is not a complete snapshot")
+			envelope.Snapshot = nil
+		}
+	}
+	return envelope, errs.errs
+}
+
+// validatePayload keeps the valid records of the member selected by Kind
+func validatePayload(payload Payload, errs *recordErrors) Payload {
+	switch payload.Kind {
+	case "":
+		return Payload{}
+	case KindCodeowners:
+		return Codeowners(keepValid(payload.Codeowners, errs, validateCodeownersEntry))
+	case KindResources:
+		return Resources(keepValid(payload.Resources, errs, validateResource))
+	case KindRelationships:
+		return Relationships(keepValid(payload.Relationships, errs, validateEdge))
+	case KindChanges:
+		if payload.Changes == nil {
+			return Changes(ChangeSet{})
+		}
+		changes := *payload.Changes
+		changes.Events = keepValid(changes.Events, errs, validateChangeEvent)
+		return Changes(changes)
+	case KindDeletions:
+		return Deletions(keepValid(payload.Deletions, errs, validateDeletion))
+	case KindIdentities:
+		return Identities(keepValid(payload.Identities, errs, validateIdentity))
+	}
+
+	errs.add(EnvelopeIndex, "payload.kind", fmt.Sprintf("unknown kind %q", payload.Kind))
+	return Payload{}
+}
+
+// keepValid returns the records passing validate; validate reports the failing field and reason
+func keepValid[T any](records []T, errs *recordErrors, validate func(T) (string, string)) []T {
+	var kept []T
+	for i, record := range records {
+		if field, message := validate(record); message != "" {
+			errs.add(i, field, message)
+			continue
+		}
+		kept = append(kept, record)
+	}
+	return kept
+}
+
+func validateCodeownersEntry(entry CodeownersEntry) (string, string) {
+	if entry.Path == "" {
+		return "path", "missing"
+	}
+	for _, owner := range entry.Owners {
+		if owner == "" {
+			return "owners", "empty owner"
+		}
+	}
+	return "", ""
+}
+
+func validateResource(resource Resource) (string, string) {
+	if resource.Name == "" && resource.ARN == "" {
+		return "name", "resource has neither a name nor an ARN"
+	}
+	return "", ""
+}
+
+func validateEdge(edge Edge) (string, string) {
+	switch {
+	case edge.From == "":
+		return "from", "missing"
+	case edge.To == "":
+		return "to", "missing"
+	case edge.Type == "":
+		return "type", "missing"
+	case edge.Confidence < 0 || edge.Confidence > 1:
+		return "confidence", fmt.Sprintf("%v is outside [0, 1]", edge.Confidence)
+	}
+	return "", ""
+}
+
+func validateChangeEvent(event ChangeEvent) (string, string) {
+	switch {
+	case event.Type == "":
+		return "type", "missing"
+	case event.EntityName == "":
+		return "entity_name", "missing"
+	}
+	return "", ""
+}
+
+func validateDeletion(deletion Deletion) (string, string) {
+	if deletion.Entity == "" {
+		return "entity", "missing"
+	}
+	return "", ""
+}
+
+func validateIdentity(identity Identity) (string, string) {
+	switch {
+	case identity.Kind != IdentityTeam && identity.Kind != IdentityPerson:
+		return "kind", fmt.Sprintf("unknown kind %q", identity.Kind)
+	case identity.Key == "":
+		return "key", "missing"
+	}
+	return "", ""
+}
+
+func clamp(confidence float64) float64 {
+	return min(max(confidence, 0), 1)
+}
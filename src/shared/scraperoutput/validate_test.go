@@ -0,0 +1,168 @@
+package scraperoutput
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	producedAt := time.Date(2024, 5, 1, 9, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	envelope := New("datadog-apm", 0.8, producedAt, Relationships([]Edge{{From: "checkout", To: "billing", Type: "depends_on"}}))
+
+	assert.Equal(t, SchemaVersion, envelope.SchemaVersion)
+	assert.Equal(t, "2024-05-01T07:30:00Z", envelope.ProducedAt)
+	assert.Equal(t, KindRelationships, envelope.Payload.Kind)
+}
+
+func TestEnvelope_JSONRoundTrip(t *testing.T) {
+	envelope := New("datadog-changes", 0.9, time.Now(), Changes(ChangeSet{
+		SnapshotID: "run-2",
+		Events:     []ChangeEvent{{Type: "member_joined", EntityKind: "team", EntityName: "payments", After: "ana@example.com"}},
+	}))
+
+	encoded, err := json.Marshal(envelope)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"schema_version":"2"`)
+	assert.Contains(t, string(encoded), `"kind":"changes"`)
+	assert.NotContains(t, string(encoded), `"relationships"`)
+
+	var decoded Envelope
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, envelope, decoded)
+}
+
+func TestValidate_DropsInvalidRecords(t *testing.T) {
+	envelope := New("datadog-service-catalog", 0.9, time.Now(), Relationships([]Edge{
+		{From: "checkout", To: "billing", Type: "depends_on"},
+		{From: "checkout", Type: "depends_on"},
+		{From: "payments", To: "checkout", Type: "owns", Confidence: 1.5},
+	}))
+
+	validated, errs := Validate(envelope)
+
+	assert.Equal(t, []Edge{{From: "checkout", To: "billing", Type: "depends_on"}}, validated.Payload.Relationships)
+	assert.Equal(t, []RecordError{
+		{Source: "datadog-service-catalog", Kind: KindRelationships, Index: 1, Field: "to", Message: "missing"},
+		{Source: "datadog-service-catalog", Kind: KindRelationships, Index: 2, Field: "confidence", Message: "1.5 is outside [0, 1]"},
+	}, errs)
+	assert.Equal(t, "datadog-service-catalog: relationships[1].to: missing", errs[0].Error())
+}
+
+func TestValidate_RecordKinds(t *testing.T) {
+	testCases := []struct {
+		name    string
+		payload Payload
+		field   string
+	}{
+		{"codeowners without path", Codeowners([]CodeownersEntry{{Owners: []string{"@team"}}}), "path"},
+		{"codeowners with empty owner", Codeowners([]CodeownersEntry{{Path: "/src", Owners: []string{""}}}), "owners"},
+		{"resource without identity", Resources([]Resource{{Owner: "team-data"}}), "name"},
+		{"change without entity", Changes(ChangeSet{Events: []ChangeEvent{{Type: "entity_removed"}}}), "entity_name"},
+		{"deletion without entity", Deletions([]Deletion{{ID: "team-2"}}), "entity"},
+		{"identity of unknown kind", Identities([]Identity{{Kind: "group", Key: "payments"}}), "kind"},
+		{"identity without key", Identities([]Identity{{Kind: IdentityPerson, Email: "ana@example.com"}}), "key"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, errs := Validate(New("test-source", 0.5, time.Now(), tc.payload))
+
+			require.Len(t, errs, 1)
+			assert.Equal(t, 0, errs[0].Index)
+			assert.Equal(t, tc.field, errs[0].Field)
+		})
+	}
+}
+
+func TestValidate_Envelope(t *testing.T) {
+	t.Run("missing source keeps no records", func(t *testing.T) {
+		validated, errs := Validate(New("", 0.5, time.Now(), Deletions([]Deletion{{Entity: "payments"}})))
+
+		assert.Empty(t, validated.Payload.Deletions)
+		assert.Equal(t, []RecordError{{Index: EnvelopeIndex, Field: "source", Message: "missing", Kind: KindDeletions}}, errs)
+	})
+
+	t.Run("unsupported schema version keeps no records", func(t *testing.T) {
+		envelope := New("datadog-deletions", 1, time.Now(), Deletions([]Deletion{{Entity: "payments"}}))
+		envelope.SchemaVersion = "9"
+
+		validated, errs := Validate(envelope)
+
+		assert.Empty(t, validated.Payload.Deletions)
+		require.Len(t, errs, 1)
+		assert.Equal(t, "schema_version", errs[0].Field)
+	})
+
+	t.Run("unknown kind keeps no records", func(t *testing.T) {
+		validated, errs := Validate(New("datadog-deletions", 1, time.Now(), Payload{Kind: "metrics"}))
+
+		assert.Equal(t, Payload{}, validated.Payload)
+		require.Len(t, errs, 1)
+		assert.Equal(t, "payload.kind", errs[0].Field)
+	})
+
+	t.Run("confidence is clamped", func(t *testing.T) {
+		validated, errs := Validate(New("aws-tags", 1.5, time.Now(), Resources(nil)))
+
+		assert.Equal(t, 1.0, validated.Confidence)
+		require.Len(t, errs, 1)
+		assert.Equal(t, "confidence", errs[0].Field)
+	})
+
+	t.Run("invalid timestamp is reported", func(t *testing.T) {
+		envelope := New("aws-tags", 0.9, time.Now(), Resources(nil))
+		envelope.ProducedAt = "yesterday"
+
+		_, errs := Validate(envelope)
+
+		require.Len(t, errs, 1)
+		assert.Equal(t, "produced_at", errs[0].Field)
+	})
+}
+
+func TestValidate_Snapshot(t *testing.T) {
+	snapshot := func(envelope Envelope, id string) Envelope {
+		envelope.Snapshot = &Snapshot{ID: id, Scope: "acme/payments"}
+		return envelope
+	}
+
+	t.Run("complete snapshot stands", func(t *testing.T) {
+		validated, errs := Validate(snapshot(New("github-codeowners", 0.8, time.Now(), Codeowners([]CodeownersEntry{{Path: "/src", Owners: []string{"@team"}}})), "run-2"))
+
+		assert.Empty(t, errs)
+		assert.Equal(t, &Snapshot{ID: "run-2", Scope: "acme/payments"}, validated.Snapshot)
+	})
+
+	t.Run("snapshot without ID is dropped", func(t *testing.T) {
+		validated, errs := Validate(snapshot(New("github-codeowners", 0.8, time.Now(), Codeowners(nil)), ""))
+
+		assert.Nil(t, validated.Snapshot)
+		require.Len(t, errs, 1)
+		assert.Equal(t, "snapshot.id", errs[0].Field)
+	})
+
+	t.Run("snapshot missing records is dropped", func(t *testing.T) {
+		validated, errs := Validate(snapshot(New("github-codeowners", 0.8, time.Now(), Codeowners([]CodeownersEntry{
+			{Path: "/src", Owners: []string{"@team"}},
+			{Owners: []string{"@team"}},
+		})), "run-2"))
+
+		assert.Nil(t, validated.Snapshot)
+		assert.Len(t, validated.Payload.Codeowners, 1)
+		require.Len(t, errs, 2)
+		assert.Equal(t, "snapshot", errs[1].Field)
+	})
+
+	t.Run("snapshot of a rejected envelope is dropped", func(t *testing.T) {
+		envelope := snapshot(New("github-codeowners", 0.8, time.Now(), Codeowners(nil)), "run-2")
+		envelope.SchemaVersion = "9"
+
+		validated, _ := Validate(envelope)
+		assert.Nil(t, validated.Snapshot)
+	})
+}
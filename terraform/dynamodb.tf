@@ -0,0 +1,80 @@
+# Relationship evidence table: the latest claims of every source, so the processor only rescores the resources
+# a run touches. Claims are partitioned by target and sorted by claim key; the from-index finds the claims an
+# entity made and the scope-index those a source made within a snapshot scope. The identity aliases claims were
+# resolved with are kept under a target of their own, outside both indexes.
+resource "aws_dynamodb_table" "evidence" {
+  name         = "${local.name_prefix}-relationship-evidence"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "target"
+  range_key    = "claim_key"
+
+  attribute {
+    name = "target"
+    type = "S"
+  }
+
+  attribute {
+    name = "claim_key"
+    type = "S"
+  }
+
+  attribute {
+    name = "from"
+    type = "S"
+  }
+
+  attribute {
+    name = "source_scope"
+    type = "S"
+  }
+
+  global_secondary_index {
+    name            = "from-index"
+    hash_key        = "from"
+    projection_type = "ALL"
+  }
+
+  global_secondary_index {
+    name            = "scope-index"
+    hash_key        = "source_scope"
+    projection_type = "ALL"
+  }
+
+  point_in_time_recovery {
+    enabled = true
+  }
+
+  server_side_encryption {
+    enabled = true
+  }
+
+  tags = merge(local.common_tags, {
+    Name     = "${local.name_prefix}-relationship-evidence"
+    Function = "processor"
+    Type     = "evidence-store"
+  })
+}
+
+# The processor replaces evidence in batches and reads it through the table and both indexes
+resource "aws_iam_role_policy" "processor_evidence" {
+  name = "${local.name_prefix}-processor-evidence"
+  role = reverse(split("/", local.iam_roles.lambda_processor))[0]
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Sid    = "EvidenceTableAccess"
+        Effect = "Allow"
+        Action = [
+          "dynamodb:BatchWriteItem",
+          "dynamodb:Query"
+        ]
+        Resource = [
+          aws_dynamodb_table.evidence.arn,
+          "${aws_dynamodb_table.evidence.arn}/index/*"
+        ]
+      }
+    ]
+  })
+}
@@ -289,6 +289,7 @@ module "processor_lambda" {
     S3_BUCKET        = module.s3_bucket.s3_bucket_id
     NEPTUNE_ENDPOINT = aws_neptune_cluster.main.endpoint
     NEPTUNE_PORT     = "8182"
+    EVIDENCE_TABLE   = aws_dynamodb_table.evidence.name
     LOG_LEVEL        = "INFO"
   }
 